@@ -15,7 +15,6 @@ import (
 	"github.com/kthcloud/go-deploy/pkg/config"
 	"github.com/kthcloud/go-deploy/pkg/db"
 	migrator "github.com/kthcloud/go-deploy/pkg/db/migrate"
-	"github.com/kthcloud/go-deploy/pkg/db/resources/job_repo"
 	"github.com/kthcloud/go-deploy/pkg/intializer"
 	"github.com/kthcloud/go-deploy/pkg/log"
 	"github.com/kthcloud/go-deploy/pkg/metrics"
@@ -63,7 +62,6 @@ func Create(opts *Options) *App {
 		{Name: "Clean up old tests", Task: intializer.CleanUpOldTests},
 		{Name: "Synchronize VM ports", Task: intializer.SynchronizeVmPorts},
 		{Name: "Run migrations", Task: migrator.Migrate},
-		{Name: "Reset running jobs", Task: func() error { return job_repo.New().ResetRunning() }},
 		{Name: "Ensure system deployments exists", Task: intializer.EnsureSystemDeploymentsExists},
 		{Name: "Ensure test users exist", Task: intializer.EnsureTestUsersExist},
 	}
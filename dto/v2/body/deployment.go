@@ -27,15 +27,33 @@ type DeploymentRead struct {
 	InternalPort    int               `json:"internalPort"`
 	InternalPorts   []int             `json:"internalPorts,omitempty"`
 	Image           *string           `json:"image,omitempty"`
+	Git             *DeploymentGit    `json:"git,omitempty"`
 	HealthCheckPath *string           `json:"healthCheckPath,omitempty"`
 	CustomDomain    *CustomDomainRead `json:"customDomain,omitempty"`
+	Probes          *Probes           `json:"probes,omitempty"`
+	Autoscaling     *AutoscalingRead  `json:"autoscaling,omitempty"`
+	AutoUpdate      *AutoUpdateRead   `json:"autoUpdate,omitempty"`
 	Visibility      string            `json:"visibility"`
+	Sidecars        []SidecarRead     `json:"sidecars,omitempty"`
 
 	NeverStale bool `json:"neverStale" bson:"neverStale" binding:"omitempty,boolean"`
 
 	// Deprecated: Use Visibility instead.
 	Private bool `json:"private"`
 
+	// Paused is set if the deployment is paused using the pause command.
+	Paused bool `json:"paused"`
+	// ScaleOverride is set if the deployment is temporarily scaled using the scale command.
+	ScaleOverride *ScaleOverrideRead `json:"scaleOverride,omitempty"`
+	// ImageWebhook is set if the deployment has an image webhook for an external registry.
+	ImageWebhook *ImageWebhookRead `json:"imageWebhook,omitempty"`
+	// Strategy is how updates of the deployment are rolled out.
+	Strategy RolloutStrategyRead `json:"strategy"`
+	// Rollout is set while an update is rolled out with the blue/green or canary strategy.
+	Rollout *RolloutRead `json:"rollout,omitempty"`
+	// Schedule is set if the deployment is scaled on a schedule.
+	Schedule *ScalingScheduleRead `json:"schedule,omitempty"`
+
 	Status        string         `json:"status"`
 	Error         *string        `json:"error,omitempty"`
 	ReplicaStatus *ReplicaStatus `json:"replicaStatus,omitempty"`
@@ -51,7 +69,7 @@ type DeploymentRead struct {
 }
 
 type DeploymentCreate struct {
-	Name string `json:"name" bson:"name" binding:"required,rfc1035,min=3,max=30,deployment_name"`
+	Name string `json:"name" bson:"name" binding:"required,rfc1035,min=3,max=30,new_deployment_name"`
 
 	CpuCores *float64        `json:"cpuCores,omitempty" bson:"cpuCores,omitempty" binding:"omitempty,min=0.1"`
 	RAM      *float64        `json:"ram,omitempty" bson:"ram,omitempty" binding:"omitempty,min=0.1"`
@@ -64,17 +82,36 @@ type DeploymentCreate struct {
 	Args         []string `json:"args" bson:"args" binding:"omitempty,min=0,max=100,dive,min=0,max=100"`
 	Visibility   string   `json:"visibility" bson:"visibility" binding:"omitempty,oneof=public private auth"`
 
+	// Sidecars are additional apps that run next to the main app, such as a cache or a worker process.
+	// Each sidecar is run as its own K8s deployment and is reachable within the namespace by its service name.
+	Sidecars []Sidecar `json:"sidecars,omitempty" bson:"sidecars,omitempty" binding:"omitempty,min=0,max=10,unique=Name,dive"`
+
 	// Boolean to make deployment never get disabled, despite being stale
 	NeverStale bool `json:"neverStale" bson:"neverStale" binding:"omitempty,boolean"`
 
 	// Deprecated: Use Visibility instead.
 	Private bool `json:"private" bson:"private" binding:"omitempty,boolean"`
 
-	Image           *string `json:"image,omitempty" bson:"image,omitempty" binding:"omitempty,min=1,max=1000"`
-	HealthCheckPath *string `json:"healthCheckPath" bson:"healthCheckPath,omitempty" binding:"omitempty,min=0,max=1000,health_check_path"`
+	Image *string `json:"image,omitempty" bson:"image,omitempty" binding:"omitempty,min=1,max=1000"`
+	// Git makes the deployment a git deployment, which is built from the repository by go-deploy.
+	// It cannot be combined with Image.
+	Git             *DeploymentGit `json:"git,omitempty" bson:"git,omitempty" binding:"omitempty,excluded_with=Image"`
+	HealthCheckPath *string        `json:"healthCheckPath" bson:"healthCheckPath,omitempty" binding:"omitempty,min=0,max=1000,health_check_path"`
 	// CustomDomain is the domain that the deployment will be available on.
 	// The max length is set to 243 to allow for a subdomain when confirming the domain.
 	CustomDomain *string `json:"customDomain,omitempty" bson:"customDomain,omitempty" binding:"omitempty,domain_name"`
+	// Probes are the health checks K8s uses to decide when to restart the deployment and when it is ready to receive traffic.
+	Probes *Probes `json:"probes,omitempty" bson:"probes,omitempty" binding:"omitempty"`
+	// Autoscaling is the autoscaling policy for the deployment.
+	// If it is not set, the deployment scales between 1 and Replicas replicas.
+	Autoscaling *Autoscaling `json:"autoscaling,omitempty" bson:"autoscaling,omitempty" binding:"omitempty"`
+	// AutoUpdate makes go-deploy poll the registry of the image and redeploy the deployment when the image changes.
+	// It is only used for prebuilt deployments.
+	AutoUpdate *AutoUpdate `json:"autoUpdate,omitempty" bson:"autoUpdate,omitempty" binding:"omitempty"`
+	// Strategy is how updates of the deployment are rolled out. Defaults to rolling.
+	Strategy *RolloutStrategy `json:"strategy,omitempty" bson:"strategy,omitempty" binding:"omitempty"`
+	// Schedule scales the deployment on a schedule, such as to 0 replicas at night.
+	Schedule *ScalingSchedule `json:"schedule,omitempty" bson:"schedule,omitempty" binding:"omitempty"`
 
 	// Zone is the zone that the deployment will be created in.
 	// If the zone is not set, the deployment will be created in the default zone.
@@ -95,21 +132,210 @@ type DeploymentUpdate struct {
 	Args         *[]string `json:"args,omitempty" bson:"args,omitempty" binding:"omitempty,min=0,max=100,dive,min=0,max=100"`
 	Visibility   *string   `json:"visibility" bson:"visibility" binding:"omitempty,oneof=public private auth"`
 
+	// Sidecars replaces the full set of sidecars for the deployment.
+	// Sidecars not included in the list are removed.
+	Sidecars *[]Sidecar `json:"sidecars,omitempty" bson:"sidecars,omitempty" binding:"omitempty,min=0,max=10,unique=Name,dive"`
+
 	NeverStale *bool `json:"neverStale,omitempty" bson:"neverStale" binding:"omitempty,boolean"`
 
 	// Deprecated: Use Visibility instead.
 	Private *bool `json:"private,omitempty" bson:"private,omitempty" binding:"omitempty,boolean"`
 
-	Image           *string `json:"image,omitempty" bson:"image,omitempty" binding:"omitempty,min=1,max=1000"`
-	HealthCheckPath *string `json:"healthCheckPath,omitempty" bson:"healthCheckPath,omitempty" binding:"omitempty,min=0,max=1000,health_check_path"`
+	Image *string `json:"image,omitempty" bson:"image,omitempty" binding:"omitempty,min=1,max=1000"`
+	// Git replaces the repository of a git deployment, and triggers a new build.
+	// It is ignored for other deployments.
+	Git             *DeploymentGit `json:"git,omitempty" bson:"git,omitempty" binding:"omitempty"`
+	HealthCheckPath *string        `json:"healthCheckPath,omitempty" bson:"healthCheckPath,omitempty" binding:"omitempty,min=0,max=1000,health_check_path"`
 	// CustomDomain is the domain that the deployment will be available on.
 	// The max length is set to 243 to allow for a subdomain when confirming the domain.
 	CustomDomain *string `json:"customDomain,omitempty" bson:"customDomain,omitempty" binding:"omitempty,domain_name"`
+	// Probes replaces all probes for the deployment.
+	// Omitted probes are removed, so an empty object removes all probes.
+	Probes *Probes `json:"probes,omitempty" bson:"probes,omitempty" binding:"omitempty"`
+	// Autoscaling replaces the autoscaling policy for the deployment.
+	// Omitted fields are reset to their defaults.
+	Autoscaling *Autoscaling `json:"autoscaling,omitempty" bson:"autoscaling,omitempty" binding:"omitempty"`
+	// AutoUpdate replaces the auto-update policy for the deployment.
+	// The policy none turns off auto-update. It is only used for prebuilt deployments.
+	AutoUpdate *AutoUpdate `json:"autoUpdate,omitempty" bson:"autoUpdate,omitempty" binding:"omitempty"`
+	// Strategy replaces how updates of the deployment are rolled out.
+	// It does not affect a rollout that is already active.
+	Strategy *RolloutStrategy `json:"strategy,omitempty" bson:"strategy,omitempty" binding:"omitempty"`
+	// Schedule replaces the scaling schedule of the deployment.
+	// A schedule without windows removes it, and restores the replicas if a window is active.
+	Schedule *ScalingSchedule `json:"schedule,omitempty" bson:"schedule,omitempty" binding:"omitempty"`
+}
+
+// DeploymentGit is the git repository that a git deployment is built from.
+type DeploymentGit struct {
+	// URL is the HTTP(S) URL of the repository, such as https://github.com/kthcloud/go-deploy.git.
+	URL string `json:"url" bson:"url" binding:"required,http_url,max=1000"`
+	// Branch is the branch to build. It defaults to main.
+	Branch string `json:"branch,omitempty" bson:"branch,omitempty" binding:"omitempty,min=1,max=255,excludesall= "`
+	// DockerfilePath is the path of the Dockerfile in the repository. It defaults to Dockerfile.
+	DockerfilePath string `json:"dockerfilePath,omitempty" bson:"dockerfilePath,omitempty" binding:"omitempty,min=1,max=1000,excludesall= "`
+}
+
+type Sidecar struct {
+	Name  string `json:"name" bson:"name" binding:"required,rfc1035,min=1,max=20,ne=main"`
+	Image string `json:"image" bson:"image" binding:"required,min=1,max=1000"`
+
+	CpuCores *float64 `json:"cpuCores,omitempty" bson:"cpuCores,omitempty" binding:"omitempty,min=0.1"`
+	RAM      *float64 `json:"ram,omitempty" bson:"ram,omitempty" binding:"omitempty,min=0.1"`
+	Replicas *int     `json:"replicas,omitempty" bson:"replicas,omitempty" binding:"omitempty,min=0,max=100"`
+
+	// InternalPorts are the ports exposed by the sidecar's service.
+	// If no ports are specified, no service is created for the sidecar.
+	InternalPorts []int    `json:"internalPorts,omitempty" bson:"internalPorts,omitempty" binding:"omitempty,min=0,max=10,unique,dive,min=1,max=65535"`
+	Envs          []Env    `json:"envs,omitempty" bson:"envs,omitempty" binding:"omitempty,env_list,min=0,max=1000,dive"`
+	Volumes       []Volume `json:"volumes,omitempty" bson:"volumes,omitempty" binding:"omitempty,min=0,max=100,dive"`
+	Args          []string `json:"args,omitempty" bson:"args,omitempty" binding:"omitempty,min=0,max=100,dive,min=0,max=100"`
+}
+
+type SidecarRead struct {
+	Name  string          `json:"name"`
+	Image string          `json:"image"`
+	Specs DeploymentSpecs `json:"specs"`
+
+	InternalPorts []int    `json:"internalPorts"`
+	Envs          []Env    `json:"envs"`
+	Volumes       []Volume `json:"volumes"`
+	Args          []string `json:"args"`
+}
+
+type Probes struct {
+	// Liveness is used to decide when to restart the container.
+	Liveness *Probe `json:"liveness,omitempty" bson:"liveness,omitempty" binding:"omitempty"`
+	// Readiness is used to decide when the container is ready to receive traffic.
+	Readiness *Probe `json:"readiness,omitempty" bson:"readiness,omitempty" binding:"omitempty"`
+	// Startup is used to hold off the other probes until the container has started.
+	Startup *Probe `json:"startup,omitempty" bson:"startup,omitempty" binding:"omitempty"`
+}
+
+type Probe struct {
+	// Type is the kind of check to perform, either http, tcp or exec.
+	Type string `json:"type" bson:"type" binding:"required,oneof=http tcp exec"`
+	// Path is the path to request for http probes.
+	Path *string `json:"path,omitempty" bson:"path,omitempty" binding:"required_if=Type http,omitempty,min=1,max=1000,health_check_path"`
+	// Port is the port to check for http and tcp probes.
+	// If not set, the internal port of the deployment is used.
+	Port *int `json:"port,omitempty" bson:"port,omitempty" binding:"omitempty,min=1,max=65535"`
+	// Command is the command to run for exec probes.
+	Command []string `json:"command,omitempty" bson:"command,omitempty" binding:"required_if=Type exec,omitempty,min=1,max=100,dive,min=1,max=1000"`
+
+	InitialDelaySeconds *int `json:"initialDelaySeconds,omitempty" bson:"initialDelaySeconds,omitempty" binding:"omitempty,min=0,max=3600"`
+	PeriodSeconds       *int `json:"periodSeconds,omitempty" bson:"periodSeconds,omitempty" binding:"omitempty,min=1,max=3600"`
+	TimeoutSeconds      *int `json:"timeoutSeconds,omitempty" bson:"timeoutSeconds,omitempty" binding:"omitempty,min=1,max=3600"`
+	FailureThreshold    *int `json:"failureThreshold,omitempty" bson:"failureThreshold,omitempty" binding:"omitempty,min=1,max=100"`
+}
+
+type Autoscaling struct {
+	// Enabled turns autoscaling on or off. Defaults to true.
+	// A deployment without autoscaling runs a fixed number of replicas, set by Replicas.
+	Enabled *bool `json:"enabled,omitempty" bson:"enabled,omitempty" binding:"omitempty,boolean"`
+	// MinReplicas is the lowest number of replicas the deployment is scaled down to. Defaults to 1.
+	MinReplicas *int `json:"minReplicas,omitempty" bson:"minReplicas,omitempty" binding:"omitempty,min=1,max=100"`
+	// MaxReplicas is the highest number of replicas the deployment is scaled up to. Defaults to Replicas.
+	// Quota is checked against this value.
+	MaxReplicas *int `json:"maxReplicas,omitempty" bson:"maxReplicas,omitempty" binding:"omitempty,min=1,max=100"`
+	// CpuTarget is the target average CPU utilization in percent of the requested CPU.
+	CpuTarget *int `json:"cpuTarget,omitempty" bson:"cpuTarget,omitempty" binding:"omitempty,min=1,max=1000"`
+	// MemoryTarget is the target average memory utilization in percent of the requested memory.
+	MemoryTarget *int `json:"memoryTarget,omitempty" bson:"memoryTarget,omitempty" binding:"omitempty,min=1,max=1000"`
+	// ScaleDownStabilizationSeconds is how long the load must stay low before the deployment is scaled down.
+	// If it is not set, the K8s default of 300 seconds is used.
+	ScaleDownStabilizationSeconds *int `json:"scaleDownStabilizationSeconds,omitempty" bson:"scaleDownStabilizationSeconds,omitempty" binding:"omitempty,min=0,max=3600"`
+}
+
+type AutoscalingRead struct {
+	Enabled     bool `json:"enabled"`
+	MinReplicas int  `json:"minReplicas"`
+	MaxReplicas int  `json:"maxReplicas"`
+	// CpuTarget and MemoryTarget are omitted if they are not known, e.g., when the deployment has not been created yet.
+	CpuTarget                     int  `json:"cpuTarget,omitempty"`
+	MemoryTarget                  int  `json:"memoryTarget,omitempty"`
+	ScaleDownStabilizationSeconds *int `json:"scaleDownStabilizationSeconds,omitempty"`
+}
+
+type AutoUpdate struct {
+	// Policy is how the image is followed.
+	// With digest, the deployment is restarted when its image tag is pushed again, such as latest.
+	// With semver, the image tag is updated to the highest version in Range, and otherwise it works like digest.
+	// With none, auto-update is turned off.
+	Policy string `json:"policy" bson:"policy" binding:"required,oneof=none digest semver"`
+	// Range is the version range to follow with the semver policy, such as 1.x, 1.2.x, ^1.2.3 or ~1.2.3.
+	Range *string `json:"range,omitempty" bson:"range,omitempty" binding:"required_if=Policy semver,omitempty,min=1,max=100,semver_range"`
+}
+
+type AutoUpdateRead struct {
+	Policy string  `json:"policy"`
+	Range  *string `json:"range,omitempty"`
+	// ImageDigest is the last resolved digest of the image.
+	ImageDigest *string `json:"imageDigest,omitempty"`
+	// CheckedAt is when the registry was last checked for a new image.
+	CheckedAt *time.Time `json:"checkedAt,omitempty"`
+}
+
+type RolloutStrategy struct {
+	// Type is how updates are rolled out.
+	// With rolling, the pods are replaced in place.
+	// With blueGreen, the new version runs next to the current one and is reachable on a preview URL until it is promoted.
+	// With canary, the new version runs next to the current one and receives CanaryWeight percent of the traffic until it is promoted.
+	// A blueGreen or canary rollout is promoted or aborted with the promote and abort commands.
+	Type string `json:"type" bson:"type" binding:"required,oneof=rolling blueGreen canary"`
+	// CanaryWeight is the percentage of the traffic sent to the new version with the canary strategy. Defaults to 10.
+	CanaryWeight *int `json:"canaryWeight,omitempty" bson:"canaryWeight,omitempty" binding:"omitempty,min=1,max=99"`
+}
+
+type RolloutStrategyRead struct {
+	Type         string `json:"type"`
+	CanaryWeight *int   `json:"canaryWeight,omitempty"`
+}
+
+type RolloutRead struct {
+	Strategy  string    `json:"strategy"`
+	StartedAt time.Time `json:"startedAt"`
+	// PreviewURL is where the new version is reachable during a blue/green rollout of a public deployment.
+	PreviewURL *string `json:"previewUrl,omitempty"`
+}
+
+type ScalingSchedule struct {
+	// Windows are the periods in which the deployment runs another number of replicas.
+	// If several windows are active at the same time, the first one in the list is used.
+	Windows []ScalingWindow `json:"windows" bson:"windows" binding:"omitempty,min=0,max=20,dive"`
+	// Timezone is the IANA timezone the windows are evaluated in, such as Europe/Stockholm. Defaults to UTC.
+	Timezone *string `json:"timezone,omitempty" bson:"timezone,omitempty" binding:"omitempty,timezone"`
+}
+
+type ScalingWindow struct {
+	// Start is a cron expression for when the window starts, such as 0 22 * * * for 22:00 every day.
+	Start string `json:"start" bson:"start" binding:"required,cron"`
+	// End is a cron expression for when the window ends, such as 0 7 * * * for 07:00 every day.
+	End string `json:"end" bson:"end" binding:"required,cron"`
+	// Replicas is the number of replicas the deployment runs during the window.
+	// The replicas the deployment had before the window are restored when it ends.
+	Replicas int `json:"replicas" bson:"replicas" binding:"min=0,max=100"`
+}
+
+type ScalingScheduleRead struct {
+	Windows  []ScalingWindow `json:"windows"`
+	Timezone string          `json:"timezone"`
+	// ScaledFrom is the number of replicas that are restored when the active window ends.
+	// It is only set while a window is active.
+	ScaledFrom *int `json:"scaledFrom,omitempty"`
+	// WakeUpAt is when the deployment runs replicas again, if it is scaled to 0 by the schedule.
+	WakeUpAt *time.Time `json:"wakeUpAt,omitempty"`
 }
 
 type Env struct {
 	Name  string `json:"name" bson:"name" binding:"required,env_name,min=1,max=100"`
-	Value string `json:"value" bson:"value" binding:"required,min=1,max=10000"`
+	Value string `json:"value" bson:"value" binding:"required_unless=Secret true,omitempty,min=1,max=10000"`
+	// Secret envs are stored encrypted and are never returned in reads.
+	// When updating, a secret env without a value keeps its current value.
+	Secret bool `json:"secret,omitempty" bson:"secret,omitempty" binding:"omitempty,boolean"`
+	// Encrypted is set once the value of a secret env has been encrypted.
+	// It can not be set by the user, so a submitted value is always encrypted.
+	Encrypted bool `json:"-" bson:"encrypted,omitempty"`
 }
 
 type Volume struct {
@@ -165,10 +391,87 @@ type DeploymentSpecs struct {
 
 type CiConfig struct {
 	Config string `json:"config"`
+	// Format is the CI system the config is for, such as github or gitlab.
+	Format string `json:"format"`
+	// Filename is where the config is expected to be placed in the repository.
+	Filename string `json:"filename"`
+}
+
+type ScaleOverrideRead struct {
+	Replicas  int       `json:"replicas"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type ImageWebhookCreate struct {
+	// RequireSignature rejects payloads without a valid HMAC signature.
+	RequireSignature bool `json:"requireSignature"`
+	// UpdateTag updates the image tag of the deployment when another tag of its image is pushed.
+	// Otherwise, only pushes of the current tag restart the deployment.
+	UpdateTag bool `json:"updateTag"`
+}
+
+type ImageWebhookRead struct {
+	RequireSignature bool      `json:"requireSignature"`
+	UpdateTag        bool      `json:"updateTag"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+type ImageWebhookCreated struct {
+	// URL is the webhook URL, including its token. It is only shown once.
+	URL string `json:"url"`
+	// Secret is the key used to sign payloads with HMAC-SHA256. It is only shown once.
+	// It is only set if the webhook requires signed payloads.
+	Secret string `json:"secret,omitempty"`
+
+	RequireSignature bool      `json:"requireSignature"`
+	UpdateTag        bool      `json:"updateTag"`
+	CreatedAt        time.Time `json:"createdAt"`
 }
 
 type DeploymentCommand struct {
-	Command string `json:"command" bson:"command" binding:"required,oneof=restart"`
+	Command string `json:"command" bson:"command" binding:"required,oneof=restart restartPod pause resume scale rollback build promote abort"`
+	// Revision is the version of the revision to roll back to.
+	// It is required for the rollback command.
+	Revision *int `json:"revision,omitempty" bson:"revision,omitempty" binding:"required_if=Command rollback,omitempty,min=1"`
+	// Pod is the name of the pod to restart.
+	// It is required for the restartPod command.
+	Pod *string `json:"pod,omitempty" bson:"pod,omitempty" binding:"required_if=Command restartPod,omitempty,min=1,max=253"`
+	// Replicas is the number of replicas to scale the main app to.
+	// It is required for the scale command.
+	Replicas *int `json:"replicas,omitempty" bson:"replicas,omitempty" binding:"required_if=Command scale,omitempty,min=0,max=100"`
+	// DurationMinutes is how long the scale lasts before the configured replicas are restored.
+	// It is required for the scale command.
+	DurationMinutes *int `json:"durationMinutes,omitempty" bson:"durationMinutes,omitempty" binding:"required_if=Command scale,omitempty,min=1,max=10080"`
+}
+
+type DeploymentCommandCreated struct {
+	ID    string `json:"id"`
+	JobID string `json:"jobId"`
+}
+
+type DeploymentRevisionRead struct {
+	Version int    `json:"version"`
+	Image   string `json:"image"`
+	// ImageDigest is the digest of the pushed image for custom deployments.
+	ImageDigest *string         `json:"imageDigest,omitempty"`
+	Specs       DeploymentSpecs `json:"specs"`
+
+	InternalPort  int      `json:"internalPort"`
+	InternalPorts []int    `json:"internalPorts,omitempty"`
+	Envs          []Env    `json:"envs"`
+	Volumes       []Volume `json:"volumes"`
+	Args          []string `json:"args"`
+	Visibility    string   `json:"visibility"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type DeploymentLogRead struct {
+	Source    string    `json:"source"`
+	Prefix    string    `json:"prefix"`
+	Line      string    `json:"line"`
+	Pod       string    `json:"pod,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 type LogMessage struct {
@@ -177,3 +480,13 @@ type LogMessage struct {
 	Line      string    `json:"line"`
 	CreatedAt time.Time `json:"createdAt"`
 }
+
+// ExecMessage is a message sent over the exec WebSocket.
+// Clients send "stdin", "resize" and "eof" messages, and the server sends "stdout", "stderr", "exit" and "error" messages.
+type ExecMessage struct {
+	Type     string `json:"type"`
+	Data     string `json:"data,omitempty"`
+	Cols     uint16 `json:"cols,omitempty"`
+	Rows     uint16 `json:"rows,omitempty"`
+	ExitCode *int   `json:"exitCode,omitempty"`
+}
@@ -3,17 +3,20 @@ package body
 import "time"
 
 type JobRead struct {
-	ID         string     `json:"id"`
-	UserID     string     `json:"userId"`
-	Type       string     `json:"type"`
-	Status     string     `json:"status"`
-	LastError  *string    `json:"lastError,omitempty"`
-	CreatedAt  time.Time  `json:"createdAt"`
-	LastRunAt  *time.Time `json:"lastRunAt,omitempty"`
-	FinishedAt *time.Time `json:"finishedAt,omitempty"`
-	RunAfter   time.Time  `json:"runAfter,omitempty"`
+	ID          string     `json:"id"`
+	UserID      string     `json:"userId"`
+	Type        string     `json:"type"`
+	Status      string     `json:"status"`
+	DependsOn   []string   `json:"dependsOn,omitempty"`
+	WaitingFor  []string   `json:"waitingFor,omitempty"`
+	LastError   *string    `json:"lastError,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	LastRunAt   *time.Time `json:"lastRunAt,omitempty"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+	RunAfter    time.Time  `json:"runAfter,omitempty"`
+	NextRetryAt *time.Time `json:"nextRetryAt,omitempty"`
 }
 
 type JobUpdate struct {
-	Status *string `json:"status" binding:"omitempty,oneof=pending running failed terminated finished completed"`
+	Status *string `json:"status" binding:"omitempty,oneof=pending running failed terminated finished completed cancelled"`
 }
@@ -22,3 +22,19 @@ type VmSnapshotDeleted struct {
 	ID    string `json:"id"`
 	JobID string `json:"jobId"`
 }
+
+type VmSnapshotPolicy struct {
+	Name      string  `json:"name" bson:"name" binding:"required,rfc1035,min=1,max=30"`
+	Schedule  string  `json:"schedule" bson:"schedule" binding:"required,cron"`
+	Retention int     `json:"retention" bson:"retention" binding:"required,min=1,max=30"`
+	Timezone  *string `json:"timezone,omitempty" bson:"timezone,omitempty" binding:"omitempty,timezone"`
+}
+
+type VmSnapshotPolicyRead struct {
+	Name      string     `json:"name"`
+	Schedule  string     `json:"schedule"`
+	Retention int        `json:"retention"`
+	Timezone  string     `json:"timezone"`
+	LastRunAt *time.Time `json:"lastRunAt,omitempty"`
+	NextRunAt *time.Time `json:"nextRunAt,omitempty"`
+}
@@ -9,6 +9,7 @@ type VmRead struct {
 	OwnerID      string  `json:"ownerId"`
 	Zone         string  `json:"zone"`
 	Host         *string `json:"host,omitempty"`
+	Image        *string `json:"image,omitempty"`
 
 	CreatedAt  time.Time  `json:"createdAt"`
 	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
@@ -17,10 +18,15 @@ type VmRead struct {
 
 	NeverStale bool `json:"neverStale"`
 
-	Specs        VmSpecs     `json:"specs"`
-	Ports        []PortRead  `json:"ports"`
-	GPU          *VmGpuLease `json:"gpu,omitempty"`
-	SshPublicKey string      `json:"sshPublicKey"`
+	Specs            VmSpecs                `json:"specs"`
+	Ports            []PortRead             `json:"ports"`
+	GPU              *VmGpuLease            `json:"gpu,omitempty"`
+	SshPublicKey     string                 `json:"sshPublicKey"`
+	SnapshotPolicies []VmSnapshotPolicyRead `json:"snapshotPolicies"`
+	Disks            []VmDisk               `json:"disks"`
+	// DetachedDisks are the disks that were detached by an update. They can be attached again until they are deleted.
+	DetachedDisks []VmDisk     `json:"detachedDisks"`
+	CloudInit     *VmCloudInit `json:"cloudInit,omitempty"`
 
 	Teams []string `json:"teams"`
 
@@ -38,8 +44,14 @@ type VmCreate struct {
 	DiskSize int `json:"diskSize" bson:"diskSize" binding:"required,min=10"`
 
 	Zone *string `json:"zone,omitempty" bson:"zone,omitempty" binding:"omitempty"`
+	// Image is the ID of an image in the VM image catalogue. If not set, the default image is used.
+	Image *string `json:"image,omitempty" bson:"image,omitempty" binding:"omitempty,min=1"`
 
 	NeverStale bool `json:"neverStale" bson:"neverStale" binding:"omitempty,boolean"`
+
+	SnapshotPolicies []VmSnapshotPolicy `json:"snapshotPolicies,omitempty" bson:"snapshotPolicies,omitempty" binding:"omitempty,snapshot_policy_list,min=0,max=5,dive"`
+	Disks            []VmDisk           `json:"disks,omitempty" bson:"disks,omitempty" binding:"omitempty,vm_disk_list,min=0,max=5,dive"`
+	CloudInit        *VmCloudInit       `json:"cloudInit,omitempty" bson:"cloudInit,omitempty" binding:"omitempty"`
 }
 
 type VmUpdate struct {
@@ -47,7 +59,27 @@ type VmUpdate struct {
 	Ports      *[]PortUpdate `json:"ports,omitempty" bson:"ports,omitempty" binding:"omitempty,port_list_names,port_list_numbers,port_list_http_proxies,min=0,max=10,dive"`
 	CpuCores   *int          `json:"cpuCores,omitempty" bson:"cpuCores,omitempty" binding:"omitempty,min=1"`
 	RAM        *int          `json:"ram,omitempty" bson:"ram,omitempty" binding:"omitempty,min=1"`
+	DiskSize   *int          `json:"diskSize,omitempty" bson:"diskSize,omitempty" binding:"omitempty,min=10"`
 	NeverStale *bool         `json:"neverStale,omitempty" bson:"neverStale" binding:"omitempty,boolean"`
+	// SnapshotPolicies replaces the snapshot policies of the VM. An empty list removes them.
+	SnapshotPolicies *[]VmSnapshotPolicy `json:"snapshotPolicies,omitempty" bson:"snapshotPolicies,omitempty" binding:"omitempty,snapshot_policy_list,min=0,max=5,dive"`
+	// Disks replaces the data disks of the VM. Disks that are left out are detached, but their data is kept
+	// until they are deleted. A detached disk is attached again by adding a disk with the same name.
+	Disks *[]VmDisk `json:"disks,omitempty" bson:"disks,omitempty" binding:"omitempty,vm_disk_list,min=0,max=5,dive"`
+	// CloudInit replaces the user-supplied cloud-init of the VM. It takes effect the next time the VM boots.
+	// An empty object removes it.
+	CloudInit *VmCloudInit `json:"cloudInit,omitempty" bson:"cloudInit,omitempty" binding:"omitempty"`
+	// SnapshotID restores the VM from one of its snapshots. It cannot be combined with other fields.
+	SnapshotID *string `json:"snapshotId,omitempty" bson:"snapshotId,omitempty" binding:"omitempty,min=1"`
+}
+
+type VmDisk struct {
+	// Name is kept short since it is part of the name of the volume in Kubernetes.
+	Name string `json:"name" bson:"name" binding:"required,rfc1035,min=1,max=15"`
+	// Size is the size of the disk in GB.
+	Size int `json:"size" bson:"size" binding:"required,min=1"`
+	// StorageClass is the storage class of the disk. If not set, the default storage class is used.
+	StorageClass *string `json:"storageClass,omitempty" bson:"storageClass,omitempty" binding:"omitempty,rfc1123,min=1,max=63"`
 }
 
 type VmUpdateOwner struct {
@@ -0,0 +1,29 @@
+package body
+
+// VmCloudInit is user-supplied cloud-config. It is merged with the parts the system requires,
+// so the owner's SSH key and the admin keys are always kept.
+type VmCloudInit struct {
+	Packages   []string          `json:"packages,omitempty" bson:"packages,omitempty" binding:"omitempty,min=0,max=50,dive,cloud_init_package"`
+	WriteFiles []VmCloudInitFile `json:"writeFiles,omitempty" bson:"writeFiles,omitempty" binding:"omitempty,min=0,max=20,dive"`
+	RunCMD     []string          `json:"runcmd,omitempty" bson:"runcmd,omitempty" binding:"omitempty,min=0,max=50,dive,min=1,max=4096"`
+	Users      []VmCloudInitUser `json:"users,omitempty" bson:"users,omitempty" binding:"omitempty,cloud_init_users,min=0,max=10,dive"`
+}
+
+type VmCloudInitFile struct {
+	Path    string `json:"path" bson:"path" binding:"required,startswith=/,min=2,max=255"`
+	Content string `json:"content" bson:"content" binding:"omitempty,max=16384"`
+	// Permissions is the octal file mode, such as 0644.
+	Permissions *string `json:"permissions,omitempty" bson:"permissions,omitempty" binding:"omitempty,file_mode"`
+	// Owner is the user and group owning the file, such as root:root.
+	Owner  *string `json:"owner,omitempty" bson:"owner,omitempty" binding:"omitempty,min=1,max=64"`
+	Append bool    `json:"append,omitempty" bson:"append,omitempty" binding:"omitempty,boolean"`
+}
+
+type VmCloudInitUser struct {
+	Name              string   `json:"name" bson:"name" binding:"required,rfc1035,min=1,max=32"`
+	SshAuthorizedKeys []string `json:"sshAuthorizedKeys,omitempty" bson:"sshAuthorizedKeys,omitempty" binding:"omitempty,min=0,max=10,dive,ssh_public_key"`
+	Groups            []string `json:"groups,omitempty" bson:"groups,omitempty" binding:"omitempty,min=0,max=10,dive,rfc1035,min=1,max=32"`
+	Shell             *string  `json:"shell,omitempty" bson:"shell,omitempty" binding:"omitempty,startswith=/,max=64"`
+	// Sudo gives the user passwordless sudo.
+	Sudo bool `json:"sudo,omitempty" bson:"sudo,omitempty" binding:"omitempty,boolean"`
+}
@@ -0,0 +1,35 @@
+package body
+
+import "time"
+
+type VmImageRead struct {
+	ID          string   `json:"id"`
+	DisplayName string   `json:"displayName"`
+	URL         string   `json:"url"`
+	DefaultUser string   `json:"defaultUser"`
+	MinDiskSize int      `json:"minDiskSize"`
+	Roles       []string `json:"roles"`
+	Bootstrap   bool     `json:"bootstrap"`
+
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+type VmImageCreate struct {
+	ID          string   `json:"id" binding:"required,rfc1123,min=1,max=30"`
+	DisplayName string   `json:"displayName" binding:"required,min=1,max=50"`
+	URL         string   `json:"url" binding:"required,startswith=http|startswith=docker"`
+	DefaultUser string   `json:"defaultUser" binding:"required,rfc1035,min=1,max=32"`
+	MinDiskSize int      `json:"minDiskSize" binding:"omitempty,min=0"`
+	Roles       []string `json:"roles" binding:"omitempty,min=0,max=20,dive,min=1"`
+	Bootstrap   bool     `json:"bootstrap" binding:"omitempty,boolean"`
+}
+
+type VmImageUpdate struct {
+	DisplayName *string   `json:"displayName,omitempty" binding:"omitempty,min=1,max=50"`
+	URL         *string   `json:"url,omitempty" binding:"omitempty,startswith=http|startswith=docker"`
+	DefaultUser *string   `json:"defaultUser,omitempty" binding:"omitempty,rfc1035,min=1,max=32"`
+	MinDiskSize *int      `json:"minDiskSize,omitempty" binding:"omitempty,min=0"`
+	Roles       *[]string `json:"roles,omitempty" binding:"omitempty,min=0,max=20,dive,min=1"`
+	Bootstrap   *bool     `json:"bootstrap,omitempty" binding:"omitempty,boolean"`
+}
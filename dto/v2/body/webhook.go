@@ -19,3 +19,39 @@ type HarborWebhook struct {
 		} `json:"repository"`
 	} `json:"event_data"`
 }
+
+// ImageWebhook is the payload sent to the image webhook of a deployment.
+//
+// It accepts Docker Hub and GHCR (GitHub package event) payloads,
+// as well as a generic payload with only the image and tag set.
+type ImageWebhook struct {
+	// Image is the pushed image, such as ghcr.io/kthcloud/app or ghcr.io/kthcloud/app:v2, in a generic payload.
+	Image string `json:"image"`
+	// Tag is the pushed tag in a generic payload. If not set, the tag in Image is used.
+	Tag string `json:"tag"`
+
+	// PushData and Repository are set in Docker Hub payloads.
+	PushData *struct {
+		Tag string `json:"tag"`
+	} `json:"push_data"`
+	Repository *struct {
+		RepoName string `json:"repo_name"`
+	} `json:"repository"`
+
+	// Package and RegistryPackage are set in GitHub package and registry_package event payloads.
+	Package         *GithubPackage `json:"package"`
+	RegistryPackage *GithubPackage `json:"registry_package"`
+}
+
+type GithubPackage struct {
+	PackageType    string `json:"package_type"`
+	PackageVersion *struct {
+		// PackageURL is the pushed image, including its tag, such as ghcr.io/kthcloud/app:v2
+		PackageURL        string `json:"package_url"`
+		ContainerMetadata *struct {
+			Tag struct {
+				Name string `json:"name"`
+			} `json:"tag"`
+		} `json:"container_metadata"`
+	} `json:"package_version"`
+}
@@ -1,5 +1,7 @@
 package query
 
+import "time"
+
 type Env struct {
 	Key string `json:"key" binding:"required,env_name,min=1,max=100"`
 	Val string `json:"val" binding:"required,min=1,max=10000"`
@@ -21,3 +23,48 @@ type DeploymentList struct {
 type DeploymentUpdate struct {
 	Envs []map[string]string `json:"envs" binding:"omitempty,dive,min=0,max=1000"`
 }
+
+type DeploymentRevisionList struct {
+	*Pagination
+}
+
+type DeploymentLogList struct {
+	*Pagination
+
+	// Source only includes logs from the given sources, such as pod or build.
+	Source []string `form:"source" binding:"omitempty,dive,oneof=pod deployment build"`
+	// Pod only includes logs from the given pod.
+	Pod *string `form:"pod" binding:"omitempty,min=1,max=253"`
+	// Since only includes logs created at or after the given time.
+	Since *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00" binding:"omitempty"`
+	// Until only includes logs created before the given time.
+	Until *time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00" binding:"omitempty"`
+	// Search only includes logs whose line contains the given text, ignoring case.
+	Search *string `form:"search" binding:"omitempty,min=1,max=1000"`
+	// Format is the format of the response. The ndjson and text formats are downloaded as files.
+	Format string `form:"format" binding:"omitempty,oneof=json ndjson text"`
+}
+
+type CiConfigGet struct {
+	// Format is the CI system to generate the config for. It defaults to github.
+	Format string `form:"format" binding:"omitempty,oneof=github gitlab forgejo shell"`
+	// Branch is the branch that triggers the build. It defaults to main.
+	Branch *string `form:"branch" binding:"omitempty,min=1,max=255,excludesall= '\"$"`
+	// Tag is how the pushed image is tagged. The sha strategy also tags the image as latest,
+	// since the deployment runs the latest image.
+	Tag string `form:"tag" binding:"omitempty,oneof=latest sha"`
+}
+
+type ImageHook struct {
+	// Token is the token of the deployment's image webhook.
+	Token string `form:"token" binding:"required,min=1,max=1000"`
+}
+
+type DeploymentExec struct {
+	// Pod is the name of the pod to exec in. If empty, the first running pod is used.
+	Pod       string `form:"pod" binding:"omitempty,max=253"`
+	Container string `form:"container" binding:"omitempty,max=63"`
+	// Command is the command to run, given once per argument. If empty, an interactive shell is started.
+	Command []string `form:"command" binding:"omitempty,max=100"`
+	TTY     bool     `form:"tty" binding:"omitempty,boolean"`
+}
@@ -0,0 +1,5 @@
+package query
+
+type VmImageList struct {
+	*Pagination
+}
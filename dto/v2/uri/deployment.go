@@ -16,10 +16,22 @@ type CiConfigGet struct {
 	DeploymentID string `uri:"deploymentId" binding:"required,uuid4"`
 }
 
+type DeploymentImageWebhook struct {
+	DeploymentID string `uri:"deploymentId" binding:"required,uuid4"`
+}
+
 type DeploymentCommand struct {
 	DeploymentID string `uri:"deploymentId" binding:"required,uuid4"`
 }
 
+type DeploymentRevisionList struct {
+	DeploymentID string `uri:"deploymentId" binding:"required,uuid4"`
+}
+
+type DeploymentLogList struct {
+	DeploymentID string `uri:"deploymentId" binding:"required,uuid4"`
+}
+
 type LogsGet struct {
 	DeploymentID string `uri:"deploymentId" bind:"required,uuid4"`
 }
@@ -27,3 +39,7 @@ type LogsGet struct {
 type BuildGet struct {
 	DeploymentID string `uri:"deploymentId" binding:"required,uuid4"`
 }
+
+type DeploymentExec struct {
+	DeploymentID string `uri:"deploymentId" binding:"required,uuid4"`
+}
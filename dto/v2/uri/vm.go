@@ -4,6 +4,10 @@ type VmGet struct {
 	VmID string `uri:"vmId" binding:"required,uuid4"`
 }
 
+type VmConsoleGet struct {
+	VmID string `uri:"vmId" binding:"required,uuid4"`
+}
+
 type VmDelete struct {
 	VmID string `uri:"vmId" binding:"required,uuid4"`
 }
@@ -12,6 +16,11 @@ type VmUpdate struct {
 	VmID string `uri:"vmId" binding:"required,uuid4"`
 }
 
+type VmDiskDelete struct {
+	VmID     string `uri:"vmId" binding:"required,uuid4"`
+	DiskName string `uri:"diskName" binding:"required,rfc1035,min=1,max=15"`
+}
+
 type GpuAttach struct {
 	VmID  string `uri:"vmId" binding:"required,uuid4"`
 	GpuID string `uri:"gpuId" binding:"omitempty,base64"`
@@ -0,0 +1,13 @@
+package uri
+
+type VmImageGet struct {
+	VmImageID string `uri:"vmImageId" binding:"required"`
+}
+
+type VmImageUpdate struct {
+	VmImageID string `uri:"vmImageId" binding:"required"`
+}
+
+type VmImageDelete struct {
+	VmImageID string `uri:"vmImageId" binding:"required"`
+}
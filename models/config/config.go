@@ -6,6 +6,7 @@ import (
 	"github.com/kthcloud/go-deploy/models/model"
 	"github.com/kthcloud/go-deploy/pkg/imp/kubevirt/kubevirt"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 // The following structs are used to parse the config.yaml file
@@ -40,6 +41,8 @@ type ConfigType struct {
 		DeploymentPingUpdate      time.Duration `yaml:"deploymentPingUpdate"`
 		DeploymentRepair          time.Duration `yaml:"deploymentRepair"`
 		DeploymentDeletionConfirm time.Duration `yaml:"deploymentDeletionConfirm"`
+		DeploymentAutoUpdate      time.Duration `yaml:"deploymentAutoUpdate"`
+		DeploymentSchedule        time.Duration `yaml:"deploymentSchedule"`
 
 		SmRepair          time.Duration `yaml:"smRepair"`
 		SmDeletionConfirm time.Duration `yaml:"smDeletionConfirm"`
@@ -47,6 +50,7 @@ type ConfigType struct {
 		VmStatusUpdate    time.Duration `yaml:"vmStatusUpdate"`
 		VmRepair          time.Duration `yaml:"vmRepair"`
 		VmDeletionConfirm time.Duration `yaml:"vmDeletionConfirm"`
+		VmSnapshot        time.Duration `yaml:"vmSnapshot"`
 
 		GpuSynchronize      time.Duration `yaml:"gpuSynchronize"`
 		GpuLeaseSynchronize time.Duration `yaml:"gpuLeaseSynchronize"`
@@ -58,6 +62,7 @@ type ConfigType struct {
 
 		JobFetch       time.Duration `yaml:"jobFetch"`
 		FailedJobFetch time.Duration `yaml:"failedJobFetch"`
+		JobReap        time.Duration `yaml:"jobReap"`
 
 		FetchSystemStats      time.Duration `yaml:"fetchSystemStats"`
 		FetchSystemCapacities time.Duration `yaml:"fetchSystemCapacities"`
@@ -65,6 +70,8 @@ type ConfigType struct {
 		FetchSystemGpuInfo    time.Duration `yaml:"fetchSystemGpuInfo"`
 	}
 
+	Job Job `yaml:"job"`
+
 	GPU struct {
 		PrivilegedGPUs []string `yaml:"privilegedGpus"`
 		ExcludedHosts  []string `yaml:"excludedHosts"`
@@ -112,6 +119,29 @@ type ConfigType struct {
 	} `yaml:"harbor"`
 }
 
+type Job struct {
+	// MaxConcurrency is the maximum number of jobs that run at the same time on all job executors together.
+	// Zero means no limit.
+	//
+	// Executors check the limits before claiming a job, so executors that claim at the same moment
+	// can exceed them by one job each.
+	MaxConcurrency int `yaml:"maxConcurrency"`
+	// MaxConcurrencyPerType is the maximum number of jobs of a type that run at the same time on all job executors together,
+	// keyed by job type. Types that are not listed have no limit.
+	MaxConcurrencyPerType map[string]int `yaml:"maxConcurrencyPerType"`
+	// RetryPolicies overrides the retry policy of job types, keyed by job type.
+	RetryPolicies map[string]JobRetryPolicy `yaml:"retryPolicies"`
+}
+
+// JobRetryPolicy overrides the retry policy of a job type.
+// Fields that are not set keep the value of the job type's own policy.
+type JobRetryPolicy struct {
+	MaxAttempts *int           `yaml:"maxAttempts"`
+	BaseDelay   *time.Duration `yaml:"baseDelay"`
+	MaxDelay    *time.Duration `yaml:"maxDelay"`
+	Jitter      *float64       `yaml:"jitter"`
+}
+
 type LocalPathConfigSource struct {
 	Path string `yaml:"path"`
 }
@@ -132,6 +162,11 @@ type VM struct {
 	Lifetime          time.Duration `yaml:"lifetime"`
 	AdminSshPublicKey string        `yaml:"adminSshPublicKey"`
 	Image             string        `yaml:"image"`
+	// DiskStorageClasses are the storage classes users may choose for data disks, besides the default one.
+	DiskStorageClasses []string `yaml:"diskStorageClasses"`
+	// ExpandDisks is set when the ExpandDisks feature gate is enabled in KubeVirt, which lets running VMs use
+	// grown disks without a restart.
+	ExpandDisks bool `yaml:"expandDisks"`
 }
 
 type Deployment struct {
@@ -154,6 +189,12 @@ type Deployment struct {
 
 	IngressClass string `yaml:"ingressClass"`
 
+	// BuildImage is the Kaniko executor image used to build git deployments
+	BuildImage string `yaml:"buildImage"`
+
+	// EnvEncryptionKey is a base64 encoded 32 byte key used to encrypt secret envs at rest
+	EnvEncryptionKey string `yaml:"envEncryptionKey"`
+
 	Resources struct {
 		AutoScale struct {
 			CpuThreshold    int `yaml:"cpuThreshold"`
@@ -173,6 +214,14 @@ type Deployment struct {
 			// RAM in GB (0.5 for 500Mi)
 			RAM float64 `yaml:"memory"`
 		} `yaml:"requests"`
+		// Build is the limits of the jobs that build git deployments.
+		// If they are not set, the limits of the deployment are used.
+		Build struct {
+			// CPU in cores (0.5 for 500m)
+			CPU float64 `yaml:"cpu"`
+			// RAM in GB (0.5 for 500Mi)
+			RAM float64 `yaml:"memory"`
+		} `yaml:"build"`
 	} `yaml:"resources"`
 }
 
@@ -196,6 +245,8 @@ type Zone struct {
 		// It is only set if the cluster sets dynamic load balancer IPs to ensure that the IP is always the same
 		LoadBalancerIP *string `yaml:"loadBalancerIp"`
 		ClusterIssuer  string  `yaml:"clusterIssuer"`
+		// RestConfig is the REST config for the zone created by querying the ConfigSource
+		RestConfig *rest.Config
 		// Client is the Kubernetes client for the zone created by querying the ConfigSource
 		Client *kubernetes.Clientset
 		// KubeVirtClient is the KubeVirt client for the zone created by querying the ConfigSource
@@ -228,4 +279,9 @@ type Zone struct {
 		Start int `yaml:"start"`
 		End   int `yaml:"end"`
 	} `yaml:"portRange"`
+	Logs struct {
+		// Retention is how long deployment logs are kept in the zone.
+		// If it is not set, model.DefaultLogRetention is used.
+		Retention time.Duration `yaml:"retention"`
+	} `yaml:"logs"`
 }
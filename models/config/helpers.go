@@ -1,7 +1,9 @@
 package config
 
 import (
+	"errors"
 	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/utils/cryptoutils"
 	"gopkg.in/yaml.v3"
 	"os"
 	"sync"
@@ -133,3 +135,33 @@ func (z *Zone) HasCapability(capability string) bool {
 	}
 	return false
 }
+
+// LogRetention returns how long deployment logs are kept in the zone.
+// If the zone does not configure a retention, model.DefaultLogRetention is returned.
+func (z *Zone) LogRetention() time.Duration {
+	if z.Logs.Retention <= 0 {
+		return model.DefaultLogRetention
+	}
+
+	return z.Logs.Retention
+}
+
+// GetEnvEncryptionKey returns the decoded key used to encrypt secret envs.
+// It returns an error if the key is not set or is invalid.
+func (d *Deployment) GetEnvEncryptionKey() ([]byte, error) {
+	if d.EnvEncryptionKey == "" {
+		return nil, errors.New("env encryption key is not set")
+	}
+
+	return cryptoutils.ParseKey(d.EnvEncryptionKey)
+}
+
+// GetBuildImage returns the image used to build git deployments.
+// If no image is configured, the latest Kaniko executor is used.
+func (d *Deployment) GetBuildImage() string {
+	if d.BuildImage == "" {
+		return "gcr.io/kaniko-project/executor:latest"
+	}
+
+	return d.BuildImage
+}
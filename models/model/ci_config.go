@@ -0,0 +1,17 @@
+package model
+
+const (
+	// CiFormatGithub is a GitHub Actions workflow.
+	CiFormatGithub = "github"
+	// CiFormatGitlab is a GitLab CI pipeline.
+	CiFormatGitlab = "gitlab"
+	// CiFormatForgejo is a Forgejo Actions workflow.
+	CiFormatForgejo = "forgejo"
+	// CiFormatShell is a plain shell script that can be run by any CI system.
+	CiFormatShell = "shell"
+
+	// CiTagLatest tags the pushed image as latest.
+	CiTagLatest = "latest"
+	// CiTagSha tags the pushed image with the commit SHA, in addition to latest.
+	CiTagSha = "sha"
+)
@@ -2,11 +2,12 @@ package model
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
-)
 
-const (
-	NLogsCache = 100
+	"github.com/kthcloud/go-deploy/service/constants"
+	"github.com/kthcloud/go-deploy/utils/cronutils"
 )
 
 type Deployment struct {
@@ -25,11 +26,27 @@ type Deployment struct {
 
 	NeverStale bool `bson:"neverStale"`
 
+	// Paused is set if the deployment is paused using a command.
+	// A paused deployment runs no replicas, but keeps its configured replicas so that it can be resumed.
+	Paused bool `bson:"paused,omitempty"`
+	// ScaleOverride is set if the main app is temporarily scaled using a command.
+	ScaleOverride *ScaleOverride `bson:"scaleOverride,omitempty"`
+
+	// Git is the repository the deployment is built from. It is only set for git deployments.
+	Git *GitSource `bson:"git,omitempty"`
+	// ImageWebhook is set if the deployment can be notified about pushed images by an external registry.
+	ImageWebhook *ImageWebhook `bson:"imageWebhook,omitempty"`
+	// Strategy is how updates of the main app are rolled out. If it is not set, the rolling strategy is used.
+	Strategy *RolloutStrategy `bson:"strategy,omitempty"`
+	// Rollout is set while an update is rolled out with the blue/green or canary strategy.
+	Rollout *Rollout `bson:"rollout,omitempty"`
+	// Schedule is set if the main app is scaled on a schedule, such as to 0 replicas on weekends.
+	Schedule *ScalingSchedule `bson:"schedule,omitempty"`
+
 	Activities map[string]Activity `bson:"activities"`
 
 	Apps       map[string]App       `bson:"apps"`
 	Subsystems DeploymentSubsystems `bson:"subsystems"`
-	Logs       []Log                `bson:"logs"`
 
 	Status string `bson:"status"`
 	// Error is set if there is an error with the deployment.
@@ -47,6 +64,12 @@ func (deployment *Deployment) GetMainApp() *App {
 	return &app
 }
 
+// HasOwnImage returns true if the deployment runs an image from its own repository in the registry.
+// This is the case for custom deployments, which are built by the user, and git deployments, which are built by go-deploy.
+func (deployment *Deployment) HasOwnImage() bool {
+	return deployment.Type == DeploymentTypeCustom || deployment.Type == DeploymentTypeGit
+}
+
 // SetMainApp sets the main app of the deployment.
 // If the app map is nil, it will be initialized before setting the app.
 func (deployment *Deployment) SetMainApp(app *App) {
@@ -56,6 +79,283 @@ func (deployment *Deployment) SetMainApp(app *App) {
 	deployment.Apps["main"] = *app
 }
 
+// WithOverrides returns a copy of the deployment where the replicas of the apps reflect the pause and scale commands.
+// The stored replicas are left untouched, so that they can be restored once the overrides are removed.
+func (deployment *Deployment) WithOverrides() *Deployment {
+	if !deployment.Paused && deployment.ScaleOverride == nil {
+		return deployment
+	}
+
+	res := *deployment
+	res.Apps = make(map[string]App, len(deployment.Apps))
+	for name, app := range deployment.Apps {
+		switch {
+		case deployment.Paused:
+			app.Replicas = 0
+		case name == "main":
+			// A temporary scale runs a fixed number of replicas, so the HPA must not scale it back
+			app.Replicas = deployment.ScaleOverride.Replicas
+			autoscaling := DeploymentAutoscaling{}
+			if app.Autoscaling != nil {
+				autoscaling = *app.Autoscaling
+			}
+			autoscaling.Disabled = true
+			app.Autoscaling = &autoscaling
+		}
+
+		res.Apps[name] = app
+	}
+
+	return &res
+}
+
+// GetStableApp returns the main app as it was before the active rollout.
+// Only the pod template is taken from before the rollout, so the app is scaled like the current main app.
+// If no rollout is active, the main app is returned.
+func (deployment *Deployment) GetStableApp() *App {
+	mainApp := deployment.GetMainApp()
+	if deployment.Rollout == nil {
+		return mainApp
+	}
+
+	stable := deployment.Rollout.Stable
+	stable.Name = mainApp.Name
+	stable.Replicas = mainApp.Replicas
+	stable.Autoscaling = mainApp.Autoscaling
+	return &stable
+}
+
+// GetRolloutStrategy returns the strategy used to roll out updates of the main app.
+func (deployment *Deployment) GetRolloutStrategy() string {
+	if deployment.Strategy == nil || deployment.Strategy.Type == "" {
+		return RolloutStrategyRolling
+	}
+
+	return deployment.Strategy.Type
+}
+
+// GetCanaryWeight returns the percentage of the traffic that is sent to the new version during a canary rollout.
+func (deployment *Deployment) GetCanaryWeight() int {
+	if deployment.Strategy == nil || deployment.Strategy.CanaryWeight == 0 {
+		return DefaultCanaryWeight
+	}
+
+	return deployment.Strategy.CanaryWeight
+}
+
+// GetSidecarApps returns all apps of the deployment except the main app.
+// The apps are sorted by name to give a stable order.
+func (deployment *Deployment) GetSidecarApps() []App {
+	sidecars := make([]App, 0, len(deployment.Apps))
+	for name, app := range deployment.Apps {
+		if name == "main" {
+			continue
+		}
+		sidecars = append(sidecars, app)
+	}
+
+	sort.Slice(sidecars, func(i, j int) bool {
+		return sidecars[i].Name < sidecars[j].Name
+	})
+
+	return sidecars
+}
+
+// Empty returns true if no probes are set.
+func (probes *DeploymentProbes) Empty() bool {
+	return probes.Liveness == nil && probes.Readiness == nil && probes.Startup == nil
+}
+
+// Empty returns true if only defaults are used.
+func (autoscaling *DeploymentAutoscaling) Empty() bool {
+	return *autoscaling == DeploymentAutoscaling{}
+}
+
+// GetPinnedImage returns the image of the app, pinned to the resolved digest if the app is auto-updated
+// and the image has changed in the registry.
+// Pinning the digest makes K8s pull the new image when the digest changes, even if the tag is the same.
+func (app *App) GetPinnedImage() string {
+	if app.AutoUpdate == nil || app.ImageDigest == "" || !app.ImageDigestPinned || strings.Contains(app.Image, "@") {
+		return app.Image
+	}
+
+	return app.Image + "@" + app.ImageDigest
+}
+
+// AutoscalingEnabled returns true if the app should have an HPA.
+func (app *App) AutoscalingEnabled() bool {
+	return app.Replicas > 0 && (app.Autoscaling == nil || !app.Autoscaling.Disabled)
+}
+
+// GetMinReplicas returns the minimum number of replicas the HPA can scale the app to.
+func (app *App) GetMinReplicas() int {
+	if app.Autoscaling != nil && app.Autoscaling.MinReplicas > 0 {
+		return app.Autoscaling.MinReplicas
+	}
+
+	return 1
+}
+
+// GetMaxReplicas returns the maximum number of replicas the HPA can scale the app to.
+func (app *App) GetMaxReplicas() int {
+	maxReplicas := app.Replicas
+	if app.Autoscaling != nil && app.Autoscaling.MaxReplicas > 0 {
+		maxReplicas = app.Autoscaling.MaxReplicas
+	}
+
+	return max(maxReplicas, app.GetMinReplicas())
+}
+
+// GetReplicaLimit returns the highest number of replicas the app can run.
+// This is the fixed number of replicas if autoscaling is disabled, otherwise the HPA's maximum.
+func (app *App) GetReplicaLimit() int {
+	if !app.AutoscalingEnabled() {
+		return app.Replicas
+	}
+
+	return app.GetMaxReplicas()
+}
+
+// GetMainReplicaLimit returns the highest number of replicas the main app can run.
+// This is the peak of its scaling schedule, or the replicas of a temporary scale if that is higher.
+func (deployment *Deployment) GetMainReplicaLimit() int {
+	limit := deployment.GetMainApp().GetPeakReplicaLimit(deployment.Schedule)
+	if deployment.ScaleOverride != nil {
+		limit = max(limit, deployment.ScaleOverride.Replicas)
+	}
+
+	return limit
+}
+
+// GetRolloutReplicas returns the number of replicas the new version of the main app runs during a rollout.
+// The new version only serves a share of the traffic, so it runs its minimum replicas. It is 0 if no rollout is active.
+func (deployment *Deployment) GetRolloutReplicas() int {
+	mainApp := deployment.GetMainApp()
+	if deployment.Rollout == nil || mainApp.Replicas == 0 {
+		return 0
+	}
+
+	return min(mainApp.GetMinReplicas(), mainApp.Replicas)
+}
+
+// GetMainUsage returns the resources the main app can use.
+// During a rollout, the version from before the update runs next to the new version, so both are counted.
+func (deployment *Deployment) GetMainUsage() *DeploymentUsage {
+	replicas := deployment.GetMainReplicaLimit()
+	stable := deployment.GetStableApp()
+
+	usage := &DeploymentUsage{
+		CpuCores: stable.CpuCores * float64(replicas),
+		RAM:      stable.RAM * float64(replicas),
+		Gpus:     len(stable.GPUs) * replicas,
+	}
+
+	if rolloutReplicas := deployment.GetRolloutReplicas(); rolloutReplicas > 0 {
+		mainApp := deployment.GetMainApp()
+		usage.CpuCores += mainApp.CpuCores * float64(rolloutReplicas)
+		usage.RAM += mainApp.RAM * float64(rolloutReplicas)
+		usage.Gpus += len(mainApp.GPUs) * rolloutReplicas
+	}
+
+	return usage
+}
+
+// GetPeakReplicaLimit returns the highest number of replicas the app can run at any time of its scaling schedule.
+// Quota is counted against the peak, so that replicas freed by a window cannot be used by other deployments.
+func (app *App) GetPeakReplicaLimit(schedule *ScalingSchedule) int {
+	limit := app.GetReplicaLimit()
+	if schedule == nil {
+		return limit
+	}
+
+	replicas := make([]int, 0, len(schedule.Windows)+1)
+	if schedule.ScaledFrom != nil {
+		replicas = append(replicas, *schedule.ScaledFrom)
+	}
+
+	for _, window := range schedule.Windows {
+		replicas = append(replicas, window.Replicas)
+	}
+
+	for _, r := range replicas {
+		scaled := *app
+		scaled.Replicas = r
+		limit = max(limit, scaled.GetReplicaLimit())
+	}
+
+	return limit
+}
+
+// GetLocation returns the location of the timezone of the schedule.
+// If the timezone is not set or unknown, UTC is used.
+func (schedule *ScalingSchedule) GetLocation() *time.Location {
+	if schedule.Timezone == "" {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// ActiveWindow returns the window that is active at the given time, or nil if no window is active.
+// A window is active if it has started more recently than it has ended. If several windows are active, the first one is returned.
+func (schedule *ScalingSchedule) ActiveWindow(now time.Time) *ScalingWindow {
+	now = now.In(schedule.GetLocation())
+
+	for idx, window := range schedule.Windows {
+		start, err := cronutils.Parse(window.Start)
+		if err != nil {
+			continue
+		}
+
+		end, err := cronutils.Parse(window.End)
+		if err != nil {
+			continue
+		}
+
+		lastStart := start.Prev(now)
+		if !lastStart.IsZero() && lastStart.After(end.Prev(now)) {
+			return &schedule.Windows[idx]
+		}
+	}
+
+	return nil
+}
+
+// WakeUpAt returns when the main app runs replicas again, if it is scaled to 0 by the schedule at the given time.
+// Windows that follow each other, such as a night window that ends when a weekend window has started, are treated as one.
+// It returns nil if no window scales the app to 0 at the given time.
+func (schedule *ScalingSchedule) WakeUpAt(now time.Time) *time.Time {
+	var wakeUpAt *time.Time
+
+	// Bound the number of windows to follow, in case the windows always cover each other
+	for range 2 * len(schedule.Windows) {
+		window := schedule.ActiveWindow(now)
+		if window == nil || window.Replicas > 0 {
+			break
+		}
+
+		end, err := cronutils.Parse(window.End)
+		if err != nil {
+			break
+		}
+
+		nextEnd := end.Next(now.In(schedule.GetLocation()))
+		if nextEnd.IsZero() {
+			break
+		}
+
+		wakeUpAt = &nextEnd
+		now = nextEnd
+	}
+
+	return wakeUpAt
+}
+
 // GetURL returns the URL of the deployment.
 // If the K8s ingress does not exist, it will return nil, or if the ingress does not have a host, it will return nil.
 func (deployment *Deployment) GetURL(externalPort *int) *string {
@@ -78,6 +378,26 @@ func (deployment *Deployment) GetURL(externalPort *int) *string {
 	return nil
 }
 
+// getPreviewURL returns the URL of the new version during a blue/green rollout.
+// It returns nil if there is no preview ingress, e.g. for private deployments.
+func (deployment *Deployment) getPreviewURL(externalPort *int) *string {
+	if deployment.Rollout == nil || deployment.Rollout.Strategy != RolloutStrategyBlueGreen {
+		return nil
+	}
+
+	ingress := deployment.Subsystems.K8s.GetIngress(constants.WithRolloutSuffix(deployment.Name))
+	if ingress == nil || !ingress.Created() || len(ingress.Hosts) == 0 {
+		return nil
+	}
+
+	url := fmt.Sprintf("https://%s", ingress.Hosts[0])
+	if externalPort != nil && *externalPort != 443 {
+		url = fmt.Sprintf("%s:%d", url, *externalPort)
+	}
+
+	return &url
+}
+
 // Ready returns true if the deployment is not being created or deleted.
 func (deployment *Deployment) Ready() bool {
 	return !deployment.DoingActivity(ActivityBeingCreated) && !deployment.DoingActivity(ActivityBeingDeleted)
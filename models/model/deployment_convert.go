@@ -5,6 +5,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/kthcloud/go-deploy/dto/v2/body"
 	"github.com/kthcloud/go-deploy/pkg/log"
@@ -32,10 +33,7 @@ func (deployment *Deployment) ToDTO(smURL *string, externalPort *int, teams []st
 			internalPortIndex = i
 			continue
 		}
-		envs[i] = body.Env{
-			Name:  env.Name,
-			Value: env.Value,
-		}
+		envs[i] = env.ToDTO()
 	}
 
 	if portIndex == -1 {
@@ -155,6 +153,42 @@ func (deployment *Deployment) ToDTO(smURL *string, externalPort *int, teams []st
 		gpus = append(gpus, dto)
 	}
 
+	var sidecars []body.SidecarRead
+	for _, sidecar := range deployment.GetSidecarApps() {
+		sidecars = append(sidecars, sidecar.ToSidecarDTO())
+	}
+
+	var scaleOverride *body.ScaleOverrideRead
+	if deployment.ScaleOverride != nil {
+		scaleOverride = &body.ScaleOverrideRead{
+			Replicas:  deployment.ScaleOverride.Replicas,
+			ExpiresAt: deployment.ScaleOverride.ExpiresAt,
+		}
+	}
+
+	var git *body.DeploymentGit
+	if deployment.Git != nil {
+		git = deployment.Git.ToDTO()
+	}
+
+	var imageWebhook *body.ImageWebhookRead
+	if deployment.ImageWebhook != nil {
+		imageWebhook = &body.ImageWebhookRead{
+			RequireSignature: deployment.ImageWebhook.RequireSignature,
+			UpdateTag:        deployment.ImageWebhook.UpdateTag,
+			CreatedAt:        deployment.ImageWebhook.CreatedAt,
+		}
+	}
+
+	var rollout *body.RolloutRead
+	if deployment.Rollout != nil {
+		rollout = &body.RolloutRead{
+			Strategy:   deployment.Rollout.Strategy,
+			StartedAt:  deployment.Rollout.StartedAt,
+			PreviewURL: deployment.getPreviewURL(externalPort),
+		}
+	}
+
 	return body.DeploymentRead{
 		ID:      deployment.ID,
 		Name:    deployment.Name,
@@ -183,12 +217,24 @@ func (deployment *Deployment) ToDTO(smURL *string, externalPort *int, teams []st
 		InternalPort:    app.InternalPort,
 		InternalPorts:   app.InternalPorts,
 		Image:           image,
+		Git:             git,
 		HealthCheckPath: healthCheckPath,
 		CustomDomain:    customDomain,
+		Probes:          app.Probes.ToDTO(),
+		Autoscaling:     deployment.autoscalingToDTO(app),
+		AutoUpdate:      app.autoUpdateToDTO(),
 		Visibility:      app.Visibility,
+		Sidecars:        sidecars,
 
 		NeverStale: deployment.NeverStale,
 
+		Paused:        deployment.Paused,
+		ScaleOverride: scaleOverride,
+		ImageWebhook:  imageWebhook,
+		Strategy:      deployment.strategyToDTO(),
+		Rollout:       rollout,
+		Schedule:      deployment.scheduleToDTO(),
+
 		Status:        status,
 		Error:         deploymentError,
 		ReplicaStatus: replicaStatus,
@@ -201,11 +247,315 @@ func (deployment *Deployment) ToDTO(smURL *string, externalPort *int, teams []st
 	}
 }
 
+// autoscalingToDTO converts the autoscaling policy of an app to a body.AutoscalingRead DTO.
+// Targets that are not set on the app are taken from the HPA, since the defaults are set in the config.
+func (deployment *Deployment) autoscalingToDTO(app *App) *body.AutoscalingRead {
+	dto := &body.AutoscalingRead{
+		Enabled:     app.AutoscalingEnabled(),
+		MinReplicas: app.GetMinReplicas(),
+		MaxReplicas: app.GetMaxReplicas(),
+	}
+
+	if app.Autoscaling != nil {
+		dto.CpuTarget = app.Autoscaling.CpuTarget
+		dto.MemoryTarget = app.Autoscaling.MemoryTarget
+		dto.ScaleDownStabilizationSeconds = app.Autoscaling.ScaleDownStabilizationSeconds
+	}
+
+	if hpa := deployment.Subsystems.K8s.GetHPA(deployment.Name); hpa != nil && hpa.Created() {
+		if dto.CpuTarget == 0 {
+			dto.CpuTarget = hpa.CpuAverageUtilization
+		}
+
+		if dto.MemoryTarget == 0 {
+			dto.MemoryTarget = hpa.MemoryAverageUtilization
+		}
+	}
+
+	return dto
+}
+
+// FromDTO converts a body.Autoscaling DTO to DeploymentAutoscaling.
+func (autoscaling *DeploymentAutoscaling) FromDTO(dto *body.Autoscaling) {
+	autoscaling.Disabled = dto.Enabled != nil && !*dto.Enabled
+	autoscaling.ScaleDownStabilizationSeconds = dto.ScaleDownStabilizationSeconds
+
+	if dto.MinReplicas != nil {
+		autoscaling.MinReplicas = *dto.MinReplicas
+	}
+
+	if dto.MaxReplicas != nil {
+		autoscaling.MaxReplicas = *dto.MaxReplicas
+	}
+
+	if dto.CpuTarget != nil {
+		autoscaling.CpuTarget = *dto.CpuTarget
+	}
+
+	if dto.MemoryTarget != nil {
+		autoscaling.MemoryTarget = *dto.MemoryTarget
+	}
+}
+
+// strategyToDTO converts the rollout strategy of a deployment to a body.RolloutStrategyRead DTO.
+// The canary weight is only included for the canary strategy.
+func (deployment *Deployment) strategyToDTO() body.RolloutStrategyRead {
+	dto := body.RolloutStrategyRead{
+		Type: deployment.GetRolloutStrategy(),
+	}
+
+	if dto.Type == RolloutStrategyCanary {
+		weight := deployment.GetCanaryWeight()
+		dto.CanaryWeight = &weight
+	}
+
+	return dto
+}
+
+// FromDTO converts a body.RolloutStrategy DTO to RolloutStrategy.
+// The canary weight is only kept for the canary strategy.
+func (strategy *RolloutStrategy) FromDTO(dto *body.RolloutStrategy) {
+	strategy.Type = dto.Type
+	if dto.Type == RolloutStrategyCanary && dto.CanaryWeight != nil {
+		strategy.CanaryWeight = *dto.CanaryWeight
+	}
+}
+
+// scheduleToDTO converts the scaling schedule of a deployment to a body.ScalingScheduleRead DTO.
+// It returns nil if the deployment has no schedule.
+func (deployment *Deployment) scheduleToDTO() *body.ScalingScheduleRead {
+	if deployment.Schedule == nil {
+		return nil
+	}
+
+	windows := make([]body.ScalingWindow, len(deployment.Schedule.Windows))
+	for i, window := range deployment.Schedule.Windows {
+		windows[i] = body.ScalingWindow{
+			Start:    window.Start,
+			End:      window.End,
+			Replicas: window.Replicas,
+		}
+	}
+
+	return &body.ScalingScheduleRead{
+		Windows:    windows,
+		Timezone:   deployment.Schedule.Timezone,
+		ScaledFrom: deployment.Schedule.ScaledFrom,
+		WakeUpAt:   deployment.Schedule.WakeUpAt(time.Now()),
+	}
+}
+
+// FromDTO converts a body.ScalingSchedule DTO to ScalingSchedule.
+// The timezone defaults to UTC.
+func (schedule *ScalingSchedule) FromDTO(dto *body.ScalingSchedule) {
+	schedule.Windows = make([]ScalingWindow, len(dto.Windows))
+	for i, window := range dto.Windows {
+		schedule.Windows[i] = ScalingWindow{
+			Start:    window.Start,
+			End:      window.End,
+			Replicas: window.Replicas,
+		}
+	}
+
+	schedule.Timezone = DefaultScheduleTimezone
+	if dto.Timezone != nil {
+		schedule.Timezone = *dto.Timezone
+	}
+}
+
+// FromDTO converts a body.AutoUpdate DTO to DeploymentAutoUpdate.
+// The range is only kept for the semver policy.
+func (autoUpdate *DeploymentAutoUpdate) FromDTO(dto *body.AutoUpdate) {
+	autoUpdate.Policy = dto.Policy
+	if dto.Policy == AutoUpdatePolicySemver && dto.Range != nil {
+		autoUpdate.Range = *dto.Range
+	}
+}
+
+// autoUpdateToDTO converts the auto-update policy of an app to a body.AutoUpdateRead DTO.
+// It returns nil if the app has no auto-update policy.
+func (app *App) autoUpdateToDTO() *body.AutoUpdateRead {
+	if app.AutoUpdate == nil {
+		return nil
+	}
+
+	dto := &body.AutoUpdateRead{
+		Policy:    app.AutoUpdate.Policy,
+		CheckedAt: utils.NonZeroOrNil(app.AutoUpdate.CheckedAt),
+	}
+
+	if app.AutoUpdate.Range != "" {
+		dto.Range = &app.AutoUpdate.Range
+	}
+
+	if app.ImageDigest != "" {
+		dto.ImageDigest = &app.ImageDigest
+	}
+
+	return dto
+}
+
+// ToDTO converts DeploymentProbes to a body.Probes DTO.
+// It returns nil if there are no probes.
+func (probes *DeploymentProbes) ToDTO() *body.Probes {
+	if probes == nil || probes.Empty() {
+		return nil
+	}
+
+	return &body.Probes{
+		Liveness:  probes.Liveness.ToDTO(),
+		Readiness: probes.Readiness.ToDTO(),
+		Startup:   probes.Startup.ToDTO(),
+	}
+}
+
+// ToDTO converts a DeploymentProbe to a body.Probe DTO.
+// It returns nil if the probe is nil.
+func (probe *DeploymentProbe) ToDTO() *body.Probe {
+	if probe == nil {
+		return nil
+	}
+
+	dto := &body.Probe{
+		Type:                probe.Type,
+		Command:             probe.Command,
+		InitialDelaySeconds: utils.NonZeroIntOrNil(probe.InitialDelaySeconds),
+		PeriodSeconds:       utils.NonZeroIntOrNil(probe.PeriodSeconds),
+		TimeoutSeconds:      utils.NonZeroIntOrNil(probe.TimeoutSeconds),
+		FailureThreshold:    utils.NonZeroIntOrNil(probe.FailureThreshold),
+	}
+
+	if probe.Path != "" {
+		dto.Path = &probe.Path
+	}
+
+	if probe.Port != 0 {
+		dto.Port = &probe.Port
+	}
+
+	return dto
+}
+
+// FromDTO converts a body.Probes DTO to DeploymentProbes.
+func (probes *DeploymentProbes) FromDTO(dto *body.Probes) {
+	probes.Liveness = probeFromDTO(dto.Liveness)
+	probes.Readiness = probeFromDTO(dto.Readiness)
+	probes.Startup = probeFromDTO(dto.Startup)
+}
+
+// probeFromDTO converts a body.Probe DTO to a DeploymentProbe.
+// It returns nil if the DTO is nil.
+func probeFromDTO(dto *body.Probe) *DeploymentProbe {
+	if dto == nil {
+		return nil
+	}
+
+	probe := &DeploymentProbe{
+		Type: dto.Type,
+	}
+
+	switch dto.Type {
+	case ProbeTypeHttp:
+		if dto.Path != nil {
+			probe.Path = *dto.Path
+		}
+		if dto.Port != nil {
+			probe.Port = *dto.Port
+		}
+	case ProbeTypeTcp:
+		if dto.Port != nil {
+			probe.Port = *dto.Port
+		}
+	case ProbeTypeExec:
+		probe.Command = dto.Command
+	}
+
+	if dto.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *dto.InitialDelaySeconds
+	}
+
+	if dto.PeriodSeconds != nil {
+		probe.PeriodSeconds = *dto.PeriodSeconds
+	}
+
+	if dto.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *dto.TimeoutSeconds
+	}
+
+	if dto.FailureThreshold != nil {
+		probe.FailureThreshold = *dto.FailureThreshold
+	}
+
+	return probe
+}
+
+// ToDTO converts a DeploymentEnv to a body.Env DTO.
+// The value of secret envs is redacted.
+func (env *DeploymentEnv) ToDTO() body.Env {
+	if env.Secret {
+		return body.Env{
+			Name:   env.Name,
+			Secret: true,
+		}
+	}
+
+	return body.Env{
+		Name:  env.Name,
+		Value: env.Value,
+	}
+}
+
+// ToSidecarDTO converts an App to a body.SidecarRead DTO.
+func (app *App) ToSidecarDTO() body.SidecarRead {
+	envs := make([]body.Env, len(app.Envs))
+	for i, env := range app.Envs {
+		envs[i] = env.ToDTO()
+	}
+
+	volumes := make([]body.Volume, len(app.Volumes))
+	for i, volume := range app.Volumes {
+		volumes[i] = body.Volume{
+			Name:       volume.Name,
+			AppPath:    volume.AppPath,
+			ServerPath: volume.ServerPath,
+		}
+	}
+
+	internalPorts := app.InternalPorts
+	if internalPorts == nil {
+		internalPorts = make([]int, 0)
+	}
+
+	args := app.Args
+	if args == nil {
+		args = make([]string, 0)
+	}
+
+	return body.SidecarRead{
+		Name:  app.Name,
+		Image: app.Image,
+		Specs: body.DeploymentSpecs{
+			CpuCores: app.CpuCores,
+			RAM:      app.RAM,
+			Replicas: app.Replicas,
+		},
+		InternalPorts: internalPorts,
+		Envs:          envs,
+		Volumes:       volumes,
+		Args:          args,
+	}
+}
+
 // FromDTO converts body.DeploymentCreate DTO to DeploymentCreateParams.
 func (p *DeploymentCreateParams) FromDTO(dto *body.DeploymentCreate, fallbackZone, fallbackImage string, fallbackPort int) {
 	p.Name = dto.Name
 
-	if dto.Image == nil {
+	if dto.Git != nil {
+		p.Image = fallbackImage
+		p.Type = DeploymentTypeGit
+		p.Git = &GitSource{}
+		p.Git.FromDTO(dto.Git)
+	} else if dto.Image == nil {
 		p.Image = fallbackImage
 		p.Type = DeploymentTypeCustom
 	} else {
@@ -242,8 +592,9 @@ func (p *DeploymentCreateParams) FromDTO(dto *body.DeploymentCreate, fallbackZon
 		}
 
 		p.Envs = append(p.Envs, DeploymentEnv{
-			Name:  env.Name,
-			Value: env.Value,
+			Name:   env.Name,
+			Value:  env.Value,
+			Secret: env.Secret,
 		})
 	}
 
@@ -295,6 +646,32 @@ func (p *DeploymentCreateParams) FromDTO(dto *body.DeploymentCreate, fallbackZon
 		p.Replicas = *dto.Replicas
 	}
 
+	if dto.Probes != nil {
+		p.Probes = &DeploymentProbes{}
+		p.Probes.FromDTO(dto.Probes)
+	}
+
+	if dto.Autoscaling != nil {
+		p.Autoscaling = &DeploymentAutoscaling{}
+		p.Autoscaling.FromDTO(dto.Autoscaling)
+	}
+
+	// Only prebuilt deployments run an image from a registry that can be followed
+	if p.Type == DeploymentTypePrebuilt && dto.AutoUpdate != nil && dto.AutoUpdate.Policy != AutoUpdatePolicyNone {
+		p.AutoUpdate = &DeploymentAutoUpdate{}
+		p.AutoUpdate.FromDTO(dto.AutoUpdate)
+	}
+
+	if dto.Strategy != nil && dto.Strategy.Type != RolloutStrategyRolling {
+		p.Strategy = &RolloutStrategy{}
+		p.Strategy.FromDTO(dto.Strategy)
+	}
+
+	if dto.Schedule != nil && len(dto.Schedule.Windows) > 0 {
+		p.Schedule = &ScalingSchedule{}
+		p.Schedule.FromDTO(dto.Schedule)
+	}
+
 	if dto.Zone != nil {
 		p.Zone = *dto.Zone
 	} else {
@@ -307,6 +684,11 @@ func (p *DeploymentCreateParams) FromDTO(dto *body.DeploymentCreate, fallbackZon
 		p.Visibility = dto.Visibility
 	}
 
+	p.Sidecars = make([]DeploymentSidecarParams, len(dto.Sidecars))
+	for i, sidecar := range dto.Sidecars {
+		p.Sidecars[i].FromDTO(&sidecar)
+	}
+
 	p.NeverStale = dto.NeverStale
 }
 
@@ -335,8 +717,9 @@ func (p *DeploymentUpdateParams) FromDTO(dto *body.DeploymentUpdate, deploymentT
 			}
 
 			envs = append(envs, DeploymentEnv{
-				Name:  env.Name,
-				Value: env.Value,
+				Name:   env.Name,
+				Value:  env.Value,
+				Secret: env.Secret,
 			})
 		}
 		p.Envs = &envs
@@ -371,6 +754,34 @@ func (p *DeploymentUpdateParams) FromDTO(dto *body.DeploymentUpdate, deploymentT
 		p.GPUs = &gpus
 	}
 
+	if dto.Probes != nil {
+		p.Probes = &DeploymentProbes{}
+		p.Probes.FromDTO(dto.Probes)
+	}
+
+	if dto.Autoscaling != nil {
+		p.Autoscaling = &DeploymentAutoscaling{}
+		p.Autoscaling.FromDTO(dto.Autoscaling)
+	}
+
+	if dto.Sidecars != nil {
+		sidecars := make([]DeploymentSidecarParams, len(*dto.Sidecars))
+		for i, sidecar := range *dto.Sidecars {
+			sidecars[i].FromDTO(&sidecar)
+		}
+		p.Sidecars = &sidecars
+	}
+
+	if dto.Strategy != nil {
+		p.Strategy = &RolloutStrategy{}
+		p.Strategy.FromDTO(dto.Strategy)
+	}
+
+	if dto.Schedule != nil {
+		p.Schedule = &ScalingSchedule{}
+		p.Schedule.FromDTO(dto.Schedule)
+	}
+
 	// Convert custom domain to puny encoded
 	if dto.CustomDomain != nil {
 		if punyEncoded, err := idna.New().ToASCII(*dto.CustomDomain); err == nil {
@@ -380,9 +791,20 @@ func (p *DeploymentUpdateParams) FromDTO(dto *body.DeploymentUpdate, deploymentT
 		}
 	}
 
-	// Only allow image updates for prebuilt deployments
+	// Only allow image and auto-update changes for prebuilt deployments
 	if deploymentType == DeploymentTypePrebuilt {
 		p.Image = dto.Image
+
+		if dto.AutoUpdate != nil {
+			p.AutoUpdate = &DeploymentAutoUpdate{}
+			p.AutoUpdate.FromDTO(dto.AutoUpdate)
+		}
+	}
+
+	// Only allow repository updates for git deployments
+	if deploymentType == DeploymentTypeGit && dto.Git != nil {
+		p.Git = &GitSource{}
+		p.Git.FromDTO(dto.Git)
 	}
 
 	p.Name = dto.Name
@@ -395,3 +817,90 @@ func (p *DeploymentUpdateParams) FromDTO(dto *body.DeploymentUpdate, deploymentT
 	p.Visibility = dto.Visibility
 	p.NeverStale = dto.NeverStale
 }
+
+// ToDTO converts a GitSource to a body.DeploymentGit DTO.
+func (git *GitSource) ToDTO() *body.DeploymentGit {
+	return &body.DeploymentGit{
+		URL:            git.URL,
+		Branch:         git.Branch,
+		DockerfilePath: git.DockerfilePath,
+	}
+}
+
+// FromDTO converts a body.DeploymentGit DTO to a GitSource.
+// Omitted fields are set to their defaults.
+func (git *GitSource) FromDTO(dto *body.DeploymentGit) {
+	git.URL = dto.URL
+	git.Branch = dto.Branch
+	git.DockerfilePath = dto.DockerfilePath
+
+	if git.Branch == "" {
+		git.Branch = DefaultGitBranch
+	}
+
+	if git.DockerfilePath == "" {
+		git.DockerfilePath = DefaultDockerfilePath
+	}
+}
+
+// FromDTO converts body.Sidecar DTO to DeploymentSidecarParams.
+// CPU cores and RAM are left as zero if not set, and should be given defaults by the caller.
+func (p *DeploymentSidecarParams) FromDTO(dto *body.Sidecar) {
+	p.Name = dto.Name
+	p.Image = dto.Image
+
+	if dto.CpuCores != nil {
+		p.CpuCores = *dto.CpuCores
+	}
+
+	if dto.RAM != nil {
+		p.RAM = *dto.RAM
+	}
+
+	p.Replicas = 1
+	if dto.Replicas != nil {
+		p.Replicas = *dto.Replicas
+	}
+
+	p.InternalPorts = dto.InternalPorts
+
+	p.Envs = make([]DeploymentEnv, len(dto.Envs))
+	for i, env := range dto.Envs {
+		p.Envs[i] = DeploymentEnv{
+			Name:   env.Name,
+			Value:  env.Value,
+			Secret: env.Secret,
+		}
+	}
+
+	p.Volumes = make([]DeploymentVolume, len(dto.Volumes))
+	for i, volume := range dto.Volumes {
+		p.Volumes[i] = DeploymentVolume{
+			Name:       volume.Name,
+			AppPath:    volume.AppPath,
+			ServerPath: volume.ServerPath,
+			Init:       false,
+		}
+	}
+
+	p.Args = dto.Args
+}
+
+// ToApp converts DeploymentSidecarParams to an App.
+func (p *DeploymentSidecarParams) ToApp() App {
+	return App{
+		Name: p.Name,
+
+		CpuCores: p.CpuCores,
+		RAM:      p.RAM,
+		Replicas: p.Replicas,
+
+		Image:         p.Image,
+		InternalPorts: p.InternalPorts,
+		Envs:          p.Envs,
+		Volumes:       p.Volumes,
+		Visibility:    VisibilityPrivate,
+
+		Args: p.Args,
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+)
+
+// DefaultLogRetention is how long deployment logs are kept if the zone does not configure a retention.
+const DefaultLogRetention = 7 * 24 * time.Hour
+
+// DeploymentLog is a log line of a deployment.
+// Logs are stored in their own collection, and are removed by MongoDB once ExpiresAt has passed.
+type DeploymentLog struct {
+	DeploymentID string `bson:"deploymentId"`
+	Zone         string `bson:"zone"`
+
+	Source string `bson:"source"`
+	Prefix string `bson:"prefix"`
+	Line   string `bson:"line"`
+	// Pod is the name of the pod the log was read from. It is only set for pod logs.
+	Pod string `bson:"pod,omitempty"`
+
+	CreatedAt time.Time `bson:"createdAt"`
+	// ExpiresAt is when the log is removed, which is decided by the retention of the zone.
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// ToDTO converts a DeploymentLog to a body.DeploymentLogRead DTO.
+func (l *DeploymentLog) ToDTO() body.DeploymentLogRead {
+	return body.DeploymentLogRead{
+		Source:    l.Source,
+		Prefix:    l.Prefix,
+		Line:      l.Line,
+		Pod:       l.Pod,
+		CreatedAt: l.CreatedAt,
+	}
+}
+
+// ToLog converts a DeploymentLog to a Log.
+func (l *DeploymentLog) ToLog() Log {
+	return Log{
+		Source:    l.Source,
+		Prefix:    l.Prefix,
+		Line:      l.Line,
+		Pod:       l.Pod,
+		CreatedAt: l.CreatedAt,
+	}
+}
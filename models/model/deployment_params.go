@@ -19,9 +19,18 @@ type DeploymentCreateParams struct {
 	PingPath      string
 	CustomDomain  *string
 	Visibility    string
+	Probes        *DeploymentProbes
+	Autoscaling   *DeploymentAutoscaling
+	AutoUpdate    *DeploymentAutoUpdate
+	Sidecars      []DeploymentSidecarParams
+	Strategy      *RolloutStrategy
+	Schedule      *ScalingSchedule
 
 	NeverStale bool
 
+	// Git is only set for git deployments.
+	Git *GitSource
+
 	Zone string
 }
 
@@ -44,8 +53,33 @@ type DeploymentUpdateParams struct {
 	PingPath      *string
 	Replicas      *int
 	Visibility    *string
+	Probes        *DeploymentProbes
+	Autoscaling   *DeploymentAutoscaling
+	AutoUpdate    *DeploymentAutoUpdate
+	Sidecars      *[]DeploymentSidecarParams
+	// Strategy with the rolling type removes the strategy, since rolling is the default.
+	Strategy *RolloutStrategy
+	// Schedule without windows removes the schedule.
+	Schedule *ScalingSchedule
 
 	NeverStale *bool
+
+	// Git is only applied to git deployments.
+	Git *GitSource
+}
+
+type DeploymentSidecarParams struct {
+	Name  string
+	Image string
+
+	CpuCores float64
+	RAM      float64
+	Replicas int
+
+	InternalPorts []int
+	Envs          []DeploymentEnv
+	Volumes       []DeploymentVolume
+	Args          []string
 }
 
 type DeploymentUpdateOwnerParams struct {
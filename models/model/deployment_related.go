@@ -9,12 +9,19 @@ const (
 	DeploymentTypeCustom = "custom"
 	// DeploymentTypePrebuilt is a deployment that uses a prebuilt image, such as nginx:latest.
 	DeploymentTypePrebuilt = "prebuilt"
+	// DeploymentTypeGit is a deployment that is built by go-deploy from a git repository.
+	DeploymentTypeGit = "git"
+
+	// DefaultGitBranch is the branch that is built if none is given.
+	DefaultGitBranch = "main"
+	// DefaultDockerfilePath is the Dockerfile that is built if none is given.
+	DefaultDockerfilePath = "Dockerfile"
 
 	// LogSourcePod is a log source for a pod in Kubernetes.
 	LogSourcePod = "pod"
 	// LogSourceDeployment is a log source for a deployment in go-deploy.
 	LogSourceDeployment = "deployment"
-	// LogSourceBuild is a log source for a build in GitLab CI.
+	// LogSourceBuild is a log source for a build, such as a build Job for git deployments.
 	LogSourceBuild = "build"
 
 	// VisibilityPublic is a public app.
@@ -23,10 +30,46 @@ const (
 	VisibilityPrivate = "private"
 	// VisibilityAuth is an app that requires authentication.
 	VisibilityAuth = "auth"
+
+	// ProbeTypeHttp is a probe that performs an HTTP GET request.
+	ProbeTypeHttp = "http"
+	// ProbeTypeTcp is a probe that opens a TCP connection.
+	ProbeTypeTcp = "tcp"
+	// ProbeTypeExec is a probe that runs a command in the container.
+	ProbeTypeExec = "exec"
+
+	// AutoUpdatePolicyNone turns off automatic image updates.
+	AutoUpdatePolicyNone = "none"
+	// AutoUpdatePolicyDigest restarts the app when the digest of its image tag changes, e.g. when latest is pushed.
+	AutoUpdatePolicyDigest = "digest"
+	// AutoUpdatePolicySemver updates the image tag to the highest version in a range, such as 1.x.
+	// If no higher version is found, it behaves like AutoUpdatePolicyDigest.
+	AutoUpdatePolicySemver = "semver"
+
+	// RolloutStrategyRolling replaces the pods of the main app in place. It is the default strategy.
+	RolloutStrategyRolling = "rolling"
+	// RolloutStrategyBlueGreen runs the new version next to the current one until it is promoted.
+	// The new version is reachable on a preview URL, but receives no traffic on the main URL until it is promoted.
+	RolloutStrategyBlueGreen = "blueGreen"
+	// RolloutStrategyCanary runs the new version next to the current one, and sends a share of the traffic to it until it is promoted.
+	RolloutStrategyCanary = "canary"
+
+	// DefaultCanaryWeight is the percentage of the traffic that is sent to a canary if none is given.
+	DefaultCanaryWeight = 10
+
+	// DefaultScheduleTimezone is the timezone of a scaling schedule if none is given.
+	DefaultScheduleTimezone = "UTC"
 )
 
 var EmptyReplicaStatus = &ReplicaStatus{}
 
+// GitSource is the git repository that a git deployment is built from.
+type GitSource struct {
+	URL            string `bson:"url"`
+	Branch         string `bson:"branch"`
+	DockerfilePath string `bson:"dockerfilePath"`
+}
+
 type App struct {
 	Name string `bson:"name"`
 
@@ -50,6 +93,22 @@ type App struct {
 
 	CustomDomain *CustomDomain `bson:"customDomain"`
 
+	// Probes are the health checks used by K8s for the app.
+	Probes *DeploymentProbes `bson:"probes,omitempty"`
+
+	// Autoscaling is the autoscaling policy for the app.
+	// If it is not set, the app scales between 1 and Replicas using the default thresholds.
+	Autoscaling *DeploymentAutoscaling `bson:"autoscaling,omitempty"`
+
+	// AutoUpdate is the policy for automatically updating the image of a prebuilt app when it changes in its registry.
+	AutoUpdate *DeploymentAutoUpdate `bson:"autoUpdate,omitempty"`
+	// ImageDigest is the last digest of the image that was resolved from the registry.
+	// It is only set for apps with AutoUpdate, and is reset when the image is changed.
+	ImageDigest string `bson:"imageDigest,omitempty"`
+	// ImageDigestPinned is set once the image has changed in the registry, which pins the image to ImageDigest.
+	// Until then, the app runs the unpinned image, so that the first resolved digest does not restart it.
+	ImageDigestPinned bool `bson:"imageDigestPinned,omitempty"`
+
 	// ReplicaStatus is a group of fields that describe the status of the replicas.
 	// It is only set for apps that has status update.
 	ReplicaStatus *ReplicaStatus `bson:"replicaStatus,omitempty"`
@@ -70,9 +129,11 @@ type ReplicaStatus struct {
 }
 
 type Log struct {
-	Source    string    `bson:"source"`
-	Prefix    string    `bson:"prefix"`
-	Line      string    `bson:"line"`
+	Source string `bson:"source"`
+	Prefix string `bson:"prefix"`
+	Line   string `bson:"line"`
+	// Pod is the name of the pod the log was read from. It is only set for pod logs.
+	Pod       string    `bson:"pod,omitempty"`
 	CreatedAt time.Time `bson:"createdAt"`
 }
 
@@ -84,6 +145,9 @@ type PodDeleted struct {
 type DeploymentEnv struct {
 	Name  string `json:"name" bson:"name"`
 	Value string `json:"value" bson:"value"`
+	// Secret is set if the value is encrypted.
+	// Secret envs are mounted from a K8s secret and are redacted in reads.
+	Secret bool `json:"secret,omitempty" bson:"secret,omitempty"`
 }
 
 type DeploymentVolume struct {
@@ -93,6 +157,54 @@ type DeploymentVolume struct {
 	ServerPath string `bson:"serverPath"`
 }
 
+type DeploymentProbes struct {
+	Liveness  *DeploymentProbe `bson:"liveness,omitempty"`
+	Readiness *DeploymentProbe `bson:"readiness,omitempty"`
+	Startup   *DeploymentProbe `bson:"startup,omitempty"`
+}
+
+// DeploymentProbe is a health check for an app.
+// Zero values for the timing fields means that the K8s defaults are used.
+type DeploymentProbe struct {
+	Type    string   `bson:"type"`
+	Path    string   `bson:"path,omitempty"`
+	Port    int      `bson:"port,omitempty"`
+	Command []string `bson:"command,omitempty"`
+
+	InitialDelaySeconds int `bson:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int `bson:"periodSeconds,omitempty"`
+	TimeoutSeconds      int `bson:"timeoutSeconds,omitempty"`
+	FailureThreshold    int `bson:"failureThreshold,omitempty"`
+}
+
+// DeploymentAutoscaling is the autoscaling policy for an app.
+// Zero values means that the defaults are used.
+type DeploymentAutoscaling struct {
+	// Disabled runs the app with a fixed number of replicas and no HPA.
+	Disabled bool `bson:"disabled,omitempty"`
+
+	MinReplicas int `bson:"minReplicas,omitempty"`
+	MaxReplicas int `bson:"maxReplicas,omitempty"`
+
+	// CpuTarget and MemoryTarget are the average utilization in percent the HPA tries to keep.
+	CpuTarget    int `bson:"cpuTarget,omitempty"`
+	MemoryTarget int `bson:"memoryTarget,omitempty"`
+
+	// ScaleDownStabilizationSeconds is how long the HPA waits before scaling down.
+	// If it is nil, the K8s default is used.
+	ScaleDownStabilizationSeconds *int `bson:"scaleDownStabilizationSeconds,omitempty"`
+}
+
+// DeploymentAutoUpdate is the policy for automatically updating the image of an app.
+type DeploymentAutoUpdate struct {
+	// Policy is either AutoUpdatePolicyDigest or AutoUpdatePolicySemver.
+	Policy string `bson:"policy"`
+	// Range is the version range that is followed with AutoUpdatePolicySemver, such as 1.x.
+	Range string `bson:"range,omitempty"`
+	// CheckedAt is when the registry was last checked for a new image.
+	CheckedAt time.Time `bson:"checkedAt,omitempty"`
+}
+
 type DeploymentGPU struct {
 	Name      string `bson:"name"`
 	ClaimName string `bson:"claimName"`
@@ -104,6 +216,62 @@ type DeploymentUsage struct {
 	Gpus     int
 }
 
+type ScaleOverride struct {
+	Replicas  int       `bson:"replicas"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// ImageWebhook lets an external registry, such as Docker Hub or GHCR, notify a prebuilt deployment about pushed images.
+type ImageWebhook struct {
+	// TokenHash is the hash of the token in the webhook URL. The token itself is only shown when the webhook is created.
+	TokenHash string `bson:"tokenHash"`
+	// Secret is the encrypted key used to verify the HMAC signature of webhook payloads.
+	// It is empty if the webhook does not require signed payloads.
+	Secret string `bson:"secret"`
+	// RequireSignature rejects payloads without a valid HMAC signature.
+	RequireSignature bool `bson:"requireSignature"`
+	// UpdateTag updates the image tag of the deployment when another tag of its image is pushed.
+	// Otherwise, only pushes of the current tag restart the deployment.
+	UpdateTag bool      `bson:"updateTag"`
+	CreatedAt time.Time `bson:"createdAt"`
+}
+
+// RolloutStrategy is how updates of the main app are rolled out.
+type RolloutStrategy struct {
+	Type string `bson:"type"`
+	// CanaryWeight is the percentage of the traffic that is sent to the new version with the canary strategy.
+	CanaryWeight int `bson:"canaryWeight,omitempty"`
+}
+
+// Rollout is an update of the main app that is rolled out with the blue/green or canary strategy.
+// The main app holds the new version, while the version it replaces keeps running until the rollout is promoted or aborted.
+type Rollout struct {
+	Strategy string `bson:"strategy"`
+	// Stable is the main app as it was before the update.
+	Stable    App       `bson:"stable"`
+	StartedAt time.Time `bson:"startedAt"`
+}
+
+// ScalingSchedule is a set of windows in which the main app runs another number of replicas, such as 0 at night.
+type ScalingSchedule struct {
+	Windows []ScalingWindow `bson:"windows"`
+	// Timezone is the IANA timezone the cron expressions of the windows are evaluated in.
+	Timezone string `bson:"timezone"`
+	// ScaledFrom is the number of replicas the main app had before the active window scaled it.
+	// It is only set while a window is active, and the replicas are restored to it when the window ends.
+	ScaledFrom *int `bson:"scaledFrom,omitempty"`
+	// ScaledTo is the number of replicas the active window scaled the main app to.
+	// It is used to notice when another window with other replicas becomes active.
+	ScaledTo *int `bson:"scaledTo,omitempty"`
+}
+
+// ScalingWindow is a recurring period, from a start to an end cron expression, in which the main app runs a fixed number of replicas.
+type ScalingWindow struct {
+	Start    string `bson:"start"`
+	End      string `bson:"end"`
+	Replicas int    `bson:"replicas"`
+}
+
 type DeploymentError struct {
 	Reason      string `bson:"reason"`
 	Description string `bson:"description"`
@@ -0,0 +1,227 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+)
+
+// DeploymentRevision is an immutable snapshot of the main app of a deployment.
+// A revision is stored every time a deployment is created or updated, and when a new image is pushed.
+type DeploymentRevision struct {
+	ID           string `bson:"id"`
+	DeploymentID string `bson:"deploymentId"`
+	// Version is increased by one for every revision of a deployment, starting at 1.
+	Version int `bson:"version"`
+
+	Image string `bson:"image"`
+	// ImageDigest is the digest of the image that was pushed to the registry.
+	// It is only set for custom deployments, and makes it possible to roll back to an earlier build.
+	ImageDigest string `bson:"imageDigest,omitempty"`
+
+	CpuCores float64 `bson:"cpuCores"`
+	RAM      float64 `bson:"ram"`
+	Replicas int     `bson:"replicas"`
+
+	InternalPort  int                `bson:"internalPort"`
+	InternalPorts []int              `bson:"internalPorts,omitempty"`
+	Envs          []DeploymentEnv    `bson:"envs"`
+	Volumes       []DeploymentVolume `bson:"volumes"`
+	Args          []string           `bson:"args"`
+	Visibility    string             `bson:"visibility"`
+
+	CreatedAt time.Time `bson:"createdAt"`
+}
+
+type DeploymentRevisionCreateParams struct {
+	Image       string
+	ImageDigest string
+
+	CpuCores float64
+	RAM      float64
+	Replicas int
+
+	InternalPort  int
+	InternalPorts []int
+	Envs          []DeploymentEnv
+	Volumes       []DeploymentVolume
+	Args          []string
+	Visibility    string
+}
+
+// FromApp creates revision params from a snapshot of the app.
+func (p *DeploymentRevisionCreateParams) FromApp(app *App, imageDigest string) {
+	p.Image = app.Image
+	p.ImageDigest = imageDigest
+	p.CpuCores = app.CpuCores
+	p.RAM = app.RAM
+	p.Replicas = app.Replicas
+	p.InternalPort = app.InternalPort
+	p.InternalPorts = app.InternalPorts
+	p.Envs = app.Envs
+	p.Volumes = app.Volumes
+	p.Args = app.Args
+	p.Visibility = app.Visibility
+}
+
+// PinnedImage returns the image of the revision.
+// If the revision has a digest, the image is pinned to that digest.
+func (r *DeploymentRevision) PinnedImage() string {
+	if r.ImageDigest == "" {
+		return r.Image
+	}
+
+	// Strip any tag or digest that the image already has
+	image := r.Image
+	if idx := strings.Index(image, "@"); idx != -1 {
+		image = image[:idx]
+	} else if idx = strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		image = image[:idx]
+	}
+
+	return fmt.Sprintf("%s@%s", image, r.ImageDigest)
+}
+
+// ToDTO converts a DeploymentRevision to a body.DeploymentRevisionRead DTO.
+func (r *DeploymentRevision) ToDTO() body.DeploymentRevisionRead {
+	envs := make([]body.Env, len(r.Envs))
+	for i, env := range r.Envs {
+		envs[i] = env.ToDTO()
+	}
+
+	volumes := make([]body.Volume, len(r.Volumes))
+	for i, volume := range r.Volumes {
+		volumes[i] = body.Volume{
+			Name:       volume.Name,
+			AppPath:    volume.AppPath,
+			ServerPath: volume.ServerPath,
+		}
+	}
+
+	var imageDigest *string
+	if r.ImageDigest != "" {
+		imageDigest = &r.ImageDigest
+	}
+
+	return body.DeploymentRevisionRead{
+		Version:     r.Version,
+		Image:       r.Image,
+		ImageDigest: imageDigest,
+		Specs: body.DeploymentSpecs{
+			CpuCores: r.CpuCores,
+			RAM:      r.RAM,
+			Replicas: r.Replicas,
+		},
+		InternalPort:  r.InternalPort,
+		InternalPorts: r.InternalPorts,
+		Envs:          envs,
+		Volumes:       volumes,
+		Args:          r.Args,
+		Visibility:    r.Visibility,
+		CreatedAt:     r.CreatedAt,
+	}
+}
+
+// ToUpdateDTO converts a DeploymentRevision to a body.DeploymentUpdate DTO that restores the revision.
+// Secret envs keep their encrypted value, which is not encrypted again when updating.
+func (r *DeploymentRevision) ToUpdateDTO() body.DeploymentUpdate {
+	// Ports are updated through the PORT and INTERNAL_PORTS envs.
+	// INTERNAL_PORTS is always included, so that ports added after the revision are removed.
+	envs := make([]body.Env, 0, len(r.Envs)+2)
+	for _, env := range r.Envs {
+		envs = append(envs, body.Env{
+			Name:      env.Name,
+			Value:     env.Value,
+			Secret:    env.Secret,
+			Encrypted: env.Secret,
+		})
+	}
+
+	portsStr := make([]string, len(r.InternalPorts))
+	for i, port := range r.InternalPorts {
+		portsStr[i] = fmt.Sprintf("%d", port)
+	}
+
+	envs = append(envs,
+		body.Env{Name: "PORT", Value: fmt.Sprintf("%d", r.InternalPort)},
+		body.Env{Name: "INTERNAL_PORTS", Value: strings.Join(portsStr, ",")},
+	)
+
+	volumes := make([]body.Volume, len(r.Volumes))
+	for i, volume := range r.Volumes {
+		volumes[i] = body.Volume{
+			Name:       volume.Name,
+			AppPath:    volume.AppPath,
+			ServerPath: volume.ServerPath,
+		}
+	}
+
+	image := r.PinnedImage()
+	args := r.Args
+	if args == nil {
+		args = make([]string, 0)
+	}
+
+	return body.DeploymentUpdate{
+		CpuCores:   &r.CpuCores,
+		RAM:        &r.RAM,
+		Replicas:   &r.Replicas,
+		Envs:       &envs,
+		Volumes:    &volumes,
+		Args:       &args,
+		Visibility: &r.Visibility,
+		Image:      &image,
+	}
+}
+
+// SameAs returns true if the revision has the same content as the params.
+// This is used to avoid storing a new revision if nothing in the snapshot changed.
+func (r *DeploymentRevision) SameAs(params *DeploymentRevisionCreateParams) bool {
+	other := DeploymentRevision{
+		Image:         params.Image,
+		ImageDigest:   params.ImageDigest,
+		CpuCores:      params.CpuCores,
+		RAM:           params.RAM,
+		Replicas:      params.Replicas,
+		InternalPort:  params.InternalPort,
+		InternalPorts: params.InternalPorts,
+		Envs:          params.Envs,
+		Volumes:       params.Volumes,
+		Args:          params.Args,
+		Visibility:    params.Visibility,
+	}
+
+	// Updates do not know the digest of the running image, so it is only compared for push events
+	if other.ImageDigest == "" {
+		other.ImageDigest = r.ImageDigest
+	}
+
+	this := *r
+	this.ID, this.DeploymentID, this.Version, this.CreatedAt = "", "", 0, time.Time{}
+
+	return reflect.DeepEqual(normalizeRevision(this), normalizeRevision(other))
+}
+
+// normalizeRevision replaces nil slices with empty slices, since they are not kept apart in the database.
+func normalizeRevision(r DeploymentRevision) DeploymentRevision {
+	if r.InternalPorts == nil {
+		r.InternalPorts = make([]int, 0)
+	}
+
+	if r.Envs == nil {
+		r.Envs = make([]DeploymentEnv, 0)
+	}
+
+	if r.Volumes == nil {
+		r.Volumes = make([]DeploymentVolume, 0)
+	}
+
+	if r.Args == nil {
+		r.Args = make([]string, 0)
+	}
+
+	return r
+}
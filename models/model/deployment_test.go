@@ -0,0 +1,40 @@
+package model
+
+import "testing"
+
+func TestWithOverrides(t *testing.T) {
+	deployment := &Deployment{
+		Apps: map[string]App{
+			"main":   {Name: "main", Replicas: 3},
+			"worker": {Name: "worker", Replicas: 1},
+		},
+	}
+
+	if deployment.WithOverrides() != deployment {
+		t.Error("expected the deployment itself when there are no overrides")
+	}
+
+	deployment.Paused = true
+	paused := deployment.WithOverrides()
+	for name, app := range paused.Apps {
+		if app.Replicas != 0 {
+			t.Errorf("expected app %s to have 0 replicas when paused, got %d", name, app.Replicas)
+		}
+	}
+
+	deployment.Paused = false
+	deployment.ScaleOverride = &ScaleOverride{Replicas: 5}
+	scaled := deployment.WithOverrides()
+	if mainApp := scaled.GetMainApp(); mainApp.Replicas != 5 || mainApp.AutoscalingEnabled() {
+		t.Errorf("expected main app to run 5 fixed replicas, got %d (autoscaling: %t)", mainApp.Replicas, mainApp.AutoscalingEnabled())
+	}
+
+	if scaled.Apps["worker"].Replicas != 1 {
+		t.Errorf("expected sidecar replicas to be unchanged, got %d", scaled.Apps["worker"].Replicas)
+	}
+
+	// The stored replicas must be kept so that they can be restored
+	if deployment.GetMainApp().Replicas != 3 {
+		t.Errorf("expected stored replicas to be unchanged, got %d", deployment.GetMainApp().Replicas)
+	}
+}
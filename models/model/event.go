@@ -5,6 +5,8 @@ import "time"
 const (
 	// TypeHttpRequest is the event type of HTTP requests.
 	TypeHttpRequest = "httpRequest"
+	// TypeDeploymentExec is the event type of exec sessions in deployment pods.
+	TypeDeploymentExec = "deploymentExec"
 )
 
 type Source struct {
@@ -0,0 +1,17 @@
+package model
+
+type GitlabCiConfig struct {
+	Build GitlabCiJob `yaml:"kthcloud-ci"`
+}
+
+type GitlabCiRule struct {
+	If string `yaml:"if"`
+}
+
+type GitlabCiJob struct {
+	Image     string            `yaml:"image"`
+	Services  []string          `yaml:"services"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+	Rules     []GitlabCiRule    `yaml:"rules"`
+	Script    []string          `yaml:"script"`
+}
@@ -40,6 +40,12 @@ const (
 	JobUpdateDeploymentOwner = "updateDeploymentOwner"
 	// JobRepairDeployment is used when repairing a deployment.
 	JobRepairDeployment = "repairDeployment"
+	// JobDoDeploymentCommand is used when doing a command on a deployment, such as pausing it.
+	JobDoDeploymentCommand = "doDeploymentCommand"
+	// JobResetDeploymentScale is used when a temporary scale of a deployment expires.
+	JobResetDeploymentScale = "resetDeploymentScale"
+	// JobBuildDeployment is used when building the image of a git deployment.
+	JobBuildDeployment = "buildDeployment"
 
 	// JobCreateSM is used when creating a storage manager.
 	JobCreateSM = "createSm"
@@ -67,6 +73,8 @@ const (
 	JobStatusFailed = "failed"
 	// JobStatusTerminated is used when a job has been terminated.
 	JobStatusTerminated = "terminated"
+	// JobStatusCancelled is used when a job has been cancelled by a user.
+	JobStatusCancelled = "cancelled"
 )
 
 type Job struct {
@@ -76,6 +84,13 @@ type Job struct {
 	Args    map[string]interface{} `bson:"args"`
 	Version string                 `bson:"version"`
 
+	// DependsOn is the list of jobs that must be completed before this job can run.
+	// If any of them is terminated, this job is terminated as well.
+	DependsOn []string `bson:"dependsOn,omitempty"`
+	// WaitingFor is the subset of DependsOn that is not yet completed.
+	// The job is only picked up by the runner once it is empty.
+	WaitingFor []string `bson:"waitingFor,omitempty"`
+
 	CreatedAt  time.Time `bson:"createdAt"`
 	LastRunAt  time.Time `bson:"lastRunAt,omitempty"`
 	FinishedAt time.Time `bson:"finishedAt,omitempty"`
@@ -83,6 +98,12 @@ type Job struct {
 
 	Attempts int `bson:"attempts"`
 
+	// WorkerID is the ID of the worker that runs the job.
+	WorkerID string `bson:"workerId,omitempty"`
+	// LeaseExpiresAt is when the worker's claim on the job expires, unless it is renewed by heartbeat.
+	// Running jobs with an expired lease are assumed to be abandoned, e.g. because the worker crashed.
+	LeaseExpiresAt time.Time `bson:"leaseExpiresAt,omitempty"`
+
 	Status    string   `bson:"status" `
 	ErrorLogs []string `bson:"errorLogs" `
 }
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"github.com/kthcloud/go-deploy/dto/v2/body"
 	"github.com/kthcloud/go-deploy/utils"
 )
@@ -12,16 +14,24 @@ func (job *Job) ToDTO(statusMessage string) body.JobRead {
 		lastError = &job.ErrorLogs[len(job.ErrorLogs)-1]
 	}
 
+	var nextRetryAt *time.Time
+	if job.Status == JobStatusFailed {
+		nextRetryAt = &job.RunAfter
+	}
+
 	return body.JobRead{
-		ID:         job.ID,
-		UserID:     job.UserID,
-		Type:       job.Type,
-		Status:     statusMessage,
-		LastError:  lastError,
-		CreatedAt:  job.CreatedAt,
-		LastRunAt:  utils.NonZeroOrNil(job.LastRunAt),
-		FinishedAt: utils.NonZeroOrNil(job.FinishedAt),
-		RunAfter:   job.RunAfter,
+		ID:          job.ID,
+		UserID:      job.UserID,
+		Type:        job.Type,
+		Status:      statusMessage,
+		DependsOn:   job.DependsOn,
+		WaitingFor:  job.WaitingFor,
+		LastError:   lastError,
+		CreatedAt:   job.CreatedAt,
+		LastRunAt:   utils.NonZeroOrNil(job.LastRunAt),
+		FinishedAt:  utils.NonZeroOrNil(job.FinishedAt),
+		RunAfter:    job.RunAfter,
+		NextRetryAt: nextRetryAt,
 	}
 }
 
@@ -2,7 +2,10 @@ package model
 
 import (
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/kthcloud/go-deploy/utils/cronutils"
 )
 
 type VM struct {
@@ -23,6 +26,17 @@ type VM struct {
 	SshPublicKey string          `bson:"sshPublicKey"`
 	PortMap      map[string]Port `bson:"portMap"`
 	Specs        VmSpecs         `bson:"specs"`
+	// Image is the image the VM was created from. It is nil for VMs created from the default image.
+	Image *VmImageRef `bson:"image,omitempty"`
+
+	SnapshotPolicyMap map[string]SnapshotPolicy `bson:"snapshotPolicyMap,omitempty"`
+	DiskMap           map[string]VmDisk         `bson:"diskMap,omitempty"`
+	// DetachedDiskMap holds the data disks that were detached by an update. Their volumes are kept until they are deleted.
+	DetachedDiskMap map[string]VmDisk `bson:"detachedDiskMap,omitempty"`
+	// CloudInit is the user-supplied cloud-init, which is kept so repairs generate the same VM.
+	CloudInit *CloudInit `bson:"cloudInit,omitempty"`
+	// Restore is set while the VM is being restored from a snapshot, so that a restore that is retried resumes it.
+	Restore *VmRestore `bson:"restore,omitempty"`
 
 	Subsystems Subsystems          `bson:"subsystems"`
 	Activities map[string]Activity `bson:"activities"`
@@ -41,6 +55,20 @@ type VmSpecs struct {
 	DiskSize int `json:"diskSize"`
 }
 
+// TotalDiskSize returns the size in GB of the root disk and every data disk, including detached ones.
+func (vm *VM) TotalDiskSize() int {
+	total := vm.Specs.DiskSize
+	for _, disk := range vm.DiskMap {
+		total += disk.Size
+	}
+
+	for _, disk := range vm.DetachedDiskMap {
+		total += disk.Size
+	}
+
+	return total
+}
+
 func (vm *VM) Ready() bool {
 	return !vm.DoingActivity(ActivityBeingCreated) && !vm.DoingActivity(ActivityBeingDeleted)
 }
@@ -108,3 +136,65 @@ func (vm *VM) GetExternalPort(privatePort int, protocol string) *int {
 
 	return nil
 }
+
+// GetLocation returns the location of the timezone of the policy.
+// If the timezone is not set or unknown, UTC is used.
+func (policy *SnapshotPolicy) GetLocation() *time.Location {
+	if policy.Timezone == "" {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(policy.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// Due returns whether the schedule of the policy has fired since the policy last ran.
+// A policy without a starting point is never due.
+func (policy *SnapshotPolicy) Due(now time.Time) bool {
+	if policy.LastRunAt == nil {
+		return false
+	}
+
+	schedule, err := cronutils.Parse(policy.Schedule)
+	if err != nil {
+		return false
+	}
+
+	lastRun := schedule.Prev(now.In(policy.GetLocation()))
+	return !lastRun.IsZero() && lastRun.After(*policy.LastRunAt)
+}
+
+// NextRunAt returns when the policy takes its next snapshot, or nil if the schedule never fires.
+func (policy *SnapshotPolicy) NextRunAt(now time.Time) *time.Time {
+	schedule, err := cronutils.Parse(policy.Schedule)
+	if err != nil {
+		return nil
+	}
+
+	next := schedule.Next(now.In(policy.GetLocation()))
+	if next.IsZero() {
+		return nil
+	}
+
+	return &next
+}
+
+// SnapshotName returns the name of the snapshot the policy takes at the given time.
+func (policy *SnapshotPolicy) SnapshotName(t time.Time) string {
+	return fmt.Sprintf("%s-%s", policy.Name, t.UTC().Format(SnapshotPolicyTimeFormat))
+}
+
+// OwnsSnapshot returns whether a snapshot with the given name was taken by the policy.
+func (policy *SnapshotPolicy) OwnsSnapshot(name string) bool {
+	suffix, found := strings.CutPrefix(name, policy.Name+"-")
+	if !found {
+		return false
+	}
+
+	_, err := time.Parse(SnapshotPolicyTimeFormat, suffix)
+	return err == nil
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/kthcloud/go-deploy/dto/v2/body"
@@ -74,6 +75,32 @@ func (vm *VM) ToDTOv2(gpuLease *GpuLease, teams []string, externalPort *int, ssh
 		})
 	}
 
+	snapshotPolicies := make([]body.VmSnapshotPolicyRead, 0, len(vm.SnapshotPolicyMap))
+	for _, policy := range vm.SnapshotPolicyMap {
+		snapshotPolicies = append(snapshotPolicies, body.VmSnapshotPolicyRead{
+			Name:      policy.Name,
+			Schedule:  policy.Schedule,
+			Retention: policy.Retention,
+			Timezone:  policy.Timezone,
+			LastRunAt: policy.LastRunAt,
+			NextRunAt: policy.NextRunAt(time.Now()),
+		})
+	}
+
+	sort.Slice(snapshotPolicies, func(i, j int) bool {
+		return snapshotPolicies[i].Name < snapshotPolicies[j].Name
+	})
+
+	var cloudInit *body.VmCloudInit
+	if vm.CloudInit != nil {
+		cloudInit = vm.CloudInit.ToDTOv2()
+	}
+
+	var image *string
+	if vm.Image != nil {
+		image = &vm.Image.ID
+	}
+
 	var internalName *string
 	if k8sVM := vm.Subsystems.K8s.VM; subsystems.Created(&k8sVM) {
 		internalName = &k8sVM.ID
@@ -86,6 +113,7 @@ func (vm *VM) ToDTOv2(gpuLease *GpuLease, teams []string, externalPort *int, ssh
 		OwnerID:      vm.OwnerID,
 		Zone:         vm.Zone,
 		Host:         host,
+		Image:        image,
 
 		CreatedAt:  vm.CreatedAt,
 		UpdatedAt:  utils.NonZeroOrNil(vm.UpdatedAt),
@@ -102,6 +130,10 @@ func (vm *VM) ToDTOv2(gpuLease *GpuLease, teams []string, externalPort *int, ssh
 		Ports:               ports,
 		GPU:                 lease,
 		SshPublicKey:        vm.SshPublicKey,
+		SnapshotPolicies:    snapshotPolicies,
+		Disks:               toDiskListDTOv2(vm.DiskMap),
+		DetachedDisks:       toDiskListDTOv2(vm.DetachedDiskMap),
+		CloudInit:           cloudInit,
 		Teams:               teams,
 		Status:              vm.Status,
 		SshConnectionString: sshConnectionString,
@@ -117,6 +149,14 @@ func (p VmCreateParams) FromDTOv2(dto *body.VmCreate, fallbackZone *string) VmCr
 	p.DiskSize = dto.DiskSize
 	p.PortMap = make(map[string]PortCreateParams)
 	p.NeverStale = dto.NeverStale
+	p.SnapshotPolicyMap = fromSnapshotPolicyListDTOv2(dto.SnapshotPolicies)
+	p.DiskMap = fromDiskListDTOv2(dto.Disks)
+
+	if dto.CloudInit != nil {
+		if cloudInit := fromCloudInitDTOv2(dto.CloudInit); !cloudInit.Empty() {
+			p.CloudInit = cloudInit
+		}
+	}
 
 	if dto.Zone == nil {
 		p.Zone = *fallbackZone
@@ -151,7 +191,23 @@ func (p VmUpdateParams) FromDTOv2(dto *body.VmUpdate) VmUpdateParams {
 	p.Name = dto.Name
 	p.CpuCores = dto.CpuCores
 	p.RAM = dto.RAM
+	p.DiskSize = dto.DiskSize
 	p.NeverStale = dto.NeverStale
+	p.SnapshotID = dto.SnapshotID
+
+	if dto.Disks != nil {
+		diskMap := fromDiskListDTOv2(*dto.Disks)
+		p.DiskMap = &diskMap
+	}
+
+	if dto.SnapshotPolicies != nil {
+		snapshotPolicyMap := fromSnapshotPolicyListDTOv2(*dto.SnapshotPolicies)
+		p.SnapshotPolicyMap = &snapshotPolicyMap
+	}
+
+	if dto.CloudInit != nil {
+		p.CloudInit = fromCloudInitDTOv2(dto.CloudInit)
+	}
 
 	if dto.Ports != nil {
 		portMap := make(map[string]PortUpdateParams)
@@ -203,6 +259,30 @@ func (sc *CreateSnapshotParams) FromDTOv2(dto *body.VmSnapshotCreate) {
 	sc.UserCreated = true
 }
 
+// fromSnapshotPolicyListDTOv2 converts a list of body.VmSnapshotPolicy to a map of SnapshotPolicy by name.
+// The policies start counting from now, so they do not take a snapshot right away.
+func fromSnapshotPolicyListDTOv2(policies []body.VmSnapshotPolicy) map[string]SnapshotPolicy {
+	now := time.Now()
+
+	snapshotPolicyMap := make(map[string]SnapshotPolicy)
+	for _, policy := range policies {
+		timezone := DefaultScheduleTimezone
+		if policy.Timezone != nil {
+			timezone = *policy.Timezone
+		}
+
+		snapshotPolicyMap[policy.Name] = SnapshotPolicy{
+			Name:      policy.Name,
+			Schedule:  policy.Schedule,
+			Retention: policy.Retention,
+			Timezone:  timezone,
+			LastRunAt: &now,
+		}
+	}
+
+	return snapshotPolicyMap
+}
+
 // fromPortCreateDTOv2 converts a body.PortCreate to a PortCreateParams.
 func fromPortCreateDTOv2(port *body.PortCreate) PortCreateParams {
 	var httpProxy *HttpProxyCreateParams
@@ -243,3 +323,99 @@ func fromPortUpdateDTOv2(port *body.PortUpdate) PortUpdateParams {
 func portName(privatePort int, protocol string) string {
 	return fmt.Sprintf("priv-%d-prot-%s", privatePort, protocol)
 }
+
+// ToDTOv2 converts a CloudInit to a body.VmCloudInit.
+func (ci *CloudInit) ToDTOv2() *body.VmCloudInit {
+	writeFiles := make([]body.VmCloudInitFile, len(ci.WriteFiles))
+	for i, file := range ci.WriteFiles {
+		writeFiles[i] = body.VmCloudInitFile{
+			Path:        file.Path,
+			Content:     file.Content,
+			Permissions: file.Permissions,
+			Owner:       file.Owner,
+			Append:      file.Append,
+		}
+	}
+
+	users := make([]body.VmCloudInitUser, len(ci.Users))
+	for i, user := range ci.Users {
+		users[i] = body.VmCloudInitUser{
+			Name:              user.Name,
+			SshAuthorizedKeys: user.SshAuthorizedKeys,
+			Groups:            user.Groups,
+			Shell:             user.Shell,
+			Sudo:              user.Sudo,
+		}
+	}
+
+	return &body.VmCloudInit{
+		Packages:   ci.Packages,
+		WriteFiles: writeFiles,
+		RunCMD:     ci.RunCMD,
+		Users:      users,
+	}
+}
+
+// fromCloudInitDTOv2 converts a body.VmCloudInit to a CloudInit.
+func fromCloudInitDTOv2(dto *body.VmCloudInit) *CloudInit {
+	writeFiles := make([]CloudInitFile, len(dto.WriteFiles))
+	for i, file := range dto.WriteFiles {
+		writeFiles[i] = CloudInitFile{
+			Path:        file.Path,
+			Content:     file.Content,
+			Permissions: file.Permissions,
+			Owner:       file.Owner,
+			Append:      file.Append,
+		}
+	}
+
+	users := make([]CloudInitUser, len(dto.Users))
+	for i, user := range dto.Users {
+		users[i] = CloudInitUser{
+			Name:              user.Name,
+			SshAuthorizedKeys: user.SshAuthorizedKeys,
+			Groups:            user.Groups,
+			Shell:             user.Shell,
+			Sudo:              user.Sudo,
+		}
+	}
+
+	return &CloudInit{
+		Packages:   dto.Packages,
+		WriteFiles: writeFiles,
+		RunCMD:     dto.RunCMD,
+		Users:      users,
+	}
+}
+
+// toDiskListDTOv2 converts a map of VmDisk to a list of body.VmDisk sorted by name.
+func toDiskListDTOv2(diskMap map[string]VmDisk) []body.VmDisk {
+	disks := make([]body.VmDisk, 0, len(diskMap))
+	for _, disk := range diskMap {
+		disks = append(disks, body.VmDisk{
+			Name:         disk.Name,
+			Size:         disk.Size,
+			StorageClass: disk.StorageClass,
+		})
+	}
+
+	sort.Slice(disks, func(i, j int) bool {
+		return disks[i].Name < disks[j].Name
+	})
+
+	return disks
+}
+
+// fromDiskListDTOv2 converts a list of body.VmDisk to a map of VmDisk by name.
+func fromDiskListDTOv2(disks []body.VmDisk) map[string]VmDisk {
+	diskMap := make(map[string]VmDisk)
+	for _, disk := range disks {
+		diskMap[disk.Name] = VmDisk{
+			Name:         disk.Name,
+			Size:         disk.Size,
+			StorageClass: disk.StorageClass,
+		}
+	}
+
+	return diskMap
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// VmImage is an image in the VM image catalogue that VMs can be created from.
+type VmImage struct {
+	// ID is the short name of the image, such as ubuntu-24.04.
+	ID          string `bson:"id"`
+	DisplayName string `bson:"displayName"`
+	// URL is where the disk image is imported from, either an http(s) URL or a docker:// container disk.
+	URL string `bson:"url"`
+	// DefaultUser is the user cloud-init creates with the SSH key of the VM.
+	DefaultUser string `bson:"defaultUser"`
+	// MinDiskSize is the smallest disk size in GB a VM with the image can have.
+	MinDiskSize int `bson:"minDiskSize"`
+	// Roles are the names of the roles that may use the image. If empty, every role may use it.
+	Roles []string `bson:"roles"`
+	// Bootstrap makes the VM clone and run kthcloud/boostrap-vm on its first boot.
+	Bootstrap bool `bson:"bootstrap"`
+
+	CreatedAt time.Time `bson:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt,omitempty"`
+}
+
+// VmImageRef is the image a VM was created from.
+// It is copied from the catalogue when the VM is created, so later changes to the catalogue do not affect the VM.
+type VmImageRef struct {
+	ID          string `bson:"id"`
+	URL         string `bson:"url"`
+	DefaultUser string `bson:"defaultUser"`
+	Bootstrap   bool   `bson:"bootstrap"`
+}
+
+// AllowedForRole returns whether a user with the given role may create VMs with the image.
+func (image *VmImage) AllowedForRole(role string) bool {
+	return len(image.Roles) == 0 || slices.Contains(image.Roles, role)
+}
+
+// ToRef returns the reference to the image that is stored in a VM.
+func (image *VmImage) ToRef() *VmImageRef {
+	return &VmImageRef{
+		ID:          image.ID,
+		URL:         image.URL,
+		DefaultUser: image.DefaultUser,
+		Bootstrap:   image.Bootstrap,
+	}
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/utils"
+)
+
+// ToDTO converts a VmImage to a body.VmImageRead.
+func (image *VmImage) ToDTO() body.VmImageRead {
+	return body.VmImageRead{
+		ID:          image.ID,
+		DisplayName: image.DisplayName,
+		URL:         image.URL,
+		DefaultUser: image.DefaultUser,
+		MinDiskSize: image.MinDiskSize,
+		Roles:       image.Roles,
+		Bootstrap:   image.Bootstrap,
+		CreatedAt:   image.CreatedAt,
+		UpdatedAt:   utils.NonZeroOrNil(image.UpdatedAt),
+	}
+}
+
+// FromDTO converts a body.VmImageCreate to a VmImageCreateParams.
+func (p VmImageCreateParams) FromDTO(dto *body.VmImageCreate) VmImageCreateParams {
+	p.ID = dto.ID
+	p.DisplayName = dto.DisplayName
+	p.URL = dto.URL
+	p.DefaultUser = dto.DefaultUser
+	p.MinDiskSize = dto.MinDiskSize
+	p.Roles = dto.Roles
+	p.Bootstrap = dto.Bootstrap
+	return p
+}
+
+// FromDTO converts a body.VmImageUpdate to a VmImageUpdateParams.
+func (p VmImageUpdateParams) FromDTO(dto *body.VmImageUpdate) VmImageUpdateParams {
+	p.DisplayName = dto.DisplayName
+	p.URL = dto.URL
+	p.DefaultUser = dto.DefaultUser
+	p.MinDiskSize = dto.MinDiskSize
+	p.Roles = dto.Roles
+	p.Bootstrap = dto.Bootstrap
+	return p
+}
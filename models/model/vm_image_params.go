@@ -0,0 +1,20 @@
+package model
+
+type VmImageCreateParams struct {
+	ID          string
+	DisplayName string
+	URL         string
+	DefaultUser string
+	MinDiskSize int
+	Roles       []string
+	Bootstrap   bool
+}
+
+type VmImageUpdateParams struct {
+	DisplayName *string
+	URL         *string
+	DefaultUser *string
+	MinDiskSize *int
+	Roles       *[]string
+	Bootstrap   *bool
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+const (
+	// LogSourceConsole is a log source for the serial console of a VM.
+	LogSourceConsole = "console"
+)
+
+// VmLog is a line of serial console output of a VM.
+// Logs are stored in their own collection, and are removed by MongoDB once ExpiresAt has passed.
+type VmLog struct {
+	VmID string `bson:"vmId"`
+	Zone string `bson:"zone"`
+
+	Line string `bson:"line"`
+
+	CreatedAt time.Time `bson:"createdAt"`
+	// ExpiresAt is when the log is removed, which is decided by the retention of the zone.
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
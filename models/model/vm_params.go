@@ -19,6 +19,13 @@ type VmCreateParams struct {
 	DiskSize int
 
 	NeverStale bool
+
+	SnapshotPolicyMap map[string]SnapshotPolicy
+	DiskMap           map[string]VmDisk
+	CloudInit         *CloudInit
+
+	// Image is set from the image catalogue when the VM is created, and is nil for the default image
+	Image *VmImageRef
 }
 
 type VmUpdateParams struct {
@@ -28,7 +35,16 @@ type VmUpdateParams struct {
 	PortMap    *map[string]PortUpdateParams
 	CpuCores   *int
 	RAM        *int
+	DiskSize   *int
 	NeverStale *bool
+
+	SnapshotPolicyMap *map[string]SnapshotPolicy
+	// DiskMap replaces the data disks. Disks that are left out are detached, but their volumes are kept.
+	DiskMap *map[string]VmDisk
+	// DetachedDiskMap replaces the detached data disks. An empty map removes them.
+	DetachedDiskMap *map[string]VmDisk
+	// CloudInit replaces the user-supplied cloud-init. An empty CloudInit removes it.
+	CloudInit *CloudInit
 }
 
 type VmUpdateOwnerParams struct {
@@ -1,5 +1,12 @@
 package model
 
+import "time"
+
+const (
+	// SnapshotPolicyTimeFormat is the format of the time suffix in the names of snapshots created by a snapshot policy.
+	SnapshotPolicyTimeFormat = "20060102-1504"
+)
+
 type VmHost struct {
 	Name string `bson:"name"`
 }
@@ -20,10 +27,71 @@ type Subsystems struct {
 	K8s VmK8s `bson:"k8s"`
 }
 
+// VmDisk is a data disk attached to a VM, in addition to its root disk.
+type VmDisk struct {
+	Name string `bson:"name"`
+	// Size is the size of the disk in GB.
+	Size int `bson:"size"`
+	// StorageClass is the storage class of the disk. It is nil for the default storage class.
+	StorageClass *string `bson:"storageClass,omitempty"`
+}
+
 type VmUsage struct {
-	CpuCores int `bson:"cpuCores"`
-	RAM      int `bson:"ram"`
-	DiskSize int `bson:"diskSize"`
+	CpuCores  int `bson:"cpuCores"`
+	RAM       int `bson:"ram"`
+	DiskSize  int `bson:"diskSize"`
+	Snapshots int `bson:"snapshots"`
+}
+
+// VmRestore is the state of a restore from a snapshot that is in progress.
+type VmRestore struct {
+	// PreRestoreSnapshotID is the snapshot of the state from before the restore, which makes it possible to undo it.
+	PreRestoreSnapshotID string `bson:"preRestoreSnapshotId"`
+	// WasRunning is whether the VM was running before it was stopped for the restore, and should be started again.
+	WasRunning bool `bson:"wasRunning"`
+}
+
+// SnapshotPolicy makes the system snapshot a VM on a schedule, such as daily at 03:00, and keeps the latest snapshots.
+type SnapshotPolicy struct {
+	Name string `bson:"name"`
+	// Schedule is the cron expression of when a snapshot is taken.
+	Schedule string `bson:"schedule"`
+	// Retention is the number of snapshots created by the policy that are kept.
+	Retention int `bson:"retention"`
+	// Timezone is the IANA timezone the cron expression is evaluated in.
+	Timezone string `bson:"timezone"`
+	// LastRunAt is when the policy last took a snapshot, or when it was created if it has not taken any yet.
+	LastRunAt *time.Time `bson:"lastRunAt,omitempty"`
+}
+
+// CloudInit is user-supplied cloud-config for a VM.
+// It is merged with the cloud-init the system requires when the VM is generated.
+type CloudInit struct {
+	Packages   []string        `bson:"packages,omitempty"`
+	WriteFiles []CloudInitFile `bson:"writeFiles,omitempty"`
+	RunCMD     []string        `bson:"runCmd,omitempty"`
+	Users      []CloudInitUser `bson:"users,omitempty"`
+}
+
+type CloudInitFile struct {
+	Path        string  `bson:"path"`
+	Content     string  `bson:"content"`
+	Permissions *string `bson:"permissions,omitempty"`
+	Owner       *string `bson:"owner,omitempty"`
+	Append      bool    `bson:"append"`
+}
+
+type CloudInitUser struct {
+	Name              string   `bson:"name"`
+	SshAuthorizedKeys []string `bson:"sshAuthorizedKeys,omitempty"`
+	Groups            []string `bson:"groups,omitempty"`
+	Shell             *string  `bson:"shell,omitempty"`
+	Sudo              bool     `bson:"sudo"`
+}
+
+// Empty returns whether the cloud-init does not add anything.
+func (ci *CloudInit) Empty() bool {
+	return len(ci.Packages) == 0 && len(ci.WriteFiles) == 0 && len(ci.RunCMD) == 0 && len(ci.Users) == 0
 }
 
 type VmStatus struct {
@@ -28,6 +28,7 @@ const (
 	JobFailed     = 10142
 	JobRunning    = 10143
 	JobTerminated = 10144
+	JobCancelled  = 10145
 
 	Success                  = 20001
 	InvalidParams            = 20002
@@ -64,6 +65,7 @@ var MsgFlags = map[int]string{
 	JobFailed:     "failed",
 	JobFinished:   "finished",
 	JobTerminated: "terminated",
+	JobCancelled:  "cancelled",
 
 	Success:                  "success",
 	Error:                    "error",
@@ -11,6 +11,7 @@ import (
 	"github.com/mitchellh/mapstructure"
 	"gopkg.in/yaml.v3"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/flowcontrol"
 	"os"
@@ -45,6 +46,7 @@ func SetupEnvironment(appMode string) error {
 
 	Config.Mode = appMode
 	Config.Filepath = filepath
+	setTimerDefaults()
 	config.LastRoleReload = time.Now()
 
 	log.Printf("go-deploy %s (Mode: %s)", version.AppVersion, appMode)
@@ -82,6 +84,26 @@ func SetupEnvironment(appMode string) error {
 	return nil
 }
 
+// setTimerDefaults sets the timers that configs written before they were introduced do not set.
+func setTimerDefaults() {
+	if Config.Timer.JobReap == 0 {
+		// Half of jobs.LeaseDuration, so abandoned jobs are requeued soon after their lease expires
+		Config.Timer.JobReap = 30 * time.Second
+	}
+
+	if Config.Timer.DeploymentAutoUpdate == 0 {
+		Config.Timer.DeploymentAutoUpdate = 10 * time.Minute
+	}
+
+	if Config.Timer.DeploymentSchedule == 0 {
+		Config.Timer.DeploymentSchedule = 1 * time.Minute
+	}
+
+	if Config.Timer.VmSnapshot == 0 {
+		Config.Timer.VmSnapshot = 1 * time.Minute
+	}
+}
+
 // checkConfig asserts that the config is correct.
 func checkConfig() error {
 	return nil
@@ -115,11 +137,12 @@ func setupK8sClusters() error {
 					return makeError(fmt.Errorf("failed to parse file config source for zone %s. details: %w", zone.Name, err))
 				}
 
-				k8sClient, kubevirtClient, err := createClientFromLocalPathConfig(zone.Name, &zoneConfig)
+				restConfig, k8sClient, kubevirtClient, err := createClientFromLocalPathConfig(zone.Name, &zoneConfig)
 				if err != nil {
 					return makeError(err)
 				}
 
+				Config.Zones[idx].K8s.RestConfig = restConfig
 				Config.Zones[idx].K8s.Client = k8sClient
 				Config.Zones[idx].K8s.KubeVirtClient = kubevirtClient
 			}
@@ -131,11 +154,12 @@ func setupK8sClusters() error {
 					return makeError(fmt.Errorf("failed to parse rancher config source for zone %s. details: %w", zone.Name, err))
 				}
 
-				k8sClient, kubevirtClient, err := createClientFromRancherConfig(zone.Name, &zoneConfig)
+				restConfig, k8sClient, kubevirtClient, err := createClientFromRancherConfig(zone.Name, &zoneConfig)
 				if err != nil {
 					return makeError(err)
 				}
 
+				Config.Zones[idx].K8s.RestConfig = restConfig
 				Config.Zones[idx].K8s.Client = k8sClient
 				Config.Zones[idx].K8s.KubeVirtClient = kubevirtClient
 			}
@@ -147,21 +171,21 @@ func setupK8sClusters() error {
 }
 
 // createClientFromLocalPathConfig creates a k8s client from a local path config.
-func createClientFromLocalPathConfig(zoneName string, config *config.LocalPathConfigSource) (*kubernetes.Clientset, *kubevirt.Clientset, error) {
+func createClientFromLocalPathConfig(zoneName string, config *config.LocalPathConfigSource) (*rest.Config, *kubernetes.Clientset, *kubevirt.Clientset, error) {
 	makeError := func(err error) error {
 		return fmt.Errorf("failed to create k8s client from local path config (zone: %s). details: %w", zoneName, err)
 	}
 
 	kubeConfig, err := os.ReadFile(config.Path)
 	if err != nil {
-		return nil, nil, makeError(err)
+		return nil, nil, nil, makeError(err)
 	}
 
 	return createK8sClients(kubeConfig)
 }
 
 // createClientFromRancherConfig creates a k8s client from a rancher config.
-func createClientFromRancherConfig(zoneName string, config *config.RancherConfigSource) (*kubernetes.Clientset, *kubevirt.Clientset, error) {
+func createClientFromRancherConfig(zoneName string, config *config.RancherConfigSource) (*rest.Config, *kubernetes.Clientset, *kubevirt.Clientset, error) {
 	makeError := func(err error) error {
 		return fmt.Errorf("failed to create k8s client from rancher config (zone: %s). details: %w", zoneName, err)
 	}
@@ -175,29 +199,29 @@ func createClientFromRancherConfig(zoneName string, config *config.RancherConfig
 			Secret: config.Secret,
 		})
 		if err != nil {
-			return nil, nil, makeError(err)
+			return nil, nil, nil, makeError(err)
 		}
 
 		kubeConfig, err := rancherClient.ReadClusterKubeConfig(config.ClusterName)
 		if err != nil {
-			return nil, nil, makeError(err)
+			return nil, nil, nil, makeError(err)
 		}
 
 		if kubeConfig == "" {
-			return nil, nil, makeError(fmt.Errorf("kubeconfig not found for cluster %s", config.ClusterName))
+			return nil, nil, nil, makeError(fmt.Errorf("kubeconfig not found for cluster %s", config.ClusterName))
 		}
 
 		cacheDir := "cache"
 		if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
 			err = os.Mkdir(cacheDir, 0755)
 			if err != nil {
-				return nil, nil, makeError(err)
+				return nil, nil, nil, makeError(err)
 			}
 		}
 
 		err = os.WriteFile(fmt.Sprintf("cache/rancher-%s.config", config.ClusterName), []byte(kubeConfig), 0644)
 		if err != nil {
-			return nil, nil, makeError(err)
+			return nil, nil, nil, makeError(err)
 		}
 
 		return createK8sClients([]byte(kubeConfig))
@@ -205,36 +229,37 @@ func createClientFromRancherConfig(zoneName string, config *config.RancherConfig
 
 	kubeConfig, err := os.ReadFile(fmt.Sprintf("cache/rancher-%s.config", config.ClusterName))
 	if err != nil {
-		return nil, nil, makeError(err)
+		return nil, nil, nil, makeError(err)
 	}
 
 	return createK8sClients(kubeConfig)
 }
 
 // createK8sClients creates a k8s client from config data.
-func createK8sClients(configData []byte) (*kubernetes.Clientset, *kubevirt.Clientset, error) {
+// The REST config is returned as well, since streaming subresources such as exec need it.
+func createK8sClients(configData []byte) (*rest.Config, *kubernetes.Clientset, *kubevirt.Clientset, error) {
 	makeError := func(err error) error {
 		return fmt.Errorf("failed to create k8s client. details: %w", err)
 	}
 
 	kubeConfig, err := clientcmd.RESTConfigFromKubeConfig(configData)
 	if err != nil {
-		return nil, nil, makeError(err)
+		return nil, nil, nil, makeError(err)
 	}
 
 	kubeConfig.RateLimiter = flowcontrol.NewFakeAlwaysRateLimiter()
 
 	k8sClient, err := kubernetes.NewForConfig(kubeConfig)
 	if err != nil {
-		return nil, nil, makeError(err)
+		return nil, nil, nil, makeError(err)
 	}
 
 	kubeVirtClient, err := kubevirt.NewForConfig(kubeConfig)
 	if err != nil {
-		return nil, nil, makeError(err)
+		return nil, nil, nil, makeError(err)
 	}
 
-	return k8sClient, kubeVirtClient, nil
+	return kubeConfig, k8sClient, kubeVirtClient, nil
 }
 
 // validateConfig validates the config and throws an error if it is invalid.
@@ -261,5 +286,11 @@ func validateConfig() error {
 		return fmt.Errorf("no default VM zone found")
 	}
 
+	if Config.Deployment.EnvEncryptionKey != "" {
+		if _, err := Config.Deployment.GetEnvEncryptionKey(); err != nil {
+			return fmt.Errorf("invalid env encryption key. details: %w", err)
+		}
+	}
+
 	return nil
 }
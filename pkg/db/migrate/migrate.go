@@ -2,10 +2,13 @@
 package migrator
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/kthcloud/go-deploy/models/model"
 	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_repo"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/vm_image_repo"
 	"github.com/kthcloud/go-deploy/pkg/log"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -39,6 +42,8 @@ func Migrate() error {
 func getMigrations() map[string]func() error {
 	return map[string]func() error{
 		"migratePrivateBooleanToVisibilityEnum_2024_06_10": migratePrivateBooleanToVisibilityEnum_2024_06_10,
+		"seedVmImageCatalogue_2026_10_18":                  seedVmImageCatalogue_2026_10_18,
+		"removeDeploymentLogArrays_2026_10_18":             removeDeploymentLogArrays_2026_10_18,
 	}
 }
 
@@ -69,3 +74,64 @@ func migratePrivateBooleanToVisibilityEnum_2024_06_10() error {
 
 	return nil
 }
+
+// seedVmImageCatalogue_2026_10_18 adds the default images to the VM image catalogue.
+// It only seeds an empty catalogue, so that images that are deleted by an admin are not added again.
+func seedVmImageCatalogue_2026_10_18() error {
+	count, err := vm_image_repo.New().Count()
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	images := []model.VmImageCreateParams{
+		{
+			ID:          "ubuntu-24-04",
+			DisplayName: "Ubuntu 24.04",
+			URL:         "https://cloud-images.ubuntu.com/noble/current/noble-server-cloudimg-amd64.img",
+			DefaultUser: "ubuntu",
+			MinDiskSize: 10,
+			Bootstrap:   true,
+		},
+		{
+			ID:          "debian-12",
+			DisplayName: "Debian 12",
+			URL:         "https://cloud.debian.org/images/cloud/bookworm/latest/debian-12-generic-amd64.qcow2",
+			DefaultUser: "debian",
+			MinDiskSize: 10,
+		},
+		{
+			ID:          "rocky-9",
+			DisplayName: "Rocky Linux 9",
+			URL:         "https://dl.rockylinux.org/pub/rocky/9/images/x86_64/Rocky-9-GenericCloud-Base.latest.x86_64.qcow2",
+			DefaultUser: "rocky",
+			MinDiskSize: 10,
+		},
+	}
+
+	for _, image := range images {
+		_, err = vm_image_repo.New().Create(&image)
+		if err != nil && !errors.Is(err, vm_image_repo.ErrVmImageAlreadyExists) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeDeploymentLogArrays_2026_10_18 removes the logs that used to be stored on the deployments.
+// They are stored in the deploymentLogs collection instead.
+func removeDeploymentLogArrays_2026_10_18() error {
+	_, err := deployment_repo.New().Collection.UpdateMany(context.TODO(),
+		bson.D{{Key: "logs", Value: bson.D{{Key: "$exists", Value: true}}}},
+		bson.D{{Key: "$unset", Value: bson.D{{Key: "logs", Value: ""}}}},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
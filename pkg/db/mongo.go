@@ -25,6 +25,10 @@ type CollectionDefinition struct {
 	// unique even for deleted documents
 	TotallyUniqueIndexes [][]string
 	TextIndexFields      []string
+	// indexes over several fields, in the given order
+	CompoundIndexes [][]string
+	// documents are removed once the time in the field has passed
+	ExpiryIndexes []string
 }
 
 // setupMongo initializes the MongoDB connection.
@@ -140,6 +144,45 @@ func (dbCtx *Context) setupMongo() error {
 
 	log.Printf(" - Ensured %d text indexes", ensureCount)
 
+	ensureCount = 0
+	for _, def := range DB.CollectionDefinitionMap {
+		for _, indexName := range def.CompoundIndexes {
+			keys := bson.D{}
+			for _, key := range indexName {
+				keys = append(keys, bson.E{Key: key, Value: 1})
+			}
+
+			_, err = DB.GetCollection(def.Name).Indexes().CreateOne(context.Background(), mongo.IndexModel{
+				Keys:    keys,
+				Options: options.Index().SetUnique(false),
+			})
+			if err != nil && !isIndexExistsError(err) {
+				return makeError(err)
+			}
+
+			ensureCount++
+		}
+	}
+
+	log.Printf(" - Ensured %d compound indexes", ensureCount)
+
+	ensureCount = 0
+	for _, def := range DB.CollectionDefinitionMap {
+		for _, indexName := range def.ExpiryIndexes {
+			_, err = DB.GetCollection(def.Name).Indexes().CreateOne(context.Background(), mongo.IndexModel{
+				Keys:    bson.D{{Key: indexName, Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			})
+			if err != nil && !isIndexExistsError(err) {
+				return makeError(err)
+			}
+
+			ensureCount++
+		}
+	}
+
+	log.Printf(" - Ensured %d expiry indexes", ensureCount)
+
 	return nil
 }
 
@@ -170,6 +213,18 @@ func GetCollectionDefinitions() map[string]CollectionDefinition {
 			UniqueIndexes:        [][]string{{"name"}},
 			TotallyUniqueIndexes: [][]string{{"id"}},
 		},
+		"deploymentLogs": {
+			Name:            "deploymentLogs",
+			Indexes:         []string{"deploymentId", "createdAt", "source", "pod"},
+			CompoundIndexes: [][]string{{"deploymentId", "createdAt"}},
+			ExpiryIndexes:   []string{"expiresAt"},
+		},
+		"deploymentRevisions": {
+			Name:                 "deploymentRevisions",
+			Indexes:              []string{"deploymentId", "createdAt"},
+			UniqueIndexes:        [][]string{{"deploymentId", "version"}},
+			TotallyUniqueIndexes: [][]string{{"id"}},
+		},
 		"events": {
 			Name:                 "events",
 			Indexes:              []string{"type", "createdAt", "source.userId"},
@@ -195,7 +250,7 @@ func GetCollectionDefinitions() map[string]CollectionDefinition {
 		},
 		"jobs": {
 			Name:                 "jobs",
-			Indexes:              []string{"userId", "type", "args.id", "status", "createdAt", "runAfter"},
+			Indexes:              []string{"userId", "type", "args.id", "status", "createdAt", "runAfter", "waitingFor", "leaseExpiresAt"},
 			TotallyUniqueIndexes: [][]string{{"id"}},
 		},
 		"notifications": {
@@ -247,6 +302,16 @@ func GetCollectionDefinitions() map[string]CollectionDefinition {
 			UniqueIndexes:        [][]string{{"name"}},
 			TotallyUniqueIndexes: [][]string{{"id"}},
 		},
+		"vmImages": {
+			Name:                 "vmImages",
+			Indexes:              []string{"roles", "createdAt"},
+			TotallyUniqueIndexes: [][]string{{"id"}},
+		},
+		"vmLogs": {
+			Name:          "vmLogs",
+			Indexes:       []string{"vmId", "createdAt"},
+			ExpiryIndexes: []string{"expiresAt"},
+		},
 		"vmPorts": {
 			Name:          "vmPorts",
 			Indexes:       []string{"publicPort", "zone", "lease.privatePort", "lease.userId", "lease.vmId"},
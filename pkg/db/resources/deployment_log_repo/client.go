@@ -0,0 +1,98 @@
+package deployment_log_repo
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/db"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/base_clients"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Client is used to manage deployment logs in the database.
+type Client struct {
+	Collection *mongo.Collection
+
+	base_clients.ResourceClient[model.DeploymentLog]
+}
+
+// New returns a new deployment log client.
+// Logs are sorted by when they were created, with the oldest log first.
+func New() *Client {
+	return &Client{
+		Collection: db.DB.GetCollection("deploymentLogs"),
+
+		ResourceClient: base_clients.ResourceClient[model.DeploymentLog]{
+			Collection:     db.DB.GetCollection("deploymentLogs"),
+			IncludeDeleted: false,
+			SortBy: &db.SortBy{
+				Field: "createdAt",
+				Order: 1,
+			},
+		},
+	}
+}
+
+// WithPagination adds pagination to the client.
+func (client *Client) WithPagination(page, pageSize int) *Client {
+	client.ResourceClient.Pagination = &db.Pagination{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	return client
+}
+
+// WithDeploymentID adds a filter to the client to only include logs of the given deployment.
+func (client *Client) WithDeploymentID(deploymentID string) *Client {
+	client.ResourceClient.AddExtraFilter(bson.D{{Key: "deploymentId", Value: deploymentID}})
+
+	return client
+}
+
+// WithSources adds a filter to the client to only include logs from the given sources.
+func (client *Client) WithSources(sources ...string) *Client {
+	client.ResourceClient.AddExtraFilter(bson.D{{Key: "source", Value: bson.D{{Key: "$in", Value: sources}}}})
+
+	return client
+}
+
+// WithPod adds a filter to the client to only include logs from the given pod.
+func (client *Client) WithPod(pod string) *Client {
+	client.ResourceClient.AddExtraFilter(bson.D{{Key: "pod", Value: pod}})
+
+	return client
+}
+
+// CreatedAfter adds a filter to the client to only include logs created after the given time.
+func (client *Client) CreatedAfter(t time.Time) *Client {
+	client.ResourceClient.AddExtraFilter(bson.D{{Key: "createdAt", Value: bson.D{{Key: "$gt", Value: t}}}})
+
+	return client
+}
+
+// CreatedSince adds a filter to the client to only include logs created at or after the given time.
+func (client *Client) CreatedSince(t time.Time) *Client {
+	client.ResourceClient.AddExtraFilter(bson.D{{Key: "createdAt", Value: bson.D{{Key: "$gte", Value: t}}}})
+
+	return client
+}
+
+// CreatedBefore adds a filter to the client to only include logs created before the given time.
+func (client *Client) CreatedBefore(t time.Time) *Client {
+	client.ResourceClient.AddExtraFilter(bson.D{{Key: "createdAt", Value: bson.D{{Key: "$lt", Value: t}}}})
+
+	return client
+}
+
+// WithSearch adds a filter to the client to only include logs whose line contains the given text, ignoring case.
+func (client *Client) WithSearch(text string) *Client {
+	client.ResourceClient.AddExtraFilter(bson.D{{Key: "line", Value: bson.D{
+		{Key: "$regex", Value: regexp.QuoteMeta(text)},
+		{Key: "$options", Value: "i"},
+	}}})
+
+	return client
+}
@@ -0,0 +1,98 @@
+package deployment_log_repo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/db"
+	"github.com/kthcloud/go-deploy/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Create stores logs for the deployment.
+// The logs expire after the given retention, and are removed by MongoDB once expired.
+func (client *Client) Create(deploymentID, zone string, retention time.Duration, logs ...model.Log) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, len(logs))
+	for i, log := range logs {
+		documents[i] = model.DeploymentLog{
+			DeploymentID: deploymentID,
+			Zone:         zone,
+			Source:       log.Source,
+			Prefix:       log.Prefix,
+			Line:         log.Line,
+			Pod:          log.Pod,
+			CreatedAt:    log.CreatedAt,
+			ExpiresAt:    log.CreatedAt.Add(retention),
+		}
+	}
+
+	_, err := client.Collection.InsertMany(context.TODO(), documents, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		return fmt.Errorf("failed to create logs for deployment %s. details: %w", deploymentID, err)
+	}
+
+	return nil
+}
+
+// ListLatest returns the n latest logs that match the filter, with the oldest log first.
+func (client *Client) ListLatest(n int) ([]model.DeploymentLog, error) {
+	client.SortBy = &db.SortBy{Field: "createdAt", Order: -1}
+	client.WithPagination(0, n)
+
+	logs, err := client.List()
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(logs)
+	return logs, nil
+}
+
+// ForEach calls the callback for every log that matches the filter, in order.
+// Pagination is ignored, so that all logs can be read without loading them into memory at once.
+//
+// It stops and returns the error if the callback returns an error.
+func (client *Client) ForEach(ctx context.Context, callback func(*model.DeploymentLog) error) error {
+	filter := db.GroupFilters(bson.D{}, client.ExtraFilter, client.Search, client.IncludeDeleted)
+
+	findOptions := options.Find()
+	if client.SortBy != nil {
+		findOptions.SetSort(bson.D{{Key: client.SortBy.Field, Value: client.SortBy.Order}})
+	}
+
+	cursor, err := client.Collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return fmt.Errorf("failed to find logs. details: %w", err)
+	}
+
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		closeErr := cursor.Close(ctx)
+		if closeErr != nil {
+			utils.PrettyPrintError(fmt.Errorf("failed to close cursor. details: %w", closeErr))
+		}
+	}(cursor, context.Background())
+
+	for cursor.Next(ctx) {
+		var log model.DeploymentLog
+		err = cursor.Decode(&log)
+		if err != nil {
+			return fmt.Errorf("failed to decode log. details: %w", err)
+		}
+
+		err = callback(&log)
+		if err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
@@ -162,6 +162,26 @@ func (client *Client) WithPendingCustomDomain() *Client {
 	return client
 }
 
+// WithAutoUpdate adds a filter to the client to only include deployments with an auto-update policy.
+func (client *Client) WithAutoUpdate() *Client {
+	filter := bson.D{{Key: "apps.main.autoUpdate", Value: bson.D{{Key: "$exists", Value: true}}}}
+
+	client.ResourceClient.AddExtraFilter(filter)
+	client.ActivityResourceClient.AddExtraFilter(filter)
+
+	return client
+}
+
+// WithScalingSchedule adds a filter to the client to only include deployments with a scaling schedule.
+func (client *Client) WithScalingSchedule() *Client {
+	filter := bson.D{{Key: "schedule", Value: bson.D{{Key: "$exists", Value: true}}}}
+
+	client.ResourceClient.AddExtraFilter(filter)
+	client.ActivityResourceClient.AddExtraFilter(filter)
+
+	return client
+}
+
 // WithZone adds a filter to the client to only include deployments in the given zone.
 func (client *Client) WithZone(zone ...string) *Client {
 	filter := bson.D{{Key: "zone", Value: bson.D{{Key: "$in", Value: zone}}}}
@@ -192,8 +212,13 @@ func (client *Client) Enabled() *Client {
 }
 
 // Disabled adds a filter to the client to only include deployments that are disabled.
+// Deployments that are paused or temporarily scaled to zero replicas are disabled as well.
 func (client *Client) Disabled() *Client {
-	filter := bson.D{{Key: "apps.main.replicas", Value: 0}}
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "apps.main.replicas", Value: 0}},
+		bson.D{{Key: "paused", Value: true}},
+		bson.D{{Key: "scaleOverride.replicas", Value: 0}},
+	}}}
 
 	client.ResourceClient.AddExtraFilter(filter)
 
@@ -2,9 +2,8 @@ package deployment_repo
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"sort"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,7 +15,6 @@ import (
 	"github.com/kthcloud/go-deploy/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // Create creates a new deployment with the given params.
@@ -51,12 +49,20 @@ func (client *Client) Create(id, ownerID string, params *model.DeploymentCreateP
 		Args:         params.Args,
 		InitCommands: params.InitCommands,
 		CustomDomain: customDomain,
+		Probes:       params.Probes,
+		Autoscaling:  params.Autoscaling,
+		AutoUpdate:   params.AutoUpdate,
 
 		ReplicaStatus: nil,
 		PingPath:      params.PingPath,
 		PingResult:    0,
 	}
 
+	apps := map[string]model.App{appName: mainApp}
+	for _, sidecar := range params.Sidecars {
+		apps[sidecar.Name] = sidecar.ToApp()
+	}
+
 	deployment := model.Deployment{
 		ID:      id,
 		Name:    params.Name,
@@ -73,13 +79,16 @@ func (client *Client) Create(id, ownerID string, params *model.DeploymentCreateP
 
 		NeverStale: params.NeverStale,
 
+		Git:      params.Git,
+		Strategy: params.Strategy,
+		Schedule: params.Schedule,
+
 		Activities: map[string]model.Activity{model.ActivityBeingCreated: {
 			Name:      model.ActivityBeingCreated,
 			CreatedAt: time.Now(),
 		}},
-		Apps:       map[string]model.App{appName: mainApp},
+		Apps:       apps,
 		Subsystems: model.DeploymentSubsystems{},
-		Logs:       make([]model.Log, 0),
 		Status:     status_codes.GetMsg(status_codes.ResourceCreating),
 	}
 
@@ -132,6 +141,7 @@ func (client *Client) UpdateWithParams(id string, params *model.DeploymentUpdate
 	}
 
 	db.AddIfNotNil(&setUpdate, "name", params.Name)
+	db.AddIfNotNil(&setUpdate, "git", params.Git)
 	db.AddIfNotNil(&setUpdate, "ownerId", params.OwnerID)
 	db.AddIfNotNil(&setUpdate, "apps.main.internalPort", params.InternalPort)
 	db.AddIfNotNil(&setUpdate, "apps.main.internalPorts", params.InternalPorts)
@@ -149,97 +159,79 @@ func (client *Client) UpdateWithParams(id string, params *model.DeploymentUpdate
 	db.AddIfNotNil(&setUpdate, "apps.main.visibility", params.Visibility)
 	db.AddIfNotNil(&setUpdate, "neverStale", params.NeverStale)
 
-	err = client.UpdateWithBsonByID(id,
-		bson.D{
-			{Key: "$set", Value: setUpdate},
-			{Key: "$unset", Value: unsetUpdate},
-		},
-	)
-	if err != nil {
-		if mongo.IsDuplicateKeyError(err) {
-			return rErrors.ErrNonUniqueField
+	if params.Probes != nil {
+		if params.Probes.Empty() {
+			db.Add(&unsetUpdate, "apps.main.probes", "")
+		} else {
+			db.Add(&setUpdate, "apps.main.probes", params.Probes)
 		}
-
-		return fmt.Errorf("failed to update deployment %s. details: %w", id, err)
 	}
 
-	return nil
-}
-
-// GetLogs returns the last NLogsCache logs for a deployment
-func (client *Client) GetLogs(id string, history int) ([]model.Log, error) {
-	projection := bson.D{
-		{Key: "logs", Value: bson.D{
-			{Key: "$slice", Value: -history},
-		}},
-	}
-
-	var deployment model.Deployment
-	err := client.Collection.FindOne(context.TODO(),
-		bson.D{{Key: "id", Value: id}},
-		options.FindOne().SetProjection(projection),
-	).Decode(&deployment)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return []model.Log{}, nil
+	if params.Autoscaling != nil {
+		if params.Autoscaling.Empty() {
+			db.Add(&unsetUpdate, "apps.main.autoscaling", "")
+		} else {
+			db.Add(&setUpdate, "apps.main.autoscaling", params.Autoscaling)
 		}
-
-		return nil, err
 	}
 
-	return deployment.Logs, nil
-}
-
-// GetLogsAfter returns the logs after the given time, with a maximum of NLogsCache logs.
-func (client *Client) GetLogsAfter(id string, createdAt time.Time) ([]model.Log, error) {
-	projection := bson.D{
-		{Key: "logs", Value: bson.D{
-			{Key: "$slice", Value: -model.NLogsCache},
-		}},
-	}
-
-	filter := bson.D{
-		{Key: "id", Value: id},
+	if params.AutoUpdate != nil {
+		if params.AutoUpdate.Policy == model.AutoUpdatePolicyNone {
+			db.Add(&unsetUpdate, "apps.main.autoUpdate", "")
+		} else {
+			db.Add(&setUpdate, "apps.main.autoUpdate", params.AutoUpdate)
+		}
 	}
 
-	deployment, err := client.GetWithFilterAndProjection(filter, projection)
-	if err != nil {
-		return nil, err
+	// The resolved digest belongs to the previous image or policy, and would otherwise pin the app to the old image
+	if (params.Image != nil && *params.Image != mainApp.Image) || params.AutoUpdate != nil {
+		db.Add(&unsetUpdate, "apps.main.imageDigest", "")
+		db.Add(&unsetUpdate, "apps.main.imageDigestPinned", "")
 	}
 
-	if deployment == nil {
-		return nil, nil
+	if params.Strategy != nil {
+		if params.Strategy.Type == model.RolloutStrategyRolling {
+			db.Add(&unsetUpdate, "strategy", "")
+		} else {
+			db.Add(&setUpdate, "strategy", params.Strategy)
+		}
 	}
 
-	filtered := make([]model.Log, 0)
-	for _, item := range deployment.Logs {
-		if item.CreatedAt.After(createdAt) {
-			filtered = append(filtered, item)
+	if params.Schedule != nil {
+		if len(params.Schedule.Windows) == 0 {
+			db.Add(&unsetUpdate, "schedule", "")
+		} else {
+			// The replicas to restore are kept, so that an active window still ends as expected
+			db.Add(&setUpdate, "schedule.windows", params.Schedule.Windows)
+			db.Add(&setUpdate, "schedule.timezone", params.Schedule.Timezone)
 		}
 	}
 
-	return filtered, nil
-}
-
-// AddLogsByName adds logs to the end of the log array.
-// Only the last NLogsCache logs are kept, and are sorted by createdAt
-func (client *Client) AddLogsByName(name string, logs ...model.Log) error {
-	sort.Slice(logs, func(i, j int) bool {
-		return logs[i].CreatedAt.Before(logs[j].CreatedAt)
-	})
+	if params.Sidecars != nil {
+		// The sidecars in the params replace all existing sidecars
+		for _, sidecar := range deployment.GetSidecarApps() {
+			if !slices.ContainsFunc(*params.Sidecars, func(p model.DeploymentSidecarParams) bool { return p.Name == sidecar.Name }) {
+				db.Add(&unsetUpdate, fmt.Sprintf("apps.%s", sidecar.Name), "")
+			}
+		}
 
-	update := bson.D{
-		{Key: "$push", Value: bson.D{
-			{Key: "logs", Value: bson.D{
-				{Key: "$each", Value: logs},
-				{Key: "$slice", Value: -model.NLogsCache},
-			}},
-		}},
+		for _, sidecar := range *params.Sidecars {
+			db.Add(&setUpdate, fmt.Sprintf("apps.%s", sidecar.Name), sidecar.ToApp())
+		}
 	}
 
-	err := client.UpdateWithBsonByName(name, update)
+	err = client.UpdateWithBsonByID(id,
+		bson.D{
+			{Key: "$set", Value: setUpdate},
+			{Key: "$unset", Value: unsetUpdate},
+		},
+	)
 	if err != nil {
-		return err
+		if mongo.IsDuplicateKeyError(err) {
+			return rErrors.ErrNonUniqueField
+		}
+
+		return fmt.Errorf("failed to update deployment %s. details: %w", id, err)
 	}
 
 	return nil
@@ -249,10 +241,10 @@ func (client *Client) AddLogsByName(name string, logs ...model.Log) error {
 func (client *Client) GetUsage() (*model.DeploymentUsage, error) {
 	projection := bson.D{
 		{Key: "_id", Value: 0},
-		{Key: "apps.main.replicas", Value: 1},
-		{Key: "apps.main.cpuCores", Value: 1},
-		{Key: "apps.main.ram", Value: 1},
-		{Key: "apps.main.gpus", Value: 1},
+		{Key: "apps", Value: 1},
+		{Key: "schedule", Value: 1},
+		{Key: "scaleOverride", Value: 1},
+		{Key: "rollout", Value: 1},
 	}
 
 	deployments, err := client.ListWithFilterAndProjection(bson.D{}, projection)
@@ -263,11 +255,24 @@ func (client *Client) GetUsage() (*model.DeploymentUsage, error) {
 	usage := &model.DeploymentUsage{}
 
 	for _, deployment := range deployments {
-		for _, app := range deployment.Apps {
-			usage.CpuCores += app.CpuCores * float64(app.Replicas)
-			usage.RAM += app.RAM * float64(app.Replicas)
+		for name, app := range deployment.Apps {
+			if name == "main" {
+				// Scheduled apps are counted at their peak, so that the replicas can be restored when a window ends.
+				// A temporary scale is counted as well, since it can go beyond the configured replicas,
+				// and so is the new version during a rollout, since it runs next to the old one
+				mainUsage := deployment.GetMainUsage()
+				usage.CpuCores += mainUsage.CpuCores
+				usage.RAM += mainUsage.RAM
+				usage.Gpus += mainUsage.Gpus
+				continue
+			}
+
+			// Autoscaled apps can use up to their max replicas, so quota is counted against that
+			replicas := app.GetReplicaLimit()
+			usage.CpuCores += app.CpuCores * float64(replicas)
+			usage.RAM += app.RAM * float64(replicas)
 			if app.GPUs != nil {
-				usage.Gpus += len(app.GPUs) * app.Replicas
+				usage.Gpus += len(app.GPUs) * replicas
 			}
 		}
 	}
@@ -343,6 +348,130 @@ func (client *Client) MarkUpdated(id string) error {
 	return nil
 }
 
+// SetPaused sets whether a deployment is paused.
+func (client *Client) SetPaused(id string, paused bool) error {
+	if paused {
+		return client.SetWithBsonByID(id, bson.D{{Key: "paused", Value: true}})
+	}
+
+	return client.UnsetByID(id, "paused")
+}
+
+// SetImageWebhook sets the image webhook of a deployment.
+// If imageWebhook is nil, the webhook is removed.
+func (client *Client) SetImageWebhook(id string, imageWebhook *model.ImageWebhook) error {
+	if imageWebhook == nil {
+		return client.UnsetByID(id, "imageWebhook")
+	}
+
+	return client.SetWithBsonByID(id, bson.D{{Key: "imageWebhook", Value: imageWebhook}})
+}
+
+// StartRollout sets the active rollout of a deployment.
+// Nothing is updated if a rollout is already active, so that the version it replaces is kept.
+func (client *Client) StartRollout(id string, rollout *model.Rollout) error {
+	filter := bson.D{
+		{Key: "id", Value: id},
+		{Key: "rollout", Value: bson.D{{Key: "$exists", Value: false}}},
+	}
+
+	return client.SetWithBsonByFilter(filter, bson.D{{Key: "rollout", Value: rollout}})
+}
+
+// PromoteRollout removes the active rollout of a deployment, which leaves the new version of the main app as the only one.
+func (client *Client) PromoteRollout(id string) error {
+	return client.UnsetByID(id, "rollout")
+}
+
+// AbortRollout restores the pod template of the main app to the version from before the active rollout, and removes the rollout.
+// Settings that do not affect the pods, such as the replicas and the custom domain, keep their current values.
+func (client *Client) AbortRollout(id string, stable *model.App) error {
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "apps.main.image", Value: stable.Image},
+			{Key: "apps.main.imageDigest", Value: stable.ImageDigest},
+			{Key: "apps.main.imageDigestPinned", Value: stable.ImageDigestPinned},
+			{Key: "apps.main.envs", Value: stable.Envs},
+			{Key: "apps.main.args", Value: stable.Args},
+			{Key: "apps.main.initCommands", Value: stable.InitCommands},
+			{Key: "apps.main.internalPort", Value: stable.InternalPort},
+			{Key: "apps.main.internalPorts", Value: stable.InternalPorts},
+			{Key: "apps.main.volumes", Value: stable.Volumes},
+			{Key: "apps.main.gpus", Value: stable.GPUs},
+			{Key: "apps.main.cpuCores", Value: stable.CpuCores},
+			{Key: "apps.main.ram", Value: stable.RAM},
+			{Key: "apps.main.probes", Value: stable.Probes},
+		}},
+		{Key: "$unset", Value: bson.D{{Key: "rollout", Value: ""}}},
+	}
+
+	return client.UpdateWithBsonByID(id, update)
+}
+
+// SetImageDigest sets the resolved digest of the main app's image and marks the image as checked.
+// If pin is true, the image of the main app is also pinned to the digest.
+// Nothing is updated if the main app no longer has an auto-update policy, e.g. if it was removed while checking.
+func (client *Client) SetImageDigest(id, digest string, pin bool) error {
+	filter := bson.D{
+		{Key: "id", Value: id},
+		{Key: "apps.main.autoUpdate", Value: bson.D{{Key: "$exists", Value: true}}},
+	}
+
+	update := bson.D{
+		{Key: "apps.main.imageDigest", Value: digest},
+		{Key: "apps.main.autoUpdate.checkedAt", Value: time.Now()},
+	}
+
+	if pin {
+		update = append(update, bson.E{Key: "apps.main.imageDigestPinned", Value: true})
+	}
+
+	return client.SetWithBsonByFilter(filter, update)
+}
+
+// SetScaleOverride sets a temporary replica count for the main app of a deployment.
+func (client *Client) SetScaleOverride(id string, override *model.ScaleOverride) error {
+	return client.SetWithBsonByID(id, bson.D{{Key: "scaleOverride", Value: override}})
+}
+
+// ClearExpiredScaleOverride removes the scale override of a deployment if it has expired.
+// It returns whether an override was removed, which is not the case if it was replaced by a later one.
+func (client *Client) ClearExpiredScaleOverride(id string) (bool, error) {
+	filter := bson.D{
+		{Key: "id", Value: id},
+		{Key: "scaleOverride.expiresAt", Value: bson.D{{Key: "$lte", Value: time.Now()}}},
+	}
+	update := bson.D{{Key: "$unset", Value: bson.D{{Key: "scaleOverride", Value: ""}}}}
+
+	res, err := client.Collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return res.ModifiedCount > 0, nil
+}
+
+// SetScheduleScaled sets the replicas the scaling schedule scaled the main app from and to.
+// If scaledFrom is nil, both are removed. Nothing is updated if the deployment no longer has a schedule.
+func (client *Client) SetScheduleScaled(id string, scaledFrom, scaledTo *int) error {
+	filter := bson.D{
+		{Key: "id", Value: id},
+		{Key: "schedule", Value: bson.D{{Key: "$exists", Value: true}}},
+	}
+
+	if scaledFrom == nil {
+		return client.UpdateWithBsonByFilter(filter, bson.D{{Key: "$unset", Value: bson.D{
+			{Key: "schedule.scaledFrom", Value: ""},
+			{Key: "schedule.scaledTo", Value: ""},
+		}}})
+	}
+
+	return client.SetWithBsonByFilter(filter, bson.D{
+		{Key: "schedule.scaledFrom", Value: *scaledFrom},
+		{Key: "schedule.scaledTo", Value: scaledTo},
+	})
+}
+
 // MarkAccessed marks a deployment as accessed to the current time.
 func (client *Client) MarkAccessed(id string) error {
 	return client.SetWithBsonByID(id, bson.D{{Key: "accessedAt", Value: time.Now()}})
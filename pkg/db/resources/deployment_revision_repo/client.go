@@ -0,0 +1,64 @@
+package deployment_revision_repo
+
+import (
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/db"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/base_clients"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Client is used to manage deployment revisions in the database.
+type Client struct {
+	Collection *mongo.Collection
+
+	base_clients.ResourceClient[model.DeploymentRevision]
+}
+
+// New returns a new deployment revision client.
+// Revisions are sorted by version, with the latest revision first.
+func New() *Client {
+	return &Client{
+		Collection: db.DB.GetCollection("deploymentRevisions"),
+
+		ResourceClient: base_clients.ResourceClient[model.DeploymentRevision]{
+			Collection:     db.DB.GetCollection("deploymentRevisions"),
+			IncludeDeleted: false,
+			SortBy: &db.SortBy{
+				Field: "version",
+				Order: -1,
+			},
+		},
+	}
+}
+
+// WithPagination adds pagination to the client.
+func (client *Client) WithPagination(page, pageSize int) *Client {
+	client.ResourceClient.Pagination = &db.Pagination{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	return client
+}
+
+// WithDeploymentID adds a filter to the client to only include revisions of the given deployment.
+func (client *Client) WithDeploymentID(deploymentID string) *Client {
+	client.ResourceClient.AddExtraFilter(bson.D{{Key: "deploymentId", Value: deploymentID}})
+
+	return client
+}
+
+// WithVersion adds a filter to the client to only include revisions with the given version.
+func (client *Client) WithVersion(version int) *Client {
+	client.ResourceClient.AddExtraFilter(bson.D{{Key: "version", Value: version}})
+
+	return client
+}
+
+// OlderThan adds a filter to the client to only include revisions with a version lower than the given version.
+func (client *Client) OlderThan(version int) *Client {
+	client.ResourceClient.AddExtraFilter(bson.D{{Key: "version", Value: bson.D{{Key: "$lt", Value: version}}}})
+
+	return client
+}
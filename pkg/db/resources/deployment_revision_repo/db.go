@@ -0,0 +1,76 @@
+package deployment_revision_repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kthcloud/go-deploy/models/model"
+	rErrors "github.com/kthcloud/go-deploy/pkg/db/resources/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Create creates a new revision for the deployment.
+// The version of the revision is one higher than the latest revision of the deployment.
+//
+// It returns ErrNonUniqueField if another revision was created with the same version at the same time.
+func (client *Client) Create(id, deploymentID string, params *model.DeploymentRevisionCreateParams) (*model.DeploymentRevision, error) {
+	latest, err := New().WithDeploymentID(deploymentID).GetLatest()
+	if err != nil {
+		return nil, err
+	}
+
+	version := 1
+	if latest != nil {
+		version = latest.Version + 1
+	}
+
+	revision := &model.DeploymentRevision{
+		ID:           id,
+		DeploymentID: deploymentID,
+		Version:      version,
+
+		Image:       params.Image,
+		ImageDigest: params.ImageDigest,
+
+		CpuCores: params.CpuCores,
+		RAM:      params.RAM,
+		Replicas: params.Replicas,
+
+		InternalPort:  params.InternalPort,
+		InternalPorts: params.InternalPorts,
+		Envs:          params.Envs,
+		Volumes:       params.Volumes,
+		Args:          params.Args,
+		Visibility:    params.Visibility,
+
+		CreatedAt: time.Now(),
+	}
+
+	_, err = client.Collection.InsertOne(context.TODO(), revision)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, rErrors.ErrNonUniqueField
+		}
+
+		return nil, fmt.Errorf("failed to create revision for deployment %s. details: %w", deploymentID, err)
+	}
+
+	return revision, nil
+}
+
+// GetLatest returns the revision with the highest version that matches the filter.
+func (client *Client) GetLatest() (*model.DeploymentRevision, error) {
+	client.WithPagination(0, 1)
+
+	revisions, err := client.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest revision. details: %w", err)
+	}
+
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+
+	return &revisions[0], nil
+}
@@ -33,6 +33,20 @@ func (client *Client) ExcludeScheduled() *Client {
 	return client
 }
 
+// WithIDs adds a filter to the client to only include the given IDs.
+func (client *Client) WithIDs(ids ...string) *Client {
+	client.AddExtraFilter(bson.D{{Key: "id", Value: bson.D{{Key: "$in", Value: ids}}}})
+
+	return client
+}
+
+// WithWaitingFor adds a filter to the client to only include jobs that are waiting for the given job.
+func (client *Client) WithWaitingFor(jobID string) *Client {
+	client.AddExtraFilter(bson.D{{Key: "waitingFor", Value: jobID}})
+
+	return client
+}
+
 // ExcludeIDs adds a filter to the client to exclude the given IDs.
 func (client *Client) ExcludeIDs(ids ...string) *Client {
 	client.AddExtraFilter(bson.D{{Key: "id", Value: bson.D{{Key: "$nin", Value: ids}}}})
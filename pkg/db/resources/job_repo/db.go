@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/kthcloud/go-deploy/models/model"
@@ -20,6 +21,17 @@ func (client *Client) Create(id, userID, jobType, version string, args map[strin
 
 // CreateScheduled creates a new job in the database that will run after the given time.
 func (client *Client) CreateScheduled(id, userID, jobType, version string, runAfter time.Time, args map[string]interface{}) error {
+	return client.create(id, userID, jobType, version, runAfter, nil, args)
+}
+
+// CreateWithDependencies creates a new job in the database that will run after the given jobs are completed.
+// If any of the jobs is terminated, the new job is terminated as well.
+func (client *Client) CreateWithDependencies(id, userID, jobType, version string, dependsOn []string, args map[string]interface{}) error {
+	return client.create(id, userID, jobType, version, time.Now(), dependsOn, args)
+}
+
+// create creates a new job in the database.
+func (client *Client) create(id, userID, jobType, version string, runAfter time.Time, dependsOn []string, args map[string]interface{}) error {
 	currentJob, err := client.GetByID(id)
 	if err != nil {
 		return err
@@ -29,17 +41,30 @@ func (client *Client) CreateScheduled(id, userID, jobType, version string, runAf
 		return fmt.Errorf("job with id %s already exists", id)
 	}
 
+	for _, dependencyID := range dependsOn {
+		exists, err := New().ExistsByID(dependencyID)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return fmt.Errorf("job %s depends on job %s, which does not exist", id, dependencyID)
+		}
+	}
+
 	job := model.Job{
-		ID:        id,
-		UserID:    userID,
-		Type:      jobType,
-		Args:      args,
-		Version:   version,
-		CreatedAt: time.Now(),
-		RunAfter:  runAfter,
-		Attempts:  0,
-		Status:    model.JobStatusPending,
-		ErrorLogs: make([]string, 0),
+		ID:         id,
+		UserID:     userID,
+		Type:       jobType,
+		Args:       args,
+		Version:    version,
+		DependsOn:  dependsOn,
+		WaitingFor: dependsOn,
+		CreatedAt:  time.Now(),
+		RunAfter:   runAfter,
+		Attempts:   0,
+		Status:     model.JobStatusPending,
+		ErrorLogs:  make([]string, 0),
 	}
 
 	_, err = client.Collection.InsertOne(context.TODO(), job)
@@ -47,58 +72,240 @@ func (client *Client) CreateScheduled(id, userID, jobType, version string, runAf
 		return fmt.Errorf("failed to create job. details: %w", err)
 	}
 
+	if len(dependsOn) > 0 {
+		// The dependencies might have finished before the job was inserted,
+		// in which case they never released it, so it is done here instead
+		err = client.resolveDependencies(id, dependsOn)
+		if err != nil {
+			return fmt.Errorf("failed to resolve dependencies for job %s. details: %w", id, err)
+		}
+	}
+
 	return nil
 }
 
-// GetNext returns the next job that should be executed.
-func (client *Client) GetNext() (*model.Job, error) {
-	now := time.Now()
-	filter := bson.D{
-		{Key: "status", Value: model.JobStatusPending},
-		{Key: "runAfter", Value: bson.D{{Key: "$lte", Value: now}}},
+// resolveDependencies releases the job from any dependency that is already completed,
+// and terminates it if any dependency is already terminated.
+func (client *Client) resolveDependencies(id string, dependsOn []string) error {
+	dependencies, err := New().WithIDs(dependsOn...).List()
+	if err != nil {
+		return err
 	}
-	opts := options.FindOneAndUpdate().SetSort(bson.D{{Key: "createdAt", Value: -1}})
-	update := bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: model.JobStatusRunning}, {Key: "lastRunAt", Value: time.Now()}}}}
 
-	var job model.Job
-	err := client.Collection.FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&job)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, nil
+	for _, dependency := range dependencies {
+		switch dependency.Status {
+		case model.JobStatusCompleted:
+			err = client.releaseDependents(dependency.ID)
+		case model.JobStatusTerminated, model.JobStatusCancelled:
+			err = client.terminateDependents(dependency.ID)
 		}
 
-		return nil, err
+		if err != nil {
+			return err
+		}
 	}
 
-	return &job, nil
+	return nil
+}
+
+// claimCandidates is the number of jobs that are fetched at a time when claiming the next job.
+// Candidates are skipped if an older job for the same resource is not finished.
+const claimCandidates = 20
+
+// GetNext returns the next job that should be executed.
+// The job is claimed by the worker, and the claim must be renewed before the lease expires.
+//
+// Jobs for the same resource, identified by args.id, are run strictly in creation order.
+func (client *Client) GetNext(workerID string, leaseDuration time.Duration) (*model.Job, error) {
+	return client.claimNext(bson.D{
+		{Key: "status", Value: model.JobStatusPending},
+		// Matches both a missing and an empty list, i.e. jobs that are not waiting for any other job
+		{Key: "waitingFor.0", Value: bson.D{{Key: "$exists", Value: false}}},
+	}, workerID, leaseDuration)
 }
 
 // GetNextFailed returns the next job that failed and should be retried.
-func (client *Client) GetNextFailed() (*model.Job, error) {
-	now := time.Now()
-	filter := bson.D{
+// The job is claimed by the worker, and the claim must be renewed before the lease expires.
+//
+// Jobs for the same resource, identified by args.id, are run strictly in creation order.
+func (client *Client) GetNextFailed(workerID string, leaseDuration time.Duration) (*model.Job, error) {
+	return client.claimNext(bson.D{
 		{Key: "status", Value: model.JobStatusFailed},
-		{Key: "runAfter", Value: bson.D{{Key: "$lte", Value: now}}},
+	}, workerID, leaseDuration)
+}
+
+// CountReady counts the jobs that are ready to run, grouped by type.
+// This is the queue depth, and does not include jobs that are scheduled or waiting for other jobs.
+func (client *Client) CountReady() (map[string]int, error) {
+	filter := bson.D{
+		{Key: "runAfter", Value: bson.D{{Key: "$lte", Value: time.Now()}}},
+		{Key: "$or", Value: bson.A{
+			bson.D{
+				{Key: "status", Value: model.JobStatusPending},
+				{Key: "waitingFor.0", Value: bson.D{{Key: "$exists", Value: false}}},
+			},
+			bson.D{{Key: "status", Value: model.JobStatusFailed}},
+		}},
 	}
-	opts := options.FindOneAndUpdate().SetSort(bson.D{{Key: "createdAt", Value: -1}})
-	update := bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: model.JobStatusRunning}, {Key: "lastRunAt", Value: time.Now()}}}}
 
-	var job model.Job
-	err := client.Collection.FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&job)
+	return client.countByType(filter)
+}
+
+// CountRunning counts the running jobs on all workers, grouped by type.
+func (client *Client) CountRunning() (map[string]int, error) {
+	return client.countByType(bson.D{{Key: "status", Value: model.JobStatusRunning}})
+}
+
+// countByType counts the jobs that match the filter, grouped by type.
+func (client *Client) countByType(filter bson.D) (map[string]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$type"}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}}},
+	}
+
+	cursor, err := client.Collection.Aggregate(context.TODO(), pipeline)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("failed to count jobs by type. details: %w", err)
+	}
+
+	var groups []struct {
+		Type  string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	err = cursor.All(context.TODO(), &groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs by type. details: %w", err)
+	}
+
+	counts := make(map[string]int)
+	for _, group := range groups {
+		counts[group.Type] = group.Count
+	}
+
+	return counts, nil
+}
+
+// claimNext claims the oldest job that matches the filter and is ready to run.
+// Filters added to the client, such as excluded types, are applied as well.
+//
+// Resources that are busy are excluded from the following pages of candidates,
+// so that many jobs for one busy resource do not starve the rest of the queue.
+func (client *Client) claimNext(filter bson.D, workerID string, leaseDuration time.Duration) (*model.Job, error) {
+	now := time.Now()
+
+	filter = append(filter, bson.E{Key: "runAfter", Value: bson.D{{Key: "$lte", Value: now}}})
+	if client.ExtraFilter != nil {
+		filter = append(filter, bson.E{Key: "$and", Value: client.ExtraFilter["$and"]})
+	}
+
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: model.JobStatusRunning},
+		{Key: "lastRunAt", Value: now},
+		{Key: "workerId", Value: workerID},
+		{Key: "leaseExpiresAt", Value: now.Add(leaseDuration)},
+	}}}
+	updateOpts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	findOpts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}).SetLimit(claimCandidates)
+
+	busyResources := make([]string, 0)
+	for {
+		pageFilter := filter
+		if len(busyResources) > 0 {
+			pageFilter = append(bson.D{{Key: "args.id", Value: bson.D{{Key: "$nin", Value: busyResources}}}}, filter...)
+		}
+
+		cursor, err := client.Collection.Find(context.TODO(), pageFilter, findOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find next job. details: %w", err)
+		}
+
+		var candidates []model.Job
+		err = cursor.All(context.TODO(), &candidates)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find next job. details: %w", err)
+		}
+
+		for _, candidate := range candidates {
+			if resourceID, ok := candidate.Args["id"].(string); ok && slices.Contains(busyResources, resourceID) {
+				continue
+			}
+
+			busy, err := client.resourceBusy(&candidate)
+			if err != nil {
+				return nil, err
+			}
+
+			if busy {
+				busyResources = append(busyResources, candidate.Args["id"].(string))
+				continue
+			}
+
+			// The status is part of the filter, so only one worker can claim the job
+			claimFilter := bson.D{{Key: "id", Value: candidate.ID}, {Key: "status", Value: candidate.Status}}
+
+			var job model.Job
+			err = client.Collection.FindOneAndUpdate(context.TODO(), claimFilter, update, updateOpts).Decode(&job)
+			if err != nil {
+				if errors.Is(err, mongo.ErrNoDocuments) {
+					continue
+				}
+
+				return nil, err
+			}
+
+			return &job, nil
+		}
+
+		// Candidates that were claimed by another worker or are for a busy resource are not part of the next page,
+		// so every page has new candidates
+		if len(candidates) < claimCandidates {
 			return nil, nil
 		}
+	}
+}
 
-		return nil, err
+// resourceBusy returns true if an older job for the same resource is not yet finished.
+// This works as a mutex per resource across all workers, since only the oldest unfinished job can be claimed.
+// Scheduled jobs that are not yet due do not hold the resource.
+func (client *Client) resourceBusy(job *model.Job) (bool, error) {
+	resourceID, ok := job.Args["id"].(string)
+	if !ok || resourceID == "" {
+		return false, nil
+	}
+
+	filter := bson.D{
+		{Key: "args.id", Value: resourceID},
+		{Key: "id", Value: bson.D{{Key: "$ne", Value: job.ID}}},
+		{Key: "createdAt", Value: bson.D{{Key: "$lt", Value: job.CreatedAt}}},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{model.JobStatusRunning, model.JobStatusFailed}}}}},
+			bson.D{
+				{Key: "status", Value: model.JobStatusPending},
+				{Key: "runAfter", Value: bson.D{{Key: "$lte", Value: time.Now()}}},
+			},
+			// A cancelled job holds the resource until its worker has stopped running it and released the lease
+			bson.D{
+				{Key: "status", Value: model.JobStatusCancelled},
+				{Key: "leaseExpiresAt", Value: bson.D{{Key: "$gt", Value: time.Now()}}},
+			},
+		}},
+	}
+
+	count, err := client.Collection.CountDocuments(context.TODO(), filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("failed to check if resource %s is busy. details: %w", resourceID, err)
 	}
 
-	return &job, nil
+	return count > 0, nil
 }
 
 // MarkCompleted marks a job as completed.
 func (client *Client) MarkCompleted(jobID string) error {
-	filter := bson.D{{Key: "id", Value: jobID}}
+	// A job cancelled while running keeps its cancelled status
+	filter := bson.D{
+		{Key: "id", Value: jobID},
+		{Key: "status", Value: bson.D{{Key: "$ne", Value: model.JobStatusCancelled}}},
+	}
 
 	// update status and finishedAt
 	update := bson.D{
@@ -110,11 +317,20 @@ func (client *Client) MarkCompleted(jobID string) error {
 		},
 	}
 
-	_, err := client.Collection.UpdateOne(context.Background(), filter, update)
+	res, err := client.Collection.UpdateOne(context.Background(), filter, update)
 	if err != nil {
 		return fmt.Errorf("failed to update job. details: %w", err)
 	}
 
+	if res.MatchedCount == 0 {
+		return nil
+	}
+
+	err = client.releaseDependents(jobID)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -122,6 +338,7 @@ func (client *Client) MarkCompleted(jobID string) error {
 func (client *Client) MarkFailed(jobID string, runAfter time.Time, attempts int, reason string) error {
 	filter := bson.D{
 		{Key: "id", Value: jobID},
+		{Key: "status", Value: bson.D{{Key: "$ne", Value: model.JobStatusCancelled}}},
 	}
 	update := bson.D{
 		{Key: "$set",
@@ -149,6 +366,7 @@ func (client *Client) MarkFailed(jobID string, runAfter time.Time, attempts int,
 func (client *Client) MarkTerminated(jobID string, reason string) error {
 	filter := bson.D{
 		{Key: "id", Value: jobID},
+		{Key: "status", Value: bson.D{{Key: "$ne", Value: model.JobStatusCancelled}}},
 	}
 	update := bson.D{
 		{Key: "$set",
@@ -167,23 +385,182 @@ func (client *Client) MarkTerminated(jobID string, reason string) error {
 		return fmt.Errorf("failed to update job. details: %w", err)
 	}
 
+	err = client.terminateDependents(jobID)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
-// ResetRunning resets all running jobs to pending.
-// This is used when the application is restarted to prevent jobs from being stuck in running state.
-func (client *Client) ResetRunning() error {
-	filter := bson.D{{Key: "status", Value: model.JobStatusRunning}}
-	update := bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: model.JobStatusPending}}}}
+// MarkCancelled marks a job as cancelled, meaning it should stop as soon as possible and not be retried.
+// Only jobs that are not yet finished can be cancelled, and it returns false if the job was already finished.
+func (client *Client) MarkCancelled(jobID string, reason string) (bool, error) {
+	filter := bson.D{
+		{Key: "id", Value: jobID},
+		{Key: "status", Value: bson.D{{Key: "$in", Value: []string{model.JobStatusPending, model.JobStatusRunning, model.JobStatusFailed}}}},
+	}
+	update := bson.D{
+		{Key: "$set",
+			Value: bson.D{
+				{Key: "status", Value: model.JobStatusCancelled},
+				{Key: "finishedAt", Value: time.Now()},
+			},
+		},
+		{Key: "$push",
+			Value: bson.D{{Key: "errorLogs", Value: reason}},
+		},
+	}
+
+	res, err := client.Collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to update job. details: %w", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return false, nil
+	}
+
+	err = client.terminateDependents(jobID)
+	if err != nil {
+		return true, err
+	}
+
+	return true, nil
+}
+
+// releaseDependents removes the job from the list of jobs that its dependents are waiting for.
+// Dependents that are not waiting for any other job are then picked up by the runner.
+func (client *Client) releaseDependents(jobID string) error {
+	filter := bson.D{{Key: "waitingFor", Value: jobID}}
+	update := bson.D{{Key: "$pull", Value: bson.D{{Key: "waitingFor", Value: jobID}}}}
 
 	_, err := client.Collection.UpdateMany(context.Background(), filter, update)
 	if err != nil {
-		return fmt.Errorf("failed to update job. details: %w", err)
+		return fmt.Errorf("failed to release dependents of job %s. details: %w", jobID, err)
+	}
+
+	return nil
+}
+
+// terminateDependents terminates all pending jobs that are waiting for the job, since it will never complete.
+// This cascades through the dependency graph, since terminating a dependent terminates its own dependents.
+func (client *Client) terminateDependents(jobID string) error {
+	dependents, err := New().WithWaitingFor(jobID).IncludeStatus(model.JobStatusPending).List()
+	if err != nil {
+		return fmt.Errorf("failed to list dependents of job %s. details: %w", jobID, err)
+	}
+
+	for _, dependent := range dependents {
+		err = client.MarkTerminated(dependent.ID, fmt.Sprintf("dependency %s was terminated", jobID))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RenewLease extends the lease of a job claimed by the worker.
+// It returns false if the worker no longer holds the lease, e.g. because it expired and the job was requeued.
+//
+// The lease of a cancelled job is renewed as well, since the worker is still running it until it observes the cancellation.
+func (client *Client) RenewLease(jobID, workerID string, leaseDuration time.Duration) (bool, error) {
+	filter := bson.D{
+		{Key: "id", Value: jobID},
+		{Key: "workerId", Value: workerID},
+		{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{model.JobStatusRunning, model.JobStatusCancelled}}}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "leaseExpiresAt", Value: time.Now().Add(leaseDuration)}}}}
+
+	res, err := client.Collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to renew lease for job %s. details: %w", jobID, err)
+	}
+
+	return res.MatchedCount > 0, nil
+}
+
+// ReleaseLease releases the lease the worker holds on a job, once it has stopped running it.
+// It is a no-op if the worker no longer holds the lease.
+func (client *Client) ReleaseLease(jobID, workerID string) error {
+	filter := bson.D{
+		{Key: "id", Value: jobID},
+		{Key: "workerId", Value: workerID},
+	}
+	update := bson.D{{Key: "$unset", Value: bson.D{{Key: "workerId", Value: ""}, {Key: "leaseExpiresAt", Value: ""}}}}
+
+	_, err := client.Collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to release lease for job %s. details: %w", jobID, err)
 	}
 
 	return nil
 }
 
+// ListExpiredLeases lists running jobs whose lease has expired.
+// Jobs without a lease were claimed before leases were introduced, and are included once they have
+// been running for longer than leaseDuration, so that workers that are still running them get time to finish.
+func (client *Client) ListExpiredLeases(leaseDuration time.Duration) ([]model.Job, error) {
+	return client.AddFilter(expiredLeaseFilter(leaseDuration)).List()
+}
+
+// ReleaseExpiredLease sets the status of a job whose lease has expired, and releases the lease.
+// The status should either be pending to requeue the job, or terminated if it should not be retried.
+// It returns false if the lease was not expired, e.g. because it was already released by another worker.
+func (client *Client) ReleaseExpiredLease(jobID, status string, attempts int, reason string, leaseDuration time.Duration) (bool, error) {
+	filter := append(bson.D{{Key: "id", Value: jobID}}, expiredLeaseFilter(leaseDuration)...)
+
+	set := bson.D{
+		{Key: "status", Value: status},
+		{Key: "attempts", Value: attempts},
+	}
+
+	if status == model.JobStatusTerminated {
+		set = append(set, bson.E{Key: "finishedAt", Value: time.Now()})
+	}
+
+	update := bson.D{
+		{Key: "$set", Value: set},
+		{Key: "$unset", Value: bson.D{{Key: "workerId", Value: ""}, {Key: "leaseExpiresAt", Value: ""}}},
+		{Key: "$push", Value: bson.D{{Key: "errorLogs", Value: reason}}},
+	}
+
+	res, err := client.Collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to release lease for job %s. details: %w", jobID, err)
+	}
+
+	if res.MatchedCount == 0 {
+		return false, nil
+	}
+
+	if status == model.JobStatusTerminated {
+		err = client.terminateDependents(jobID)
+		if err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+// expiredLeaseFilter returns a filter that matches running jobs whose lease has expired.
+// Jobs without a lease are treated as if their lease was taken when they were last run.
+func expiredLeaseFilter(leaseDuration time.Duration) bson.D {
+	now := time.Now()
+	return bson.D{
+		{Key: "status", Value: model.JobStatusRunning},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "leaseExpiresAt", Value: bson.D{{Key: "$lt", Value: now}}}},
+			bson.D{
+				{Key: "leaseExpiresAt", Value: bson.D{{Key: "$exists", Value: false}}},
+				{Key: "lastRunAt", Value: bson.D{{Key: "$lt", Value: now.Add(-leaseDuration)}}},
+			},
+		}},
+	}
+}
+
 // UpdateWithParams updates the job with the given params.
 func (client *Client) UpdateWithParams(id string, params *model.JobUpdateParams) error {
 	updateData := bson.D{}
@@ -199,5 +576,19 @@ func (client *Client) UpdateWithParams(id string, params *model.JobUpdateParams)
 		return fmt.Errorf("failed to update job %s. details: %w", id, err)
 	}
 
+	// Dependents must follow manual status changes as well, or they would wait forever
+	if params.Status != nil {
+		switch *params.Status {
+		case model.JobStatusCompleted:
+			err = client.releaseDependents(id)
+		case model.JobStatusTerminated, model.JobStatusCancelled:
+			err = client.terminateDependents(id)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to update dependents of job %s. details: %w", id, err)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,44 @@
+package vm_image_repo
+
+import (
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/db"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/base_clients"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Client is used to manage the VM image catalogue in the database.
+type Client struct {
+	base_clients.ResourceClient[model.VmImage]
+}
+
+// New returns a new VM image client.
+func New() *Client {
+	return &Client{
+		ResourceClient: base_clients.ResourceClient[model.VmImage]{
+			Collection: db.DB.GetCollection("vmImages"),
+		},
+	}
+}
+
+// WithPagination sets the pagination for the client.
+func (client *Client) WithPagination(page, pageSize int) *Client {
+	client.ResourceClient.Pagination = &db.Pagination{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	return client
+}
+
+// WithRole adds a filter to the client to only include images the given role may use.
+func (client *Client) WithRole(role string) *Client {
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "roles", Value: bson.D{{Key: "$size", Value: 0}}}},
+		bson.D{{Key: "roles", Value: role}},
+	}}}
+
+	client.ResourceClient.AddExtraFilter(filter)
+
+	return client
+}
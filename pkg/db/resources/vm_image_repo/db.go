@@ -0,0 +1,66 @@
+package vm_image_repo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/db"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Create creates a new VM image in the catalogue.
+func (client *Client) Create(params *model.VmImageCreateParams) (*model.VmImage, error) {
+	roles := params.Roles
+	if roles == nil {
+		roles = make([]string, 0)
+	}
+
+	image := model.VmImage{
+		ID:          params.ID,
+		DisplayName: params.DisplayName,
+		URL:         params.URL,
+		DefaultUser: params.DefaultUser,
+		MinDiskSize: params.MinDiskSize,
+		Roles:       roles,
+		Bootstrap:   params.Bootstrap,
+		CreatedAt:   time.Now(),
+	}
+
+	err := client.CreateIfUnique(params.ID, &image, bson.D{{Key: "id", Value: params.ID}})
+	if err != nil {
+		if errors.Is(err, db.ErrUniqueConstraint) {
+			return nil, ErrVmImageAlreadyExists
+		}
+
+		return nil, fmt.Errorf("failed to create vm image %s. details: %w", params.ID, err)
+	}
+
+	return client.GetByID(params.ID)
+}
+
+// UpdateWithParams updates a VM image with the given params.
+func (client *Client) UpdateWithParams(id string, params *model.VmImageUpdateParams) error {
+	setUpdate := bson.D{}
+
+	db.AddIfNotNil(&setUpdate, "displayName", params.DisplayName)
+	db.AddIfNotNil(&setUpdate, "url", params.URL)
+	db.AddIfNotNil(&setUpdate, "defaultUser", params.DefaultUser)
+	db.AddIfNotNil(&setUpdate, "minDiskSize", params.MinDiskSize)
+	db.AddIfNotNil(&setUpdate, "roles", params.Roles)
+	db.AddIfNotNil(&setUpdate, "bootstrap", params.Bootstrap)
+
+	if len(setUpdate) == 0 {
+		return nil
+	}
+
+	db.Add(&setUpdate, "updatedAt", time.Now())
+
+	err := client.SetWithBsonByID(id, setUpdate)
+	if err != nil {
+		return fmt.Errorf("failed to update vm image %s. details: %w", id, err)
+	}
+
+	return nil
+}
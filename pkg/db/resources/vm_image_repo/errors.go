@@ -0,0 +1,8 @@
+package vm_image_repo
+
+import "fmt"
+
+var (
+	// ErrVmImageAlreadyExists is returned when a VM image with the same ID already exists.
+	ErrVmImageAlreadyExists = fmt.Errorf("vm image already exists")
+)
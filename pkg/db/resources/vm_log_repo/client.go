@@ -0,0 +1,59 @@
+package vm_log_repo
+
+import (
+	"time"
+
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/db"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/base_clients"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Client is used to manage VM console logs in the database.
+type Client struct {
+	Collection *mongo.Collection
+
+	base_clients.ResourceClient[model.VmLog]
+}
+
+// New returns a new VM log client.
+// Logs are sorted by when they were created, with the oldest log first.
+func New() *Client {
+	return &Client{
+		Collection: db.DB.GetCollection("vmLogs"),
+
+		ResourceClient: base_clients.ResourceClient[model.VmLog]{
+			Collection:     db.DB.GetCollection("vmLogs"),
+			IncludeDeleted: false,
+			SortBy: &db.SortBy{
+				Field: "createdAt",
+				Order: 1,
+			},
+		},
+	}
+}
+
+// WithPagination adds pagination to the client.
+func (client *Client) WithPagination(page, pageSize int) *Client {
+	client.ResourceClient.Pagination = &db.Pagination{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	return client
+}
+
+// WithVmID adds a filter to the client to only include logs of the given VM.
+func (client *Client) WithVmID(vmID string) *Client {
+	client.ResourceClient.AddExtraFilter(bson.D{{Key: "vmId", Value: vmID}})
+
+	return client
+}
+
+// CreatedAfter adds a filter to the client to only include logs created after the given time.
+func (client *Client) CreatedAfter(t time.Time) *Client {
+	client.ResourceClient.AddExtraFilter(bson.D{{Key: "createdAt", Value: bson.D{{Key: "$gt", Value: t}}}})
+
+	return client
+}
@@ -0,0 +1,52 @@
+package vm_log_repo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/db"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Create stores console logs for the VM.
+// The logs expire after the given retention, and are removed by MongoDB once expired.
+func (client *Client) Create(vmID, zone string, retention time.Duration, logs ...model.Log) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, len(logs))
+	for i, log := range logs {
+		documents[i] = model.VmLog{
+			VmID:      vmID,
+			Zone:      zone,
+			Line:      log.Line,
+			CreatedAt: log.CreatedAt,
+			ExpiresAt: log.CreatedAt.Add(retention),
+		}
+	}
+
+	_, err := client.Collection.InsertMany(context.TODO(), documents, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		return fmt.Errorf("failed to create logs for vm %s. details: %w", vmID, err)
+	}
+
+	return nil
+}
+
+// ListLatest returns the n latest logs that match the filter, with the oldest log first.
+func (client *Client) ListLatest(n int) ([]model.VmLog, error) {
+	client.SortBy = &db.SortBy{Field: "createdAt", Order: -1}
+	client.WithPagination(0, n)
+
+	logs, err := client.List()
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(logs)
+	return logs, nil
+}
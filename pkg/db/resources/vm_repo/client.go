@@ -117,6 +117,16 @@ func (client *Client) WithNoActivities() *Client {
 	return client
 }
 
+// WithSnapshotPolicies adds a filter to the client to only return VMs with any snapshot policy.
+func (client *Client) WithSnapshotPolicies() *Client {
+	filter := bson.D{{Key: "snapshotPolicyMap", Value: bson.D{{Key: "$exists", Value: true}}}}
+
+	client.ResourceClient.AddExtraFilter(filter)
+	client.ActivityResourceClient.AddExtraFilter(filter)
+
+	return client
+}
+
 // WithIDs adds a filter to the client to only return VMs with the given IDs.
 func (client *Client) WithIDs(ids ...string) *Client {
 	filter := bson.D{{Key: "id", Value: bson.D{{Key: "$in", Value: ids}}}}
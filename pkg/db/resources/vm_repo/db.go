@@ -59,8 +59,12 @@ func (client *Client) Create(id, owner string, params *model.VmCreateParams) (*m
 
 		NeverStale: params.NeverStale,
 
-		SshPublicKey: params.SshPublicKey,
-		PortMap:      portMap,
+		SshPublicKey:      params.SshPublicKey,
+		PortMap:           portMap,
+		SnapshotPolicyMap: params.SnapshotPolicyMap,
+		DiskMap:           params.DiskMap,
+		CloudInit:         params.CloudInit,
+		Image:             params.Image,
 		Specs: model.VmSpecs{
 			CpuCores: params.CpuCores,
 			RAM:      params.RAM,
@@ -222,8 +226,41 @@ func (client *Client) UpdateWithParams(id string, params *model.VmUpdateParams)
 	db.AddIfNotNil(&setUpdate, "ownerId", params.OwnerID)
 	db.AddIfNotNil(&setUpdate, "specs.cpuCores", params.CpuCores)
 	db.AddIfNotNil(&setUpdate, "specs.ram", params.RAM)
+	db.AddIfNotNil(&setUpdate, "specs.diskSize", params.DiskSize)
 	db.AddIfNotNil(&setUpdate, "neverStale", params.NeverStale)
 
+	if params.SnapshotPolicyMap != nil {
+		if len(*params.SnapshotPolicyMap) == 0 {
+			db.Add(&unsetUpdate, "snapshotPolicyMap", "")
+		} else {
+			db.Add(&setUpdate, "snapshotPolicyMap", *params.SnapshotPolicyMap)
+		}
+	}
+
+	if params.DiskMap != nil {
+		if len(*params.DiskMap) == 0 {
+			db.Add(&unsetUpdate, "diskMap", "")
+		} else {
+			db.Add(&setUpdate, "diskMap", *params.DiskMap)
+		}
+	}
+
+	if params.DetachedDiskMap != nil {
+		if len(*params.DetachedDiskMap) == 0 {
+			db.Add(&unsetUpdate, "detachedDiskMap", "")
+		} else {
+			db.Add(&setUpdate, "detachedDiskMap", *params.DetachedDiskMap)
+		}
+	}
+
+	if params.CloudInit != nil {
+		if params.CloudInit.Empty() {
+			db.Add(&unsetUpdate, "cloudInit", "")
+		} else {
+			db.Add(&setUpdate, "cloudInit", *params.CloudInit)
+		}
+	}
+
 	err := client.UpdateWithBsonByID(id,
 		bson.D{
 			{Key: "$set", Value: setUpdate},
@@ -248,6 +285,9 @@ func (client *Client) GetUsage() (*model.VmUsage, error) {
 		{Key: "id", Value: 1},
 		{Key: "name", Value: 1},
 		{Key: "specs", Value: 1},
+		{Key: "diskMap", Value: 1},
+		{Key: "detachedDiskMap", Value: 1},
+		{Key: "subsystems.k8s.vmSnapshotMap", Value: 1},
 	}
 
 	vms, err := client.ListWithFilterAndProjection(bson.D{}, projection)
@@ -256,15 +296,23 @@ func (client *Client) GetUsage() (*model.VmUsage, error) {
 	}
 
 	usage := &model.VmUsage{
-		CpuCores: 0,
-		RAM:      0,
-		DiskSize: 0,
+		CpuCores:  0,
+		RAM:       0,
+		DiskSize:  0,
+		Snapshots: 0,
 	}
 
 	for _, vm := range vms {
 		usage.CpuCores += vm.Specs.CpuCores
 		usage.RAM += vm.Specs.RAM
-		usage.DiskSize += vm.Specs.DiskSize
+		usage.DiskSize += vm.TotalDiskSize()
+
+		// Only snapshots requested by the user count toward the quota
+		for _, snapshot := range vm.Subsystems.K8s.VmSnapshotMap {
+			if snapshot.UserCreated {
+				usage.Snapshots++
+			}
+		}
 	}
 
 	return usage, nil
@@ -308,6 +356,26 @@ func (client *Client) UnsetCurrentHost(name string) error {
 	return client.UnsetByName(name, "host")
 }
 
+// SetSnapshotPolicyRun sets when a snapshot policy of a VM last took a snapshot.
+func (client *Client) SetSnapshotPolicyRun(id, name string, lastRunAt time.Time) error {
+	filter := bson.D{
+		{Key: "id", Value: id},
+		{Key: "snapshotPolicyMap." + name, Value: bson.D{{Key: "$exists", Value: true}}},
+	}
+
+	return client.SetWithBsonByFilter(filter, bson.D{{Key: "snapshotPolicyMap." + name + ".lastRunAt", Value: lastRunAt}})
+}
+
+// SetRestore sets the state of the restore from a snapshot that is in progress.
+// If restore is nil, the state is removed.
+func (client *Client) SetRestore(id string, restore *model.VmRestore) error {
+	if restore == nil {
+		return client.UnsetByID(id, "restore")
+	}
+
+	return client.SetWithBsonByID(id, bson.D{{Key: "restore", Value: restore}})
+}
+
 // MarkRepaired marks a VM as repaired.
 // It sets RepairedAt and unsets the repairing activity.
 func (client *Client) MarkRepaired(id string) error {
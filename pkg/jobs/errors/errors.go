@@ -1,13 +1,54 @@
 package errors
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// Class is the classification of an error returned by a job, which decides what happens to the job.
+type Class string
+
+const (
+	// ClassFailed is used for transient errors. The job is retried according to its retry policy.
+	ClassFailed Class = "failed"
+	// ClassTerminated is used for permanent errors. The job is not retried.
+	ClassTerminated Class = "terminated"
+	// ClassUnknown is used for errors that were not classified by the job.
+	// They are treated as permanent errors.
+	ClassUnknown Class = "unknown"
+)
+
+// JobError is an error returned by a job, classified by whether the job should be retried.
+type JobError struct {
+	Class Class
+	Err   error
+}
+
+func (e *JobError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Class, e.Err.Error())
+}
+
+func (e *JobError) Unwrap() error {
+	return e.Err
+}
 
 // MakeTerminatedError makes a terminated error.
 func MakeTerminatedError(err error) error {
-	return fmt.Errorf("terminated: %w", err)
+	return &JobError{Class: ClassTerminated, Err: err}
 }
 
 // MakeFailedError makes a failed error.
 func MakeFailedError(err error) error {
-	return fmt.Errorf("failed: %w", err)
+	return &JobError{Class: ClassFailed, Err: err}
+}
+
+// Classify returns the class of the error and the underlying error.
+// Errors that are not a JobError are returned as is with ClassUnknown.
+func Classify(err error) (Class, error) {
+	var jobErr *JobError
+	if errors.As(err, &jobErr) {
+		return jobErr.Class, jobErr.Err
+	}
+
+	return ClassUnknown, err
 }
@@ -1,6 +1,10 @@
 package jobs
 
 import (
+	"context"
+	"slices"
+	"time"
+
 	"github.com/kthcloud/go-deploy/models/model"
 	"github.com/kthcloud/go-deploy/models/version"
 	"github.com/kthcloud/go-deploy/pkg/jobs/utils"
@@ -9,12 +13,17 @@ import (
 
 // JobDefinition is a definition of a job.
 // It contains the job itself and the functions that are executed when the job is created, updated, deleted, etc.
+//
+// The context passed to JobFunc is cancelled when the job is cancelled.
 type JobDefinition struct {
 	Job           *model.Job
-	JobFunc       func(*model.Job) error
+	JobFunc       func(context.Context, *model.Job) error
 	EntryFunc     func(*model.Job) error
 	ExitFunc      func(*model.Job) error
 	TerminateFunc func(*model.Job) (bool, error)
+	// RetryPolicy is used when JobFunc returns a failed error.
+	// If it is nil, DefaultRetryPolicy is used.
+	RetryPolicy *RetryPolicy
 }
 
 type JobDefinitions map[string]JobDefinition
@@ -31,12 +40,33 @@ func GetJobDef(job *model.Job) *JobDefinition {
 	return &jobDef
 }
 
+// Types returns all job types that have a job definition.
+func Types() []string {
+	var types []string
+	for _, defs := range jobMapper() {
+		for jobType := range defs {
+			types = append(types, jobType)
+		}
+	}
+
+	slices.Sort(types)
+	return slices.Compact(types)
+}
+
 // jobMapper maps job types to job definitions.
 func jobMapper() map[string]map[string]JobDefinition {
 	coreJobVM := Builder().Add(utils.VmDeleted)
 	leafJobVM := Builder().Add(utils.VmDeleted).Add(utils.UpdatingOwner)
 	oneCreateSnapshotPerUser := Builder().Add(utils.VmDeleted).Add(utils.UpdatingOwner).Add(utils.OnlyCreateSnapshotPerUser)
 
+	// Deletions are retried for longer, since giving up leaves resources behind
+	persistentRetry := &RetryPolicy{
+		MaxAttempts: 10,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    30 * time.Minute,
+		Jitter:      0.1,
+	}
+
 	coreJobDeployment := Builder().Add(utils.DeploymentDeleted)
 	leafJobDeployment := Builder().Add(utils.DeploymentDeleted).Add(utils.UpdatingOwner)
 
@@ -49,8 +79,9 @@ func jobMapper() map[string]map[string]JobDefinition {
 			ExitFunc:      utils.DRemActivity(model.ActivityBeingCreated),
 		},
 		model.JobDeleteDeployment: {
-			JobFunc:   v2.DeleteDeployment,
-			EntryFunc: utils.DAddActivity(model.ActivityBeingDeleted),
+			JobFunc:     v2.DeleteDeployment,
+			EntryFunc:   utils.DAddActivity(model.ActivityBeingDeleted),
+			RetryPolicy: persistentRetry,
 		},
 		model.JobUpdateDeployment: {
 			JobFunc:       v2.UpdateDeployment,
@@ -70,13 +101,28 @@ func jobMapper() map[string]map[string]JobDefinition {
 			EntryFunc:     utils.DAddActivity(model.ActivityRepairing),
 			ExitFunc:      utils.DRemActivity(model.ActivityRepairing),
 		},
+		model.JobDoDeploymentCommand: {
+			JobFunc:       v2.DoDeploymentCommand,
+			TerminateFunc: leafJobDeployment.Build(),
+		},
+		model.JobResetDeploymentScale: {
+			JobFunc:       v2.ResetDeploymentScale,
+			TerminateFunc: coreJobDeployment.Build(),
+		},
+		model.JobBuildDeployment: {
+			JobFunc:       v2.BuildDeployment,
+			TerminateFunc: leafJobDeployment.Build(),
+			EntryFunc:     utils.DAddActivity(model.ActivityBuilding),
+			ExitFunc:      utils.DRemActivity(model.ActivityBuilding),
+		},
 
 		// SM
 		model.JobCreateSM: {
 			JobFunc: v2.CreateSM,
 		},
 		model.JobDeleteSM: {
-			JobFunc: v2.DeleteSM,
+			JobFunc:     v2.DeleteSM,
+			RetryPolicy: persistentRetry,
 		},
 		model.JobRepairSM: {
 			JobFunc: v2.RepairSM,
@@ -90,8 +136,9 @@ func jobMapper() map[string]map[string]JobDefinition {
 			ExitFunc:      utils.VmRemActivity(model.ActivityBeingCreated),
 		},
 		model.JobDeleteVM: {
-			JobFunc:   v2.DeleteVM,
-			EntryFunc: utils.VmAddActivity(model.ActivityBeingDeleted),
+			JobFunc:     v2.DeleteVM,
+			EntryFunc:   utils.VmAddActivity(model.ActivityBeingDeleted),
+			RetryPolicy: persistentRetry,
 		},
 		model.JobUpdateVM: {
 			JobFunc:       v2.UpdateVM,
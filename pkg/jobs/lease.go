@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/job_repo"
+	"github.com/kthcloud/go-deploy/pkg/log"
+	"github.com/kthcloud/go-deploy/utils"
+)
+
+// LeaseDuration is how long a worker's claim on a job lasts unless it is renewed.
+// If the worker crashes, the job is requeued by the reaper after the lease expires.
+const LeaseDuration = 1 * time.Minute
+
+// heartbeatInterval is how often the lease of a running job is renewed.
+// It is well below LeaseDuration, so that a single failed renewal does not lose the lease.
+const heartbeatInterval = LeaseDuration / 3
+
+// workerID identifies this process when claiming jobs.
+// A restarted process gets a new ID, so jobs claimed before the restart are reaped once their leases expire.
+var workerID = newWorkerID()
+
+// WorkerID returns the ID that this process uses when claiming jobs.
+func WorkerID() string {
+	return workerID
+}
+
+// newWorkerID creates a worker ID from the hostname, which is the pod name when running in K8s.
+func newWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8])
+}
+
+// heartbeat renews the lease of the job until the context is done.
+// If the lease is lost, the job has been requeued, so the job is cancelled to stop this run of it.
+func heartbeat(ctx context.Context, cancel context.CancelFunc, jobID string) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			renewed, err := job_repo.New().RenewLease(jobID, workerID, LeaseDuration)
+			if err != nil {
+				utils.PrettyPrintError(fmt.Errorf("error renewing lease for job %s. details: %w", jobID, err))
+				continue
+			}
+
+			if !renewed {
+				log.Printf("Lost lease for job %s, stopping it", jobID)
+				cancel()
+				return
+			}
+		}
+	}
+}
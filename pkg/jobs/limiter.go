@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"github.com/kthcloud/go-deploy/pkg/config"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/job_repo"
+)
+
+// Capacity checks the concurrency limits in the config against the jobs running on all job executors.
+//
+// It returns true if no more jobs can be started at all, and otherwise the job types of which no more jobs can be started.
+func Capacity() (bool, []string, error) {
+	maxConcurrency := config.Config.Job.MaxConcurrency
+	if maxConcurrency <= 0 && len(config.Config.Job.MaxConcurrencyPerType) == 0 {
+		return false, nil, nil
+	}
+
+	running, err := job_repo.New().CountRunning()
+	if err != nil {
+		return false, nil, err
+	}
+
+	total := 0
+	for _, count := range running {
+		total += count
+	}
+
+	if maxConcurrency > 0 && total >= maxConcurrency {
+		return true, nil, nil
+	}
+
+	var types []string
+	for jobType, limit := range config.Config.Job.MaxConcurrencyPerType {
+		if limit > 0 && running[jobType] >= limit {
+			types = append(types, jobType)
+		}
+	}
+
+	return false, types, nil
+}
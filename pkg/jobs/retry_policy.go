@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/config"
+)
+
+// RetryPolicy decides how many times a job is attempted, and how long to wait between attempts.
+// The delay is doubled for every attempt, starting at BaseDelay, and is capped at MaxDelay.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a job is attempted before it is terminated.
+	MaxAttempts int
+	// BaseDelay is the delay after the first failed attempt.
+	BaseDelay time.Duration
+	// MaxDelay is the maximum delay between attempts.
+	MaxDelay time.Duration
+	// Jitter is the fraction of the delay that is randomly added or subtracted,
+	// so that jobs that failed at the same time are not retried at the same time.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is used by job types that do not specify a retry policy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   30 * time.Second,
+	MaxDelay:    10 * time.Minute,
+	Jitter:      0.1,
+}
+
+// Delay returns the delay before the next attempt, given the number of attempts made so far.
+func (p *RetryPolicy) Delay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempts-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+// GetRetryPolicy returns the effective retry policy for the job.
+// It is the policy of the job definition, or the default policy, with any overrides from the config applied.
+func GetRetryPolicy(job *model.Job) RetryPolicy {
+	policy := DefaultRetryPolicy
+	if jobDef := GetJobDef(job); jobDef != nil && jobDef.RetryPolicy != nil {
+		policy = *jobDef.RetryPolicy
+	}
+
+	if override, ok := config.Config.Job.RetryPolicies[job.Type]; ok {
+		if override.MaxAttempts != nil {
+			policy.MaxAttempts = *override.MaxAttempts
+		}
+
+		if override.BaseDelay != nil {
+			policy.BaseDelay = *override.BaseDelay
+		}
+
+		if override.MaxDelay != nil {
+			policy.MaxDelay = *override.MaxDelay
+		}
+
+		if override.Jitter != nil {
+			policy.Jitter = *override.Jitter
+		}
+	}
+
+	return policy
+}
@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	jErrors "github.com/kthcloud/go-deploy/pkg/jobs/errors"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 30 * time.Second, MaxDelay: 2 * time.Minute}
+
+	expected := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 2 * time.Minute}
+	for i, want := range expected {
+		if got := policy.Delay(i + 1); got != want {
+			t.Errorf("attempt %d: expected delay %s, got %s", i+1, want, got)
+		}
+	}
+}
+
+func TestRetryPolicyJitter(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Minute, Jitter: 0.1}
+
+	for i := 0; i < 100; i++ {
+		delay := policy.Delay(1)
+		if delay < 54*time.Second || delay > 66*time.Second {
+			t.Fatalf("expected delay within 10%% of 1m, got %s", delay)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cause := errors.New("cause")
+
+	class, err := jErrors.Classify(jErrors.MakeFailedError(cause))
+	if class != jErrors.ClassFailed || err != cause {
+		t.Errorf("expected failed class with cause, got %s and %v", class, err)
+	}
+
+	// Classification should survive wrapping
+	class, err = jErrors.Classify(fmt.Errorf("wrapped: %w", jErrors.MakeTerminatedError(cause)))
+	if class != jErrors.ClassTerminated || err != cause {
+		t.Errorf("expected terminated class with cause, got %s and %v", class, err)
+	}
+
+	class, _ = jErrors.Classify(cause)
+	if class != jErrors.ClassUnknown {
+		t.Errorf("expected unknown class, got %s", class)
+	}
+}
@@ -1,14 +1,13 @@
 package jobs
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"github.com/kthcloud/go-deploy/models/model"
 	"github.com/kthcloud/go-deploy/pkg/db/resources/job_repo"
+	jErrors "github.com/kthcloud/go-deploy/pkg/jobs/errors"
 	"github.com/kthcloud/go-deploy/pkg/log"
 	"github.com/kthcloud/go-deploy/utils"
-	"math"
-	"strings"
 	"time"
 )
 
@@ -16,7 +15,9 @@ type Runner struct {
 	Job *model.Job
 }
 
-const jobAttemptsLimit = 5
+// cancellationPollInterval is how often a running job checks if it has been cancelled.
+// Cancellation is read from the database, since the job might be cancelled through another replica.
+const cancellationPollInterval = 5 * time.Second
 
 // NewRunner creates a new job runner for the given job.
 func NewRunner(job *model.Job) *Runner {
@@ -65,6 +66,15 @@ func (runner *Runner) Run() {
 // wrapper is a helper function that runs the EntryFunc, JobFunc and ExitFunc of the given job definition,
 // and updates the job's status according to the result of the JobFunc.
 func wrapper(def *JobDefinition) {
+	// The lease is held until the job is no longer running, so that no other job for the same resource
+	// is started while this one is still stopping, e.g. after being cancelled
+	defer func() {
+		err := job_repo.New().ReleaseLease(def.Job.ID, workerID)
+		if err != nil {
+			utils.PrettyPrintError(fmt.Errorf("error releasing lease for job %s (%s). details: %w", def.Job.ID, def.Job.Type, err))
+		}
+	}()
+
 	if def.EntryFunc != nil {
 		err := def.EntryFunc(def.Job)
 		if err != nil {
@@ -95,45 +105,63 @@ func wrapper(def *JobDefinition) {
 		}
 	}()
 
-	err := def.JobFunc(def.Job)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The heartbeat keeps going after the job is cancelled, until the job function returns
+	running, stopHeartbeat := context.WithCancel(context.Background())
+	defer stopHeartbeat()
+
+	go watchCancellation(ctx, cancel, def.Job.ID)
+	go heartbeat(running, cancel, def.Job.ID)
+
+	err := def.JobFunc(ctx, def.Job)
+
+	if ctx.Err() != nil {
+		// The job was either cancelled or lost its lease, and its status is already set
+		log.Printf("Job %s (%s) stopped before finishing", def.Job.ID, def.Job.Type)
+		return
+	}
 
 	if err != nil {
-		if strings.HasPrefix(err.Error(), "failed") {
-			err = errors.Unwrap(err)
+		class, cause := jErrors.Classify(err)
+
+		switch class {
+		case jErrors.ClassFailed:
+			policy := GetRetryPolicy(def.Job)
 
 			attempts := def.Job.Attempts + 1
-			if attempts >= jobAttemptsLimit {
-				utils.PrettyPrintError(fmt.Errorf("terminated job %s (%s) after %d failed attempts. details: %w", def.Job.ID, def.Job.Type, attempts, err))
-				err = job_repo.New().MarkTerminated(def.Job.ID, err.Error())
+			if attempts >= policy.MaxAttempts {
+				utils.PrettyPrintError(fmt.Errorf("terminated job %s (%s) after %d failed attempts. details: %w", def.Job.ID, def.Job.Type, attempts, cause))
+				err = job_repo.New().MarkTerminated(def.Job.ID, cause.Error())
 				if err != nil {
 					utils.PrettyPrintError(fmt.Errorf("error marking job %s (%s) as terminated. details: %w", def.Job.ID, def.Job.Type, err))
 					return
 				}
 				return
-			} else {
-				delay := int(math.Pow(2, float64(attempts-1)) * 30)
-				runAfter := def.Job.LastRunAt.Add(time.Duration(delay) * time.Second)
-				utils.PrettyPrintError(fmt.Errorf("failed job %s (%s), attempt: %d/%d delay: %ds details: %w", def.Job.ID, def.Job.Type, attempts, jobAttemptsLimit, delay, err))
+			}
 
-				err = job_repo.New().MarkFailed(def.Job.ID, runAfter, attempts, err.Error())
-				if err != nil {
-					utils.PrettyPrintError(fmt.Errorf("error marking job %s (%s) as failed. details: %w", def.Job.ID, def.Job.Type, err))
-					return
-				}
+			delay := policy.Delay(attempts)
+			runAfter := def.Job.LastRunAt.Add(delay)
+			utils.PrettyPrintError(fmt.Errorf("failed job %s (%s), attempt: %d/%d delay: %s details: %w", def.Job.ID, def.Job.Type, attempts, policy.MaxAttempts, delay.Round(time.Second), cause))
+
+			err = job_repo.New().MarkFailed(def.Job.ID, runAfter, attempts, cause.Error())
+			if err != nil {
+				utils.PrettyPrintError(fmt.Errorf("error marking job %s (%s) as failed. details: %w", def.Job.ID, def.Job.Type, err))
+				return
 			}
-		} else if strings.HasPrefix(err.Error(), "terminated") {
-			err = errors.Unwrap(err)
-			utils.PrettyPrintError(fmt.Errorf("terminated job %s (%s). details: %w", def.Job.ID, def.Job.Type, err))
+		case jErrors.ClassTerminated:
+			utils.PrettyPrintError(fmt.Errorf("terminated job %s (%s). details: %w", def.Job.ID, def.Job.Type, cause))
 
-			err = job_repo.New().MarkTerminated(def.Job.ID, err.Error())
+			err = job_repo.New().MarkTerminated(def.Job.ID, cause.Error())
 			if err != nil {
 				utils.PrettyPrintError(fmt.Errorf("error marking job %s (%s) as terminated. details: %w", def.Job.ID, def.Job.Type, err))
 				return
 			}
-		} else {
-			utils.PrettyPrintError(fmt.Errorf("error executing job %s (%s), terminating. details: %w", def.Job.ID, def.Job.Type, err))
+		default:
+			utils.PrettyPrintError(fmt.Errorf("error executing job %s (%s), terminating. details: %w", def.Job.ID, def.Job.Type, cause))
 
-			err = job_repo.New().MarkTerminated(def.Job.ID, err.Error())
+			err = job_repo.New().MarkTerminated(def.Job.ID, cause.Error())
 			if err != nil {
 				utils.PrettyPrintError(fmt.Errorf("error marking job %s (%s) as terminated. details: %w", def.Job.ID, def.Job.Type, err))
 				return
@@ -147,3 +175,28 @@ func wrapper(def *JobDefinition) {
 		}
 	}
 }
+
+// watchCancellation cancels the context when the job is cancelled in the database.
+// It stops when the context is done.
+func watchCancellation(ctx context.Context, cancel context.CancelFunc, jobID string) {
+	ticker := time.NewTicker(cancellationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job, err := job_repo.New().GetByID(jobID)
+			if err != nil {
+				utils.PrettyPrintError(fmt.Errorf("error checking if job %s is cancelled. details: %w", jobID, err))
+				continue
+			}
+
+			if job != nil && job.Status == model.JobStatusCancelled {
+				cancel()
+				return
+			}
+		}
+	}
+}
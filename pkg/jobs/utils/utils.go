@@ -104,6 +104,30 @@ func WaitForJob(context context.Context, job *model.Job, statuses []string) erro
 	}
 }
 
+// CancelRelatedJobs cancels all unfinished jobs for the resource, except the given job.
+//
+// It is used by delete jobs. Jobs for the same resource run one at a time in creation order,
+// so any unfinished job for the resource was created after the delete job, and can never run before it.
+func CancelRelatedJobs(job *model.Job, resourceID string) error {
+	relatedJobs, err := job_repo.New().
+		ExcludeStatus(model.JobStatusTerminated, model.JobStatusCompleted, model.JobStatusCancelled).
+		ExcludeIDs(job.ID).
+		FilterArgs("id", resourceID).
+		List()
+	if err != nil {
+		return err
+	}
+
+	for _, relatedJob := range relatedJobs {
+		_, err = job_repo.New().MarkCancelled(relatedJob.ID, fmt.Sprintf("cancelled since the resource is being deleted by job %s", job.ID))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // WaitForJobs waits for a list of jobs to reach one of the given statuses.
 func WaitForJobs(context context.Context, jobs []model.Job, statuses []string) error {
 	for _, job := range jobs {
@@ -261,7 +285,7 @@ func UpdatingOwner(job *model.Job) (bool, error) {
 	filter := bson.D{
 		{Key: "args.id", Value: id},
 		{Key: "type", Value: model.JobUpdateVmOwner},
-		{Key: "status", Value: bson.D{{Key: "$nin", Value: []string{model.JobStatusCompleted, model.JobStatusTerminated}}}},
+		{Key: "status", Value: bson.D{{Key: "$nin", Value: []string{model.JobStatusCompleted, model.JobStatusTerminated, model.JobStatusCancelled}}}},
 	}
 
 	anyUpdatingOwnerJob, err := job_repo.New().AddFilter(filter).ExistsAny()
@@ -278,7 +302,7 @@ func OnlyCreateSnapshotPerUser(job *model.Job) (bool, error) {
 		WithUserID(job.UserID).
 		ExcludeIDs(job.ID).
 		IncludeTypes(model.JobCreateVmUserSnapshot).
-		ExcludeStatus(model.JobStatusCompleted, model.JobStatusTerminated).
+		ExcludeStatus(model.JobStatusCompleted, model.JobStatusTerminated, model.JobStatusCancelled).
 		ExistsAny()
 	if err != nil {
 		return false, err
@@ -4,22 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"time"
 
 	"github.com/kthcloud/go-deploy/dto/v2/body"
 	"github.com/kthcloud/go-deploy/models/model"
 	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_repo"
-	"github.com/kthcloud/go-deploy/pkg/db/resources/job_repo"
 	jErrors "github.com/kthcloud/go-deploy/pkg/jobs/errors"
 	"github.com/kthcloud/go-deploy/pkg/jobs/utils"
-	"github.com/kthcloud/go-deploy/pkg/log"
 	"github.com/kthcloud/go-deploy/pkg/services/confirm"
 	"github.com/kthcloud/go-deploy/service"
 	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	dOpts "github.com/kthcloud/go-deploy/service/v2/deployments/opts"
 	"github.com/mitchellh/mapstructure"
 )
 
-func CreateDeployment(job *model.Job) error {
+func CreateDeployment(ctx context.Context, job *model.Job) error {
 	err := utils.AssertParameters(job, []string{"id", "ownerId", "params"})
 	if err != nil {
 		return jErrors.MakeTerminatedError(err)
@@ -33,7 +31,7 @@ func CreateDeployment(job *model.Job) error {
 		return jErrors.MakeTerminatedError(err)
 	}
 
-	err = service.V2(utils.GetAuthInfo(job)).Deployments().Create(id, ownerID, &params)
+	err = service.V2WithContext(ctx, utils.GetAuthInfo(job)).Deployments().Create(id, ownerID, &params)
 	if err != nil {
 		var zoneCapabilityMissingErr sErrors.ZoneCapabilityMissingErr
 		if errors.As(err, &zoneCapabilityMissingErr) {
@@ -41,14 +39,14 @@ func CreateDeployment(job *model.Job) error {
 		}
 
 		// If there was some error, we trigger a repair, since rerunning it would cause a ErrNonUniqueField
-		_ = service.V2(utils.GetAuthInfo(job)).Deployments().Repair(id)
+		_ = service.V2WithContext(ctx, utils.GetAuthInfo(job)).Deployments().Repair(id)
 		return jErrors.MakeTerminatedError(err)
 	}
 
 	return nil
 }
 
-func DeleteDeployment(job *model.Job) error {
+func DeleteDeployment(ctx context.Context, job *model.Job) error {
 	err := utils.AssertParameters(job, []string{"id"})
 	if err != nil {
 		return jErrors.MakeTerminatedError(err)
@@ -61,45 +59,13 @@ func DeleteDeployment(job *model.Job) error {
 		return jErrors.MakeTerminatedError(err)
 	}
 
-	relatedJobs, err := job_repo.New().
-		ExcludeScheduled().
-		ExcludeTypes(model.JobDeleteDeployment).
-		ExcludeStatus(model.JobStatusTerminated, model.JobStatusCompleted).
-		ExcludeIDs(job.ID).
-		FilterArgs("id", id).
-		List()
+	// Jobs created after this one would otherwise wait for it to finish, and act on a deleted resource once it has
+	err = utils.CancelRelatedJobs(job, id)
 	if err != nil {
 		return jErrors.MakeTerminatedError(err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	go func() {
-		err = utils.WaitForJobs(ctx, relatedJobs, []string{model.JobStatusCompleted, model.JobStatusTerminated})
-		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				return
-			}
-
-			if errors.Is(err, context.DeadlineExceeded) {
-				log.Println("Timeout waiting for related jobs to finish for model", id)
-				return
-			}
-
-			log.Println("Failed to wait for related jobs for model", id, ". details:", err)
-		}
-
-		cancel()
-	}()
-
-	select {
-	case <-time.After(30 * time.Second):
-		return jErrors.MakeTerminatedError(fmt.Errorf("timeout waiting for related jobs to finish"))
-	case <-ctx.Done():
-	}
-
-	err = service.V2(utils.GetAuthInfo(job)).Deployments().Delete(id)
+	err = service.V2WithContext(ctx, utils.GetAuthInfo(job)).Deployments().Delete(id)
 	if err != nil {
 		if !errors.Is(err, sErrors.ErrDeploymentNotFound) {
 			return jErrors.MakeFailedError(err)
@@ -123,7 +89,7 @@ func DeleteDeployment(job *model.Job) error {
 	return nil
 }
 
-func UpdateDeployment(job *model.Job) error {
+func UpdateDeployment(ctx context.Context, job *model.Job) error {
 	err := utils.AssertParameters(job, []string{"id", "params"})
 	if err != nil {
 		return jErrors.MakeTerminatedError(err)
@@ -136,7 +102,10 @@ func UpdateDeployment(job *model.Job) error {
 		return jErrors.MakeTerminatedError(err)
 	}
 
-	err = service.V2(utils.GetAuthInfo(job)).Deployments().Update(id, &update)
+	// Updates made by the scaling schedule are not revisions, since rolling back to them would fight the schedule
+	noRevision, _ := job.Args["noRevision"].(bool)
+
+	err = service.V2WithContext(ctx, utils.GetAuthInfo(job)).Deployments().Update(id, &update, dOpts.UpdateOpts{NoRevision: noRevision})
 	if err != nil {
 		switch {
 		case errors.Is(err, sErrors.ErrDeploymentNotFound):
@@ -158,7 +127,7 @@ func UpdateDeployment(job *model.Job) error {
 	return nil
 }
 
-func UpdateDeploymentOwner(job *model.Job) error {
+func UpdateDeploymentOwner(ctx context.Context, job *model.Job) error {
 	err := utils.AssertParameters(job, []string{"id", "params"})
 	if err != nil {
 		return jErrors.MakeTerminatedError(err)
@@ -171,7 +140,7 @@ func UpdateDeploymentOwner(job *model.Job) error {
 		return jErrors.MakeTerminatedError(err)
 	}
 
-	err = service.V2(utils.GetAuthInfo(job)).Deployments().UpdateOwner(id, &params)
+	err = service.V2WithContext(ctx, utils.GetAuthInfo(job)).Deployments().UpdateOwner(id, &params)
 	if err != nil {
 		if errors.Is(err, sErrors.ErrDeploymentNotFound) {
 			return jErrors.MakeTerminatedError(err)
@@ -182,7 +151,7 @@ func UpdateDeploymentOwner(job *model.Job) error {
 
 	if job.HasArg("resourceMigrationId") {
 		resourceMigrationID := job.Args["resourceMigrationId"].(string)
-		err = service.V2(utils.GetAuthInfo(job)).ResourceMigrations().Delete(resourceMigrationID)
+		err = service.V2WithContext(ctx, utils.GetAuthInfo(job)).ResourceMigrations().Delete(resourceMigrationID)
 		if err != nil {
 			return jErrors.MakeTerminatedError(err)
 		}
@@ -191,7 +160,7 @@ func UpdateDeploymentOwner(job *model.Job) error {
 	return nil
 }
 
-func RepairDeployment(job *model.Job) error {
+func RepairDeployment(ctx context.Context, job *model.Job) error {
 	err := utils.AssertParameters(job, []string{"id"})
 	if err != nil {
 		return jErrors.MakeTerminatedError(err)
@@ -199,7 +168,7 @@ func RepairDeployment(job *model.Job) error {
 
 	id := job.Args["id"].(string)
 
-	err = service.V2(utils.GetAuthInfo(job)).Deployments().Repair(id)
+	err = service.V2WithContext(ctx, utils.GetAuthInfo(job)).Deployments().Repair(id)
 	if err != nil {
 		return jErrors.MakeTerminatedError(err)
 	}
@@ -211,3 +180,73 @@ func RepairDeployment(job *model.Job) error {
 
 	return nil
 }
+
+func DoDeploymentCommand(ctx context.Context, job *model.Job) error {
+	err := utils.AssertParameters(job, []string{"id", "params"})
+	if err != nil {
+		return jErrors.MakeTerminatedError(err)
+	}
+
+	id := job.Args["id"].(string)
+	var params body.DeploymentCommand
+	err = mapstructure.Decode(job.Args["params"].(map[string]interface{}), &params)
+	if err != nil {
+		return jErrors.MakeTerminatedError(err)
+	}
+
+	err = service.V2WithContext(ctx, utils.GetAuthInfo(job)).Deployments().DoCommand(id, &params)
+	if err != nil {
+		switch {
+		case errors.Is(err, sErrors.ErrDeploymentNotFound):
+			return jErrors.MakeTerminatedError(err)
+		case errors.Is(err, sErrors.ErrPodNotFound):
+			return jErrors.MakeTerminatedError(err)
+		}
+
+		return jErrors.MakeFailedError(err)
+	}
+
+	return nil
+}
+
+func ResetDeploymentScale(ctx context.Context, job *model.Job) error {
+	err := utils.AssertParameters(job, []string{"id"})
+	if err != nil {
+		return jErrors.MakeTerminatedError(err)
+	}
+
+	id := job.Args["id"].(string)
+
+	err = service.V2WithContext(ctx).Deployments().ResetScale(id)
+	if err != nil {
+		return jErrors.MakeFailedError(err)
+	}
+
+	return nil
+}
+
+func BuildDeployment(ctx context.Context, job *model.Job) error {
+	err := utils.AssertParameters(job, []string{"id"})
+	if err != nil {
+		return jErrors.MakeTerminatedError(err)
+	}
+
+	id := job.Args["id"].(string)
+
+	err = service.V2WithContext(ctx, utils.GetAuthInfo(job)).Deployments().Build(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, sErrors.ErrDeploymentNotFound):
+			return jErrors.MakeTerminatedError(err)
+		case errors.Is(err, sErrors.ErrDeploymentNotGit):
+			return jErrors.MakeTerminatedError(err)
+		case errors.Is(err, sErrors.ErrBuildFailed):
+			// The build output is already in the deployment's logs, and retrying would most likely fail again
+			return jErrors.MakeTerminatedError(err)
+		}
+
+		return jErrors.MakeFailedError(err)
+	}
+
+	return nil
+}
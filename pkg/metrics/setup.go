@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/kthcloud/go-deploy/pkg/db/key_value"
 	"github.com/kthcloud/go-deploy/utils"
@@ -24,6 +25,9 @@ type MetricDefinition struct {
 	Description string
 	Key         string
 	MetricType  ginmetrics.MetricType
+	// Label is the name of the label of a metric with one value per label value, such as one value per job type.
+	// The values of such metrics are stored as a JSON object keyed by label value.
+	Label string
 }
 
 const (
@@ -46,6 +50,10 @@ const (
 	KeyJobsTerminated = "metrics:jobs:terminated"
 	// KeyJobsCompleted is the key for the total number of jobs with status job.StatusCompleted
 	KeyJobsCompleted = "metrics:jobs:completed"
+	// KeyJobsQueueDepth is the key for the number of jobs that are ready to run, per job type
+	KeyJobsQueueDepth = "metrics:jobs:queue_depth"
+	// KeyJobsInFlight is the key for the number of jobs running on all job executors, per job type
+	KeyJobsInFlight = "metrics:jobs:in_flight"
 )
 
 func Setup() error {
@@ -60,11 +68,16 @@ func Setup() error {
 	for _, def := range collectors {
 		switch def.MetricType {
 		case ginmetrics.Gauge:
+			labels := []string{}
+			if def.Label != "" {
+				labels = []string{def.Label}
+			}
+
 			err := m.AddMetric(&ginmetrics.Metric{
 				Type:        ginmetrics.Gauge,
 				Name:        def.Name,
 				Description: def.Description,
-				Labels:      []string{},
+				Labels:      labels,
 			})
 			if err != nil {
 				return fmt.Errorf("failed to add metric %s to monitor. details: %w", def.Name, err)
@@ -93,6 +106,11 @@ func Sync() {
 			continue
 		}
 
+		if collector.Label != "" {
+			syncLabeled(monitor, &collector, valueStr)
+			continue
+		}
+
 		value, err := strconv.ParseFloat(valueStr, 64)
 		if err != nil {
 			utils.PrettyPrintError(fmt.Errorf("error parsing value %s when synchronizing metrics. details: %w", valueStr, err))
@@ -118,6 +136,30 @@ func Sync() {
 	}
 }
 
+// syncLabeled synchronizes a metric with one value per label value.
+func syncLabeled(monitor *ginmetrics.Monitor, collector *MetricDefinition, valueStr string) {
+	var values map[string]float64
+	err := json.Unmarshal([]byte(valueStr), &values)
+	if err != nil {
+		utils.PrettyPrintError(fmt.Errorf("error parsing value %s when synchronizing metrics. details: %w", valueStr, err))
+		return
+	}
+
+	metric := monitor.GetMetric(collector.Name)
+	if metric == nil {
+		utils.PrettyPrintError(fmt.Errorf("metric %s not found when synchronizing metrics", collector.Name))
+		return
+	}
+
+	for labelValue, value := range values {
+		err = metric.SetGaugeValue([]string{labelValue}, value)
+		if err != nil {
+			utils.PrettyPrintError(fmt.Errorf("error setting gauge value for metric %s when synchronizing metrics. details: %w", collector.Name, err))
+			return
+		}
+	}
+}
+
 // GetCollectors returns all collectors.
 func GetCollectors() []MetricDefinition {
 	defs := []MetricDefinition{
@@ -175,6 +217,20 @@ func GetCollectors() []MetricDefinition {
 			Key:         KeyJobsCompleted,
 			MetricType:  ginmetrics.Gauge,
 		},
+		{
+			Name:        "jobs_queue_depth",
+			Description: "Number of jobs ready to run, per job type",
+			Key:         KeyJobsQueueDepth,
+			MetricType:  ginmetrics.Gauge,
+			Label:       "type",
+		},
+		{
+			Name:        "jobs_in_flight",
+			Description: "Number of jobs running on all job executors, per job type",
+			Key:         KeyJobsInFlight,
+			MetricType:  ginmetrics.Gauge,
+			Label:       "type",
+		},
 	}
 
 	for i := range defs {
@@ -22,7 +22,7 @@ func staleResourceCleaner() error {
 	}
 
 	for _, deployment := range deployments {
-		if deployment.NeverStale || deployment.GetMainApp().Replicas == 0 {
+		if deployment.NeverStale || deployment.Paused || deployment.GetMainApp().Replicas == 0 {
 			continue
 		}
 
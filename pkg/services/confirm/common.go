@@ -46,7 +46,7 @@ func appDeletedK8s(deployment *model.Deployment, app *model.App) bool {
 	}
 
 	secretDeleted := true
-	if deployment.Type == model.DeploymentTypeCustom {
+	if deployment.HasOwnImage() {
 		for mapName, secret := range deployment.Subsystems.K8s.SecretMap {
 			if secret.Created() && mapName == deployment.Name+"-image-pull-secret" {
 				secretDeleted = false
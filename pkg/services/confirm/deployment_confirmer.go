@@ -29,7 +29,8 @@ func DeploymentDeletionConfirmer() error {
 
 		allFinished := slices.IndexFunc(relatedJobs, func(j model.Job) bool {
 			return j.Status != model.JobStatusCompleted &&
-				j.Status != model.JobStatusTerminated
+				j.Status != model.JobStatusTerminated &&
+				j.Status != model.JobStatusCancelled
 		}) == -1
 
 		if allFinished {
@@ -29,7 +29,7 @@ func GcDeletionConfirmer() error {
 		}
 
 		allFinished := slices0.IndexFunc(([]model.Job)(relatedJobs), (func(model.Job) bool)(func(j model.Job) bool {
-			return j.Status != model.JobStatusCompleted && j.Status != model.JobStatusTerminated
+			return j.Status != model.JobStatusCompleted && j.Status != model.JobStatusTerminated && j.Status != model.JobStatusCancelled
 		})) == -1
 
 		if allFinished {
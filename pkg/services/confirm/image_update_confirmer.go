@@ -0,0 +1,33 @@
+package confirm
+
+import (
+	"fmt"
+
+	"github.com/kthcloud/go-deploy/pkg/config"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_repo"
+	"github.com/kthcloud/go-deploy/service"
+	"github.com/kthcloud/go-deploy/utils"
+)
+
+// ImageUpdateConfirmer is a worker that checks the registries of deployments with an auto-update policy for new images.
+// A registry that cannot be reached only affects its own deployments, so errors are printed and the rest are still checked.
+func ImageUpdateConfirmer() error {
+	deployments, err := deployment_repo.New().WithAutoUpdate().List()
+	if err != nil {
+		return err
+	}
+
+	for _, deployment := range deployments {
+		zone := config.Config.GetZone(deployment.Zone)
+		if zone == nil || !zone.Enabled {
+			continue
+		}
+
+		err = service.V2().Deployments().CheckImageUpdate(deployment.ID)
+		if err != nil {
+			utils.PrettyPrintError(fmt.Errorf("failed to check image update for deployment %s. details: %w", deployment.ID, err))
+		}
+	}
+
+	return nil
+}
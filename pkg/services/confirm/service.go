@@ -18,4 +18,5 @@ func Setup(ctx context.Context) {
 	go services.PeriodicWorker(ctx, "vmDeletionConfirmer", VmDeletionConfirmer, config.Config.Timer.VmDeletionConfirm)
 	go services.PeriodicWorker(ctx, "customDomainConfirmer", CustomDomainConfirmer, config.Config.Timer.CustomDomainConfirm)
 	go services.PeriodicWorker(ctx, "gpClaimDeletionConfirmer", GcDeletionConfirmer, config.Config.Timer.DeploymentDeletionConfirm)
+	go services.PeriodicWorker(ctx, "imageUpdateConfirmer", ImageUpdateConfirmer, config.Config.Timer.DeploymentAutoUpdate)
 }
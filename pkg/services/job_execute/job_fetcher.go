@@ -1,13 +1,27 @@
 package job_execute
 
 import (
+	"sync"
+
 	"github.com/kthcloud/go-deploy/pkg/db/resources/job_repo"
 	"github.com/kthcloud/go-deploy/pkg/jobs"
 )
 
+// fetchLock makes sure that the fetchers do not start more jobs than the concurrency limits allow,
+// since the limits are checked before a job is claimed.
+var fetchLock sync.Mutex
+
 // JobFetcher is a worker that fetches new jobs from the database and runs them.
 func JobFetcher() error {
-	job, err := job_repo.New().GetNext()
+	fetchLock.Lock()
+	defer fetchLock.Unlock()
+
+	jmc, ok, err := limitedClient()
+	if err != nil || !ok {
+		return err
+	}
+
+	job, err := jmc.GetNext(jobs.WorkerID(), jobs.LeaseDuration)
 	if err != nil {
 		return err
 	}
@@ -23,7 +37,15 @@ func JobFetcher() error {
 
 // FailedJobFetcher is a worker that fetches failed jobs from the database and runs them.
 func FailedJobFetcher() error {
-	job, err := job_repo.New().GetNextFailed()
+	fetchLock.Lock()
+	defer fetchLock.Unlock()
+
+	jmc, ok, err := limitedClient()
+	if err != nil || !ok {
+		return err
+	}
+
+	job, err := jmc.GetNextFailed(jobs.WorkerID(), jobs.LeaseDuration)
 	if err != nil {
 		return err
 	}
@@ -36,3 +58,23 @@ func FailedJobFetcher() error {
 
 	return nil
 }
+
+// limitedClient returns a job client that only fetches job types that are below their concurrency limit.
+// It returns false if the job executors are at the global concurrency limit.
+func limitedClient() (*job_repo.Client, bool, error) {
+	atCapacity, typesAtCapacity, err := jobs.Capacity()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if atCapacity {
+		return nil, false, nil
+	}
+
+	jmc := job_repo.New()
+	if len(typesAtCapacity) > 0 {
+		jmc.ExcludeTypes(typesAtCapacity...)
+	}
+
+	return jmc, true, nil
+}
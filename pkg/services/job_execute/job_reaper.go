@@ -0,0 +1,43 @@
+package job_execute
+
+import (
+	"fmt"
+
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/job_repo"
+	"github.com/kthcloud/go-deploy/pkg/jobs"
+	"github.com/kthcloud/go-deploy/pkg/log"
+)
+
+// JobReaper is a worker that requeues running jobs whose lease has expired.
+// A lease expires when the worker running the job stops sending heartbeats, e.g. because it crashed.
+// Jobs that have reached the attempts limit of their retry policy are terminated instead.
+func JobReaper() error {
+	expired, err := job_repo.New().ListExpiredLeases(jobs.LeaseDuration)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range expired {
+		attempts := job.Attempts + 1
+		status := model.JobStatusPending
+		reason := fmt.Sprintf("lease held by worker %s expired, requeued job", job.WorkerID)
+
+		if attempts >= jobs.GetRetryPolicy(&job).MaxAttempts {
+			status = model.JobStatusTerminated
+			reason = fmt.Sprintf("lease held by worker %s expired after %d attempts, terminated job", job.WorkerID, attempts)
+		}
+
+		released, err := job_repo.New().ReleaseExpiredLease(job.ID, status, attempts, reason, jobs.LeaseDuration)
+		if err != nil {
+			return err
+		}
+
+		// Another replica might have released it first
+		if released {
+			log.Printf("Job %s (%s) %s", job.ID, job.Type, reason)
+		}
+	}
+
+	return nil
+}
@@ -8,10 +8,12 @@ import (
 )
 
 // Setup starts the job workers.
-// Job execution workers are workers that runs any jobs that are ready to be executed.
+// Job execution workers are workers that runs any jobs that are ready to be executed,
+// and requeues jobs that were abandoned by a crashed worker.
 func Setup(ctx context.Context) {
 	log.Println("Starting job workers")
 
 	go services.PeriodicWorker(ctx, "jobFetcher", JobFetcher, config.Config.Timer.JobFetch)
 	go services.PeriodicWorker(ctx, "failedJobFetcher", FailedJobFetcher, config.Config.Timer.FailedJobFetch)
+	go services.PeriodicWorker(ctx, "jobReaper", JobReaper, config.Config.Timer.JobReap)
 }
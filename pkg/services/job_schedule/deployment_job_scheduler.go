@@ -19,7 +19,7 @@ func DeploymentRepairScheduler() error {
 	for _, deployment := range withNoActivities {
 		exists, err := job_repo.New().
 			IncludeTypes(model.JobRepairDeployment).
-			ExcludeStatus(model.JobStatusTerminated, model.JobStatusCompleted).
+			ExcludeStatus(model.JobStatusTerminated, model.JobStatusCompleted, model.JobStatusCancelled).
 			FilterArgs("id", deployment.ID).
 			ExistsAny()
 		if err != nil {
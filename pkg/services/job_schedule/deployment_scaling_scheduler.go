@@ -0,0 +1,33 @@
+package job_schedule
+
+import (
+	"fmt"
+
+	"github.com/kthcloud/go-deploy/pkg/config"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_repo"
+	"github.com/kthcloud/go-deploy/service"
+	"github.com/kthcloud/go-deploy/utils"
+)
+
+// DeploymentScalingScheduler is a worker that scales deployments according to their scaling schedules.
+// A deployment that cannot be scaled does not affect the others, so errors are printed and the rest are still scheduled.
+func DeploymentScalingScheduler() error {
+	deployments, err := deployment_repo.New().WithScalingSchedule().List()
+	if err != nil {
+		return err
+	}
+
+	for _, deployment := range deployments {
+		zone := config.Config.GetZone(deployment.Zone)
+		if zone == nil || !zone.Enabled {
+			continue
+		}
+
+		err = service.V2().Deployments().ApplySchedule(deployment.ID)
+		if err != nil {
+			utils.PrettyPrintError(fmt.Errorf("failed to apply scaling schedule for deployment %s. details: %w", deployment.ID, err))
+		}
+	}
+
+	return nil
+}
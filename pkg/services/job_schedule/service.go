@@ -11,6 +11,8 @@ import (
 func Setup(ctx context.Context) {
 	log.Println("Starting job schedulers")
 	go services.PeriodicWorker(ctx, "deploymentRepairScheduler", DeploymentRepairScheduler, config.Config.Timer.DeploymentRepair)
+	go services.PeriodicWorker(ctx, "deploymentScalingScheduler", DeploymentScalingScheduler, config.Config.Timer.DeploymentSchedule)
 	go services.PeriodicWorker(ctx, "smRepairScheduler", SmRepairScheduler, config.Config.Timer.SmRepair)
 	go services.PeriodicWorker(ctx, "vmRepairScheduler", VmRepairScheduler, config.Config.Timer.VmRepair)
+	go services.PeriodicWorker(ctx, "vmSnapshotScheduler", VmSnapshotScheduler, config.Config.Timer.VmSnapshot)
 }
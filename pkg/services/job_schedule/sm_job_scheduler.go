@@ -19,7 +19,7 @@ func SmRepairScheduler() error {
 	for _, sm := range withNoActivities {
 		exists, err := job_repo.New().
 			IncludeTypes(model.JobRepairSM).
-			ExcludeStatus(model.JobStatusTerminated, model.JobStatusCompleted).
+			ExcludeStatus(model.JobStatusTerminated, model.JobStatusCompleted, model.JobStatusCancelled).
 			FilterArgs("id", sm.ID).
 			ExistsAny()
 		if err != nil {
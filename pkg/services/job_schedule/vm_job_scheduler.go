@@ -19,7 +19,7 @@ func VmRepairScheduler() error {
 	for _, vm := range withNoActivities {
 		exists, err := job_repo.New().
 			IncludeTypes(model.JobRepairVM).
-			ExcludeStatus(model.JobStatusTerminated, model.JobStatusCompleted).
+			ExcludeStatus(model.JobStatusTerminated, model.JobStatusCompleted, model.JobStatusCancelled).
 			FilterArgs("id", vm.ID).
 			ExistsAny()
 		if err != nil {
@@ -0,0 +1,33 @@
+package job_schedule
+
+import (
+	"fmt"
+
+	"github.com/kthcloud/go-deploy/pkg/config"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/vm_repo"
+	"github.com/kthcloud/go-deploy/service"
+	"github.com/kthcloud/go-deploy/utils"
+)
+
+// VmSnapshotScheduler is a worker that snapshots VMs according to their snapshot policies, and prunes old snapshots.
+// A VM that cannot be snapshotted does not affect the others, so errors are printed and the rest are still scheduled.
+func VmSnapshotScheduler() error {
+	vms, err := vm_repo.New().WithSnapshotPolicies().List()
+	if err != nil {
+		return err
+	}
+
+	for _, vm := range vms {
+		zone := config.Config.GetZone(vm.Zone)
+		if zone == nil || !zone.Enabled {
+			continue
+		}
+
+		err = service.V2().VMs().Snapshots().ApplyPolicies(vm.ID)
+		if err != nil {
+			utils.PrettyPrintError(fmt.Errorf("failed to apply snapshot policies for vm %s. details: %w", vm.ID, err))
+		}
+	}
+
+	return nil
+}
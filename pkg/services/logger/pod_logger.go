@@ -13,6 +13,7 @@ import (
 	"github.com/kthcloud/go-deploy/pkg/config"
 	"github.com/kthcloud/go-deploy/pkg/db/key_value"
 	"github.com/kthcloud/go-deploy/pkg/db/message_queue"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_log_repo"
 	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_repo"
 	"github.com/kthcloud/go-deploy/pkg/log"
 	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s"
@@ -65,8 +66,24 @@ func OnPodEvent(ctx context.Context, zone *configModels.Zone, cancelFuncs map[st
 				return nil
 			}
 
+			// The deployment is resolved on the first log, since the pod only knows the name of its deployment
+			var deploymentID string
 			onLog := func(deploymentName string, lines []model.Log) {
-				err = deployment_repo.New().AddLogsByName(deploymentName, lines...)
+				if deploymentID == "" {
+					deployment, err := deployment_repo.New().GetByName(deploymentName)
+					if err != nil {
+						utils.PrettyPrintError(fmt.Errorf("failed to get deployment %s for pod %s. details: %w", deploymentName, logEvent.PodName, err))
+						return
+					}
+
+					if deployment == nil {
+						return
+					}
+
+					deploymentID = deployment.ID
+				}
+
+				err = deployment_log_repo.New().Create(deploymentID, zone.Name, zone.LogRetention(), lines...)
 				if err != nil {
 					utils.PrettyPrintError(fmt.Errorf("failed to add k8s logs for deployment %s. details: %w", logEvent.PodName, err))
 					return
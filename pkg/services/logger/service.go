@@ -19,12 +19,20 @@ const (
 
 	// LogsKey is the key for logs.
 	LogsKey = "logs"
+	// VmLogsKey is the key for VM console logs.
+	VmLogsKey = "vmLogs"
 )
 
 var (
 	LoggerLifetime    = time.Second * 10
 	LoggerUpdate      = time.Second * 5
 	LoggerSynchronize = time.Second * 30
+
+	// VmConsoleIdleTimeout is how long a VM's serial console can be without output before capturing stops.
+	// KubeVirt only allows one connection to the serial console, so it is released for interactive use.
+	VmConsoleIdleTimeout = time.Minute * 10
+	// VmConsoleRelease is how long the serial console is left released before capturing resumes.
+	VmConsoleRelease = time.Minute * 30
 )
 
 func LastLogKey(podName, zoneName string) string {
@@ -39,6 +47,15 @@ func LogKey(podName, zoneName string) string {
 	return LogsKey + ":" + zoneName + ":" + podName
 }
 
+func VmOwnerLogKey(vmiName, zoneName string) string {
+	return VmLogsKey + ":" + zoneName + ":" + vmiName + ":owner"
+}
+
+// VmConsoleReleasedKey is set while the serial console of a VMI is released, and is not captured.
+func VmConsoleReleasedKey(vmiName, zoneName string) string {
+	return VmLogsKey + ":" + zoneName + ":" + vmiName + ":released"
+}
+
 func LogQueueKey(zoneName string) string {
 	return "queue:" + LogsKey + ":" + zoneName
 }
@@ -89,6 +106,7 @@ func Setup(ctx context.Context, roles []LogRole) {
 			go services.Worker(ctx, "deploymentLoggerControl", PodLoggerControl)
 		case LogRoleWorker:
 			go services.Worker(ctx, "deploymentLoggerWorker", PodLogger)
+			go services.Worker(ctx, "vmLoggerWorker", VmLogger)
 		}
 	}
 
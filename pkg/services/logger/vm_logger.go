@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	configModels "github.com/kthcloud/go-deploy/models/config"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/models/version"
+	"github.com/kthcloud/go-deploy/pkg/config"
+	"github.com/kthcloud/go-deploy/pkg/db/key_value"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/vm_log_repo"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/vm_repo"
+	"github.com/kthcloud/go-deploy/pkg/log"
+	k8sModels "github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
+	"github.com/kthcloud/go-deploy/service"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	"github.com/kthcloud/go-deploy/utils"
+)
+
+// VmLogger is a worker that captures the serial console output of VMs.
+//
+// Every running VMI is captured by exactly one logger, which is ensured by an owner key per VMI.
+// If a logger stops, its owner key expires and the VMI is picked up by another logger.
+func VmLogger(ctx context.Context) error {
+	name, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+
+	for _, zone := range config.Config.EnabledZones() {
+		if !zone.HasCapability(configModels.ZoneCapabilityVM) {
+			continue
+		}
+
+		log.Println("Setting up vm console capture for zone", zone.Name)
+
+		z := zone
+		go func() {
+			active := make(map[string]bool)
+			mu := sync.Mutex{}
+
+			ticker := time.NewTicker(LoggerUpdate)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					vmis, err := service.V2().VMs().K8s().ListRunningVmis(&z)
+					if err != nil {
+						utils.PrettyPrintError(fmt.Errorf("failed to list running vmis in zone %s. details: %w", z.Name, err))
+						continue
+					}
+
+					for _, vmi := range vmis {
+						mu.Lock()
+						if active[vmi.ID] {
+							mu.Unlock()
+							continue
+						}
+						active[vmi.ID] = true
+						mu.Unlock()
+
+						go func(vmi k8sModels.RunningVmi) {
+							defer func() {
+								mu.Lock()
+								delete(active, vmi.ID)
+								mu.Unlock()
+							}()
+
+							err := captureVmConsole(ctx, &z, name, vmi)
+							if err != nil {
+								utils.PrettyPrintError(err)
+							}
+						}(vmi)
+					}
+				}
+			}
+		}()
+	}
+
+	return nil
+}
+
+// captureVmConsole captures the serial console of a VMI until it stops, or until ownership is lost.
+// It does nothing if another logger owns the VMI, or if the console is released.
+//
+// The console is released when it has no output for VmConsoleIdleTimeout, so that it can be used
+// interactively, e.g. with virtctl console. Capturing resumes once VmConsoleRelease has passed.
+func captureVmConsole(ctx context.Context, zone *configModels.Zone, owner string, vmi k8sModels.RunningVmi) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to capture console of vmi %s. details: %w", vmi.ID, err)
+	}
+
+	kvc := key_value.New()
+	ownerKey := VmOwnerLogKey(vmi.ID, zone.Name)
+	releasedKey := VmConsoleReleasedKey(vmi.ID, zone.Name)
+
+	released, err := kvc.IsSet(releasedKey)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if released {
+		return nil
+	}
+
+	// We initially use a 2x lifetime to ensure that the logger is not removed while it is being set up
+	didSet, err := kvc.SetNX(ownerKey, owner, LoggerLifetime*2)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if !didSet {
+		return nil
+	}
+
+	defer func() {
+		_ = kvc.Del(ownerKey)
+	}()
+
+	vm, err := vm_repo.New(version.V2).GetByName(vmi.Name)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if vm == nil {
+		return nil
+	}
+
+	loggerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Keep ownership of the VMI while capturing
+	go func() {
+		tick := time.NewTicker(LoggerUpdate)
+		defer tick.Stop()
+
+		for {
+			select {
+			case <-loggerCtx.Done():
+				return
+			case <-tick.C:
+				didSet, err := kvc.SetXX(ownerKey, owner, LoggerLifetime)
+				if err != nil {
+					utils.PrettyPrintError(fmt.Errorf("failed to update ownership of vmi %s. details: %w", vmi.ID, err))
+					cancel()
+					return
+				}
+
+				if !didSet {
+					log.Printf("Logger no longer owns vmi %s. Cancelling", vmi.ID)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	onLog := func(lines []model.Log) {
+		err := vm_log_repo.New().Create(vm.ID, zone.Name, zone.LogRetention(), lines...)
+		if err != nil {
+			utils.PrettyPrintError(fmt.Errorf("failed to add console logs for vm %s. details: %w", vm.ID, err))
+		}
+	}
+
+	log.Println("Capturing console of vmi", vmi.ID)
+	err = service.V2().VMs().K8s().StreamConsole(loggerCtx, zone, vmi.ID, VmConsoleIdleTimeout, onLog)
+	if err != nil {
+		if errors.Is(err, sErrors.ErrVmNotFound) {
+			return nil
+		}
+
+		if errors.Is(err, sErrors.ErrVmConsoleIdle) {
+			log.Println("Console of vmi", vmi.ID, "is idle, releasing it for", VmConsoleRelease)
+			err = kvc.Set(releasedKey, owner, VmConsoleRelease)
+			if err != nil {
+				return makeError(err)
+			}
+
+			return nil
+		}
+
+		return makeError(err)
+	}
+
+	log.Println("Console capture of vmi", vmi.ID, "stopped")
+	return nil
+}
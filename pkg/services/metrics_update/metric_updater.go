@@ -1,11 +1,13 @@
 package metrics_update
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/kthcloud/go-deploy/models/model"
 	"github.com/kthcloud/go-deploy/pkg/db/key_value"
 	"github.com/kthcloud/go-deploy/pkg/db/resources/job_repo"
 	"github.com/kthcloud/go-deploy/pkg/db/resources/user_repo"
+	"github.com/kthcloud/go-deploy/pkg/jobs"
 	"github.com/kthcloud/go-deploy/pkg/metrics"
 	"github.com/kthcloud/go-deploy/utils"
 	"go.mongodb.org/mongo-driver/bson"
@@ -22,6 +24,8 @@ var metricsFuncMap = map[string]func() error{
 	"jobs-failed":          jobMetrics(metrics.KeyJobsFailed, strPtr(model.JobStatusFailed)),
 	"jobs-terminated":      jobMetrics(metrics.KeyJobsTerminated, strPtr(model.JobStatusTerminated)),
 	"jobs-completed":       jobMetrics(metrics.KeyJobsCompleted, strPtr(model.JobStatusCompleted)),
+	"jobs-queue-depth":     jobTypeMetrics(metrics.KeyJobsQueueDepth, (*job_repo.Client).CountReady),
+	"jobs-in-flight":       jobTypeMetrics(metrics.KeyJobsInFlight, (*job_repo.Client).CountRunning),
 }
 
 // MetricsUpdater is a worker that updates metrics.
@@ -105,6 +109,34 @@ func jobMetrics(key string, status *string) func() error {
 	}
 }
 
+// jobTypeMetrics computes the number of jobs per job type and stores it in the key-value store.
+// Every job type is included, so that types without any jobs are reported as zero.
+func jobTypeMetrics(key string, count func(*job_repo.Client) (map[string]int, error)) func() error {
+	return func() error {
+		counts, err := count(job_repo.New())
+		if err != nil {
+			return fmt.Errorf("error counting jobs per type when computing metrics. details: %w", err)
+		}
+
+		values := make(map[string]int)
+		for _, jobType := range jobs.Types() {
+			values[jobType] = counts[jobType]
+		}
+
+		valueStr, err := json.Marshal(values)
+		if err != nil {
+			return fmt.Errorf("error encoding value for key %s when computing metrics. details: %w", key, err)
+		}
+
+		err = key_value.New().Set(key, string(valueStr), 0)
+		if err != nil {
+			return fmt.Errorf("error setting value for key %s when computing metrics. details: %w", key, err)
+		}
+
+		return nil
+	}
+}
+
 // strPtr is a helper function that returns a pointer to a string.
 func strPtr(s string) *string {
 	return &s
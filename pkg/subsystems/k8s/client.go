@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"github.com/kthcloud/go-deploy/pkg/imp/kubevirt/kubevirt"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 // ClientConf is the configuration for the Kubernetes wrapper client.
 type ClientConf struct {
+	RestConfig        *rest.Config
 	K8sClient         *kubernetes.Clientset
 	KubeVirtK8sClient *kubevirt.Clientset
 	Namespace         string
@@ -15,6 +17,7 @@ type ClientConf struct {
 
 // Client is a wrapper around the Kubernetes client.
 type Client struct {
+	RestConfig        *rest.Config
 	K8sClient         *kubernetes.Clientset
 	KubeVirtK8sClient *kubevirt.Clientset
 	Namespace         string
@@ -27,6 +30,7 @@ func New(conf *ClientConf) (*Client, error) {
 	}
 
 	client := Client{
+		RestConfig:        conf.RestConfig,
 		K8sClient:         conf.K8sClient,
 		KubeVirtK8sClient: conf.KubeVirtK8sClient,
 		Namespace:         conf.Namespace,
@@ -8,4 +8,7 @@ var (
 
 	// ErrNotFound is returned when a resource is not found.
 	ErrNotFound = fmt.Errorf("resource not found")
+
+	// ErrIdle is returned when a connection is closed since nothing was received for too long.
+	ErrIdle = fmt.Errorf("connection idle")
 )
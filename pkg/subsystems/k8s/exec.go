@@ -0,0 +1,118 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/errors"
+	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// ExecOpts are the options used when executing a command in a pod.
+type ExecOpts struct {
+	Container string
+	Command   []string
+	TTY       bool
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	// Stderr is ignored if TTY is set, since the TTY merges it into Stdout.
+	Stderr io.Writer
+
+	// Resize receives terminal size changes. It is only used if TTY is set.
+	Resize <-chan models.TerminalSize
+}
+
+// sizeQueue implements remotecommand.TerminalSizeQueue on top of a channel.
+type sizeQueue struct {
+	ctx    context.Context
+	resize <-chan models.TerminalSize
+}
+
+// Next blocks until the next terminal size is available, and returns nil when there are no more sizes.
+func (q *sizeQueue) Next() *remotecommand.TerminalSize {
+	select {
+	case <-q.ctx.Done():
+		return nil
+	case size, ok := <-q.resize:
+		if !ok {
+			return nil
+		}
+
+		return &remotecommand.TerminalSize{Width: size.Width, Height: size.Height}
+	}
+}
+
+// Exec executes a command in a pod and streams its input and output.
+// It blocks until the command exits or the context is cancelled.
+func (client *Client) Exec(ctx context.Context, podName string, opts *ExecOpts) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to exec in pod %s. details: %w", podName, err)
+	}
+
+	if client.RestConfig == nil {
+		return makeError(fmt.Errorf("no rest config available for client"))
+	}
+
+	req := client.K8sClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(client.Namespace).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: opts.Container,
+			Command:   opts.Command,
+			Stdin:     opts.Stdin != nil,
+			Stdout:    opts.Stdout != nil,
+			Stderr:    opts.Stderr != nil && !opts.TTY,
+			TTY:       opts.TTY,
+		}, scheme.ParameterCodec)
+
+	// Prefer the WebSocket protocol, and fall back to SPDY for clusters that do not support it yet
+	wsExecutor, err := remotecommand.NewWebSocketExecutor(client.RestConfig, "GET", req.URL().String())
+	if err != nil {
+		return makeError(err)
+	}
+
+	spdyExecutor, err := remotecommand.NewSPDYExecutor(client.RestConfig, "POST", req.URL())
+	if err != nil {
+		return makeError(err)
+	}
+
+	executor, err := remotecommand.NewFallbackExecutor(wsExecutor, spdyExecutor, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+	if err != nil {
+		return makeError(err)
+	}
+
+	streamOpts := remotecommand.StreamOptions{
+		Stdin:  opts.Stdin,
+		Stdout: opts.Stdout,
+		Tty:    opts.TTY,
+	}
+
+	if !opts.TTY {
+		streamOpts.Stderr = opts.Stderr
+	}
+
+	if opts.TTY && opts.Resize != nil {
+		streamOpts.TerminalSizeQueue = &sizeQueue{ctx: ctx, resize: opts.Resize}
+	}
+
+	err = executor.StreamWithContext(ctx, streamOpts)
+	if err != nil {
+		if IsNotFoundErr(err) {
+			return makeError(errors.ErrNotFound)
+		}
+
+		return makeError(err)
+	}
+
+	return nil
+}
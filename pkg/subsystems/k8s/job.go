@@ -1,14 +1,39 @@
 package k8s
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"slices"
+	"time"
+
 	"github.com/kthcloud/go-deploy/pkg/log"
 	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"time"
 )
 
+const (
+	// JobStatusRunning is the status of a job that has not yet completed.
+	JobStatusRunning = "running"
+	// JobStatusSucceeded is the status of a job that completed successfully.
+	JobStatusSucceeded = "succeeded"
+	// JobStatusFailed is the status of a job that failed.
+	JobStatusFailed = "failed"
+
+	// jobPodPendingTimeout is how long the pod of a job can be pending before giving up on streaming its logs.
+	jobPodPendingTimeout = 10 * time.Minute
+)
+
+// failedWaitingReasons are the reasons a container can be waiting for that it will not recover from by itself.
+var failedWaitingReasons = []string{
+	"ErrImagePull",
+	"ImagePullBackOff",
+	"InvalidImageName",
+	"CreateContainerConfigError",
+	"CreateContainerError",
+}
+
 // ReadJob reads a Job from Kubernetes.
 func (client *Client) ReadJob(name string) (*models.JobPublic, error) {
 	makeError := func(err error) error {
@@ -130,3 +155,103 @@ func (client *Client) CreateOneShotJob(public *models.JobPublic) error {
 
 	return nil
 }
+
+// GetJobStatus gets the status of a Job in Kubernetes.
+//
+// If the job does not exist, an empty string is returned.
+func (client *Client) GetJobStatus(name string) (string, error) {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to get status of k8s job %s. details: %w", name, err)
+	}
+
+	k8sJob, err := client.K8sClient.BatchV1().Jobs(client.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if IsNotFoundErr(err) {
+			return "", nil
+		}
+
+		return "", makeError(err)
+	}
+
+	if k8sJob.Status.Succeeded > 0 {
+		return JobStatusSucceeded, nil
+	}
+
+	for _, condition := range k8sJob.Status.Conditions {
+		if condition.Type == "Failed" && condition.Status == v1.ConditionTrue {
+			return JobStatusFailed, nil
+		}
+	}
+
+	return JobStatusRunning, nil
+}
+
+// StreamJobLogs follows the logs of the pod created by a Job and sends them to the callback function.
+//
+// This function is blocking and returns when the pod's log stream ends or the context is cancelled.
+func (client *Client) StreamJobLogs(ctx context.Context, jobName string, onLog func(lines []models.LogLine)) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to stream logs for k8s job %s. details: %w", jobName, err)
+	}
+
+	// Wait for the job's pod to leave the pending phase.
+	// A pod that cannot start, e.g. because its image cannot be pulled, is not waited for.
+	deadline := time.After(jobPodPendingTimeout)
+
+	var podName string
+	for podName == "" {
+		pods, err := client.K8sClient.CoreV1().Pods(client.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("job-name=%s", jobName),
+		})
+		if err != nil {
+			return makeError(err)
+		}
+
+		for _, pod := range pods.Items {
+			if pod.Status.Phase != v1.PodPending {
+				podName = pod.Name
+				break
+			}
+
+			for _, status := range pod.Status.ContainerStatuses {
+				if status.State.Waiting != nil && slices.Contains(failedWaitingReasons, status.State.Waiting.Reason) {
+					return makeError(fmt.Errorf("pod %s cannot start. reason: %s, message: %s", pod.Name, status.State.Waiting.Reason, status.State.Waiting.Message))
+				}
+			}
+		}
+
+		if podName != "" {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-deadline:
+			return makeError(fmt.Errorf("pod did not start within %s", jobPodPendingTimeout))
+		case <-time.After(1 * time.Second):
+		}
+	}
+
+	logStream, err := client.getPodLogStream(ctx, client.Namespace, podName, time.Time{})
+	if err != nil {
+		return makeError(err)
+	}
+	defer func() { _ = logStream.Close() }()
+
+	reader := bufio.NewScanner(logStream)
+	reader.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for reader.Scan() {
+		onLog([]models.LogLine{{
+			Line:      reader.Text(),
+			CreatedAt: time.Now(),
+		}})
+	}
+
+	if err = reader.Err(); err != nil && ctx.Err() == nil {
+		return makeError(err)
+	}
+
+	return nil
+}
@@ -3,6 +3,9 @@ package keys
 const (
 	// LabelDeployName is the label name for the `name` of a manifest.
 	LabelDeployName = "app.kubernetes.io/deploy-name"
+	// LabelPartOf is the label name for the `name` of the higher-level resource a manifest is part of.
+	// It is used to group the K8s deployments of all apps in a deployment.
+	LabelPartOf = "app.kubernetes.io/part-of"
 
 	// AnnotationExternalIP is the label name for the `external IP` of a manifest.
 	// Right now this is only used for MetalLB manifests.
@@ -10,12 +13,28 @@ const (
 	// AnnotationSharedIP is the label name for the `shared IP` of a manifest.
 	// Right now this is only used for MetalLB manifests.
 	AnnotationSharedIP = "metallb.universe.tf/allow-shared-ip"
+	// AnnotationSecretHash is the annotation name for the `secret hash` of a pod template.
+	// It is used to trigger a rolling restart when the secrets referenced by the pods change.
+	AnnotationSecretHash = "app.kubernetes.io/deploy-secret-hash"
+	// AnnotationFixedReplicas is the annotation name for the `fixed replicas` of a deployment.
+	// It is set on deployments that are not scaled by an HPA.
+	AnnotationFixedReplicas = "app.kubernetes.io/deploy-fixed-replicas"
 	// AnnotationCreationTimestamp is the label name for the `creation timestamp` of a manifest.
 	AnnotationCreationTimestamp = "app.kubernetes.io/deploy-created-at"
+	// AnnotationUserCreated is the annotation name for whether a VM snapshot was requested by a user.
+	// Snapshots without it are created by the system, and do not count toward the user's quota.
+	AnnotationUserCreated = "app.kubernetes.io/deploy-user-created"
 	// AnnotationClusterIssuer is the annotation name for the `cluster issuer` in a cert-manager manifest.
 	AnnotationClusterIssuer = "cert-manager.io/cluster-issuer"
 	// AnnotationCommonName is the annotation name for the `common name` in a cert-manager manifest.
 	AnnotationCommonName = "cert-manager.io/common-name"
 	// AnnotationAcmeChallengeType is the annotation name for the `acme challenge type` in a cert-manager manifest.
 	AnnotationAcmeChallengeType = "cert-manager.io/acme-challenge-type"
+	// AnnotationCanary is the annotation name that makes an ingress-nginx ingress a `canary` of another ingress with the same host.
+	AnnotationCanary = "nginx.ingress.kubernetes.io/canary"
+	// AnnotationCanaryWeight is the annotation name for the `canary weight` of an ingress-nginx canary ingress.
+	// It is the percentage of the requests that are sent to the canary.
+	AnnotationCanaryWeight = "nginx.ingress.kubernetes.io/canary-weight"
+	// AnnotationRewriteTarget is the annotation name for the `rewrite target` of an ingress-nginx ingress.
+	AnnotationRewriteTarget = "nginx.ingress.kubernetes.io/rewrite-target"
 )
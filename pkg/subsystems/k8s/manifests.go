@@ -3,6 +3,7 @@ package k8s
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -189,21 +190,46 @@ func CreateDeploymentManifest(public *models.DeploymentPublic) *appsv1.Deploymen
 	}
 	labels[keys.LabelDeployName] = public.Name
 
+	podAnnotations := map[string]string{
+		keys.AnnotationCreationTimestamp: public.CreatedAt.Format(timeFormat),
+	}
+	if public.SecretHash != "" {
+		podAnnotations[keys.AnnotationSecretHash] = public.SecretHash
+	}
+
+	var livenessProbe, readinessProbe, startupProbe *apiv1.Probe
+	if public.LivenessProbe != nil {
+		livenessProbe = public.LivenessProbe.ToK8sProbe()
+	}
+	if public.ReadinessProbe != nil {
+		readinessProbe = public.ReadinessProbe.ToK8sProbe()
+	}
+	if public.StartupProbe != nil {
+		startupProbe = public.StartupProbe.ToK8sProbe()
+	}
+
 	var replicas int32
 	if public.Disabled {
 		replicas = 0
+	} else if public.FixedReplicas > 0 {
+		replicas = int32(public.FixedReplicas)
 	} else {
 		replicas = 1
 	}
 
+	annotations := map[string]string{
+		keys.AnnotationCreationTimestamp: public.CreatedAt.Format(timeFormat),
+	}
+	if public.FixedReplicas > 0 {
+		annotations[keys.AnnotationFixedReplicas] = strconv.Itoa(public.FixedReplicas)
+	}
+
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      public.Name,
-			Namespace: public.Namespace,
-			Labels:    labels,
-			Annotations: map[string]string{
-				keys.AnnotationCreationTimestamp: public.CreatedAt.Format(timeFormat),
-			},
+			Name:        public.Name,
+			Namespace:   public.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: &replicas,
@@ -214,10 +240,8 @@ func CreateDeploymentManifest(public *models.DeploymentPublic) *appsv1.Deploymen
 			},
 			Template: apiv1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
-					Annotations: map[string]string{
-						keys.AnnotationCreationTimestamp: public.CreatedAt.Format(timeFormat),
-					},
+					Labels:      labels,
+					Annotations: podAnnotations,
 				},
 				Spec: apiv1.PodSpec{
 					Volumes:        volumes,
@@ -235,8 +259,11 @@ func CreateDeploymentManifest(public *models.DeploymentPublic) *appsv1.Deploymen
 								Requests: requests,
 								Claims:   resourceClaimUsages,
 							},
-							Lifecycle:    lifecycle,
-							VolumeMounts: normalContainerMounts,
+							Lifecycle:      lifecycle,
+							VolumeMounts:   normalContainerMounts,
+							LivenessProbe:  livenessProbe,
+							ReadinessProbe: readinessProbe,
+							StartupProbe:   startupProbe,
 						},
 					},
 					InitContainers:   initContainers,
@@ -345,6 +372,15 @@ func CreateIngressManifest(public *models.IngressPublic) *networkingv1.Ingress {
 		annotations[keys.AnnotationAcmeChallengeType] = "http01"
 	}
 
+	if public.CanaryWeight != nil {
+		annotations[keys.AnnotationCanary] = "true"
+		annotations[keys.AnnotationCanaryWeight] = strconv.Itoa(*public.CanaryWeight)
+	}
+
+	if public.RewriteTarget != nil {
+		annotations[keys.AnnotationRewriteTarget] = *public.RewriteTarget
+	}
+
 	return &networkingv1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      public.Name,
@@ -444,6 +480,12 @@ func CreateJobManifest(public *models.JobPublic) *v1.Job {
 					ClaimName: *volume.PvcName,
 				},
 			}
+		} else if volume.SecretName != nil {
+			volumeSource = apiv1.VolumeSource{
+				Secret: &apiv1.SecretVolumeSource{
+					SecretName: *volume.SecretName,
+				},
+			}
 		} else {
 			volumeSource = apiv1.VolumeSource{
 				EmptyDir: &apiv1.EmptyDirVolumeSource{},
@@ -462,11 +504,15 @@ func CreateJobManifest(public *models.JobPublic) *v1.Job {
 			volumeMounts = append(volumeMounts, apiv1.VolumeMount{
 				Name:      volume.Name,
 				MountPath: volume.MountPath,
+				SubPath:   volume.SubPath,
 			})
 		}
 	}
 
 	ttl := int32(5)
+	if public.TtlAfterFinished != nil {
+		ttl = int32(public.TtlAfterFinished.Seconds())
+	}
 	var backoffLimit *int32
 	if public.MaxTries != nil {
 		backoffLimit = intToInt32Ptr(*public.MaxTries)
@@ -505,6 +551,10 @@ func CreateJobManifest(public *models.JobPublic) *v1.Job {
 							Command:         public.Command,
 							Args:            public.Args,
 							VolumeMounts:    volumeMounts,
+							Resources: apiv1.ResourceRequirements{
+								Limits:   createResourceList(public.Resources.Limits.CPU, public.Resources.Limits.Memory),
+								Requests: createResourceList(public.Resources.Requests.CPU, public.Resources.Requests.Memory),
+							},
 						},
 					},
 				},
@@ -534,6 +584,15 @@ func CreateSecretManifest(public *models.SecretPublic) *apiv1.Secret {
 
 // CreateHpaManifest creates a Kubernetes HorizontalPodAutoscaler manifest from a models.HpaPublic.
 func CreateHpaManifest(public *models.HpaPublic) *autoscalingv2.HorizontalPodAutoscaler {
+	var behavior *autoscalingv2.HorizontalPodAutoscalerBehavior
+	if public.ScaleDownStabilizationSeconds != nil {
+		behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{
+			ScaleDown: &autoscalingv2.HPAScalingRules{
+				StabilizationWindowSeconds: intToInt32Ptr(*public.ScaleDownStabilizationSeconds),
+			},
+		}
+	}
+
 	return &autoscalingv2.HorizontalPodAutoscaler{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      public.Name,
@@ -573,6 +632,7 @@ func CreateHpaManifest(public *models.HpaPublic) *autoscalingv2.HorizontalPodAut
 					},
 				},
 			},
+			Behavior: behavior,
 		},
 	}
 }
@@ -619,6 +679,114 @@ func CreateVmManifest(public *models.VmPublic, resourceVersion ...string) *kubev
 	}
 	labels[keys.LabelDeployName] = public.Name
 
+	disks := []kubevirtv1.Disk{
+		{
+			Name: "rootdisk",
+			DiskDevice: kubevirtv1.DiskDevice{
+				Disk: &kubevirtv1.DiskTarget{
+					Bus: "virtio",
+				},
+			},
+		},
+		{
+			Name: "cloudinit",
+			DiskDevice: kubevirtv1.DiskDevice{
+				Disk: &kubevirtv1.DiskTarget{
+					Bus: "virtio",
+				},
+			},
+		},
+	}
+
+	volumes := []kubevirtv1.Volume{
+		{
+			Name: "rootdisk",
+			VolumeSource: kubevirtv1.VolumeSource{
+				DataVolume: &kubevirtv1.DataVolumeSource{
+					Name: models.RootDiskName(name),
+				},
+			},
+		},
+		{
+			Name: "cloudinit",
+			VolumeSource: kubevirtv1.VolumeSource{
+				CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{
+					UserData: public.CloudInit,
+				},
+			},
+		},
+	}
+
+	// The instance ID makes cloud-init rerun its per-instance modules when the user-data changes
+	var firmware *kubevirtv1.Firmware
+	if public.InstanceID != "" {
+		firmware = &kubevirtv1.Firmware{Serial: models.InstanceIDSerial(public.InstanceID)}
+	}
+
+	dataVolumeTemplates := []kubevirtv1.DataVolumeTemplateSpec{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: models.RootDiskName(name),
+			},
+			Spec: cdibetav1.DataVolumeSpec{
+				PVC: &apiv1.PersistentVolumeClaimSpec{
+					StorageClassName: strToPtr(models.DefaultVmDiskStorageClass),
+					AccessModes: []apiv1.PersistentVolumeAccessMode{
+						apiv1.ReadWriteMany,
+					},
+					Resources: apiv1.VolumeResourceRequirements{
+						Requests: apiv1.ResourceList{
+							apiv1.ResourceStorage: resource.MustParse(fmt.Sprintf("%dGi", public.DiskSize)),
+						},
+					},
+				},
+				Source: dvSource,
+			},
+		},
+	}
+
+	// Data disks are blank, and use the storage API so CDI picks the access mode the storage class supports
+	for _, disk := range public.Disks {
+		volumeName := models.DataDiskVolumeName(disk.Name)
+
+		disks = append(disks, kubevirtv1.Disk{
+			Name: volumeName,
+			DiskDevice: kubevirtv1.DiskDevice{
+				Disk: &kubevirtv1.DiskTarget{
+					Bus: "virtio",
+				},
+			},
+		})
+
+		volumes = append(volumes, kubevirtv1.Volume{
+			Name: volumeName,
+			VolumeSource: kubevirtv1.VolumeSource{
+				DataVolume: &kubevirtv1.DataVolumeSource{
+					Name: models.DataDiskName(name, disk.Name),
+				},
+			},
+		})
+
+		dataVolumeTemplates = append(dataVolumeTemplates, kubevirtv1.DataVolumeTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: models.DataDiskName(name, disk.Name),
+			},
+			Spec: cdibetav1.DataVolumeSpec{
+				Storage: &cdibetav1.StorageSpec{
+					StorageClassName: strToPtr(disk.StorageClass),
+					Resources: apiv1.VolumeResourceRequirements{
+						Requests: apiv1.ResourceList{
+							apiv1.ResourceStorage: resource.MustParse(fmt.Sprintf("%dGi", disk.Size)),
+						},
+					},
+				},
+				Source: &cdibetav1.DataVolumeSource{
+					Blank: &cdibetav1.DataVolumeBlankImage{},
+				},
+			},
+		})
+	}
+
 	return &kubevirtv1.VirtualMachine{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -642,25 +810,8 @@ func CreateVmManifest(public *models.VmPublic, resourceVersion ...string) *kubev
 				Spec: kubevirtv1.VirtualMachineInstanceSpec{
 					Domain: kubevirtv1.DomainSpec{
 						Devices: kubevirtv1.Devices{
-							GPUs: gpus,
-							Disks: []kubevirtv1.Disk{
-								{
-									Name: "rootdisk",
-									DiskDevice: kubevirtv1.DiskDevice{
-										Disk: &kubevirtv1.DiskTarget{
-											Bus: "virtio",
-										},
-									},
-								},
-								{
-									Name: "cloudinit",
-									DiskDevice: kubevirtv1.DiskDevice{
-										Disk: &kubevirtv1.DiskTarget{
-											Bus: "virtio",
-										},
-									},
-								},
-							},
+							GPUs:  gpus,
+							Disks: disks,
 							Interfaces: []kubevirtv1.Interface{
 								{
 									Name: "default",
@@ -671,6 +822,7 @@ func CreateVmManifest(public *models.VmPublic, resourceVersion ...string) *kubev
 							},
 							Rng: &kubevirtv1.Rng{},
 						},
+						Firmware: firmware,
 						Resources: kubevirtv1.ResourceRequirements{
 							Requests: apiv1.ResourceList{
 								apiv1.ResourceMemory: resource.MustParse(fmt.Sprintf("%dGi", public.RAM)),
@@ -690,47 +842,10 @@ func CreateVmManifest(public *models.VmPublic, resourceVersion ...string) *kubev
 							},
 						},
 					},
-					Volumes: []kubevirtv1.Volume{
-						{
-							Name: "rootdisk",
-							VolumeSource: kubevirtv1.VolumeSource{
-								DataVolume: &kubevirtv1.DataVolumeSource{
-									Name: fmt.Sprintf("%s-rootdisk-dv", name),
-								},
-							},
-						},
-						{
-							Name: "cloudinit",
-							VolumeSource: kubevirtv1.VolumeSource{
-								CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{
-									UserData: public.CloudInit,
-								},
-							},
-						},
-					},
-				},
-			},
-			DataVolumeTemplates: []kubevirtv1.DataVolumeTemplateSpec{
-				{
-					ObjectMeta: metav1.ObjectMeta{
-						Name: fmt.Sprintf("%s-rootdisk-dv", name),
-					},
-					Spec: cdibetav1.DataVolumeSpec{
-						PVC: &apiv1.PersistentVolumeClaimSpec{
-							StorageClassName: strToPtr("deploy-vm-disks"),
-							AccessModes: []apiv1.PersistentVolumeAccessMode{
-								apiv1.ReadWriteMany,
-							},
-							Resources: apiv1.VolumeResourceRequirements{
-								Requests: apiv1.ResourceList{
-									apiv1.ResourceStorage: resource.MustParse(fmt.Sprintf("%dGi", public.DiskSize)),
-								},
-							},
-						},
-						Source: dvSource,
-					},
+					Volumes: volumes,
 				},
 			},
+			DataVolumeTemplates: dataVolumeTemplates,
 		},
 	}
 }
@@ -740,6 +855,14 @@ func CreateVmSnapshotManifest(public *models.VmSnapshotPublic) *snapshotalpha1.V
 	name := public.ID
 	deployName := public.Name
 
+	annotations := map[string]string{
+		keys.AnnotationCreationTimestamp: public.CreatedAt.Format(timeFormat),
+	}
+
+	if public.UserCreated {
+		annotations[keys.AnnotationUserCreated] = "true"
+	}
+
 	return &snapshotalpha1.VirtualMachineSnapshot{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -747,9 +870,8 @@ func CreateVmSnapshotManifest(public *models.VmSnapshotPublic) *snapshotalpha1.V
 			Labels: map[string]string{
 				keys.LabelDeployName: deployName,
 			},
-			Annotations: map[string]string{
-				keys.AnnotationCreationTimestamp: public.CreatedAt.Format(timeFormat),
-			}},
+			Annotations: annotations,
+		},
 		Spec: snapshotalpha1.VirtualMachineSnapshotSpec{
 			Source: apiv1.TypedLocalObjectReference{
 				APIGroup: strToPtr("kubevirt.io"),
@@ -763,6 +885,29 @@ func CreateVmSnapshotManifest(public *models.VmSnapshotPublic) *snapshotalpha1.V
 	}
 }
 
+// CreateVmRestoreManifest creates a Kubernetes VirtualMachineRestore manifest from a models.VmRestorePublic.
+func CreateVmRestoreManifest(public *models.VmRestorePublic) *snapshotalpha1.VirtualMachineRestore {
+	return &snapshotalpha1.VirtualMachineRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      public.ID,
+			Namespace: public.Namespace,
+			Labels: map[string]string{
+				keys.LabelDeployName: public.VmID,
+			},
+			Annotations: map[string]string{
+				keys.AnnotationCreationTimestamp: public.CreatedAt.Format(timeFormat),
+			}},
+		Spec: snapshotalpha1.VirtualMachineRestoreSpec{
+			Target: apiv1.TypedLocalObjectReference{
+				APIGroup: strToPtr("kubevirt.io"),
+				Kind:     "VirtualMachine",
+				Name:     public.VmID,
+			},
+			VirtualMachineSnapshotName: public.SnapshotID,
+		},
+	}
+}
+
 // CreateNetworkPolicyManifest creates a Kubernetes NetworkPolicy manifest from a models.NetworkPolicyPublic.
 func CreateNetworkPolicyManifest(public *models.NetworkPolicyPublic) *networkingv1.NetworkPolicy {
 	to := make([]networkingv1.NetworkPolicyPeer, 0)
@@ -5,6 +5,7 @@ import (
 
 	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/keys"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 type K8sResource interface {
@@ -14,6 +15,13 @@ type K8sResource interface {
 type EnvVar struct {
 	Name  string `bson:"name"`
 	Value string `bson:"value"`
+	// SecretRef is set if the value should be read from a secret instead of Value.
+	SecretRef *SecretKeyRef `bson:"secretRef,omitempty"`
+}
+
+type SecretKeyRef struct {
+	Name string `bson:"name"`
+	Key  string `bson:"key"`
 }
 
 type Volume struct {
@@ -21,6 +29,11 @@ type Volume struct {
 	PvcName   *string `bson:"pvcName"`
 	MountPath string  `bson:"mountPath"`
 	Init      bool    `bson:"init"`
+
+	// SecretName mounts a secret instead of a PVC. It is currently only supported for jobs.
+	SecretName *string `bson:"secretName,omitempty"`
+	// SubPath mounts a single file or directory of the volume. It is currently only supported for jobs.
+	SubPath string `bson:"subPath,omitempty"`
 }
 
 type InitContainer struct {
@@ -58,8 +71,46 @@ type Toleration struct {
 	Effect   string `bson:"effect"`
 }
 
+// Probe is a health check for a container.
+// Exactly one of HttpGet, TcpSocket or Exec should be set.
+type Probe struct {
+	HttpGet   *ProbeHttpGet   `bson:"httpGet,omitempty"`
+	TcpSocket *ProbeTcpSocket `bson:"tcpSocket,omitempty"`
+	Exec      *ProbeExec      `bson:"exec,omitempty"`
+
+	InitialDelaySeconds int `bson:"initialDelaySeconds"`
+	PeriodSeconds       int `bson:"periodSeconds"`
+	TimeoutSeconds      int `bson:"timeoutSeconds"`
+	FailureThreshold    int `bson:"failureThreshold"`
+}
+
+type ProbeHttpGet struct {
+	Path string `bson:"path"`
+	Port int    `bson:"port"`
+}
+
+type ProbeTcpSocket struct {
+	Port int `bson:"port"`
+}
+
+type ProbeExec struct {
+	Command []string `bson:"command"`
+}
+
 // ToK8sEnvVar converts an EnvVar to a v1.EnvVar.
 func (envVar *EnvVar) ToK8sEnvVar() v1.EnvVar {
+	if envVar.SecretRef != nil {
+		return v1.EnvVar{
+			Name: envVar.Name,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: envVar.SecretRef.Name},
+					Key:                  envVar.SecretRef.Key,
+				},
+			},
+		}
+	}
+
 	return v1.EnvVar{
 		Name:      envVar.Name,
 		Value:     envVar.Value,
@@ -69,12 +120,82 @@ func (envVar *EnvVar) ToK8sEnvVar() v1.EnvVar {
 
 // EnvVarFromK8s converts a v1.EnvVar to an EnvVar.
 func EnvVarFromK8s(envVar *v1.EnvVar) EnvVar {
+	if envVar.ValueFrom != nil && envVar.ValueFrom.SecretKeyRef != nil {
+		return EnvVar{
+			Name: envVar.Name,
+			SecretRef: &SecretKeyRef{
+				Name: envVar.ValueFrom.SecretKeyRef.Name,
+				Key:  envVar.ValueFrom.SecretKeyRef.Key,
+			},
+		}
+	}
+
 	return EnvVar{
 		Name:  envVar.Name,
 		Value: envVar.Value,
 	}
 }
 
+// ToK8sProbe converts a Probe to a v1.Probe.
+func (probe *Probe) ToK8sProbe() *v1.Probe {
+	k8sProbe := &v1.Probe{
+		InitialDelaySeconds: int32(probe.InitialDelaySeconds),
+		PeriodSeconds:       int32(probe.PeriodSeconds),
+		TimeoutSeconds:      int32(probe.TimeoutSeconds),
+		FailureThreshold:    int32(probe.FailureThreshold),
+	}
+
+	if probe.HttpGet != nil {
+		k8sProbe.HTTPGet = &v1.HTTPGetAction{
+			Path:   probe.HttpGet.Path,
+			Port:   intstr.FromInt32(int32(probe.HttpGet.Port)),
+			Scheme: v1.URISchemeHTTP,
+		}
+	} else if probe.TcpSocket != nil {
+		k8sProbe.TCPSocket = &v1.TCPSocketAction{
+			Port: intstr.FromInt32(int32(probe.TcpSocket.Port)),
+		}
+	} else if probe.Exec != nil {
+		k8sProbe.Exec = &v1.ExecAction{
+			Command: probe.Exec.Command,
+		}
+	}
+
+	return k8sProbe
+}
+
+// ProbeFromK8s converts a v1.Probe to a Probe.
+// It returns nil if the probe is nil.
+func ProbeFromK8s(k8sProbe *v1.Probe) *Probe {
+	if k8sProbe == nil {
+		return nil
+	}
+
+	probe := &Probe{
+		InitialDelaySeconds: int(k8sProbe.InitialDelaySeconds),
+		PeriodSeconds:       int(k8sProbe.PeriodSeconds),
+		TimeoutSeconds:      int(k8sProbe.TimeoutSeconds),
+		FailureThreshold:    int(k8sProbe.FailureThreshold),
+	}
+
+	if k8sProbe.HTTPGet != nil {
+		probe.HttpGet = &ProbeHttpGet{
+			Path: k8sProbe.HTTPGet.Path,
+			Port: k8sProbe.HTTPGet.Port.IntValue(),
+		}
+	} else if k8sProbe.TCPSocket != nil {
+		probe.TcpSocket = &ProbeTcpSocket{
+			Port: k8sProbe.TCPSocket.Port.IntValue(),
+		}
+	} else if k8sProbe.Exec != nil {
+		probe.Exec = &ProbeExec{
+			Command: k8sProbe.Exec.Command,
+		}
+	}
+
+	return probe
+}
+
 // formatCreatedAt formats a Kubernetes manifest's creation timestamp to a time.Time.
 func formatCreatedAt(annotations map[string]string) time.Time {
 	created, ok := annotations[keys.AnnotationCreationTimestamp]
@@ -1,8 +1,10 @@
 package models
 
 import (
+	"strconv"
 	"time"
 
+	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/keys"
 	appsv1 "k8s.io/api/apps/v1"
 )
 
@@ -22,7 +24,17 @@ type DeploymentPublic struct {
 	Volumes          []Volume               `bson:"volumes"`
 	ResourceClaims   []DynamicResourceClaim `bson:"resourcClaims,omitempty"`
 	Tolerations      []Toleration           `bson:"tolerations,omitempty"`
-	CreatedAt        time.Time              `bson:"createdAt"`
+	LivenessProbe    *Probe                 `bson:"livenessProbe,omitempty"`
+	ReadinessProbe   *Probe                 `bson:"readinessProbe,omitempty"`
+	StartupProbe     *Probe                 `bson:"startupProbe,omitempty"`
+	// SecretHash is a hash of the secrets referenced by the deployment.
+	// It is set on the pod template, so that a change causes a rolling restart.
+	SecretHash string    `bson:"secretHash,omitempty"`
+	CreatedAt  time.Time `bson:"createdAt"`
+
+	// FixedReplicas is the number of replicas for deployments that are not scaled by an HPA.
+	// If it is zero, the deployment starts with one replica and is expected to be scaled by an HPA.
+	FixedReplicas int `bson:"fixedReplicas,omitempty"`
 
 	// Disabled is a flag that can be set to true to disable the deployment.
 	// This is useful for deployments that should not be running, but should still exist.
@@ -54,6 +66,8 @@ func CreateDeploymentPublicFromRead(deployment *appsv1.Deployment) *DeploymentPu
 	var claims []DynamicResourceClaim
 	var tolerations []Toleration
 	var image string
+	var livenessProbe, readinessProbe, startupProbe *Probe
+	var fixedReplicas int
 
 	for _, k8sVolume := range deployment.Spec.Template.Spec.Volumes {
 		var pvcName *string
@@ -105,6 +119,9 @@ func CreateDeploymentPublicFromRead(deployment *appsv1.Deployment) *DeploymentPu
 		image = firstContainer.Image
 		command = firstContainer.Command
 		args = firstContainer.Args
+		livenessProbe = ProbeFromK8s(firstContainer.LivenessProbe)
+		readinessProbe = ProbeFromK8s(firstContainer.ReadinessProbe)
+		startupProbe = ProbeFromK8s(firstContainer.StartupProbe)
 
 		if resources.Limits != nil {
 			if resources.Limits.Cpu() != nil {
@@ -184,6 +201,10 @@ func CreateDeploymentPublicFromRead(deployment *appsv1.Deployment) *DeploymentPu
 		}
 	}
 
+	if value, ok := deployment.Annotations[keys.AnnotationFixedReplicas]; ok {
+		fixedReplicas, _ = strconv.Atoi(value)
+	}
+
 	// Delete any k8sVolumes that does not have a mount path, they need to be recreated
 	for i := len(volumes) - 1; i >= 0; i-- {
 		if volumes[i].MountPath == "" {
@@ -209,6 +230,11 @@ func CreateDeploymentPublicFromRead(deployment *appsv1.Deployment) *DeploymentPu
 		Volumes:        volumes,
 		ResourceClaims: claims,
 		Tolerations:    tolerations,
+		LivenessProbe:  livenessProbe,
+		ReadinessProbe: readinessProbe,
+		StartupProbe:   startupProbe,
+		SecretHash:     deployment.Spec.Template.Annotations[keys.AnnotationSecretHash],
+		FixedReplicas:  fixedReplicas,
 		CreatedAt:      formatCreatedAt(deployment.Annotations),
 	}
 }
@@ -13,14 +13,19 @@ type Target struct {
 }
 
 type HpaPublic struct {
-	Name                     string    `bson:"name"`
-	Namespace                string    `bson:"namespace"`
-	MinReplicas              int       `bson:"minReplicas"`
-	MaxReplicas              int       `bson:"maxReplicas"`
-	Target                   Target    `bson:"target"`
-	CpuAverageUtilization    int       `bson:"cpuAverageUtilization"`
-	MemoryAverageUtilization int       `bson:"memoryAverageUtilization"`
-	CreatedAt                time.Time `bson:"createdAt"`
+	Name                     string `bson:"name"`
+	Namespace                string `bson:"namespace"`
+	MinReplicas              int    `bson:"minReplicas"`
+	MaxReplicas              int    `bson:"maxReplicas"`
+	Target                   Target `bson:"target"`
+	CpuAverageUtilization    int    `bson:"cpuAverageUtilization"`
+	MemoryAverageUtilization int    `bson:"memoryAverageUtilization"`
+
+	// ScaleDownStabilizationSeconds is the scale down stabilization window.
+	// If it is nil, no scaling behavior is set and the K8s defaults are used.
+	ScaleDownStabilizationSeconds *int `bson:"scaleDownStabilizationSeconds,omitempty"`
+
+	CreatedAt time.Time `bson:"createdAt"`
 }
 
 func (h *HpaPublic) Created() bool {
@@ -37,6 +42,7 @@ func CreateHpaPublicFromRead(hpa *v2.HorizontalPodAutoscaler) *HpaPublic {
 	var maxReplicas int
 	var cpuAverageUtilization int
 	var memoryAverageUtilization int
+	var scaleDownStabilizationSeconds *int
 
 	if hpa.Spec.MinReplicas != nil {
 		minReplicas = int(*hpa.Spec.MinReplicas)
@@ -56,6 +62,11 @@ func CreateHpaPublicFromRead(hpa *v2.HorizontalPodAutoscaler) *HpaPublic {
 		}
 	}
 
+	if hpa.Spec.Behavior != nil && hpa.Spec.Behavior.ScaleDown != nil && hpa.Spec.Behavior.ScaleDown.StabilizationWindowSeconds != nil {
+		seconds := int(*hpa.Spec.Behavior.ScaleDown.StabilizationWindowSeconds)
+		scaleDownStabilizationSeconds = &seconds
+	}
+
 	return &HpaPublic{
 		Name:        hpa.Name,
 		Namespace:   hpa.Namespace,
@@ -66,8 +77,9 @@ func CreateHpaPublicFromRead(hpa *v2.HorizontalPodAutoscaler) *HpaPublic {
 			Name:       hpa.Spec.ScaleTargetRef.Name,
 			ApiVersion: hpa.Spec.ScaleTargetRef.APIVersion,
 		},
-		CpuAverageUtilization:    cpuAverageUtilization,
-		MemoryAverageUtilization: memoryAverageUtilization,
-		CreatedAt:                formatCreatedAt(hpa.Annotations),
+		CpuAverageUtilization:         cpuAverageUtilization,
+		MemoryAverageUtilization:      memoryAverageUtilization,
+		ScaleDownStabilizationSeconds: scaleDownStabilizationSeconds,
+		CreatedAt:                     formatCreatedAt(hpa.Annotations),
 	}
 }
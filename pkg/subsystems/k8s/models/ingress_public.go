@@ -3,6 +3,7 @@ package models
 import (
 	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/keys"
 	v1 "k8s.io/api/networking/v1"
+	"strconv"
 	"time"
 )
 
@@ -22,6 +23,11 @@ type IngressPublic struct {
 	CreatedAt    time.Time   `bson:"createdAt"`
 	CustomCert   *CustomCert `bson:"customCert,omitempty"`
 	TlsSecret    *string     `bson:"tlsSecret,omitempty"`
+	// CanaryWeight makes the ingress a canary of another ingress with the same hosts.
+	// It is the percentage of the traffic to the hosts that is sent to this ingress.
+	CanaryWeight *int `bson:"canaryWeight,omitempty"`
+	// RewriteTarget replaces the path of the requests before they are sent to the service.
+	RewriteTarget *string `bson:"rewriteTarget,omitempty"`
 }
 
 func (i *IngressPublic) Created() bool {
@@ -62,6 +68,18 @@ func CreateIngressPublicFromRead(ingress *v1.Ingress) *IngressPublic {
 		}
 	}
 
+	var canaryWeight *int
+	if ingress.Annotations[keys.AnnotationCanary] == "true" {
+		if weight, err := strconv.Atoi(ingress.Annotations[keys.AnnotationCanaryWeight]); err == nil {
+			canaryWeight = &weight
+		}
+	}
+
+	var rewriteTarget *string
+	if target, ok := ingress.Annotations[keys.AnnotationRewriteTarget]; ok {
+		rewriteTarget = &target
+	}
+
 	hosts := make([]string, 0)
 	for _, rule := range ingress.Spec.Rules {
 		hosts = append(hosts, rule.Host)
@@ -73,15 +91,17 @@ func CreateIngressPublicFromRead(ingress *v1.Ingress) *IngressPublic {
 	}
 
 	return &IngressPublic{
-		Name:         ingress.Name,
-		Namespace:    ingress.Namespace,
-		ServiceName:  serviceName,
-		ServicePort:  servicePort,
-		IngressClass: ingressClassName,
-		Hosts:        hosts,
-		Placeholder:  false,
-		CreatedAt:    formatCreatedAt(ingress.Annotations),
-		CustomCert:   customCert,
-		TlsSecret:    tlsSecret,
+		Name:          ingress.Name,
+		Namespace:     ingress.Namespace,
+		ServiceName:   serviceName,
+		ServicePort:   servicePort,
+		IngressClass:  ingressClassName,
+		Hosts:         hosts,
+		Placeholder:   false,
+		CreatedAt:     formatCreatedAt(ingress.Annotations),
+		CustomCert:    customCert,
+		TlsSecret:     tlsSecret,
+		CanaryWeight:  canaryWeight,
+		RewriteTarget: rewriteTarget,
 	}
 }
@@ -2,18 +2,23 @@ package models
 
 import (
 	v1 "k8s.io/api/batch/v1"
+	v1core "k8s.io/api/core/v1"
 	"time"
 )
 
 type JobPublic struct {
-	Name      string    `bson:"name"`
-	Namespace string    `bson:"namespace"`
-	Image     string    `bson:"image"`
-	Command   []string  `bson:"command"`
-	Args      []string  `bson:"args"`
-	Volumes   []Volume  `bson:"volumes"`
+	Name      string   `bson:"name"`
+	Namespace string   `bson:"namespace"`
+	Image     string   `bson:"image"`
+	Command   []string `bson:"command"`
+	Args      []string `bson:"args"`
+	Volumes   []Volume `bson:"volumes"`
+	// Resources are the limits and requests of the job's container. They are not set if they are empty.
+	Resources Resources `bson:"resources"`
 	MaxTries  *int      `bson:"maxTries,omitempty"`
-	CreatedAt time.Time `bson:"createdAt"`
+	// TtlAfterFinished is how long a finished job is kept before it is removed. It defaults to 5 seconds.
+	TtlAfterFinished *time.Duration `bson:"ttlAfterFinished,omitempty"`
+	CreatedAt        time.Time      `bson:"createdAt"`
 }
 
 func (job *JobPublic) Created() bool {
@@ -41,10 +46,29 @@ func CreateJobPublicFromRead(job *v1.Job) *JobPublic {
 		})
 	}
 
+	var resources Resources
 	if len(job.Spec.Template.Spec.Containers) > 0 {
 		firstContainer := job.Spec.Template.Spec.Containers[0]
 		volumeMounts := firstContainer.VolumeMounts
 
+		if limits := firstContainer.Resources.Limits; limits != nil {
+			if cpu, ok := limits[v1core.ResourceCPU]; ok {
+				resources.Limits.CPU = cpu.String()
+			}
+			if memory, ok := limits[v1core.ResourceMemory]; ok {
+				resources.Limits.Memory = memory.String()
+			}
+		}
+
+		if requests := firstContainer.Resources.Requests; requests != nil {
+			if cpu, ok := requests[v1core.ResourceCPU]; ok {
+				resources.Requests.CPU = cpu.String()
+			}
+			if memory, ok := requests[v1core.ResourceMemory]; ok {
+				resources.Requests.Memory = memory.String()
+			}
+		}
+
 		for _, volumeMount := range volumeMounts {
 			// if we cannot find the volume mount in the volumes list, then it is not a volume we care about
 			for _, volume := range volumes {
@@ -69,6 +93,7 @@ func CreateJobPublicFromRead(job *v1.Job) *JobPublic {
 		Command:   job.Spec.Template.Spec.Containers[0].Command,
 		Args:      job.Spec.Template.Spec.Containers[0].Args,
 		Volumes:   volumes,
+		Resources: resources,
 		CreatedAt: formatCreatedAt(job.Annotations),
 	}
 }
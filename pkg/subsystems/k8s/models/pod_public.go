@@ -13,3 +13,9 @@ func CreatePodPublicFromRead(pod v1.Pod) *PodPublic {
 		Namespace: pod.Namespace,
 	}
 }
+
+// TerminalSize is the size of a terminal attached to a pod exec session.
+type TerminalSize struct {
+	Width  uint16
+	Height uint16
+}
@@ -1,11 +1,16 @@
 package models
 
 import (
+	"fmt"
 	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/keys"
 	kubevirtv1 "kubevirt.io/api/core/v1"
+	"strings"
 	"time"
 )
 
+// DefaultVmDiskStorageClass is the storage class of the root disk, and of data disks that do not specify one.
+const DefaultVmDiskStorageClass = "deploy-vm-disks"
+
 type VmPublic struct {
 	ID        string            `bson:"id"`
 	Name      string            `bson:"name"`
@@ -16,8 +21,13 @@ type VmPublic struct {
 	RAM      int      `bson:"memory"`
 	DiskSize int      `bson:"diskSize"`
 	GPUs     []string `bson:"gpus"`
+	// Disks are the data disks attached to the VM, in addition to the root disk.
+	Disks []VmDiskPublic `bson:"disks"`
 
 	CloudInit string `bson:"cloudInit"`
+	// InstanceID is the cloud-init instance ID of the VM. Cloud-init reruns its per-instance modules when it changes.
+	// If it is empty, the instance ID generated by KubeVirt is used.
+	InstanceID string `bson:"instanceId,omitempty"`
 	// Image is the URL of the image to use for the VM
 	// It may either be an HTTP URL or a Docker image.
 	//
@@ -30,6 +40,36 @@ type VmPublic struct {
 	CreatedAt time.Time `bson:"createdAt"`
 }
 
+type VmDiskPublic struct {
+	Name         string `bson:"name"`
+	Size         int    `bson:"size"`
+	StorageClass string `bson:"storageClass"`
+}
+
+// instanceIDPrefix is the prefix of the SMBIOS serial that sets the NoCloud instance ID.
+// Cloud-init prefers it over the meta-data generated by KubeVirt, which cannot be set in the VM spec.
+const instanceIDPrefix = "ds=nocloud;i="
+
+// InstanceIDSerial returns the SMBIOS serial that sets the NoCloud instance ID of a VM.
+func InstanceIDSerial(instanceID string) string {
+	return instanceIDPrefix + instanceID
+}
+
+// RootDiskName returns the name of the DataVolume, and PVC, backing the root disk of a VM.
+func RootDiskName(vmID string) string {
+	return fmt.Sprintf("%s-rootdisk-dv", vmID)
+}
+
+// DataDiskName returns the name of the DataVolume, and PVC, backing a data disk of a VM.
+func DataDiskName(vmID, diskName string) string {
+	return fmt.Sprintf("%s-data-%s-dv", vmID, diskName)
+}
+
+// DataDiskVolumeName returns the name of the volume and disk of a data disk in the VM template.
+func DataDiskVolumeName(diskName string) string {
+	return fmt.Sprintf("data-%s", diskName)
+}
+
 func (vm *VmPublic) Created() bool {
 	return !vm.CreatedAt.IsZero()
 }
@@ -44,6 +84,7 @@ func CreateVmPublicFromRead(vm *kubevirtv1.VirtualMachine) *VmPublic {
 	var cpuCores int
 	var diskSize int
 	var cloudInit string
+	var instanceID string
 	var image string
 	var name string
 
@@ -68,6 +109,10 @@ func CreateVmPublicFromRead(vm *kubevirtv1.VirtualMachine) *VmPublic {
 				cloudInit = volume.CloudInitNoCloud.UserData
 			}
 		}
+
+		if firmware := vm.Spec.Template.Spec.Domain.Firmware; firmware != nil {
+			instanceID = strings.TrimPrefix(firmware.Serial, instanceIDPrefix)
+		}
 	}
 
 	if len(vm.Spec.DataVolumeTemplates) > 0 && vm.Spec.DataVolumeTemplates[0].Spec.PVC != nil {
@@ -85,23 +130,47 @@ func CreateVmPublicFromRead(vm *kubevirtv1.VirtualMachine) *VmPublic {
 		}
 	}
 
+	disks := make([]VmDiskPublic, 0)
+	for _, dvt := range vm.Spec.DataVolumeTemplates {
+		prefix := fmt.Sprintf("%s-data-", vm.Name)
+		if !strings.HasPrefix(dvt.Name, prefix) || dvt.Spec.Storage == nil {
+			continue
+		}
+
+		disk := VmDiskPublic{
+			Name: strings.TrimSuffix(strings.TrimPrefix(dvt.Name, prefix), "-dv"),
+		}
+
+		if v := dvt.Spec.Storage.Resources.Requests; v != nil {
+			disk.Size = int(v.Storage().Value() / 1024 / 1024 / 1024)
+		}
+
+		if dvt.Spec.Storage.StorageClassName != nil {
+			disk.StorageClass = *dvt.Spec.Storage.StorageClassName
+		}
+
+		disks = append(disks, disk)
+	}
+
 	gpus := make([]string, 0)
 	for _, gpu := range vm.Spec.Template.Spec.Domain.Devices.GPUs {
 		gpus = append(gpus, gpu.DeviceName)
 	}
 
 	return &VmPublic{
-		ID:        vm.Name,
-		Name:      name,
-		Namespace: vm.Namespace,
-		Labels:    clearSystemLabels(vm.Labels),
-		CpuCores:  cpuCores,
-		RAM:       ram,
-		DiskSize:  diskSize,
-		GPUs:      gpus,
-		CloudInit: cloudInit,
-		Image:     image,
-		Running:   running,
-		CreatedAt: formatCreatedAt(vm.Annotations),
+		ID:         vm.Name,
+		Name:       name,
+		Namespace:  vm.Namespace,
+		Labels:     clearSystemLabels(vm.Labels),
+		CpuCores:   cpuCores,
+		RAM:        ram,
+		DiskSize:   diskSize,
+		GPUs:       gpus,
+		Disks:      disks,
+		CloudInit:  cloudInit,
+		InstanceID: instanceID,
+		Image:      image,
+		Running:    running,
+		CreatedAt:  formatCreatedAt(vm.Annotations),
 	}
 }
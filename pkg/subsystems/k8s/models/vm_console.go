@@ -0,0 +1,26 @@
+package models
+
+import (
+	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/keys"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// RunningVmi is a VMI that is running, and thus has a serial console that can be read.
+type RunningVmi struct {
+	// ID is the name of the VMI in Kubernetes.
+	ID string
+	// Name is the name of the VM in go-deploy.
+	Name string
+}
+
+func CreateRunningVmiFromRead(vmi *kubevirtv1.VirtualMachineInstance) *RunningVmi {
+	var deployName string
+	if n, ok := vmi.Labels[keys.LabelDeployName]; ok {
+		deployName = n
+	}
+
+	return &RunningVmi{
+		ID:   vmi.Name,
+		Name: deployName,
+	}
+}
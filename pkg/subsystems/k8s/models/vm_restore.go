@@ -0,0 +1,58 @@
+package models
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"kubevirt.io/api/snapshot/v1alpha1"
+	"time"
+)
+
+type VmRestorePublic struct {
+	ID         string `json:"id"`
+	Namespace  string `json:"namespace"`
+	VmID       string `json:"vmId"`
+	SnapshotID string `json:"snapshotId"`
+	Complete   bool   `json:"complete"`
+	// Failure is the reason the restore failed, and is empty unless KubeVirt reports it as failed.
+	Failure   string    `json:"failure,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (r *VmRestorePublic) Created() bool {
+	return !r.CreatedAt.IsZero()
+}
+
+func (r *VmRestorePublic) IsPlaceholder() bool {
+	return false
+}
+
+func CreateVmRestorePublicFromRead(vmRestore *v1alpha1.VirtualMachineRestore) *VmRestorePublic {
+	var complete bool
+	if vmRestore.Status != nil && vmRestore.Status.Complete != nil {
+		complete = *vmRestore.Status.Complete
+	}
+
+	var failure string
+	if vmRestore.Status != nil {
+		for _, condition := range vmRestore.Status.Conditions {
+			if condition.Type == v1alpha1.ConditionFailure && condition.Status == v1.ConditionTrue {
+				failure = condition.Message
+				if failure == "" {
+					failure = condition.Reason
+				}
+				if failure == "" {
+					failure = "unknown reason"
+				}
+			}
+		}
+	}
+
+	return &VmRestorePublic{
+		ID:         vmRestore.Name,
+		Namespace:  vmRestore.Namespace,
+		VmID:       vmRestore.Spec.Target.Name,
+		SnapshotID: vmRestore.Spec.VirtualMachineSnapshotName,
+		Complete:   complete,
+		Failure:    failure,
+		CreatedAt:  formatCreatedAt(vmRestore.Annotations),
+	}
+}
@@ -7,12 +7,14 @@ import (
 )
 
 type VmSnapshotPublic struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Namespace string    `json:"namespace"`
-	VmID      string    `json:"vmId"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Namespace   string    `json:"namespace"`
+	VmID        string    `json:"vmId"`
+	Status      string    `json:"status"`
+	ReadyToUse  bool      `json:"readyToUse"`
+	UserCreated bool      `json:"userCreated"`
+	CreatedAt   time.Time `json:"createdAt"`
 }
 
 func (s *VmSnapshotPublic) Created() bool {
@@ -32,18 +34,22 @@ func CreateVmSnapshotPublicFromRead(vmSnapshot *v1alpha1.VirtualMachineSnapshot)
 	}
 
 	var status string
+	var readyToUse bool
 	if vmSnapshot.Status != nil {
 		status = string(vmSnapshot.Status.Phase)
+		readyToUse = vmSnapshot.Status.ReadyToUse != nil && *vmSnapshot.Status.ReadyToUse
 	} else {
 		status = "Unknown"
 	}
 
 	return &VmSnapshotPublic{
-		ID:        vmSnapshot.Name,
-		Name:      name,
-		Namespace: vmSnapshot.Namespace,
-		VmID:      vmSnapshot.Spec.Source.Name,
-		Status:    status,
-		CreatedAt: formatCreatedAt(vmSnapshot.Annotations),
+		ID:          vmSnapshot.Name,
+		Name:        name,
+		Namespace:   vmSnapshot.Namespace,
+		VmID:        vmSnapshot.Spec.Source.Name,
+		Status:      status,
+		ReadyToUse:  readyToUse,
+		UserCreated: vmSnapshot.Annotations[keys.AnnotationUserCreated] == "true",
+		CreatedAt:   formatCreatedAt(vmSnapshot.Annotations),
 	}
 }
@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/keys"
 	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
@@ -45,6 +46,27 @@ func (client *Client) ListPods() ([]models.PodPublic, error) {
 	return res, nil
 }
 
+// ListPodsByDeployName returns a list of running pods that belong to a deployment.
+func (client *Client) ListPodsByDeployName(deployName string) ([]models.PodPublic, error) {
+	pods, err := client.K8sClient.CoreV1().Pods(client.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", keys.LabelDeployName, deployName),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res []models.PodPublic
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != v1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		res = append(res, *models.CreatePodPublicFromRead(pod))
+	}
+
+	return res, nil
+}
+
 // PodExists checks if a pod exists in the cluster.
 func (client *Client) PodExists(podName string) (bool, error) {
 	_, err := client.K8sClient.CoreV1().Pods(client.Namespace).Get(context.TODO(), podName, metav1.GetOptions{})
@@ -59,6 +81,21 @@ func (client *Client) PodExists(podName string) (bool, error) {
 	return true, nil
 }
 
+// DeletePod deletes a pod in the cluster.
+// Pods that are part of a deployment are recreated by Kubernetes, so this can be used to restart a single pod.
+func (client *Client) DeletePod(podName string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to delete k8s pod %s. details: %w", podName, err)
+	}
+
+	err := client.K8sClient.CoreV1().Pods(client.Namespace).Delete(context.TODO(), podName, metav1.DeleteOptions{})
+	if err != nil && !IsNotFoundErr(err) {
+		return makeError(err)
+	}
+
+	return nil
+}
+
 // SetupPodWatcher is a function that sets up a pod watcher with a callback.
 // It triggers the callback when a pod event occurs.
 func (client *Client) SetupPodWatcher(ctx context.Context, callback func(podName, event string)) error {
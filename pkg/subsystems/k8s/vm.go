@@ -87,6 +87,11 @@ func (client *Client) UpdateVM(public *models.VmPublic) (*models.VmPublic, error
 			return nil, makeError(err)
 		}
 
+		err = client.syncVmDisks(public)
+		if err != nil {
+			return nil, makeError(err)
+		}
+
 		return models.CreateVmPublicFromRead(res), nil
 	}
 
@@ -148,6 +153,27 @@ func (client *Client) DeleteVMIs(vmID string) error {
 	return nil
 }
 
+// WaitVmiDeleted waits until the VMI for a VM is gone, i.e. the VM is fully stopped.
+//
+// It assumes that the VirtualMachine parent has the same name as the VMI.
+func (client *Client) WaitVmiDeleted(ctx context.Context, vmID string) error {
+	maxWait := 120
+	for i := 0; i < maxWait; i++ {
+		_, err := client.KubeVirtK8sClient.KubevirtV1().VirtualMachineInstances(client.Namespace).Get(ctx, vmID, metav1.GetOptions{})
+		if err != nil && IsNotFoundErr(err) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(1 * time.Second):
+		}
+	}
+
+	return fmt.Errorf("timeout waiting for vmi %s to be deleted", vmID)
+}
+
 // ListVmStatus returns the statuses of all VMs.
 func (client *Client) ListVmStatus() ([]*models.VmStatus, error) {
 	makeError := func(err error) error {
@@ -0,0 +1,192 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/errors"
+	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/transport/websocket"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+const (
+	// consoleProtocol is the WebSocket subprotocol KubeVirt uses for raw serial console data.
+	consoleProtocol = "plain.kubevirt.io"
+	// consoleFlushDelay is how long a partial line is kept before it is sent.
+	// This ensures that prompts without a trailing newline, such as "login: ", are not held back.
+	consoleFlushDelay = 2 * time.Second
+	// consoleMaxLineLength is the maximum length of a line before it is sent, even if it does not end.
+	consoleMaxLineLength = 4096
+)
+
+// ListRunningVmis returns all VMIs that are running.
+func (client *Client) ListRunningVmis() ([]models.RunningVmi, error) {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to list running k8s vmis. details: %w", err)
+	}
+
+	vmis, err := client.KubeVirtK8sClient.KubevirtV1().VirtualMachineInstances(client.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, makeError(err)
+	}
+
+	res := make([]models.RunningVmi, 0)
+	for _, vmi := range vmis.Items {
+		if vmi.Status.Phase != kubevirtv1.Running || vmi.DeletionTimestamp != nil {
+			continue
+		}
+
+		res = append(res, *models.CreateRunningVmiFromRead(&vmi))
+	}
+
+	return res, nil
+}
+
+// StreamVmConsole reads the serial console of a VMI and sends the output line by line to the callback.
+// It blocks until the context is cancelled or the console is disconnected, such as when the VMI stops.
+//
+// KubeVirt only allows one serial console connection per VMI at a time, so the console is disconnected
+// if it has no output for idleTimeout, in which case errors.ErrIdle is returned.
+func (client *Client) StreamVmConsole(ctx context.Context, vmiName string, idleTimeout time.Duration, onLog func(lines []models.LogLine)) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to stream console of vmi %s. details: %w", vmiName, err)
+	}
+
+	if client.RestConfig == nil {
+		return makeError(fmt.Errorf("no rest config available for client"))
+	}
+
+	_, err := client.KubeVirtK8sClient.KubevirtV1().VirtualMachineInstances(client.Namespace).Get(ctx, vmiName, metav1.GetOptions{})
+	if err != nil {
+		if IsNotFoundErr(err) {
+			return makeError(errors.ErrNotFound)
+		}
+
+		return makeError(err)
+	}
+
+	rt, holder, err := websocket.RoundTripperFor(client.RestConfig)
+	if err != nil {
+		return makeError(err)
+	}
+
+	url := fmt.Sprintf("%s/apis/subresources.kubevirt.io/v1/namespaces/%s/virtualmachineinstances/%s/console",
+		strings.TrimSuffix(client.RestConfig.Host, "/"), client.Namespace, vmiName)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return makeError(err)
+	}
+
+	conn, err := websocket.Negotiate(rt, holder, req, consoleProtocol)
+	if err != nil {
+		return makeError(err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	chunks := make(chan []byte)
+	readErr := make(chan error, 1)
+
+	go func() {
+		defer close(chunks)
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				readErr <- err
+				return
+			}
+
+			select {
+			case chunks <- data:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var partial []byte
+	flush := time.NewTimer(consoleFlushDelay)
+	defer flush.Stop()
+
+	idle := time.NewTimer(idleTimeout)
+	defer idle.Stop()
+
+	send := func(line []byte) {
+		onLog([]models.LogLine{{
+			Line:      strings.TrimRight(string(line), "\r"),
+			CreatedAt: time.Now(),
+		}})
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-flush.C:
+			if len(bytes.TrimSpace(partial)) > 0 {
+				send(partial)
+			}
+			partial = nil
+		case <-idle.C:
+			if len(bytes.TrimSpace(partial)) > 0 {
+				send(partial)
+			}
+
+			return makeError(errors.ErrIdle)
+		case data, ok := <-chunks:
+			if !ok {
+				if len(bytes.TrimSpace(partial)) > 0 {
+					send(partial)
+				}
+
+				if ctx.Err() != nil {
+					return nil
+				}
+
+				return makeError(<-readErr)
+			}
+
+			partial = append(partial, data...)
+
+			lines := make([]models.LogLine, 0)
+			for {
+				idx := bytes.IndexByte(partial, '\n')
+				if idx == -1 && len(partial) < consoleMaxLineLength {
+					break
+				}
+
+				if idx == -1 {
+					idx = consoleMaxLineLength
+				}
+
+				lines = append(lines, models.LogLine{
+					Line:      strings.TrimRight(string(partial[:idx]), "\r"),
+					CreatedAt: time.Now(),
+				})
+
+				if idx < len(partial) && partial[idx] == '\n' {
+					idx++
+				}
+				partial = partial[idx:]
+			}
+
+			if len(lines) > 0 {
+				onLog(lines)
+			}
+
+			flush.Reset(consoleFlushDelay)
+			idle.Reset(idleTimeout)
+		}
+	}
+}
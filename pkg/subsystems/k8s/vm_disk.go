@@ -0,0 +1,114 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"github.com/kthcloud/go-deploy/pkg/log"
+	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var dataVolumeResource = schema.GroupVersionResource{Group: "cdi.kubevirt.io", Version: "v1beta1", Resource: "datavolumes"}
+
+// ExpandPVC grows a PersistentVolumeClaim to at least the given size in GB. It never shrinks it.
+//
+// KubeVirt expands the disk image on the PVC, while the VM is running if the ExpandDisks feature gate is enabled,
+// and otherwise the next time the VM starts.
+func (client *Client) ExpandPVC(name string, size int) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to expand k8s pvc %s. details: %w", name, err)
+	}
+
+	pvc, err := client.K8sClient.CoreV1().PersistentVolumeClaims(client.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if IsNotFoundErr(err) {
+			return nil
+		}
+
+		return makeError(err)
+	}
+
+	desired := resource.MustParse(fmt.Sprintf("%dGi", size))
+	if current, ok := pvc.Spec.Resources.Requests[apiv1.ResourceStorage]; ok && current.Cmp(desired) >= 0 {
+		return nil
+	}
+
+	if pvc.Spec.Resources.Requests == nil {
+		pvc.Spec.Resources.Requests = apiv1.ResourceList{}
+	}
+	pvc.Spec.Resources.Requests[apiv1.ResourceStorage] = desired
+
+	_, err = client.K8sClient.CoreV1().PersistentVolumeClaims(client.Namespace).Update(context.TODO(), pvc, metav1.UpdateOptions{})
+	if err != nil {
+		return makeError(err)
+	}
+
+	return nil
+}
+
+// StorageClassAllowsExpansion returns whether PVCs of a storage class can be expanded.
+// A storage class that does not exist does not allow expansion.
+func (client *Client) StorageClassAllowsExpansion(name string) (bool, error) {
+	sc, err := client.K8sClient.StorageV1().StorageClasses().Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if IsNotFoundErr(err) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to get k8s storage class %s. details: %w", name, err)
+	}
+
+	return sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion, nil
+}
+
+// DeleteDataVolume deletes a CDI DataVolume, and with it the PVC it owns.
+func (client *Client) DeleteDataVolume(name string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to delete k8s data volume %s. details: %w", name, err)
+	}
+
+	if name == "" {
+		log.Println("No name supplied when deleting k8s data volume. Assuming it was deleted")
+		return nil
+	}
+
+	if client.RestConfig == nil {
+		return makeError(fmt.Errorf("no rest config supplied"))
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(client.RestConfig)
+	if err != nil {
+		return makeError(err)
+	}
+
+	err = dynamicClient.Resource(dataVolumeResource).Namespace(client.Namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil && !IsNotFoundErr(err) {
+		return makeError(err)
+	}
+
+	return nil
+}
+
+// syncVmDisks makes the volumes of a VM match its spec after it was updated.
+//
+// KubeVirt does not resize existing DataVolumes when the templates change, so the PVCs of grown disks are expanded.
+// The DataVolumes of detached disks are left as they are, since they are only deleted explicitly.
+func (client *Client) syncVmDisks(public *models.VmPublic) error {
+	err := client.ExpandPVC(models.RootDiskName(public.ID), public.DiskSize)
+	if err != nil {
+		return err
+	}
+
+	for _, disk := range public.Disks {
+		err = client.ExpandPVC(models.DataDiskName(public.ID, disk.Name), disk.Size)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
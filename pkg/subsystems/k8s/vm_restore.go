@@ -0,0 +1,92 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+func (client *Client) ReadVmRestore(id string) (*models.VmRestorePublic, error) {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to read k8s vm restore. details: %w", err)
+	}
+
+	vmRestore, err := client.KubeVirtK8sClient.SnapshotV1alpha1().VirtualMachineRestores(client.Namespace).Get(context.TODO(), id, metav1.GetOptions{})
+	if err != nil {
+		if IsNotFoundErr(err) {
+			return nil, nil
+		}
+
+		return nil, makeError(err)
+	}
+
+	return models.CreateVmRestorePublicFromRead(vmRestore), nil
+}
+
+// CreateVmRestore creates a VirtualMachineRestore that restores a VM from a snapshot.
+//
+// KubeVirt only starts the restore once the VM is stopped, so the VM should be stopped before calling this.
+func (client *Client) CreateVmRestore(public *models.VmRestorePublic) (*models.VmRestorePublic, error) {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to create k8s vm restore. details: %w", err)
+	}
+
+	public.ID = fmt.Sprintf("vm-restore-%s", uuid.New().String())
+	public.CreatedAt = time.Now()
+
+	manifest := CreateVmRestoreManifest(public)
+	_, err := client.KubeVirtK8sClient.SnapshotV1alpha1().VirtualMachineRestores(client.Namespace).Create(context.TODO(), manifest, metav1.CreateOptions{})
+	if err != nil {
+		return nil, makeError(err)
+	}
+
+	return client.ReadVmRestore(public.ID)
+}
+
+func (client *Client) DeleteVmRestore(id string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to delete k8s vm restore. details: %w", err)
+	}
+
+	err := client.KubeVirtK8sClient.SnapshotV1alpha1().VirtualMachineRestores(client.Namespace).Delete(context.TODO(), id, metav1.DeleteOptions{})
+	if err != nil && !IsNotFoundErr(err) {
+		return makeError(err)
+	}
+
+	return nil
+}
+
+// WaitVmRestoreCompleted waits until KubeVirt reports the VirtualMachineRestore as complete.
+// It returns an error if the restore fails, is not complete within ten minutes, or the context is cancelled.
+func (client *Client) WaitVmRestoreCompleted(ctx context.Context, id string) error {
+	maxWait := 600
+	for i := 0; i < maxWait; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(1 * time.Second):
+		}
+
+		vmRestore, err := client.ReadVmRestore(id)
+		if err != nil {
+			return err
+		}
+
+		if vmRestore == nil {
+			return fmt.Errorf("vm restore %s not found", id)
+		}
+
+		if vmRestore.Failure != "" {
+			return fmt.Errorf("vm restore %s failed. details: %s", id, vmRestore.Failure)
+		}
+
+		if vmRestore.Complete {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("timeout waiting for vm restore %s to complete", id)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/keys"
 	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubevirt.io/api/snapshot/v1alpha1"
 	"time"
 )
 
@@ -59,8 +60,13 @@ func (client *Client) CreateVmSnapshot(public *models.VmSnapshotPublic) (*models
 		return nil, makeError(err)
 	}
 
-	if len(snapshots.Items) > 0 && err == nil {
-		return models.CreateVmSnapshotPublicFromRead(&snapshots.Items[0]), nil
+	if err == nil {
+		// Snapshot names are only unique per VM
+		for _, snapshot := range snapshots.Items {
+			if snapshot.Spec.Source.Name == public.VmID {
+				return models.CreateVmSnapshotPublicFromRead(&snapshot), nil
+			}
+		}
 	}
 
 	public.ID = fmt.Sprintf("vm-snapshot-%s", uuid.New().String())
@@ -98,6 +104,38 @@ func (client *Client) DeleteVmSnapshot(id string) error {
 	return nil
 }
 
+// WaitVmSnapshotReady waits for a vm snapshot to be ready to use.
+// It returns an error if the snapshot fails, is not ready within ten minutes, or the context is cancelled.
+func (client *Client) WaitVmSnapshotReady(ctx context.Context, id string) error {
+	maxWait := 600
+	for i := 0; i < maxWait; i++ {
+		vmSnapshot, err := client.ReadVmSnapshot(id)
+		if err != nil {
+			return err
+		}
+
+		if vmSnapshot == nil {
+			return fmt.Errorf("vm snapshot %s not found", id)
+		}
+
+		if vmSnapshot.ReadyToUse {
+			return nil
+		}
+
+		if vmSnapshot.Status == string(v1alpha1.Failed) {
+			return fmt.Errorf("vm snapshot %s failed", id)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(1 * time.Second):
+		}
+	}
+
+	return fmt.Errorf("timeout waiting for vm snapshot %s to be ready", id)
+}
+
 func (client *Client) waitVmSnapshotDeleted(id string) error {
 	maxWait := 120
 	for i := 0; i < maxWait; i++ {
@@ -0,0 +1,175 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kthcloud/go-deploy/utils/requestutils"
+	"golang.org/x/net/publicsuffix"
+)
+
+type tokenResponse struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+// do sends a request to the registry.
+// If the registry requires a bearer token, an anonymous token is fetched and the request is sent again.
+func (c *Client) do(method, requestURL string, headers map[string]string) (*http.Response, error) {
+	res, err := c.send(method, requestURL, headers, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusUnauthorized {
+		return res, nil
+	}
+
+	challenge := res.Header.Get("WWW-Authenticate")
+	requestutils.CloseBody(res.Body)
+
+	token, err := c.anonymousToken(requestURL, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err = c.send(method, requestURL, headers, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+		requestutils.CloseBody(res.Body)
+		return nil, ErrUnauthorized
+	}
+
+	return res, nil
+}
+
+// send sends a single request to the registry, with a bearer token if one is given.
+func (c *Client) send(method, requestURL string, headers map[string]string, token string) (*http.Response, error) {
+	req, err := http.NewRequest(method, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+// anonymousToken fetches an anonymous token from the realm given in a WWW-Authenticate challenge.
+// The realm must be served over HTTPS on the same domain as the registry, e.g. auth.docker.io for registry-1.docker.io.
+func (c *Client) anonymousToken(requestURL, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", ErrUnauthorized
+	}
+
+	parsed := parseChallenge(params)
+	if parsed["realm"] == "" {
+		return "", ErrUnauthorized
+	}
+
+	tokenURL, err := url.Parse(parsed["realm"])
+	if err != nil {
+		return "", fmt.Errorf("invalid token realm %s. details: %w", parsed["realm"], err)
+	}
+
+	registryURL, err := url.Parse(requestURL)
+	if err != nil {
+		return "", err
+	}
+
+	if tokenURL.Scheme != "https" || !sameDomain(tokenURL.Hostname(), registryURL.Hostname()) {
+		return "", ErrUntrustedRealm
+	}
+
+	query := tokenURL.Query()
+	for _, key := range []string{"service", "scope"} {
+		if parsed[key] != "" {
+			query.Set(key, parsed[key])
+		}
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	res, err := c.send(http.MethodGet, tokenURL.String(), nil, "")
+	if err != nil {
+		return "", err
+	}
+
+	if !requestutils.IsGoodStatusCode(res.StatusCode) {
+		requestutils.CloseBody(res.Body)
+		return "", ErrUnauthorized
+	}
+
+	tokens, err := requestutils.ParseBody[tokenResponse](res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if tokens.Token != "" {
+		return tokens.Token, nil
+	}
+
+	if tokens.AccessToken != "" {
+		return tokens.AccessToken, nil
+	}
+
+	return "", ErrUnauthorized
+}
+
+// sameDomain returns whether two hosts belong to the same registrable domain, such as docker.io.
+func sameDomain(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+
+	domainA, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(a))
+	if err != nil {
+		return false
+	}
+
+	domainB, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(b))
+	if err != nil {
+		return false
+	}
+
+	return domainA == domainB
+}
+
+// parseChallenge parses the comma separated key="value" parameters of a WWW-Authenticate challenge.
+func parseChallenge(params string) map[string]string {
+	res := make(map[string]string)
+
+	for params != "" {
+		key, rest, found := strings.Cut(strings.TrimLeft(params, " ,"), "=")
+		if !found {
+			break
+		}
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end == -1 {
+				break
+			}
+
+			value, params = rest[1:end+1], rest[end+2:]
+		} else {
+			value, params, _ = strings.Cut(rest, ",")
+		}
+
+		res[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+
+	return res
+}
@@ -0,0 +1,51 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSameDomain(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{a: "auth.docker.io", b: "registry-1.docker.io", expected: true},
+		{a: "ghcr.io", b: "ghcr.io", expected: true},
+		{a: "quay.io", b: "quay.io", expected: true},
+		{a: "evil.example.com", b: "ghcr.io", expected: false},
+		{a: "169.254.169.254", b: "registry-1.docker.io", expected: false},
+		{a: "localhost", b: "ghcr.io", expected: false},
+	}
+
+	for _, tt := range tests {
+		if got := sameDomain(tt.a, tt.b); got != tt.expected {
+			t.Errorf("sameDomain(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestRejectInternalAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		rejected bool
+	}{
+		{address: "140.82.121.33:443", rejected: false},
+		{address: "[2606:4700::6810:84e5]:443", rejected: false},
+		{address: "127.0.0.1:443", rejected: true},
+		{address: "10.0.0.1:443", rejected: true},
+		{address: "192.168.1.1:443", rejected: true},
+		{address: "169.254.169.254:80", rejected: true},
+		{address: "0.0.0.0:443", rejected: true},
+		{address: "[::1]:443", rejected: true},
+		{address: "[fd00::1]:443", rejected: true},
+		{address: "[::ffff:127.0.0.1]:443", rejected: true},
+	}
+
+	for _, tt := range tests {
+		err := rejectInternalAddress("tcp", tt.address, nil)
+		if rejected := errors.Is(err, ErrInternalAddress); rejected != tt.rejected {
+			t.Errorf("rejectInternalAddress(%s) = %v, want rejected %v", tt.address, err, tt.rejected)
+		}
+	}
+}
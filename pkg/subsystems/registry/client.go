@@ -0,0 +1,50 @@
+package registry
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// Client is a minimal client for the OCI distribution API of container registries.
+// It only supports public repositories, and authenticates with anonymous bearer tokens when a registry requires it.
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient creates a new registry client.
+//
+// The registries are given by users, so the client refuses to connect to private, loopback and link-local addresses.
+// The check is made when connecting, so that it also covers redirects and host names that resolve to such addresses.
+func NewClient() *Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: rejectInternalAddress,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the registry, which would bypass the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second, Transport: transport},
+	}
+}
+
+// rejectInternalAddress returns ErrInternalAddress if the address is not a public address.
+func rejectInternalAddress(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	addr := addrPort.Addr().Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return ErrInternalAddress
+	}
+
+	return nil
+}
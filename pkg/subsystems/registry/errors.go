@@ -0,0 +1,11 @@
+package registry
+
+import "errors"
+
+var (
+	ErrNotFound     = errors.New("image not found in registry")
+	ErrUnauthorized = errors.New("registry denied anonymous access")
+
+	ErrInternalAddress = errors.New("registry resolves to an internal address")
+	ErrUntrustedRealm  = errors.New("registry token realm is not on the registry's domain")
+)
@@ -0,0 +1,48 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kthcloud/go-deploy/utils/requestutils"
+)
+
+// manifestMediaTypes are the manifest types that are accepted when resolving a digest.
+// Indexes are listed first, so that multi-platform images resolve to the digest of the index, as done by K8s.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// GetDigest returns the digest of the manifest the image's tag points to.
+func (c *Client) GetDigest(ref *Reference) (string, error) {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to get digest of %s/%s:%s. details: %w", ref.Registry, ref.Repository, ref.Tag, err)
+	}
+
+	res, err := c.do(http.MethodHead, fmt.Sprintf("%s/manifests/%s", ref.apiURL(), ref.Tag), map[string]string{
+		"Accept": strings.Join(manifestMediaTypes, ", "),
+	})
+	if err != nil {
+		return "", makeError(err)
+	}
+	defer requestutils.CloseBody(res.Body)
+
+	if res.StatusCode == http.StatusNotFound {
+		return "", makeError(ErrNotFound)
+	}
+
+	if !requestutils.IsGoodStatusCode(res.StatusCode) {
+		return "", makeError(fmt.Errorf("registry responded with status %d", res.StatusCode))
+	}
+
+	digest := res.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", makeError(fmt.Errorf("registry did not return a digest"))
+	}
+
+	return digest, nil
+}
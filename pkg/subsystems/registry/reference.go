@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reference is a parsed image reference, such as nginx:1.25 or ghcr.io/kthcloud/go-deploy:latest.
+type Reference struct {
+	// Registry is the host of the registry, where Docker Hub is docker.io.
+	Registry string
+	// Repository is the path of the repository in the registry, such as library/nginx.
+	Repository string
+	// Tag is the tag of the image. It defaults to latest.
+	Tag string
+	// Digest is set if the image is pinned to a digest.
+	Digest string
+}
+
+// ParseReference parses an image reference.
+// Images without a registry are assumed to be on Docker Hub, and official Docker Hub images are put under library/.
+func ParseReference(image string) (*Reference, error) {
+	if image == "" {
+		return nil, fmt.Errorf("image reference is empty")
+	}
+
+	ref := &Reference{}
+
+	if name, digest, found := strings.Cut(image, "@"); found {
+		image = name
+		ref.Digest = digest
+	}
+
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		ref.Tag = image[idx+1:]
+		image = image[:idx]
+	}
+
+	if ref.Tag == "" {
+		ref.Tag = "latest"
+	}
+
+	registry, repository, found := strings.Cut(image, "/")
+	if !found || (!strings.ContainsAny(registry, ".:") && registry != "localhost") {
+		registry, repository = "docker.io", image
+	}
+
+	if registry == "index.docker.io" || registry == "registry-1.docker.io" {
+		registry = "docker.io"
+	}
+
+	if registry == "docker.io" && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	if repository == "" {
+		return nil, fmt.Errorf("image reference %s has no repository", image)
+	}
+
+	ref.Registry = strings.ToLower(registry)
+	ref.Repository = strings.ToLower(repository)
+
+	return ref, nil
+}
+
+// WithTag returns a copy of the reference with another tag and no digest.
+func (ref *Reference) WithTag(tag string) *Reference {
+	return &Reference{
+		Registry:   ref.Registry,
+		Repository: ref.Repository,
+		Tag:        tag,
+	}
+}
+
+// apiURL returns the base URL of the registry's distribution API.
+func (ref *Reference) apiURL() string {
+	host := ref.Registry
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+
+	return fmt.Sprintf("https://%s/v2/%s", host, ref.Repository)
+}
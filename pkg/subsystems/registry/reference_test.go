@@ -0,0 +1,56 @@
+package registry
+
+import (
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected Reference
+	}{
+		{image: "nginx", expected: Reference{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"}},
+		{image: "nginx:1.25", expected: Reference{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"}},
+		{image: "grafana/grafana:10.2.0", expected: Reference{Registry: "docker.io", Repository: "grafana/grafana", Tag: "10.2.0"}},
+		{image: "index.docker.io/library/redis", expected: Reference{Registry: "docker.io", Repository: "library/redis", Tag: "latest"}},
+		{image: "ghcr.io/kthcloud/go-deploy:v1", expected: Reference{Registry: "ghcr.io", Repository: "kthcloud/go-deploy", Tag: "v1"}},
+		{image: "localhost:5000/app", expected: Reference{Registry: "localhost:5000", Repository: "app", Tag: "latest"}},
+		{image: "nginx:1.25@sha256:abc", expected: Reference{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25", Digest: "sha256:abc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			got, err := ParseReference(tt.image)
+			if err != nil {
+				t.Fatalf("ParseReference(%s) failed: %v", tt.image, err)
+			}
+
+			if *got != tt.expected {
+				t.Errorf("ParseReference(%s) = %+v, want %+v", tt.image, *got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApiURL(t *testing.T) {
+	ref, _ := ParseReference("nginx")
+	if got := ref.apiURL(); got != "https://registry-1.docker.io/v2/library/nginx" {
+		t.Errorf("apiURL() = %s", got)
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	got := parseChallenge(`realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull,push"`)
+
+	expected := map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/nginx:pull,push",
+	}
+
+	for key, value := range expected {
+		if got[key] != value {
+			t.Errorf("parseChallenge()[%s] = %s, want %s", key, got[key], value)
+		}
+	}
+}
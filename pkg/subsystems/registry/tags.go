@@ -0,0 +1,84 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kthcloud/go-deploy/utils/requestutils"
+)
+
+// maxTagPages is the largest number of pages that are fetched when listing tags.
+const maxTagPages = 20
+
+type tagList struct {
+	Tags []string `json:"tags"`
+}
+
+// ListTags returns the tags of the image's repository.
+// The registry might paginate the list, in which case all pages are fetched, up to a limit.
+func (c *Client) ListTags(ref *Reference) ([]string, error) {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to list tags of %s/%s. details: %w", ref.Registry, ref.Repository, err)
+	}
+
+	var tags []string
+
+	next := fmt.Sprintf("%s/tags/list?n=1000", ref.apiURL())
+	for page := 0; next != "" && page < maxTagPages; page++ {
+		res, err := c.do(http.MethodGet, next, map[string]string{"Accept": "application/json"})
+		if err != nil {
+			return nil, makeError(err)
+		}
+
+		if res.StatusCode == http.StatusNotFound {
+			requestutils.CloseBody(res.Body)
+			return nil, makeError(ErrNotFound)
+		}
+
+		if !requestutils.IsGoodStatusCode(res.StatusCode) {
+			requestutils.CloseBody(res.Body)
+			return nil, makeError(fmt.Errorf("registry responded with status %d", res.StatusCode))
+		}
+
+		list, err := requestutils.ParseBody[tagList](res.Body)
+		if err != nil {
+			return nil, makeError(err)
+		}
+
+		tags = append(tags, list.Tags...)
+
+		next, err = nextPage(res, next)
+		if err != nil {
+			return nil, makeError(err)
+		}
+	}
+
+	return tags, nil
+}
+
+// nextPage returns the URL of the next page from the Link header of a response, or an empty string if it is the last page.
+func nextPage(res *http.Response, current string) (string, error) {
+	link := res.Header.Get("Link")
+	if link == "" || !strings.Contains(link, `rel="next"`) {
+		return "", nil
+	}
+
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start == -1 || end < start {
+		return "", nil
+	}
+
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+
+	next, err := base.Parse(link[start+1 : end])
+	if err != nil {
+		return "", err
+	}
+
+	return next.String(), nil
+}
@@ -243,6 +243,26 @@ func CreateDeployment(c *gin.Context) {
 		return
 	}
 
+	replicas := 1
+	if requestBody.Replicas != nil {
+		replicas = *requestBody.Replicas
+	}
+
+	if !validAutoscaling(requestBody.Autoscaling, replicas) {
+		context.UserError("Autoscaling minReplicas must be less than or equal to maxReplicas")
+		return
+	}
+
+	strategy := model.RolloutStrategyRolling
+	if requestBody.Strategy != nil {
+		strategy = requestBody.Strategy.Type
+	}
+
+	if !validRolloutStrategy(strategy, requestBody.Visibility) {
+		context.UserError("Deployments with auth visibility can only use the rolling strategy")
+		return
+	}
+
 	if err := validateGpuRequests(&requestBody.GPUs, *requestBody.Zone, auth, deployV2); err != nil {
 		if errors.Is(err, ErrCouldNotGetGpuClaims) {
 			context.ServerError(err, ErrCouldNotGetGpuClaims)
@@ -268,6 +288,18 @@ func CreateDeployment(c *gin.Context) {
 		return
 	}
 
+	// Encrypt secret envs before they are stored in the job
+	envLists := [][]body.Env{requestBody.Envs}
+	for _, sidecar := range requestBody.Sidecars {
+		envLists = append(envLists, sidecar.Envs)
+	}
+
+	err = deployV2.Deployments().EncryptSecretEnvs(envLists...)
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
 	deploymentID := uuid.New().String()
 	jobID := uuid.New().String()
 	err = deployV2.Jobs().Create(jobID, auth.User.ID, model.JobCreateDeployment, version.V2, map[string]interface{}{
@@ -420,6 +452,12 @@ func UpdateDeployment(c *gin.Context) {
 	}
 
 	if requestBody.Name != nil {
+		// Existing names are kept valid, but a deployment cannot be renamed to one that collides with sidecars
+		if strings.Contains(*requestBody.Name, "-sidecar") {
+			context.UserError("Name must not contain -sidecar")
+			return
+		}
+
 		available, err := deployV2.Deployments().NameAvailable(*requestBody.Name)
 		if err != nil {
 			context.ServerError(err, ErrInternal)
@@ -450,6 +488,31 @@ func UpdateDeployment(c *gin.Context) {
 		return
 	}
 
+	replicas := deployment.GetMainApp().Replicas
+	if requestBody.Replicas != nil {
+		replicas = *requestBody.Replicas
+	}
+
+	if !validAutoscaling(requestBody.Autoscaling, replicas) {
+		context.UserError("Autoscaling minReplicas must be less than or equal to maxReplicas")
+		return
+	}
+
+	strategy := deployment.GetRolloutStrategy()
+	if requestBody.Strategy != nil {
+		strategy = requestBody.Strategy.Type
+	}
+
+	visibility := deployment.GetMainApp().Visibility
+	if requestBody.Visibility != nil {
+		visibility = *requestBody.Visibility
+	}
+
+	if !validRolloutStrategy(strategy, visibility) {
+		context.UserError("Deployments with auth visibility can only use the rolling strategy")
+		return
+	}
+
 	err = deployV2.Deployments().CheckQuota(requestURI.DeploymentID, &opts.QuotaOptions{Update: &requestBody})
 	if err != nil {
 		var quotaExceededErr sErrors.QuotaExceededError
@@ -468,6 +531,23 @@ func UpdateDeployment(c *gin.Context) {
 		return
 	}
 
+	// Encrypt secret envs before they are stored in the job
+	var envLists [][]body.Env
+	if requestBody.Envs != nil {
+		envLists = append(envLists, *requestBody.Envs)
+	}
+	if requestBody.Sidecars != nil {
+		for _, sidecar := range *requestBody.Sidecars {
+			envLists = append(envLists, sidecar.Envs)
+		}
+	}
+
+	err = deployV2.Deployments().EncryptSecretEnvs(envLists...)
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
 	jobID := uuid.New().String()
 	err = deployV2.Jobs().Create(jobID, auth.User.ID, model.JobUpdateDeployment, version.V2, map[string]interface{}{
 		"id":       deployment.ID,
@@ -584,3 +664,31 @@ func getDeploymentExternalPort(zoneName string) *int {
 
 	return nil
 }
+
+// validAutoscaling checks that the autoscaling bounds do not contradict each other.
+// A bound that is not set is compared by its default, which is 1 for MinReplicas and replicas for MaxReplicas.
+// Autoscaling is off when replicas is 0, so the bounds are not checked then.
+func validAutoscaling(autoscaling *body.Autoscaling, replicas int) bool {
+	if autoscaling == nil || replicas == 0 {
+		return true
+	}
+
+	minReplicas := 1
+	if autoscaling.MinReplicas != nil {
+		minReplicas = *autoscaling.MinReplicas
+	}
+
+	maxReplicas := replicas
+	if autoscaling.MaxReplicas != nil {
+		maxReplicas = *autoscaling.MaxReplicas
+	}
+
+	return minReplicas <= maxReplicas
+}
+
+// validRolloutStrategy checks that the rollout strategy can be used with the visibility of the deployment.
+// The traffic of auth deployments passes through the auth proxy, which cannot send it to the new version,
+// so they only support rolling updates.
+func validRolloutStrategy(strategy, visibility string) bool {
+	return strategy == model.RolloutStrategyRolling || visibility != model.VisibilityAuth
+}
@@ -5,20 +5,25 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kthcloud/go-deploy/dto/v2/query"
 	"github.com/kthcloud/go-deploy/dto/v2/uri"
 	"github.com/kthcloud/go-deploy/pkg/sys"
 	"github.com/kthcloud/go-deploy/service"
 	dErrors "github.com/kthcloud/go-deploy/service/errors"
+	"github.com/kthcloud/go-deploy/service/v2/deployments/opts"
 )
 
 // GetCiConfig
 // @Summary Get CI config
-// @Description Get CI config
+// @Description Get CI config. The config can be generated for GitHub Actions, GitLab CI, Forgejo Actions or as a plain shell script.
 // @Tags Deployment
 // @Produce  json
 // @Security ApiKeyAuth
 // @Security KeycloakOAuth
 // @Param deploymentId path string true "Deployment ID"
+// @Param format query string false "CI system (github, gitlab, forgejo, shell), defaults to github"
+// @Param branch query string false "Branch that triggers the build, defaults to main"
+// @Param tag query string false "Image tag strategy (latest, sha), defaults to latest"
 // @Success 200 {object} body.CiConfig
 // @Failure 400 {object} sys.ErrorResponse
 // @Failure 500 {object} sys.ErrorResponse
@@ -32,13 +37,23 @@ func GetCiConfig(c *gin.Context) {
 		return
 	}
 
+	var requestQuery query.CiConfigGet
+	if err := context.GinContext.ShouldBindQuery(&requestQuery); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
 	auth, err := WithAuth(&context)
 	if err != nil {
 		context.ServerError(err, ErrAuthInfoNotAvailable)
 		return
 	}
 
-	config, err := service.V2(auth).Deployments().GetCiConfig(requestURI.DeploymentID)
+	config, err := service.V2(auth).Deployments().GetCiConfig(requestURI.DeploymentID, opts.CiConfigOpts{
+		Format:      requestQuery.Format,
+		Branch:      requestQuery.Branch,
+		TagStrategy: requestQuery.Tag,
+	})
 	if err != nil {
 		if errors.Is(err, dErrors.ErrDeploymentNotFound) {
 			context.NotFound("Deployment not found")
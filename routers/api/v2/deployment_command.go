@@ -1,17 +1,28 @@
 package v2
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/kthcloud/go-deploy/dto/v2/body"
 	"github.com/kthcloud/go-deploy/dto/v2/uri"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/models/version"
 	"github.com/kthcloud/go-deploy/pkg/sys"
 	"github.com/kthcloud/go-deploy/service"
+	"github.com/kthcloud/go-deploy/service/clients"
+	"github.com/kthcloud/go-deploy/service/core"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
 	"github.com/kthcloud/go-deploy/service/v2/deployments/opts"
 )
 
 // DoDeploymentCommand
 // @Summary Do command
-// @Description Do command
+// @Description Do command. Every command is run as a job, and the ID of the job is returned.
+// @Description The rollback command restores a revision by enqueuing an update job.
+// @Description The build command rebuilds a git deployment by enqueuing a build job.
+// @Description The promote and abort commands finish an active blue/green or canary rollout.
 // @Tags Deployment
 // @Accept json
 // @Produce json
@@ -19,8 +30,9 @@ import (
 // @Security KeycloakOAuth
 // @Param deploymentId path string true "Deployment ID"
 // @Param body body body.DeploymentCommand true "Command body"
-// @Success 204 "No Content"
+// @Success 200 {object} body.DeploymentCommandCreated
 // @Failure 400 {object} sys.ErrorResponse
+// @Failure 403 {object} sys.ErrorResponse
 // @Failure 404 {object} sys.ErrorResponse
 // @Failure 423 {object} sys.ErrorResponse
 // @Failure 500 {object} sys.ErrorResponse
@@ -64,7 +76,134 @@ func DoDeploymentCommand(c *gin.Context) {
 		return
 	}
 
-	deployV2.Deployments().DoCommand(requestURI.DeploymentID, requestBody.Command)
+	switch requestBody.Command {
+	case "rollback":
+		rollbackDeployment(&context, deployV2, deployment, *requestBody.Revision, auth)
+		return
+	case "build":
+		buildDeployment(&context, deployV2, deployment, auth)
+		return
+	case "pause":
+		if deployment.Paused {
+			context.UserError("Deployment is already paused")
+			return
+		}
+	case "resume":
+		if !deployment.Paused {
+			context.UserError("Deployment is not paused")
+			return
+		}
+	case "promote", "abort":
+		if deployment.Rollout == nil {
+			context.UserError("Deployment has no active rollout")
+			return
+		}
+	case "scale":
+		err = deployV2.Deployments().CheckQuota(deployment.ID, &opts.QuotaOptions{Update: &body.DeploymentUpdate{}, ScaleOverride: requestBody.Replicas})
+		if err != nil {
+			var quotaExceededErr sErrors.QuotaExceededError
+			if errors.As(err, &quotaExceededErr) {
+				context.Forbidden(quotaExceededErr.Error())
+				return
+			}
+
+			context.ServerError(err, ErrInternal)
+			return
+		}
+	}
+
+	jobID := uuid.New().String()
+	err = deployV2.Jobs().Create(jobID, auth.User.ID, model.JobDoDeploymentCommand, version.V2, map[string]interface{}{
+		"id":       deployment.ID,
+		"params":   requestBody,
+		"authInfo": auth,
+	})
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	context.Ok(body.DeploymentCommandCreated{
+		ID:    deployment.ID,
+		JobID: jobID,
+	})
+}
+
+// rollbackDeployment enqueues an update job that restores the deployment to the given revision.
+func rollbackDeployment(context *sys.ClientContext, deployV2 clients.V2, deployment *model.Deployment, revisionVersion int, auth *core.AuthInfo) {
+	revision, err := deployV2.Deployments().GetRevision(deployment.ID, revisionVersion)
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	if revision == nil {
+		context.NotFound("Revision not found")
+		return
+	}
+
+	update := revision.ToUpdateDTO()
+
+	err = deployV2.Deployments().CheckQuota(deployment.ID, &opts.QuotaOptions{Update: &update})
+	if err != nil {
+		var quotaExceededErr sErrors.QuotaExceededError
+		if errors.As(err, &quotaExceededErr) {
+			context.Forbidden(quotaExceededErr.Error())
+			return
+		}
+
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	canUpdate, reason := deployV2.Deployments().CanAddActivity(deployment.ID, model.ActivityUpdating)
+	if !canUpdate {
+		context.Locked(reason)
+		return
+	}
+
+	jobID := uuid.New().String()
+	err = deployV2.Jobs().Create(jobID, auth.User.ID, model.JobUpdateDeployment, version.V2, map[string]interface{}{
+		"id":       deployment.ID,
+		"params":   update,
+		"authInfo": auth,
+	})
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	context.Ok(body.DeploymentCommandCreated{
+		ID:    deployment.ID,
+		JobID: jobID,
+	})
+}
+
+// buildDeployment enqueues a build job for a git deployment.
+func buildDeployment(context *sys.ClientContext, deployV2 clients.V2, deployment *model.Deployment, auth *core.AuthInfo) {
+	if deployment.Type != model.DeploymentTypeGit {
+		context.UserError("Only git deployments can be built")
+		return
+	}
+
+	canBuild, reason := deployV2.Deployments().CanAddActivity(deployment.ID, model.ActivityBuilding)
+	if !canBuild {
+		context.Locked(reason)
+		return
+	}
+
+	jobID := uuid.New().String()
+	err := deployV2.Jobs().Create(jobID, auth.User.ID, model.JobBuildDeployment, version.V2, map[string]interface{}{
+		"id":       deployment.ID,
+		"authInfo": auth,
+	})
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
 
-	context.OkNoContent()
+	context.Ok(body.DeploymentCommandCreated{
+		ID:    deployment.ID,
+		JobID: jobID,
+	})
 }
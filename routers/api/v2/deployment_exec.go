@@ -0,0 +1,238 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/dto/v2/query"
+	"github.com/kthcloud/go-deploy/dto/v2/uri"
+	"github.com/kthcloud/go-deploy/pkg/log"
+	k8sModels "github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
+	"github.com/kthcloud/go-deploy/pkg/sys"
+	"github.com/kthcloud/go-deploy/service"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	"github.com/kthcloud/go-deploy/service/v2/deployments/opts"
+	"github.com/kthcloud/go-deploy/utils"
+	utilExec "k8s.io/client-go/util/exec"
+)
+
+const (
+	// execPingInterval is the interval between pings sent to keep the exec WebSocket alive.
+	execPingInterval = 30 * time.Second
+	// execWriteTimeout is the maximum time a write to the exec WebSocket may take.
+	execWriteTimeout = 10 * time.Second
+)
+
+// execUpgrader upgrades exec requests to WebSockets.
+// Origins are not checked since CORS allows all origins, and requests are authenticated using tokens.
+var execUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// execConn wraps a WebSocket so that it can be written to concurrently.
+type execConn struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+// send writes a message to the WebSocket.
+func (c *execConn) send(msg body.ExecMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(execWriteTimeout))
+	return c.conn.WriteJSON(msg)
+}
+
+// ping writes a ping control message to the WebSocket.
+func (c *execConn) ping() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(execWriteTimeout))
+}
+
+// execStream is an io.Writer that forwards output as messages of a given type.
+type execStream struct {
+	conn    *execConn
+	msgType string
+}
+
+// Write sends p as a message to the WebSocket.
+func (s *execStream) Write(p []byte) (int, error) {
+	err := s.conn.send(body.ExecMessage{Type: s.msgType, Data: string(p)})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// DeploymentExec
+// @Summary Exec in a deployment's pod using WebSocket
+// @Description Open a shell or run a command in one of the deployment's pods.
+// @Description Messages are JSON encoded body.ExecMessage. Clients send "stdin", "resize" and "eof", and the server sends "stdout", "stderr", "exit" and "error".
+// @Tags Deployment
+// @Security ApiKeyAuth
+// @Security KeycloakOAuth
+// @Param deploymentId path string true "Deployment ID"
+// @Param pod query string false "Pod name, defaults to the first running pod"
+// @Param container query string false "Container name"
+// @Param command query []string false "Command to run, one argument per value. Defaults to an interactive shell"
+// @Param tty query bool false "Allocate a TTY"
+// @Success 101 {string} string
+// @Failure 400 {object} sys.ErrorResponse
+// @Failure 404 {object} sys.ErrorResponse
+// @Failure 500 {object} sys.ErrorResponse
+// @Router /v2/deployments/{deploymentId}/exec [get]
+func DeploymentExec(c *gin.Context) {
+	sysContext := sys.NewContext(c)
+
+	var requestURI uri.DeploymentExec
+	if err := sysContext.GinContext.ShouldBindUri(&requestURI); err != nil {
+		sysContext.BindingError(CreateBindingError(err))
+		return
+	}
+
+	var requestQuery query.DeploymentExec
+	if err := sysContext.GinContext.ShouldBindQuery(&requestQuery); err != nil {
+		sysContext.BindingError(CreateBindingError(err))
+		return
+	}
+
+	auth, err := WithAuth(&sysContext)
+	if err != nil {
+		sysContext.ServerError(err, ErrAuthInfoNotAvailable)
+		return
+	}
+
+	deployV2 := service.V2(auth)
+
+	// Check access before upgrading, so that the client gets a proper HTTP error
+	deployment, err := deployV2.Deployments().Get(requestURI.DeploymentID, opts.GetOpts{Shared: true})
+	if err != nil {
+		sysContext.ServerError(err, ErrInternal)
+		return
+	}
+
+	if deployment == nil {
+		sysContext.NotFound("Deployment not found")
+		return
+	}
+
+	// The upgrader writes an HTTP error response itself if the upgrade fails
+	ws, err := execUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer func() { _ = ws.Close() }()
+
+	conn := &execConn{conn: ws}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stdinReader, stdinWriter := io.Pipe()
+	resize := make(chan k8sModels.TerminalSize, 1)
+
+	go func() {
+		defer cancel()
+		defer func() { _ = stdinWriter.Close() }()
+
+		for {
+			_, data, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var msg body.ExecMessage
+			if err = json.Unmarshal(data, &msg); err != nil {
+				_ = conn.send(body.ExecMessage{Type: "error", Data: "Invalid message"})
+				continue
+			}
+
+			switch msg.Type {
+			case "stdin":
+				if _, err = stdinWriter.Write([]byte(msg.Data)); err != nil {
+					return
+				}
+			case "eof":
+				_ = stdinWriter.Close()
+			case "resize":
+				// Only the latest size is relevant, so replace any size that has not been consumed yet
+				select {
+				case <-resize:
+				default:
+				}
+				resize <- k8sModels.TerminalSize{Width: msg.Cols, Height: msg.Rows}
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(execPingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := conn.ping(); err != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	err = deployV2.Deployments().Exec(ctx, requestURI.DeploymentID, &opts.ExecOpts{
+		Pod:       requestQuery.Pod,
+		Container: requestQuery.Container,
+		Command:   requestQuery.Command,
+		TTY:       requestQuery.TTY,
+		Stdin:     stdinReader,
+		Stdout:    &execStream{conn: conn, msgType: "stdout"},
+		Stderr:    &execStream{conn: conn, msgType: "stderr"},
+		Resize:    resize,
+		ClientIP:  sysContext.GinContext.ClientIP(),
+	})
+
+	exitCode := 0
+	var exitErr utilExec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		exitCode = exitErr.ExitStatus()
+	case errors.Is(err, sErrors.ErrDeploymentNotFound):
+		_ = conn.send(body.ExecMessage{Type: "error", Data: "Deployment not found"})
+		return
+	case errors.Is(err, sErrors.ErrPodNotFound):
+		_ = conn.send(body.ExecMessage{Type: "error", Data: "Pod not found"})
+		return
+	case ctx.Err() != nil:
+		// The client disconnected
+		return
+	default:
+		utils.PrettyPrintError(err)
+		log.Println("Exec session in deployment", requestURI.DeploymentID, "failed")
+		_ = conn.send(body.ExecMessage{Type: "error", Data: ErrInternal.Error()})
+		return
+	}
+
+	_ = conn.send(body.ExecMessage{Type: "exit", ExitCode: &exitCode})
+
+	conn.mu.Lock()
+	_ = ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(execWriteTimeout))
+	conn.mu.Unlock()
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/kthcloud/go-deploy/service"
 	sErrors "github.com/kthcloud/go-deploy/service/errors"
 	"github.com/kthcloud/go-deploy/service/v2/deployments/opts"
+	"github.com/kthcloud/go-deploy/utils"
 )
 
 // HandleHarborHook
@@ -80,6 +81,21 @@ func HandleHarborHook(c *gin.Context) {
 
 		deployV2.Deployments().AddLogs(deployment.ID, newLog)
 
+		// Record the digest of the pushed image, so that the deployment can be rolled back to this build.
+		// Failing to record it should not stop the deployment from being restarted with the new image.
+		if len(webhook.EventData.Resources) > 0 && webhook.EventData.Resources[0].Digest != "" {
+			err = deployV2.Deployments().RecordImagePush(deployment.ID, webhook.EventData.Resources[0].Digest)
+			if err != nil {
+				utils.PrettyPrintError(fmt.Errorf("failed to record image push for deployment %s. details: %w", deployment.ID, err))
+			}
+		}
+
+		// Git deployments are restarted by their build job once the build succeeds
+		if deployment.Type == model.DeploymentTypeGit {
+			context.OkNoContent()
+			return
+		}
+
 		err = deployV2.Deployments().Restart(deployment.ID)
 		if err != nil {
 			var failedToStartActivityErr *sErrors.FailedToStartActivityError
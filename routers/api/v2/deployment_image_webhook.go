@@ -0,0 +1,205 @@
+package v2
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/dto/v2/query"
+	"github.com/kthcloud/go-deploy/dto/v2/uri"
+	"github.com/kthcloud/go-deploy/pkg/sys"
+	"github.com/kthcloud/go-deploy/service"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	"github.com/kthcloud/go-deploy/service/v2/deployments/opts"
+)
+
+// maxImageHookPayloadSize is the largest image webhook payload that is read.
+const maxImageHookPayloadSize = 1 << 20
+
+// CreateDeploymentImageWebhook
+// @Summary Create image webhook
+// @Description Create an image webhook for a prebuilt deployment, replacing the current one if it exists.
+// @Description The webhook URL and secret are only returned once.
+// @Tags Deployment
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security KeycloakOAuth
+// @Param deploymentId path string true "Deployment ID"
+// @Param body body body.ImageWebhookCreate true "Image webhook body"
+// @Success 200 {object} body.ImageWebhookCreated
+// @Failure 400 {object} sys.ErrorResponse
+// @Failure 403 {object} sys.ErrorResponse
+// @Failure 404 {object} sys.ErrorResponse
+// @Failure 500 {object} sys.ErrorResponse
+// @Router /v2/deployments/{deploymentId}/imageWebhook [post]
+func CreateDeploymentImageWebhook(c *gin.Context) {
+	context := sys.NewContext(c)
+
+	var requestURI uri.DeploymentImageWebhook
+	if err := context.GinContext.ShouldBindUri(&requestURI); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	var requestBody body.ImageWebhookCreate
+	if err := context.GinContext.ShouldBindJSON(&requestBody); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	auth, err := WithAuth(&context)
+	if err != nil {
+		context.ServerError(err, ErrAuthInfoNotAvailable)
+		return
+	}
+
+	deployV2 := service.V2(auth)
+
+	deployment, err := deployV2.Deployments().Get(requestURI.DeploymentID, opts.GetOpts{Shared: true})
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	if deployment == nil {
+		context.NotFound("Deployment not found")
+		return
+	}
+
+	imageWebhook, err := deployV2.Deployments().CreateImageWebhook(deployment.ID, &requestBody)
+	if err != nil {
+		switch {
+		case errors.Is(err, sErrors.ErrDeploymentNotFound):
+			context.NotFound("Deployment not found")
+		case errors.Is(err, sErrors.ErrDeploymentHasOwnImage):
+			context.UserError("Image webhooks are only available for prebuilt deployments")
+		default:
+			context.ServerError(err, ErrInternal)
+		}
+		return
+	}
+
+	context.Ok(imageWebhook)
+}
+
+// DeleteDeploymentImageWebhook
+// @Summary Delete image webhook
+// @Description Delete the image webhook of a deployment
+// @Tags Deployment
+// @Produce json
+// @Security ApiKeyAuth
+// @Security KeycloakOAuth
+// @Param deploymentId path string true "Deployment ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} sys.ErrorResponse
+// @Failure 404 {object} sys.ErrorResponse
+// @Failure 500 {object} sys.ErrorResponse
+// @Router /v2/deployments/{deploymentId}/imageWebhook [delete]
+func DeleteDeploymentImageWebhook(c *gin.Context) {
+	context := sys.NewContext(c)
+
+	var requestURI uri.DeploymentImageWebhook
+	if err := context.GinContext.ShouldBindUri(&requestURI); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	auth, err := WithAuth(&context)
+	if err != nil {
+		context.ServerError(err, ErrAuthInfoNotAvailable)
+		return
+	}
+
+	deployV2 := service.V2(auth)
+
+	deployment, err := deployV2.Deployments().Get(requestURI.DeploymentID, opts.GetOpts{Shared: true})
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	if deployment == nil {
+		context.NotFound("Deployment not found")
+		return
+	}
+
+	err = deployV2.Deployments().DeleteImageWebhook(deployment.ID)
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	context.OkNoContent()
+}
+
+// HandleImageHook
+// @Summary Handle image hook
+// @Description Handle a push event for the image of a prebuilt deployment.
+// @Description It accepts Docker Hub, GHCR (GitHub package events) and generic payloads.
+// @Description If the payload is signed, the HMAC-SHA256 signature is read from the X-Hub-Signature-256 or X-Signature-256 header.
+// @Tags Deployment
+// @Accept json
+// @Param deploymentId path string true "Deployment ID"
+// @Param token query string true "Webhook token"
+// @Param X-Hub-Signature-256 header string false "HMAC-SHA256 signature of the payload"
+// @Param body body body.ImageWebhook true "Image webhook body"
+// @Produce json
+// @Success 204 "No Content"
+// @Failure 400 {object} sys.ErrorResponse
+// @Failure 401 {object} sys.ErrorResponse
+// @Failure 404 {object} sys.ErrorResponse
+// @Failure 423 {object} sys.ErrorResponse
+// @Failure 500 {object} sys.ErrorResponse
+// @Router /v2/hooks/images/{deploymentId} [post]
+func HandleImageHook(c *gin.Context) {
+	context := sys.NewContext(c)
+
+	var requestURI uri.DeploymentImageWebhook
+	if err := context.GinContext.ShouldBindUri(&requestURI); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	var requestQuery query.ImageHook
+	if err := context.GinContext.ShouldBindQuery(&requestQuery); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(context.GinContext.Request.Body, maxImageHookPayloadSize))
+	if err != nil {
+		context.UserError("Failed to read payload")
+		return
+	}
+
+	signature := context.GinContext.GetHeader("X-Hub-Signature-256")
+	if signature == "" {
+		signature = context.GinContext.GetHeader("X-Signature-256")
+	}
+
+	err = service.V2().Deployments().HandleImageWebhook(requestURI.DeploymentID, requestQuery.Token, signature, payload)
+	if err != nil {
+		var failedToStartActivityErr *sErrors.FailedToStartActivityError
+		switch {
+		case errors.As(err, &failedToStartActivityErr):
+			context.Locked(failedToStartActivityErr.Error())
+		case errors.Is(err, sErrors.ErrDeploymentNotFound), errors.Is(err, sErrors.ErrImageWebhookNotFound):
+			context.NotFound("Image webhook not found")
+		case errors.Is(err, sErrors.ErrInvalidWebhookToken):
+			context.Unauthorized("Invalid token")
+		case errors.Is(err, sErrors.ErrInvalidWebhookSignature):
+			context.Unauthorized("Invalid signature")
+		case errors.Is(err, sErrors.ErrInvalidWebhookPayload):
+			context.UserError("No pushed image found in payload")
+		case errors.Is(err, sErrors.ErrImageMismatch):
+			context.UserError("Pushed image does not match the deployment's image")
+		default:
+			context.ServerError(err, ErrInternal)
+		}
+		return
+	}
+
+	context.OkNoContent()
+}
@@ -2,17 +2,25 @@ package v2
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/dto/v2/query"
 	"github.com/kthcloud/go-deploy/dto/v2/uri"
+	"github.com/kthcloud/go-deploy/models/model"
 	"github.com/kthcloud/go-deploy/pkg/sys"
 	"github.com/kthcloud/go-deploy/service"
 	errors2 "github.com/kthcloud/go-deploy/service/errors"
 	"github.com/kthcloud/go-deploy/service/v2/deployments"
+	"github.com/kthcloud/go-deploy/service/v2/deployments/opts"
+	v12 "github.com/kthcloud/go-deploy/service/v2/utils"
+	"github.com/kthcloud/go-deploy/utils"
 )
 
 // GetLogs
@@ -27,7 +35,7 @@ import (
 // @Failure 400 {object} sys.ErrorResponse
 // @Failure 404 {object} sys.ErrorResponse
 // @Failure 500 {object} sys.ErrorResponse
-// @Router /v2/deployments/{deploymentId}/logs [get]
+// @Router /v2/deployments/{deploymentId}/logs-sse [get]
 func GetLogs(c *gin.Context) {
 	sysContext := sys.NewContext(c)
 
@@ -92,4 +100,119 @@ func GetLogs(c *gin.Context) {
 	})
 }
 
+// ListDeploymentLogs
+// @Summary List deployment logs
+// @Description List and search deployment logs, with the oldest log first.
+// @Description The ndjson and text formats are downloaded as files, and include all matching logs regardless of pagination.
+// @Tags Deployment
+// @Produce json
+// @Produce plain
+// @Security ApiKeyAuth
+// @Security KeycloakOAuth
+// @Param deploymentId path string true "Deployment ID"
+// @Param source query []string false "Only include logs from the given sources (pod, deployment, build)"
+// @Param pod query string false "Only include logs from the given pod"
+// @Param since query string false "Only include logs created at or after the given time (RFC3339)"
+// @Param until query string false "Only include logs created before the given time (RFC3339)"
+// @Param search query string false "Only include logs containing the given text, ignoring case"
+// @Param format query string false "Response format (json, ndjson, text), defaults to json"
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Number of items per page"
+// @Success 200 {array} body.DeploymentLogRead
+// @Failure 400 {object} sys.ErrorResponse
+// @Failure 404 {object} sys.ErrorResponse
+// @Failure 500 {object} sys.ErrorResponse
+// @Router /v2/deployments/{deploymentId}/logs [get]
+func ListDeploymentLogs(c *gin.Context) {
+	context := sys.NewContext(c)
+
+	var requestURI uri.DeploymentLogList
+	if err := context.GinContext.ShouldBindUri(&requestURI); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	var requestQuery query.DeploymentLogList
+	if err := context.GinContext.ShouldBindQuery(&requestQuery); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	auth, err := WithAuth(&context)
+	if err != nil {
+		context.ServerError(err, ErrAuthInfoNotAvailable)
+		return
+	}
+
+	deployV2 := service.V2(auth)
+
+	deployment, err := deployV2.Deployments().Get(requestURI.DeploymentID, opts.GetOpts{Shared: true})
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	if deployment == nil {
+		context.NotFound("Deployment not found")
+		return
+	}
+
+	listOpts := opts.ListLogsOpts{
+		Sources: requestQuery.Source,
+		Pod:     requestQuery.Pod,
+		Since:   requestQuery.Since,
+		Until:   requestQuery.Until,
+		Search:  requestQuery.Search,
+	}
+
+	if requestQuery.Format == "ndjson" || requestQuery.Format == "text" {
+		extension := "log"
+		contentType := "text/plain; charset=utf-8"
+		if requestQuery.Format == "ndjson" {
+			extension = "ndjson"
+			contentType = "application/x-ndjson"
+		}
+
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s-logs.%s\"", deployment.Name, extension))
+		c.Status(http.StatusOK)
+
+		encoder := json.NewEncoder(c.Writer)
+		err = deployV2.Deployments().ForEachLog(deployment.ID, c.Request.Context(), func(l *model.DeploymentLog) error {
+			if requestQuery.Format == "ndjson" {
+				return encoder.Encode(l.ToDTO())
+			}
+
+			_, err := fmt.Fprintf(c.Writer, "%s %s %s\n", l.CreatedAt.Format(time.RFC3339), l.Prefix, l.Line)
+			return err
+		}, listOpts)
+		if err != nil {
+			// The status has already been sent, so the download is cut short instead
+			utils.PrettyPrintError(fmt.Errorf("failed to download logs for deployment %s. details: %w", deployment.ID, err))
+		}
+
+		return
+	}
+
+	listOpts.Pagination = v12.GetOrDefaultPagination(requestQuery.Pagination)
+
+	logs, err := deployV2.Deployments().ListLogs(deployment.ID, listOpts)
+	if err != nil {
+		if errors.Is(err, errors2.ErrDeploymentNotFound) {
+			context.NotFound("Deployment not found")
+			return
+		}
+
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	dtoLogs := make([]body.DeploymentLogRead, len(logs))
+	for i, l := range logs {
+		dtoLogs[i] = l.ToDTO()
+	}
+
+	context.Ok(dtoLogs)
+}
+
 // mhuaaaaaaaaaaaah, i love you i love you i love you
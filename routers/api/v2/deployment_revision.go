@@ -0,0 +1,85 @@
+package v2
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/dto/v2/query"
+	"github.com/kthcloud/go-deploy/dto/v2/uri"
+	"github.com/kthcloud/go-deploy/pkg/sys"
+	"github.com/kthcloud/go-deploy/service"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	"github.com/kthcloud/go-deploy/service/v2/deployments/opts"
+	v12 "github.com/kthcloud/go-deploy/service/v2/utils"
+)
+
+// ListDeploymentRevisions
+// @Summary List deployment revisions
+// @Description List deployment revisions, with the latest revision first
+// @Tags Deployment
+// @Produce json
+// @Security ApiKeyAuth
+// @Security KeycloakOAuth
+// @Param deploymentId path string true "Deployment ID"
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Number of items per page"
+// @Success 200 {array} body.DeploymentRevisionRead
+// @Failure 400 {object} sys.ErrorResponse
+// @Failure 404 {object} sys.ErrorResponse
+// @Failure 500 {object} sys.ErrorResponse
+// @Router /v2/deployments/{deploymentId}/revisions [get]
+func ListDeploymentRevisions(c *gin.Context) {
+	context := sys.NewContext(c)
+
+	var requestURI uri.DeploymentRevisionList
+	if err := context.GinContext.ShouldBindUri(&requestURI); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	var requestQuery query.DeploymentRevisionList
+	if err := context.GinContext.ShouldBind(&requestQuery); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	auth, err := WithAuth(&context)
+	if err != nil {
+		context.ServerError(err, ErrAuthInfoNotAvailable)
+		return
+	}
+
+	deployV2 := service.V2(auth)
+
+	deployment, err := deployV2.Deployments().Get(requestURI.DeploymentID, opts.GetOpts{Shared: true})
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	if deployment == nil {
+		context.NotFound("Deployment not found")
+		return
+	}
+
+	revisions, err := deployV2.Deployments().ListRevisions(deployment.ID, opts.ListRevisionsOpts{
+		Pagination: v12.GetOrDefaultPagination(requestQuery.Pagination),
+	})
+	if err != nil {
+		if errors.Is(err, sErrors.ErrDeploymentNotFound) {
+			context.NotFound("Deployment not found")
+			return
+		}
+
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	dtoRevisions := make([]body.DeploymentRevisionRead, len(revisions))
+	for i, revision := range revisions {
+		dtoRevisions[i] = revision.ToDTO()
+	}
+
+	context.Ok(dtoRevisions)
+}
@@ -121,7 +121,7 @@ func ListJobs(c *gin.Context) {
 
 // UpdateJob
 // @Summary Update job
-// @Description Update job. Only allowed for admins.
+// @Description Update job. Only allowed for admins, except for setting the status to cancelled, which the owner of the job can do as well.
 // @Tags Job
 // @Accept json
 // @Produce json
@@ -164,6 +164,11 @@ func UpdateJob(c *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, sErrors.ErrJobFinished) {
+			context.UserError("Job is already finished and cannot be cancelled")
+			return
+		}
+
 		context.ServerError(err, ErrInternal)
 		return
 	}
@@ -189,6 +194,8 @@ func jobStatusMessage(status string) string {
 		return status_codes.GetMsg(status_codes.JobFailed)
 	case model.JobStatusTerminated:
 		return status_codes.GetMsg(status_codes.JobTerminated)
+	case model.JobStatusCancelled:
+		return status_codes.GetMsg(status_codes.JobCancelled)
 
 	default:
 		return status_codes.GetMsg(status_codes.Unknown)
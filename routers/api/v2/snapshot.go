@@ -2,14 +2,19 @@ package v2
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/dto/v2/query"
+	"github.com/kthcloud/go-deploy/dto/v2/uri"
 	"github.com/kthcloud/go-deploy/pkg/sys"
+	"github.com/kthcloud/go-deploy/service"
+	v2Utils "github.com/kthcloud/go-deploy/service/v2/utils"
+	"github.com/kthcloud/go-deploy/service/v2/vms/opts"
 )
 
 // GetSnapshot
 // @Summary Get snapshot
 // @Description Get snapshot
 // @Tags Snapshot
-// @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Security KeycloakOAuth
@@ -19,14 +24,11 @@ import (
 // @Failure 400 {object} sys.ErrorResponse
 // @Failure 404 {object} sys.ErrorResponse
 // @Failure 500 {object} sys.ErrorResponse
-// @Router /v2/vms/{vmId}/snapshot/{snapshotId} [post]
+// @Router /v2/vms/{vmId}/snapshots/{snapshotId} [get]
 func GetSnapshot(c *gin.Context) {
 	context := sys.NewContext(c)
 
-	// Not yet implemented
-	context.NotImplemented()
-
-	/*var requestURI uri.VmSnapshotGet
+	var requestURI uri.VmSnapshotGet
 	if err := context.GinContext.ShouldBindUri(&requestURI); err != nil {
 		context.BindingError(CreateBindingError(err))
 		return
@@ -34,7 +36,7 @@ func GetSnapshot(c *gin.Context) {
 
 	auth, err := WithAuth(&context)
 	if err != nil {
-		context.ServerError(err, AuthInfoNotAvailableErr)
+		context.ServerError(err, ErrAuthInfoNotAvailable)
 		return
 	}
 
@@ -42,7 +44,7 @@ func GetSnapshot(c *gin.Context) {
 
 	vm, err := deployV2.VMs().Get(requestURI.VmID, opts.GetOpts{Shared: true})
 	if err != nil {
-		context.ServerError(err, InternalError)
+		context.ServerError(err, ErrInternal)
 		return
 	}
 
@@ -51,9 +53,9 @@ func GetSnapshot(c *gin.Context) {
 		return
 	}
 
-	snapshot, err := deployV2.VMs().Snapshots().Get(requestURI.VmID, requestURI.SnapshotID, opts.GetSnapshotOpts{})
+	snapshot, err := deployV2.VMs().Snapshots().Get(vm.ID, requestURI.SnapshotID, opts.GetSnapshotOpts{})
 	if err != nil {
-		context.ServerError(err, InternalError)
+		context.ServerError(err, ErrInternal)
 		return
 	}
 
@@ -62,53 +64,63 @@ func GetSnapshot(c *gin.Context) {
 		return
 	}
 
-	context.Ok(snapshot.ToDTOv2())*/
+	context.Ok(snapshot.ToDTOv2())
 }
 
 // ListSnapshots
 // @Summary List snapshots
-// @Description List snapshots
+// @Description List snapshots of a VM, with the oldest snapshot first
 // @Tags Snapshot
 // @Produce json
 // @Security ApiKeyAuth
 // @Security KeycloakOAuth
-// @Param vmId path string true "Filter by VM ID"
+// @Param vmId path string true "VM ID"
 // @Param page query int false "Page number"
 // @Param pageSize query int false "Number of items per page"
 // @Success 200 {array} body.VmSnapshotRead
 // @Failure 400 {object} sys.ErrorResponse
 // @Failure 404 {object} sys.ErrorResponse
-// @Failure 423 {object} sys.ErrorResponse
 // @Failure 500 {object} sys.ErrorResponse
-// @Router /v2/snapshots/{vmId} [get]
+// @Router /v2/vms/{vmId}/snapshots [get]
 func ListSnapshots(c *gin.Context) {
 	context := sys.NewContext(c)
 
-	// Not yet implemented
-	context.NotImplemented()
+	var requestURI uri.VmSnapshotList
+	if err := context.GinContext.ShouldBindUri(&requestURI); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
 
-	/*var requestQuery query.VmSnapshotList
+	var requestQuery query.VmSnapshotList
 	if err := context.GinContext.ShouldBind(&requestQuery); err != nil {
 		context.BindingError(CreateBindingError(err))
 		return
 	}
 
-	var requestURI uri.VmSnapshotList
-	if err := context.GinContext.ShouldBindUri(&requestURI); err != nil {
-		context.BindingError(CreateBindingError(err))
+	auth, err := WithAuth(&context)
+	if err != nil {
+		context.ServerError(err, ErrAuthInfoNotAvailable)
 		return
 	}
 
-	snapshots, err := service.V2().VMs().Snapshots().List(requestURI.VmID, opts.ListSnapshotOpts{
-		Pagination: utils.GetOrDefaultPagination(requestQuery.Pagination),
-	})
+	deployV2 := service.V2(auth)
+
+	vm, err := deployV2.VMs().Get(requestURI.VmID, opts.GetOpts{Shared: true})
 	if err != nil {
-		context.ServerError(err, InternalError)
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	if vm == nil {
+		context.NotFound("VM not found")
 		return
 	}
 
-	if snapshots == nil {
-		context.Ok([]interface{}{})
+	snapshots, err := deployV2.VMs().Snapshots().List(vm.ID, opts.ListSnapshotOpts{
+		Pagination: v2Utils.GetOrDefaultPagination(requestQuery.Pagination),
+	})
+	if err != nil {
+		context.ServerError(err, ErrInternal)
 		return
 	}
 
@@ -117,7 +129,7 @@ func ListSnapshots(c *gin.Context) {
 		dtoSnapshots[i] = snapshot.ToDTOv2()
 	}
 
-	context.Ok(dtoSnapshots)*/
+	context.Ok(dtoSnapshots)
 }
 
 // CreateSnapshot
@@ -133,7 +145,7 @@ func ListSnapshots(c *gin.Context) {
 // @Failure 400 {object} sys.ErrorResponse
 // @Failure 404 {object} sys.ErrorResponse
 // @Failure 500 {object} sys.ErrorResponse
-// @Router /v2/snapshots/{vmId} [post]
+// @Router /v2/vms/{vmId}/snapshots [post]
 func CreateSnapshot(c *gin.Context) {
 	context := sys.NewContext(c)
 
@@ -235,7 +247,7 @@ func CreateSnapshot(c *gin.Context) {
 // @Failure 400 {object} sys.ErrorResponse
 // @Failure 404 {object} sys.ErrorResponse
 // @Failure 500 {object} sys.ErrorResponse
-// @Router /v2/vms/{vmId}/snapshot/{snapshotId} [delete]
+// @Router /v2/vms/{vmId}/snapshots/{snapshotId} [delete]
 func DeleteSnapshot(c *gin.Context) {
 	context := sys.NewContext(c)
 
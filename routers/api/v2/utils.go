@@ -94,11 +94,29 @@ func msgForTag(fe validator.FieldError) string {
 	case "domain_name":
 		return "Must be a valid domain name that can be puny-encoded and is less than 243 characters"
 	case "deployment_name":
-		return "Must not end with -custom-domain or -auth-proxy"
+		return "Must not end with -custom-domain, -auth-proxy or -rollout"
+	case "new_deployment_name":
+		return "Must not end with -custom-domain, -auth-proxy or -rollout, or contain -sidecar"
 	case "vm_name":
 		return "Must not end with"
 	case "vm_port_name":
 		return "Must not end with -custom-domain or -proxy"
+	case "semver_range":
+		return "Must be a valid version range, ex. 1.x, 1.2.x, ^1.2.3 or ~1.2.3"
+	case "cron":
+		return "Must be a valid cron expression with five fields, ex. 0 22 * * *"
+	case "timezone":
+		return "Must be a valid IANA timezone, ex. Europe/Stockholm"
+	case "snapshot_policy_list":
+		return "Every snapshot policy name must be unique"
+	case "cloud_init_package":
+		return "Package names must start with a letter or digit and may only contain letters, digits and .+:=~_-"
+	case "cloud_init_users":
+		return "Every cloud-init user name must be unique"
+	case "file_mode":
+		return "Must be an octal file mode, ex. 0644"
+	case "vm_disk_list":
+		return "Every disk name must be unique"
 	}
 	return fe.Error()
 }
@@ -2,6 +2,8 @@ package v2
 
 import (
 	"errors"
+	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/kthcloud/go-deploy/models/model"
 	"github.com/kthcloud/go-deploy/models/version"
 	"github.com/kthcloud/go-deploy/pkg/config"
+	k8sModels "github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
 	"github.com/kthcloud/go-deploy/pkg/sys"
 	"github.com/kthcloud/go-deploy/service"
 	sErrors "github.com/kthcloud/go-deploy/service/errors"
@@ -226,6 +229,29 @@ func CreateVM(c *gin.Context) {
 		return
 	}
 
+	if storageClass := disallowedStorageClass(requestBody.Disks); storageClass != nil {
+		context.UserError(fmt.Sprintf("Storage class %s is not allowed", *storageClass))
+		return
+	}
+
+	if requestBody.Image != nil {
+		image, err := deployV2.VMs().Images().Get(*requestBody.Image)
+		if err != nil {
+			context.ServerError(err, ErrInternal)
+			return
+		}
+
+		if image == nil || (!auth.User.IsAdmin && !image.AllowedForRole(auth.GetEffectiveRole().Name)) {
+			context.NotFound("VM image not found")
+			return
+		}
+
+		if requestBody.DiskSize < image.MinDiskSize {
+			context.UserError(fmt.Sprintf("VM image %s requires a disk size of at least %d GB", image.ID, image.MinDiskSize))
+			return
+		}
+	}
+
 	err = deployV2.VMs().CheckQuota("", auth.User.ID, &auth.GetEffectiveRole().Quotas, opts.QuotaOpts{Create: &requestBody})
 	if err != nil {
 		var quotaExceedErr sErrors.QuotaExceededError
@@ -321,6 +347,72 @@ func DeleteVM(c *gin.Context) {
 	})
 }
 
+// DeleteVmDisk
+// @Summary Delete detached VM disk
+// @Description Delete a disk that was detached from a VM, along with its data
+// @Tags VM
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Security KeycloakOAuth
+// @Param vmId path string true "VM ID"
+// @Param diskName path string true "Disk name"
+// @Success 204 "No Content"
+// @Failure 400 {object} sys.ErrorResponse
+// @Failure 401 {object} sys.ErrorResponse
+// @Failure 403 {object} sys.ErrorResponse
+// @Failure 404 {object} sys.ErrorResponse
+// @Failure 500 {object} sys.ErrorResponse
+// @Router /v2/vms/{vmId}/disks/{diskName} [delete]
+func DeleteVmDisk(c *gin.Context) {
+	context := sys.NewContext(c)
+
+	var requestURI uri.VmDiskDelete
+	if err := context.GinContext.ShouldBindUri(&requestURI); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	auth, err := WithAuth(&context)
+	if err != nil {
+		context.ServerError(err, ErrAuthInfoNotAvailable)
+		return
+	}
+
+	deployV2 := service.V2(auth)
+
+	vm, err := deployV2.VMs().Get(requestURI.VmID, opts.GetOpts{Shared: true})
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	if vm == nil {
+		context.NotFound("VM not found")
+		return
+	}
+
+	if _, ok := vm.DiskMap[requestURI.DiskName]; ok {
+		context.UserError("Disk is attached to the VM. Detach it before deleting it")
+		return
+	}
+
+	err = deployV2.VMs().DeleteDetachedDisk(vm.ID, requestURI.DiskName)
+	if err != nil {
+		switch {
+		case errors.Is(err, sErrors.ErrVmNotFound):
+			context.NotFound("VM not found")
+		case errors.Is(err, sErrors.ErrVmDiskNotFound):
+			context.NotFound("Detached disk not found")
+		default:
+			context.ServerError(err, ErrInternal)
+		}
+		return
+	}
+
+	context.OkNoContent()
+}
+
 // UpdateVM
 // @Summary Update VM
 // @Description Update VM
@@ -403,11 +495,83 @@ func UpdateVM(c *gin.Context) {
 		}
 	}
 
+	if requestBody.SnapshotID != nil {
+		if requestBody.Name != nil || requestBody.Ports != nil || requestBody.CpuCores != nil || requestBody.RAM != nil || requestBody.DiskSize != nil || requestBody.Disks != nil || requestBody.NeverStale != nil || requestBody.SnapshotPolicies != nil || requestBody.CloudInit != nil {
+			context.UserError("Snapshot cannot be applied together with other updates")
+			return
+		}
+
+		snapshot, err := deployV2.VMs().Snapshots().Get(vm.ID, *requestBody.SnapshotID)
+		if err != nil {
+			context.ServerError(err, ErrInternal)
+			return
+		}
+
+		if snapshot == nil {
+			context.NotFound("Snapshot not found")
+			return
+		}
+	}
+
+	if requestBody.DiskSize != nil && *requestBody.DiskSize < vm.Specs.DiskSize {
+		context.UserError("Disk size cannot be decreased")
+		return
+	}
+
+	if requestBody.Disks != nil {
+		if storageClass := disallowedStorageClass(*requestBody.Disks); storageClass != nil {
+			context.UserError(fmt.Sprintf("Storage class %s is not allowed", *storageClass))
+			return
+		}
+
+		storageClassOrDefault := func(storageClass *string) string {
+			if storageClass == nil {
+				return k8sModels.DefaultVmDiskStorageClass
+			}
+
+			return *storageClass
+		}
+
+		for _, disk := range *requestBody.Disks {
+			// Disks that are attached again keep their volumes, so they are checked against them as well
+			current, ok := vm.DiskMap[disk.Name]
+			if !ok {
+				current, ok = vm.DetachedDiskMap[disk.Name]
+			}
+
+			if !ok {
+				continue
+			}
+
+			if disk.Size < current.Size {
+				context.UserError(fmt.Sprintf("Size of disk %s cannot be decreased", disk.Name))
+				return
+			}
+
+			if storageClassOrDefault(disk.StorageClass) != storageClassOrDefault(current.StorageClass) {
+				context.UserError(fmt.Sprintf("Storage class of disk %s cannot be changed", disk.Name))
+				return
+			}
+		}
+	}
+
 	if requestBody.NeverStale != nil && !auth.User.IsAdmin {
 		context.Forbidden("User is not allowed to modify the neverStale value")
 		return
 	}
 
+	err = deployV2.VMs().CheckDiskExpansion(vm.ID, &requestBody)
+	if err != nil {
+		var diskNotExpandableErr sErrors.DiskNotExpandableError
+		if errors.As(err, &diskNotExpandableErr) {
+			context.UserError(fmt.Sprintf("Storage class %s does not allow disks to be expanded", diskNotExpandableErr.StorageClass))
+			return
+		}
+
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
 	err = deployV2.VMs().CheckQuota(vm.ID, auth.User.ID, &auth.GetEffectiveRole().Quotas, opts.QuotaOpts{Update: &requestBody})
 	if err != nil {
 		var quotaExceededErr sErrors.QuotaExceededError
@@ -437,6 +601,21 @@ func UpdateVM(c *gin.Context) {
 	})
 }
 
+// disallowedStorageClass returns the first storage class of the disks that users may not choose, or nil if every one is allowed.
+func disallowedStorageClass(disks []body.VmDisk) *string {
+	for _, disk := range disks {
+		if disk.StorageClass == nil || *disk.StorageClass == k8sModels.DefaultVmDiskStorageClass {
+			continue
+		}
+
+		if !slices.Contains(config.Config.VM.DiskStorageClasses, *disk.StorageClass) {
+			return disk.StorageClass
+		}
+	}
+
+	return nil
+}
+
 func getVmAppExternalPort(zoneName string) *int {
 	zone := config.Config.GetZone(zoneName)
 	if zone == nil {
@@ -0,0 +1,93 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/dto/v2/uri"
+	"github.com/kthcloud/go-deploy/pkg/sys"
+	"github.com/kthcloud/go-deploy/service"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+)
+
+// GetVmConsole
+// @Summary Get VM serial console output using Server-Sent Events
+// @Description Get the serial console output of a VM using Server-Sent Events.
+// @Description The output is captured continuously, so recent history is sent first, followed by new output.
+// @Description The console is released for interactive use after 10 minutes without output, and is not captured for the next 30 minutes.
+// @Tags VM
+// @Produce json
+// @Security ApiKeyAuth
+// @Security KeycloakOAuth
+// @Param vmId path string true "VM ID"
+// @Success 200 {string} string
+// @Failure 400 {object} sys.ErrorResponse
+// @Failure 404 {object} sys.ErrorResponse
+// @Failure 500 {object} sys.ErrorResponse
+// @Router /v2/vms/{vmId}/console-sse [get]
+func GetVmConsole(c *gin.Context) {
+	sysContext := sys.NewContext(c)
+
+	var requestURI uri.VmConsoleGet
+	if err := sysContext.GinContext.ShouldBindUri(&requestURI); err != nil {
+		sysContext.BindingError(CreateBindingError(err))
+		return
+	}
+
+	auth, err := WithAuth(&sysContext)
+	if err != nil {
+		sysContext.ServerError(err, ErrAuthInfoNotAvailable)
+		return
+	}
+
+	type Message struct {
+		source    string
+		prefix    string
+		msg       string
+		createdAt time.Time
+	}
+
+	ch := make(chan Message)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handler := func(source, prefix, msg string, createdAt time.Time) {
+		select {
+		case ch <- Message{source, prefix, msg, createdAt}:
+		case <-ctx.Done():
+		}
+	}
+
+	deployV2 := service.V2(auth)
+
+	err = deployV2.VMs().SetupConsoleStream(requestURI.VmID, ctx, handler, 100)
+	if err != nil {
+		if errors.Is(err, sErrors.ErrVmNotFound) {
+			sysContext.NotFound("VM not found")
+			return
+		}
+
+		sysContext.ServerError(err, ErrInternal)
+		return
+	}
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case msg := <-ch:
+			c.SSEvent(msg.source, body.LogMessage{
+				Source:    msg.source,
+				Prefix:    msg.prefix,
+				Line:      msg.msg,
+				CreatedAt: msg.createdAt,
+			})
+			return true
+		}
+	})
+}
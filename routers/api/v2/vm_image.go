@@ -0,0 +1,298 @@
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/dto/v2/query"
+	"github.com/kthcloud/go-deploy/dto/v2/uri"
+	"github.com/kthcloud/go-deploy/pkg/config"
+	"github.com/kthcloud/go-deploy/pkg/sys"
+	"github.com/kthcloud/go-deploy/service"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	"github.com/kthcloud/go-deploy/service/v2/utils"
+	"github.com/kthcloud/go-deploy/service/v2/vms/opts"
+)
+
+// GetVmImage
+// @Summary Get VM image
+// @Description Get VM image
+// @Tags VmImage
+// @Produce json
+// @Security ApiKeyAuth
+// @Security KeycloakOAuth
+// @Param vmImageId path string true "VM image ID"
+// @Success 200 {object} body.VmImageRead
+// @Failure 400 {object} sys.ErrorResponse
+// @Failure 404 {object} sys.ErrorResponse
+// @Failure 500 {object} sys.ErrorResponse
+// @Router /v2/vmImages/{vmImageId} [get]
+func GetVmImage(c *gin.Context) {
+	context := sys.NewContext(c)
+
+	var requestURI uri.VmImageGet
+	if err := context.GinContext.ShouldBindUri(&requestURI); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	auth, err := WithAuth(&context)
+	if err != nil {
+		context.ServerError(err, ErrAuthInfoNotAvailable)
+		return
+	}
+
+	deployV2 := service.V2(auth)
+
+	image, err := deployV2.VMs().Images().Get(requestURI.VmImageID)
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	// Images the user may not use are hidden
+	if image == nil || (!auth.User.IsAdmin && !image.AllowedForRole(auth.GetEffectiveRole().Name)) {
+		context.NotFound("VM image not found")
+		return
+	}
+
+	context.Ok(image.ToDTO())
+}
+
+// ListVmImages
+// @Summary List VM images
+// @Description List VM images the user may create VMs with. Admins see every image.
+// @Tags VmImage
+// @Produce json
+// @Security ApiKeyAuth
+// @Security KeycloakOAuth
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Number of items per page"
+// @Success 200 {array} body.VmImageRead
+// @Failure 400 {object} sys.ErrorResponse
+// @Failure 500 {object} sys.ErrorResponse
+// @Router /v2/vmImages [get]
+func ListVmImages(c *gin.Context) {
+	context := sys.NewContext(c)
+
+	var requestQuery query.VmImageList
+	if err := context.GinContext.ShouldBind(&requestQuery); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	auth, err := WithAuth(&context)
+	if err != nil {
+		context.ServerError(err, ErrAuthInfoNotAvailable)
+		return
+	}
+
+	deployV2 := service.V2(auth)
+
+	var role *string
+	if !auth.User.IsAdmin {
+		role = &auth.GetEffectiveRole().Name
+	}
+
+	images, err := deployV2.VMs().Images().List(opts.ListVmImageOpts{
+		Pagination: utils.GetOrDefaultPagination(requestQuery.Pagination),
+		Role:       role,
+	})
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	dtoImages := make([]body.VmImageRead, len(images))
+	for i, image := range images {
+		dtoImages[i] = image.ToDTO()
+	}
+
+	context.Ok(dtoImages)
+}
+
+// CreateVmImage
+// @Summary Create VM image
+// @Description Add an image to the VM image catalogue. Only admins may manage the catalogue.
+// @Tags VmImage
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security KeycloakOAuth
+// @Param body body body.VmImageCreate true "VM image body"
+// @Success 201 {object} body.VmImageRead
+// @Failure 400 {object} sys.ErrorResponse
+// @Failure 403 {object} sys.ErrorResponse
+// @Failure 500 {object} sys.ErrorResponse
+// @Router /v2/vmImages [post]
+func CreateVmImage(c *gin.Context) {
+	context := sys.NewContext(c)
+
+	var requestBody body.VmImageCreate
+	if err := context.GinContext.ShouldBindJSON(&requestBody); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	auth, err := WithAuth(&context)
+	if err != nil {
+		context.ServerError(err, ErrAuthInfoNotAvailable)
+		return
+	}
+
+	if auth.User == nil || !auth.User.IsAdmin {
+		context.Forbidden("VM images can only be managed by admins")
+		return
+	}
+
+	if role := unknownRole(requestBody.Roles); role != nil {
+		context.UserError(fmt.Sprintf("Role %s not found", *role))
+		return
+	}
+
+	image, err := service.V2(auth).VMs().Images().Create(&requestBody)
+	if err != nil {
+		if errors.Is(err, sErrors.ErrVmImageAlreadyExists) {
+			context.UserError("VM image already exists")
+			return
+		}
+
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	context.JSONResponse(http.StatusCreated, image.ToDTO())
+}
+
+// UpdateVmImage
+// @Summary Update VM image
+// @Description Update an image in the VM image catalogue. VMs that were created from the image are not affected.
+// @Tags VmImage
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security KeycloakOAuth
+// @Param vmImageId path string true "VM image ID"
+// @Param body body body.VmImageUpdate true "VM image update"
+// @Success 200 {object} body.VmImageRead
+// @Failure 400 {object} sys.ErrorResponse
+// @Failure 403 {object} sys.ErrorResponse
+// @Failure 404 {object} sys.ErrorResponse
+// @Failure 500 {object} sys.ErrorResponse
+// @Router /v2/vmImages/{vmImageId} [post]
+func UpdateVmImage(c *gin.Context) {
+	context := sys.NewContext(c)
+
+	var requestURI uri.VmImageUpdate
+	if err := context.GinContext.ShouldBindUri(&requestURI); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	var requestBody body.VmImageUpdate
+	if err := context.GinContext.ShouldBindJSON(&requestBody); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	auth, err := WithAuth(&context)
+	if err != nil {
+		context.ServerError(err, ErrAuthInfoNotAvailable)
+		return
+	}
+
+	if auth.User == nil || !auth.User.IsAdmin {
+		context.Forbidden("VM images can only be managed by admins")
+		return
+	}
+
+	if requestBody.Roles != nil {
+		if role := unknownRole(*requestBody.Roles); role != nil {
+			context.UserError(fmt.Sprintf("Role %s not found", *role))
+			return
+		}
+	}
+
+	image, err := service.V2(auth).VMs().Images().Update(requestURI.VmImageID, &requestBody)
+	if err != nil {
+		if errors.Is(err, sErrors.ErrVmImageNotFound) {
+			context.NotFound("VM image not found")
+			return
+		}
+
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	context.Ok(image.ToDTO())
+}
+
+// DeleteVmImage
+// @Summary Delete VM image
+// @Description Remove an image from the VM image catalogue. VMs that were created from the image are not affected.
+// @Tags VmImage
+// @Produce json
+// @Security ApiKeyAuth
+// @Security KeycloakOAuth
+// @Param vmImageId path string true "VM image ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} sys.ErrorResponse
+// @Failure 403 {object} sys.ErrorResponse
+// @Failure 404 {object} sys.ErrorResponse
+// @Failure 500 {object} sys.ErrorResponse
+// @Router /v2/vmImages/{vmImageId} [delete]
+func DeleteVmImage(c *gin.Context) {
+	context := sys.NewContext(c)
+
+	var requestURI uri.VmImageDelete
+	if err := context.GinContext.ShouldBindUri(&requestURI); err != nil {
+		context.BindingError(CreateBindingError(err))
+		return
+	}
+
+	auth, err := WithAuth(&context)
+	if err != nil {
+		context.ServerError(err, ErrAuthInfoNotAvailable)
+		return
+	}
+
+	if auth.User == nil || !auth.User.IsAdmin {
+		context.Forbidden("VM images can only be managed by admins")
+		return
+	}
+
+	deployV2 := service.V2(auth)
+
+	image, err := deployV2.VMs().Images().Get(requestURI.VmImageID)
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	if image == nil {
+		context.NotFound("VM image not found")
+		return
+	}
+
+	err = deployV2.VMs().Images().Delete(image.ID)
+	if err != nil {
+		context.ServerError(err, ErrInternal)
+		return
+	}
+
+	context.OkNoContent()
+}
+
+// unknownRole returns the first role name that is not configured, or nil if every role exists.
+func unknownRole(roles []string) *string {
+	for _, role := range roles {
+		if config.Config.GetRole(role) == nil {
+			return &role
+		}
+	}
+
+	return nil
+}
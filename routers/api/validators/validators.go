@@ -8,6 +8,8 @@ import (
 	"github.com/go-playground/validator/v10"
 	bodyV2 "github.com/kthcloud/go-deploy/dto/v2/body"
 	"github.com/kthcloud/go-deploy/pkg/config"
+	"github.com/kthcloud/go-deploy/utils/cronutils"
+	"github.com/kthcloud/go-deploy/utils/versionutils"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/net/idna"
 )
@@ -336,7 +338,7 @@ func DeploymentName(fl validator.FieldLevel) bool {
 		return false
 	}
 
-	illegalSuffixes := []string{"-auth-proxy", "-custom-domain"}
+	illegalSuffixes := []string{"-auth-proxy", "-custom-domain", "-rollout"}
 	for _, suffix := range illegalSuffixes {
 		if strings.HasSuffix(name, suffix) {
 			return false
@@ -346,6 +348,17 @@ func DeploymentName(fl validator.FieldLevel) bool {
 	return true
 }
 
+// NewDeploymentName is a validator for the names of new deployments.
+// It extends DeploymentName with rules that existing deployments might not follow.
+func NewDeploymentName(fl validator.FieldLevel) bool {
+	if !DeploymentName(fl) {
+		return false
+	}
+
+	// Sidecars are named <deployment>-sidecar-<app> in K8s, so deployments cannot use the infix
+	return !strings.Contains(fl.Field().Interface().(string), "-sidecar")
+}
+
 // VmName is a validator for VM names.
 // It ensures that the name is valid according to some reserved internal names and suffixes
 func VmName(fl validator.FieldLevel) bool {
@@ -418,3 +431,104 @@ func Rfc1123(fl validator.FieldLevel) bool {
 
 	return rfc1123SubdomainRE.MatchString(val)
 }
+
+// SemverRange is a validator for version ranges, such as 1.x or ^1.2.3.
+func SemverRange(fl validator.FieldLevel) bool {
+	r, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	return versionutils.ValidSemVerRange(r)
+}
+
+// Cron is a validator for cron expressions with five fields, such as 0 22 * * *.
+func Cron(fl validator.FieldLevel) bool {
+	expression, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	return cronutils.Valid(expression)
+}
+
+// SnapshotPolicyList is a validator for snapshot policy lists.
+// It ensures that every policy name is unique.
+func SnapshotPolicyList(fl validator.FieldLevel) bool {
+	policies, ok := fl.Field().Interface().([]bodyV2.VmSnapshotPolicy)
+	if !ok {
+		return false
+	}
+
+	names := make(map[string]bool)
+	for _, policy := range policies {
+		if _, exists := names[policy.Name]; exists {
+			return false
+		}
+		names[policy.Name] = true
+	}
+
+	return true
+}
+
+// CloudInitPackage is a validator for cloud-init package names.
+// It allows version pins, such as nginx=1.24.0-1, but not options or whitespace.
+func CloudInitPackage(fl validator.FieldLevel) bool {
+	name, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	regex := regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9.+:=~_-]{0,99}$`)
+	return regex.MatchString(name)
+}
+
+// FileMode is a validator for octal file modes, such as 0644.
+// It requires four octal digits, where the first is 0.
+func FileMode(fl validator.FieldLevel) bool {
+	mode, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	regex := regexp.MustCompile(`^0[0-7]{3}$`)
+	return regex.MatchString(mode)
+}
+
+// CloudInitUsers is a validator for cloud-init user lists.
+// It ensures that every user name is unique.
+func CloudInitUsers(fl validator.FieldLevel) bool {
+	users, ok := fl.Field().Interface().([]bodyV2.VmCloudInitUser)
+	if !ok {
+		return false
+	}
+
+	names := make(map[string]bool)
+	for _, user := range users {
+		if _, exists := names[user.Name]; exists {
+			return false
+		}
+		names[user.Name] = true
+	}
+
+	return true
+}
+
+// VmDiskList is a validator for VM disk lists.
+// It ensures that every disk name is unique.
+func VmDiskList(fl validator.FieldLevel) bool {
+	disks, ok := fl.Field().Interface().([]bodyV2.VmDisk)
+	if !ok {
+		return false
+	}
+
+	names := make(map[string]bool)
+	for _, disk := range disks {
+		if _, exists := names[disk.Name]; exists {
+			return false
+		}
+		names[disk.Name] = true
+	}
+
+	return true
+}
@@ -152,8 +152,16 @@ func registerCustomValidators() {
 			"time_in_future":         validators.TimeInFuture,
 			"volume_name":            validators.VolumeName,
 			"deployment_name":        validators.DeploymentName,
+			"new_deployment_name":    validators.NewDeploymentName,
 			"vm_name":                validators.VmName,
 			"vm_port_name":           validators.VmPortName,
+			"semver_range":           validators.SemverRange,
+			"cron":                   validators.Cron,
+			"snapshot_policy_list":   validators.SnapshotPolicyList,
+			"cloud_init_package":     validators.CloudInitPackage,
+			"cloud_init_users":       validators.CloudInitUsers,
+			"file_mode":              validators.FileMode,
+			"vm_disk_list":           validators.VmDiskList,
 		}
 
 		for tag, fn := range registrations {
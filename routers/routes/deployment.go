@@ -7,12 +7,17 @@ import (
 )
 
 const (
-	DeploymentsPath          = "/v2/deployments"
-	DeploymentPath           = "/v2/deployments/:deploymentId"
-	DeploymentCiConfigPath   = "/v2/deployments/:deploymentId/ciConfig"
-	DeploymentCommandPath    = "/v2/deployments/:deploymentId/command"
-	DeploymentLogsPath       = "/v2/deployments/:deploymentId/logs-sse"
-	DeploymentHarborHookPath = "/v2/hooks/deployments/harbor"
+	DeploymentsPath            = "/v2/deployments"
+	DeploymentPath             = "/v2/deployments/:deploymentId"
+	DeploymentCiConfigPath     = "/v2/deployments/:deploymentId/ciConfig"
+	DeploymentCommandPath      = "/v2/deployments/:deploymentId/command"
+	DeploymentRevisionsPath    = "/v2/deployments/:deploymentId/revisions"
+	DeploymentLogsPath         = "/v2/deployments/:deploymentId/logs-sse"
+	DeploymentLogListPath      = "/v2/deployments/:deploymentId/logs"
+	DeploymentExecPath         = "/v2/deployments/:deploymentId/exec"
+	DeploymentImageWebhookPath = "/v2/deployments/:deploymentId/imageWebhook"
+	DeploymentHarborHookPath   = "/v2/hooks/deployments/harbor"
+	DeploymentImageHookPath    = "/v2/hooks/images/:deploymentId"
 )
 
 type DeploymentRoutingGroup struct{ RoutingGroupBase }
@@ -30,6 +35,11 @@ func (group *DeploymentRoutingGroup) PrivateRoutes() []Route {
 
 		{Method: "GET", Pattern: DeploymentCiConfigPath, HandlerFunc: v2.GetCiConfig},
 		{Method: "POST", Pattern: DeploymentCommandPath, HandlerFunc: v2.DoDeploymentCommand},
+		{Method: "POST", Pattern: DeploymentImageWebhookPath, HandlerFunc: v2.CreateDeploymentImageWebhook},
+		{Method: "DELETE", Pattern: DeploymentImageWebhookPath, HandlerFunc: v2.DeleteDeploymentImageWebhook},
+		{Method: "GET", Pattern: DeploymentRevisionsPath, HandlerFunc: v2.ListDeploymentRevisions},
+		{Method: "GET", Pattern: DeploymentExecPath, HandlerFunc: v2.DeploymentExec},
+		{Method: "GET", Pattern: DeploymentLogListPath, HandlerFunc: v2.ListDeploymentLogs},
 		{Method: "GET", Pattern: DeploymentLogsPath, HandlerFunc: v2.GetLogs, Middleware: []gin.HandlerFunc{middleware.SseSetup()}},
 	}
 }
@@ -37,5 +47,6 @@ func (group *DeploymentRoutingGroup) PrivateRoutes() []Route {
 func (group *DeploymentRoutingGroup) HookRoutes() []Route {
 	return []Route{
 		{Method: "POST", Pattern: DeploymentHarborHookPath, HandlerFunc: v2.HandleHarborHook},
+		{Method: "POST", Pattern: DeploymentImageHookPath, HandlerFunc: v2.HandleImageHook},
 	}
 }
@@ -39,6 +39,7 @@ func RoutingGroups() []RoutingGroup {
 		TeamRoutes(),
 		UserRoutes(),
 		VmActionRoutes(),
+		VmImageRoutes(),
 		VmRoutes(),
 		ZoneRoutes(),
 	}
@@ -3,8 +3,8 @@ package routes
 import "github.com/kthcloud/go-deploy/routers/api/v2"
 
 const (
-	SnapshotsPath = "/v2/snapshots"
-	SnapshotPath  = "/v2/snapshots/:snapshotId"
+	SnapshotsPath = "/v2/vms/:vmId/snapshots"
+	SnapshotPath  = "/v2/vms/:vmId/snapshots/:snapshotId"
 )
 
 type SnapshotRoutingGroup struct{ RoutingGroupBase }
@@ -1,12 +1,16 @@
 package routes
 
 import (
+	"github.com/gin-gonic/gin"
 	v2 "github.com/kthcloud/go-deploy/routers/api/v2"
+	"github.com/kthcloud/go-deploy/routers/api/v2/middleware"
 )
 
 const (
-	VmsPath = "/v2/vms"
-	VmPath  = "/v2/vms/:vmId"
+	VmsPath       = "/v2/vms"
+	VmPath        = "/v2/vms/:vmId"
+	VmConsolePath = "/v2/vms/:vmId/console-sse"
+	VmDiskPath    = "/v2/vms/:vmId/disks/:diskName"
 )
 
 type VmRoutingGroup struct{ RoutingGroupBase }
@@ -22,5 +26,7 @@ func (group *VmRoutingGroup) PrivateRoutes() []Route {
 		{Method: "POST", Pattern: VmsPath, HandlerFunc: v2.CreateVM},
 		{Method: "POST", Pattern: VmPath, HandlerFunc: v2.UpdateVM},
 		{Method: "DELETE", Pattern: VmPath, HandlerFunc: v2.DeleteVM},
+		{Method: "DELETE", Pattern: VmDiskPath, HandlerFunc: v2.DeleteVmDisk},
+		{Method: "GET", Pattern: VmConsolePath, HandlerFunc: v2.GetVmConsole, Middleware: []gin.HandlerFunc{middleware.SseSetup()}},
 	}
 }
@@ -0,0 +1,24 @@
+package routes
+
+import "github.com/kthcloud/go-deploy/routers/api/v2"
+
+const (
+	VmImagesPath = "/v2/vmImages"
+	VmImagePath  = "/v2/vmImages/:vmImageId"
+)
+
+type VmImageRoutingGroup struct{ RoutingGroupBase }
+
+func VmImageRoutes() *VmImageRoutingGroup {
+	return &VmImageRoutingGroup{}
+}
+
+func (group *VmImageRoutingGroup) PrivateRoutes() []Route {
+	return []Route{
+		{Method: "GET", Pattern: VmImagePath, HandlerFunc: v2.GetVmImage},
+		{Method: "GET", Pattern: VmImagesPath, HandlerFunc: v2.ListVmImages},
+		{Method: "POST", Pattern: VmImagesPath, HandlerFunc: v2.CreateVmImage},
+		{Method: "POST", Pattern: VmImagePath, HandlerFunc: v2.UpdateVmImage},
+		{Method: "DELETE", Pattern: VmImagePath, HandlerFunc: v2.DeleteVmImage},
+	}
+}
@@ -1249,6 +1249,9 @@ if [[ "$REPLY" =~ ^[Yy]$ ]]; then
   export harbor_password="Harbor12345"
   export harbor_webhook_secret="secret"
 
+  # Secret envs
+  export env_encryption_key=$(head -c 32 /dev/urandom | base64)
+
   envsubst < config.yml.tmpl > ../../config.local.yml
 
   echo -e ""
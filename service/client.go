@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+
 	"github.com/kthcloud/go-deploy/service/clients"
 	"github.com/kthcloud/go-deploy/service/core"
 	"github.com/kthcloud/go-deploy/service/v2"
@@ -9,3 +11,8 @@ import (
 func V2(authInfo ...*core.AuthInfo) clients.V2 {
 	return v2.New(authInfo...)
 }
+
+// V2WithContext returns a V2 client whose long-running operations stop when the context is cancelled.
+func V2WithContext(ctx context.Context, authInfo ...*core.AuthInfo) clients.V2 {
+	return v2.NewWithContext(ctx, authInfo...)
+}
@@ -1,6 +1,8 @@
 package clients
 
 import (
+	"context"
+
 	"github.com/kthcloud/go-deploy/service/core"
 	apiV2 "github.com/kthcloud/go-deploy/service/v2/api"
 )
@@ -8,6 +10,7 @@ import (
 type V2 interface {
 	Auth() *core.AuthInfo
 	HasAuth() bool
+	Context() context.Context
 
 	Deployments() apiV2.Deployments
 	Discovery() apiV2.Discovery
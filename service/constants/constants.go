@@ -7,6 +7,12 @@ const (
 	AppNameImagePullSecret = "image-pull-secret"
 	// AppNameCustomDomain is the name of the custom domain app in various contexts
 	AppNameCustomDomain = "custom-domain"
+	// AppNameEnvSecret is the name of the secret env app in various contexts
+	AppNameEnvSecret = "env-secret"
+	// AppNameSidecar is the name of the sidecar apps in various contexts
+	AppNameSidecar = "sidecar"
+	// AppNameRollout is the name of the app that runs the new version during a rollout in various contexts
+	AppNameRollout = "rollout"
 
 	// VmProxyAppName is the name of the VM proxy app in various contexts
 	VmProxyAppName = "vm-proxy"
@@ -24,6 +30,10 @@ const (
 
 	// WildcardCertSecretName is the name of the wildcard cert secret in various contexts
 	WildcardCertSecretName = "wildcard-cert"
+
+	// MessageSourceKeepAlive is the source of the messages sent to keep a stream alive.
+	// They are sent to the client of both deployment log streams and VM console streams.
+	MessageSourceKeepAlive = "keep-alive"
 )
 
 // WithImagePullSecretSuffix returns the image pull secret app name with the given suffix
@@ -35,3 +45,18 @@ func WithImagePullSecretSuffix(appName string) string {
 func WithCustomDomainSuffix(appName string) string {
 	return appName + "-" + AppNameCustomDomain
 }
+
+// WithEnvSecretSuffix returns the secret env app name with the given suffix
+func WithEnvSecretSuffix(appName string) string {
+	return appName + "-" + AppNameEnvSecret
+}
+
+// WithRolloutSuffix returns the rollout app name with the given suffix
+func WithRolloutSuffix(appName string) string {
+	return appName + "-" + AppNameRollout
+}
+
+// WithSidecarSuffix returns the sidecar app name with the given suffix
+func WithSidecarSuffix(appName, sidecarName string) string {
+	return appName + "-" + AppNameSidecar + "-" + sidecarName
+}
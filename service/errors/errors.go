@@ -53,6 +53,21 @@ func NewZoneCapabilityMissingError(zone, capability string) ZoneCapabilityMissin
 	return ZoneCapabilityMissingErr{Zone: zone, Capability: capability}
 }
 
+// DiskNotExpandableError is returned when a disk is grown, but its storage class does not allow expansion.
+type DiskNotExpandableError struct {
+	StorageClass string
+}
+
+// Error returns the reason for the disk not expandable error.
+func (e DiskNotExpandableError) Error() string {
+	return fmt.Sprintf("storage class %s does not allow disks to be expanded", e.StorageClass)
+}
+
+// NewDiskNotExpandableError creates a new DiskNotExpandableError.
+func NewDiskNotExpandableError(storageClass string) DiskNotExpandableError {
+	return DiskNotExpandableError{StorageClass: storageClass}
+}
+
 var (
 	// ErrAuthInfoNotAvailable is returned when the auth info is not available
 	ErrAuthInfoNotAvailable = fmt.Errorf("auth info not available")
@@ -67,6 +82,33 @@ var (
 	// ErrDeploymentHasNoCiConfig is returned when the deployment does not have a CI config.
 	ErrDeploymentHasNoCiConfig = fmt.Errorf("deployment does not have a CI config")
 
+	// ErrPodNotFound is returned when the pod is not found or does not belong to the deployment.
+	ErrPodNotFound = fmt.Errorf("pod not found")
+
+	// ErrDeploymentNotGit is returned when a build is requested for a deployment that is not built from git.
+	ErrDeploymentNotGit = fmt.Errorf("deployment is not built from git")
+
+	// ErrBuildFailed is returned when the build of a git deployment fails.
+	ErrBuildFailed = fmt.Errorf("build failed")
+
+	// ErrDeploymentHasOwnImage is returned when an image webhook is created for a deployment that is built into its own registry repository.
+	ErrDeploymentHasOwnImage = fmt.Errorf("deployment uses its own image")
+
+	// ErrImageWebhookNotFound is returned when the deployment does not have an image webhook.
+	ErrImageWebhookNotFound = fmt.Errorf("image webhook not found")
+
+	// ErrInvalidWebhookToken is returned when the token of a webhook request is invalid.
+	ErrInvalidWebhookToken = fmt.Errorf("invalid webhook token")
+
+	// ErrInvalidWebhookSignature is returned when the signature of a webhook payload is missing or invalid.
+	ErrInvalidWebhookSignature = fmt.Errorf("invalid webhook signature")
+
+	// ErrInvalidWebhookPayload is returned when the image cannot be found in a webhook payload.
+	ErrInvalidWebhookPayload = fmt.Errorf("invalid webhook payload")
+
+	// ErrImageMismatch is returned when the image in a webhook payload is not the image of the deployment.
+	ErrImageMismatch = fmt.Errorf("image does not match the deployment's image")
+
 	// ErrMainAppNotFound is returned when the main app is not found.
 	// This could be caused by stale data in the database.
 	ErrMainAppNotFound = fmt.Errorf("main app not found")
@@ -84,6 +126,12 @@ var (
 	// This is most likely caused by a race-condition between a some model call and a deletion call.
 	ErrVmNotFound = fmt.Errorf("vm not found")
 
+	// ErrVmDiskNotFound is returned when the disk is not found among the detached disks of a vm.
+	ErrVmDiskNotFound = fmt.Errorf("vm disk not found")
+
+	// ErrVmConsoleIdle is returned when the serial console of a vm is disconnected since it had no output for too long.
+	ErrVmConsoleIdle = fmt.Errorf("vm console idle")
+
 	// ErrGpuNotFound is returned when the gpu is not found.
 	ErrGpuNotFound = fmt.Errorf("gpu not found")
 
@@ -119,6 +167,15 @@ var (
 	// ErrSnapshotNotFound is returned when the snapshot is not found.
 	ErrSnapshotNotFound = fmt.Errorf("snapshot not found")
 
+	// ErrSnapshotNotReady is returned when the snapshot is not ready to be used, e.g. if it is still being taken.
+	ErrSnapshotNotReady = fmt.Errorf("snapshot not ready")
+
+	// ErrVmImageNotFound is returned when the image is not found in the VM image catalogue.
+	ErrVmImageNotFound = fmt.Errorf("vm image not found")
+
+	// ErrVmImageAlreadyExists is returned when an image with the same ID already exists in the VM image catalogue.
+	ErrVmImageAlreadyExists = fmt.Errorf("vm image already exists")
+
 	// ErrNonUniqueField is returned when a field is not unique, such as the name of a deployment.
 	ErrNonUniqueField = fmt.Errorf("non unique field")
 
@@ -148,6 +205,9 @@ var (
 	// ErrJobNotFound is returned when the job is not found.
 	ErrJobNotFound = fmt.Errorf("job not found")
 
+	// ErrJobFinished is returned when the job is already finished and cannot be cancelled.
+	ErrJobFinished = fmt.Errorf("job finished")
+
 	// ErrNotificationNotFound is returned when the notification is not found.
 	ErrNotificationNotFound = fmt.Errorf("notification not found")
 
@@ -29,23 +29,40 @@ type Deployments interface {
 	GetByName(name string, opts ...dOpts.GetOpts) (*model.Deployment, error)
 	List(opts ...dOpts.ListOpts) ([]model.Deployment, error)
 	Create(id, userID string, dtoDeploymentCreate *body.DeploymentCreate) error
-	Update(id string, dtoDeploymentUpdate *body.DeploymentUpdate) error
+	Update(id string, dtoDeploymentUpdate *body.DeploymentUpdate, opts ...dOpts.UpdateOpts) error
 	UpdateOwner(id string, params *model.DeploymentUpdateOwnerParams) error
 	Delete(id string) error
 	Repair(id string) error
 
 	Restart(id string) error
-	DoCommand(id string, command string)
 
-	GetCiConfig(id string) (*body.CiConfig, error)
+	CreateImageWebhook(id string, dtoCreate *body.ImageWebhookCreate) (*body.ImageWebhookCreated, error)
+	DeleteImageWebhook(id string) error
+	HandleImageWebhook(id, token, signature string, payload []byte) error
+	CheckImageUpdate(id string) error
+	ApplySchedule(id string) error
+	Build(id string) error
+	DoCommand(id string, params *body.DeploymentCommand) error
+	ResetScale(id string) error
+
+	GetCiConfig(id string, opts ...dOpts.CiConfigOpts) (*body.CiConfig, error)
+
+	ListRevisions(id string, opts ...dOpts.ListRevisionsOpts) ([]model.DeploymentRevision, error)
+	GetRevision(id string, version int) (*model.DeploymentRevision, error)
+	RecordImagePush(id, digest string) error
 
 	SetupLogStream(id string, ctx context.Context, handler func(string, string, string, time.Time), history int) error
+	ListLogs(id string, opts ...dOpts.ListLogsOpts) ([]model.DeploymentLog, error)
+	ForEachLog(id string, ctx context.Context, callback func(*model.DeploymentLog) error, opts ...dOpts.ListLogsOpts) error
 	AddLogs(id string, logs ...model.Log)
 
+	Exec(ctx context.Context, id string, execOpts *dOpts.ExecOpts) error
+
 	StartActivity(id string, activity string) error
 	CanAddActivity(id, activity string) (bool, string)
 
 	CheckQuota(id string, params *dOpts.QuotaOptions) error
+	EncryptSecretEnvs(envLists ...[]body.Env) error
 	NameAvailable(name string) (bool, error)
 	GetUsage(userID string) (*model.DeploymentUsage, error)
 
@@ -66,8 +83,9 @@ type Events interface {
 type Jobs interface {
 	Get(id string, opts ...jobOpts.GetOpts) (*model.Job, error)
 	List(opts ...jobOpts.ListOpts) ([]model.Job, error)
-	Create(id, userID, jobType, version string, args map[string]interface{}) error
+	Create(id, userID, jobType, version string, args map[string]interface{}, opts ...jobOpts.CreateOpts) error
 	Update(id string, jobUpdateDTO *body.JobUpdate) (*model.Job, error)
+	Cancel(id string) (*model.Job, error)
 }
 
 type Notifications interface {
@@ -140,19 +158,24 @@ type VMs interface {
 	UpdateOwner(id string, params *model.VmUpdateOwnerParams) error
 	Delete(id string) error
 	Repair(id string) error
+	DeleteDetachedDisk(id, diskName string) error
 
 	IsAccessible(id string) (bool, error)
 
 	CheckQuota(id, userID string, quota *model.Quotas, opts ...vmOpts.QuotaOpts) error
+	CheckDiskExpansion(id string, dtoVmUpdate *body.VmUpdate) error
 	GetUsage(userID string) (*model.VmUsage, error)
 	NameAvailable(name string) (bool, error)
 	SshConnectionString(id string) (*string, error)
 
 	DoAction(id string, action *body.VmActionCreate) error
 
+	SetupConsoleStream(id string, ctx context.Context, handler func(string, string, string, time.Time), history int) error
+
 	Snapshots() Snapshots
 	GpuLeases() GpuLeases
 	GpuGroups() GpuGroups
+	Images() VmImages
 
 	K8s() *vmK8sService.Client
 }
@@ -164,6 +187,7 @@ type Snapshots interface {
 	Create(vmID string, opts ...vmOpts.CreateSnapshotOpts) (*model.SnapshotV2, error)
 	Delete(vmID, id string) error
 	Apply(vmID, id string) error
+	ApplyPolicies(vmID string) error
 }
 
 type GPUs interface{}
@@ -187,6 +211,14 @@ type GpuGroups interface {
 	Exists(id string) (bool, error)
 }
 
+type VmImages interface {
+	Get(id string) (*model.VmImage, error)
+	List(opts ...vmOpts.ListVmImageOpts) ([]model.VmImage, error)
+	Create(dtoVmImageCreate *body.VmImageCreate) (*model.VmImage, error)
+	Update(id string, dtoVmImageUpdate *body.VmImageUpdate) (*model.VmImage, error)
+	Delete(id string) error
+}
+
 type System interface {
 	ListCapacities(n int) ([]body.TimestampedSystemCapacities, error)
 	ListStats(n int) ([]body.TimestampedSystemStats, error)
@@ -1,6 +1,8 @@
 package v2
 
 import (
+	"context"
+
 	"github.com/kthcloud/go-deploy/service/core"
 	"github.com/kthcloud/go-deploy/service/v2/api"
 	"github.com/kthcloud/go-deploy/service/v2/deployments"
@@ -20,9 +22,15 @@ import (
 type Client struct {
 	auth  *core.AuthInfo
 	cache *core.Cache
+	ctx   context.Context
 }
 
 func New(authInfo ...*core.AuthInfo) *Client {
+	return NewWithContext(context.Background(), authInfo...)
+}
+
+// NewWithContext creates a client whose long-running operations stop when the context is cancelled.
+func NewWithContext(ctx context.Context, authInfo ...*core.AuthInfo) *Client {
 	var auth *core.AuthInfo
 	if len(authInfo) > 0 {
 		auth = authInfo[0]
@@ -31,9 +39,14 @@ func New(authInfo ...*core.AuthInfo) *Client {
 	return &Client{
 		auth:  auth,
 		cache: core.NewCache(),
+		ctx:   ctx,
 	}
 }
 
+func (c *Client) Context() context.Context {
+	return c.ctx
+}
+
 func (c *Client) Auth() *core.AuthInfo {
 	return c.auth
 }
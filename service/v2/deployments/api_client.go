@@ -38,5 +38,5 @@ func (c *Client) Harbor() *harbor_service.Client {
 
 // K8s returns the client for the K8s service.
 func (c *Client) K8s() *k8s_service.Client {
-	return k8s_service.New(c.Cache)
+	return k8s_service.New(c.Cache).WithContext(c.V2.Context())
 }
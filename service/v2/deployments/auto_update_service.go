@@ -0,0 +1,139 @@
+package deployments
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/models/version"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_repo"
+	"github.com/kthcloud/go-deploy/pkg/subsystems/registry"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	jobOpts "github.com/kthcloud/go-deploy/service/v2/jobs/opts"
+	"github.com/kthcloud/go-deploy/utils/versionutils"
+)
+
+// CheckImageUpdate checks the registry of a deployment with an auto-update policy for a new image.
+//
+// With the semver policy, an update job is created if a higher version in the range is found.
+// Otherwise, the digest of the current tag is resolved, and the deployment is redeployed if it has changed.
+// The first digest that is resolved is only stored, since the deployment already runs that image,
+// and the image is not pinned to a digest until a new one is found, so that the first check does not restart the deployment.
+//
+// Deployments without an auto-update policy, and deployments that are busy, are skipped.
+func (c *Client) CheckImageUpdate(id string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to check image update for deployment %s. details: %w", id, err)
+	}
+
+	d, err := c.Get(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if d == nil {
+		return sErrors.ErrDeploymentNotFound
+	}
+
+	mainApp := d.GetMainApp()
+	if d.Type != model.DeploymentTypePrebuilt || mainApp.AutoUpdate == nil {
+		return nil
+	}
+
+	if !d.Ready() || d.DoingActivity(model.ActivityUpdating) {
+		return nil
+	}
+
+	ref, err := registry.ParseReference(mainApp.Image)
+	if err != nil {
+		return makeError(err)
+	}
+
+	// An image pinned to a digest by the user never changes
+	if ref.Digest != "" {
+		return nil
+	}
+
+	registryClient := registry.NewClient()
+
+	if mainApp.AutoUpdate.Policy == model.AutoUpdatePolicySemver {
+		updated, err := c.updateToHighestVersion(d, ref, registryClient)
+		if err != nil {
+			return makeError(err)
+		}
+
+		if updated {
+			return nil
+		}
+	}
+
+	digest, err := registryClient.GetDigest(ref)
+	if err != nil {
+		return makeError(err)
+	}
+
+	changed := mainApp.ImageDigest != "" && mainApp.ImageDigest != digest
+
+	err = deployment_repo.New().SetImageDigest(id, digest, changed)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if !changed {
+		return nil
+	}
+
+	c.addCommandLog(id, fmt.Sprintf("New digest %s found for %s. Redeploying", digest, mainApp.Image))
+
+	// The image is pinned to the digest, so applying the new digest rolls out the new image
+	err = c.K8s().Repair(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	return nil
+}
+
+// updateToHighestVersion creates an update job that changes the image tag of the deployment
+// to the highest version in its auto-update range, if it is higher than the current tag.
+// It returns whether an update job was created.
+func (c *Client) updateToHighestVersion(d *model.Deployment, ref *registry.Reference, registryClient *registry.Client) (bool, error) {
+	mainApp := d.GetMainApp()
+
+	versionRange, err := versionutils.ParseSemVerRange(mainApp.AutoUpdate.Range)
+	if err != nil {
+		return false, err
+	}
+
+	tags, err := registryClient.ListTags(ref)
+	if err != nil {
+		return false, err
+	}
+
+	tag, found := versionRange.HighestInRange(tags)
+	if !found || tag == ref.Tag {
+		return false, nil
+	}
+
+	// Only move forward, unless the current tag is not a version in the range, e.g. if the range was changed
+	highest, _ := versionutils.ParseSemVer(tag)
+	if current, ok := versionutils.ParseSemVer(ref.Tag); ok && versionRange.Contains(current) && highest.Compare(current) <= 0 {
+		return false, nil
+	}
+
+	repository, _ := splitImage(mainApp.Image)
+	image := fmt.Sprintf("%s:%s", repository, tag)
+
+	c.addCommandLog(d.ID, fmt.Sprintf("Version %s found in range %s. Updating image to %s", tag, mainApp.AutoUpdate.Range, image))
+
+	err = c.V2.Jobs().Create(uuid.New().String(), d.OwnerID, model.JobUpdateDeployment, version.V2, map[string]interface{}{
+		"id":     d.ID,
+		"params": body.DeploymentUpdate{Image: &image},
+	}, jobOpts.CreateOpts{})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,85 @@
+package deployments
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/models/version"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	jobOpts "github.com/kthcloud/go-deploy/service/v2/jobs/opts"
+)
+
+// Build builds the image of a git deployment and restarts it once the image is pushed.
+//
+// The build runs as a job in the deployment's namespace, and its output is added to the deployment's logs.
+// It returns sErrors.ErrDeploymentNotGit if the deployment is not built from git,
+// and sErrors.ErrBuildFailed if the build fails.
+//
+// This function is blocking until the build completes.
+func (c *Client) Build(id string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to build deployment %s. details: %w", id, err)
+	}
+
+	d, err := c.Get(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if d == nil {
+		return sErrors.ErrDeploymentNotFound
+	}
+
+	if d.Type != model.DeploymentTypeGit || d.Git == nil {
+		return sErrors.ErrDeploymentNotGit
+	}
+
+	c.addBuildLog(id, fmt.Sprintf("Build of %s (branch %s) started", d.Git.URL, d.Git.Branch))
+
+	err = c.K8s().Build(id, createImagePath(d.OwnerID, d.Name), func(lines []model.Log) {
+		c.AddLogs(id, lines...)
+	})
+	if err != nil {
+		if errors.Is(err, sErrors.ErrBuildFailed) {
+			c.addBuildLog(id, "Build failed")
+			return err
+		}
+
+		return makeError(err)
+	}
+
+	c.addBuildLog(id, "Build succeeded")
+
+	err = c.Restart(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	return nil
+}
+
+// scheduleBuild creates a job that builds the git deployment.
+func (c *Client) scheduleBuild(d *model.Deployment) error {
+	err := c.V2.Jobs().Create(uuid.New().String(), d.OwnerID, model.JobBuildDeployment, version.V2, map[string]interface{}{
+		"id": d.ID,
+	}, jobOpts.CreateOpts{})
+	if err != nil {
+		return fmt.Errorf("failed to schedule build of deployment %s. details: %w", d.ID, err)
+	}
+
+	return nil
+}
+
+// addBuildLog adds a log line about a build to the deployment.
+func (c *Client) addBuildLog(id, line string) {
+	c.AddLogs(id, model.Log{
+		Source: model.LogSourceBuild,
+		Prefix: "[build]",
+		// Since this is sent as a string, and not a JSON object, we need to prepend the createdAt
+		Line:      fmt.Sprintf("%s %s", time.Now().Format(time.RFC3339), line),
+		CreatedAt: time.Now(),
+	})
+}
@@ -8,16 +8,30 @@ import (
 	"github.com/kthcloud/go-deploy/models/model"
 	"github.com/kthcloud/go-deploy/pkg/config"
 	"github.com/kthcloud/go-deploy/service/errors"
+	sUtils "github.com/kthcloud/go-deploy/service/utils"
 	"github.com/kthcloud/go-deploy/service/v2/deployments/opts"
 	"github.com/kthcloud/go-deploy/utils/subsystemutils"
 	"gopkg.in/yaml.v3"
 )
 
+// ciConfigParams contains everything a CI config needs to build and push the deployment's image.
+type ciConfigParams struct {
+	registry string
+	username string
+	password string
+	image    string
+	branch   string
+	sha      bool
+}
+
 // GetCiConfig returns the CI config for the deployment.
 //
+// The config is generated for the CI system given in the options, and defaults to a GitHub Actions workflow.
 // It returns an error if the deployment is not found, or if the deployment is not ready.
 // It returns nil if the deployment is not a custom deployment.
-func (c *Client) GetCiConfig(id string) (*body.CiConfig, error) {
+func (c *Client) GetCiConfig(id string, ciConfigOpts ...opts.CiConfigOpts) (*body.CiConfig, error) {
+	o := sUtils.GetFirstOrDefault(ciConfigOpts)
+
 	deployment, err := c.Get(id, opts.GetOpts{Shared: true})
 	if err != nil {
 		return nil, err
@@ -35,54 +49,185 @@ func (c *Client) GetCiConfig(id string) (*body.CiConfig, error) {
 		return nil, errors.ErrDeploymentHasNoCiConfig
 	}
 
-	tag := fmt.Sprintf("%s/%s/%s",
-		config.Config.Registry.URL,
-		subsystemutils.GetPrefixedName(deployment.OwnerID),
-		deployment.Name,
-	)
+	params := ciConfigParams{
+		registry: config.Config.Registry.URL,
+		username: deployment.Subsystems.Harbor.Robot.HarborName,
+		password: deployment.Subsystems.Harbor.Robot.Secret,
+		image: fmt.Sprintf("%s/%s/%s",
+			config.Config.Registry.URL,
+			subsystemutils.GetPrefixedName(deployment.OwnerID),
+			deployment.Name,
+		),
+		branch: "main",
+		sha:    o.TagStrategy == model.CiTagSha,
+	}
 
-	username := deployment.Subsystems.Harbor.Robot.HarborName
-	password := deployment.Subsystems.Harbor.Robot.Secret
+	if o.Branch != nil {
+		params.branch = *o.Branch
+	}
+
+	switch o.Format {
+	case model.CiFormatGitlab:
+		return gitlabCiConfig(&params)
+	case model.CiFormatForgejo:
+		return forgejoCiConfig(&params)
+	case model.CiFormatShell:
+		return shellCiConfig(&params), nil
+	default:
+		return githubCiConfig(&params)
+	}
+}
+
+// githubCiConfig returns a GitHub Actions workflow that builds and pushes the image.
+func githubCiConfig(params *ciConfigParams) (*body.CiConfig, error) {
+	tags := params.image
+	if params.sha {
+		tags = fmt.Sprintf("%s,%s:${{ github.sha }}", params.image, params.image)
+	}
+
+	marshalledConfig, err := marshalActionConfig(actionConfig(params, "ubuntu-latest", "", tags))
+	if err != nil {
+		return nil, err
+	}
+
+	return &body.CiConfig{
+		Config:   marshalledConfig,
+		Format:   model.CiFormatGithub,
+		Filename: ".github/workflows/kthcloud-ci.yml",
+	}, nil
+}
+
+// forgejoCiConfig returns a Forgejo Actions workflow that builds and pushes the image.
+//
+// Forgejo Actions uses the same syntax as GitHub Actions, but its runners fetch actions from
+// the Forgejo instance by default, so the Docker actions are referenced by their full URL.
+func forgejoCiConfig(params *ciConfigParams) (*body.CiConfig, error) {
+	tags := params.image
+	if params.sha {
+		tags = fmt.Sprintf("%s,%s:${{ github.sha }}", params.image, params.image)
+	}
+
+	marshalledConfig, err := marshalActionConfig(actionConfig(params, "docker", "https://github.com/", tags))
+	if err != nil {
+		return nil, err
+	}
+
+	return &body.CiConfig{
+		Config:   marshalledConfig,
+		Format:   model.CiFormatForgejo,
+		Filename: ".forgejo/workflows/kthcloud-ci.yml",
+	}, nil
+}
 
-	githubCiConfig := model.GithubActionConfig{
+// gitlabCiConfig returns a GitLab CI pipeline that builds and pushes the image using Docker-in-Docker.
+func gitlabCiConfig(params *ciConfigParams) (*body.CiConfig, error) {
+	gitlabCiConfig := model.GitlabCiConfig{
+		Build: model.GitlabCiJob{
+			Image:     "docker:27",
+			Services:  []string{"docker:27-dind"},
+			Variables: map[string]string{"DOCKER_TLS_CERTDIR": "/certs"},
+			Rules:     []model.GitlabCiRule{{If: fmt.Sprintf(`$CI_COMMIT_BRANCH == "%s"`, params.branch)}},
+			Script:    dockerScript(params, "$CI_COMMIT_SHA"),
+		},
+	}
+
+	marshalledConfig, err := yaml.Marshal(gitlabCiConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &body.CiConfig{
+		Config:   string(marshalledConfig),
+		Format:   model.CiFormatGitlab,
+		Filename: ".gitlab-ci.yml",
+	}, nil
+}
+
+// shellCiConfig returns a shell script that builds and pushes the image.
+// It is meant for CI systems that do not have a dedicated format, and only needs Docker and Git.
+func shellCiConfig(params *ciConfigParams) *body.CiConfig {
+	lines := []string{
+		"#!/bin/sh",
+		"set -eu",
+		"",
+		fmt.Sprintf("if [ \"$(git rev-parse --abbrev-ref HEAD)\" != %s ]; then", shellQuote(params.branch)),
+		fmt.Sprintf("  echo %s", shellQuote("Not on branch "+params.branch+", skipping build")),
+		"  exit 0",
+		"fi",
+		"",
+	}
+
+	lines = append(lines, dockerScript(params, "$(git rev-parse HEAD)")...)
+
+	return &body.CiConfig{
+		Config:   strings.Join(lines, "\n") + "\n",
+		Format:   model.CiFormatShell,
+		Filename: "kthcloud-ci.sh",
+	}
+}
+
+// actionConfig returns a GitHub Actions style workflow.
+// The actionPrefix is prepended to the referenced actions.
+func actionConfig(params *ciConfigParams, runsOn, actionPrefix, tags string) model.GithubActionConfig {
+	return model.GithubActionConfig{
 		Name: "kthcloud-ci",
 		On: model.On{
-			Push:             model.Push{Branches: []string{"main"}},
+			Push:             model.Push{Branches: []string{params.branch}},
 			WorkflowDispatch: struct{}{},
 		},
 		Jobs: model.Jobs{Docker: model.Docker{
-			RunsOn: "ubuntu-latest",
+			RunsOn: runsOn,
 			Steps: []model.Steps{
 				{
 					Name: "Login to Docker Hub",
-					Uses: "docker/login-action@v3",
+					Uses: actionPrefix + "docker/login-action@v3",
 					With: model.With{
-						Registry: config.Config.Registry.URL,
-						Username: username,
-						Password: password,
+						Registry: params.registry,
+						Username: params.username,
+						Password: params.password,
 					},
 				},
 				{
 					Name: "Build and push",
-					Uses: "docker/build-push-action@v5",
+					Uses: actionPrefix + "docker/build-push-action@v5",
 					With: model.With{
 						Push: true,
-						Tags: tag,
+						Tags: tags,
 					},
 				},
 			},
 		}},
 	}
+}
 
-	marshalledConfig, err := yaml.Marshal(githubCiConfig)
+// marshalActionConfig marshals a GitHub Actions style workflow to YAML.
+func marshalActionConfig(actionConfig model.GithubActionConfig) (string, error) {
+	marshalledConfig, err := yaml.Marshal(actionConfig)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	ciConfig := body.CiConfig{Config: string(marshalledConfig)}
-
 	// We replace workflow_dispatch: {} with workflow_dispatch: for nicer readability.
-	ciConfig.Config = strings.ReplaceAll(ciConfig.Config, "workflow_dispatch: {}\n", "workflow_dispatch:\n")
+	return strings.ReplaceAll(string(marshalledConfig), "workflow_dispatch: {}\n", "workflow_dispatch:\n"), nil
+}
+
+// dockerScript returns the shell commands that log in to the registry, and build and push the image.
+// The sha is a shell expression that evaluates to the commit SHA, and is only used with the sha tag strategy.
+func dockerScript(params *ciConfigParams, sha string) []string {
+	build := fmt.Sprintf("docker build -t %s", shellQuote(params.image))
+	if params.sha {
+		build += fmt.Sprintf(" -t %s:\"%s\"", shellQuote(params.image), sha)
+	}
+
+	return []string{
+		fmt.Sprintf("echo %s | docker login %s -u %s --password-stdin", shellQuote(params.password), shellQuote(params.registry), shellQuote(params.username)),
+		build + " .",
+		fmt.Sprintf("docker push --all-tags %s", shellQuote(params.image)),
+	}
+}
 
-	return &ciConfig, nil
+// shellQuote quotes a string for use as a single shell word.
+// Robot account names contain a $, so they must never be expanded by the shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
@@ -0,0 +1,64 @@
+package deployments
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestCiConfigFormats(t *testing.T) {
+	params := &ciConfigParams{
+		registry: "registry.example.com",
+		username: "robot$deploy-user+app",
+		password: "secret",
+		image:    "registry.example.com/deploy-user/app",
+		branch:   "dev",
+		sha:      true,
+	}
+
+	github, err := githubCiConfig(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gitlab, err := gitlabCiConfig(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forgejo, err := forgejoCiConfig(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, config := range []string{github.Config, gitlab.Config, forgejo.Config} {
+		var parsed map[string]interface{}
+		if err = yaml.Unmarshal([]byte(config), &parsed); err != nil {
+			t.Errorf("config is not valid yaml: %v\n%s", err, config)
+		}
+
+		if !strings.Contains(config, "dev") {
+			t.Errorf("expected config to be triggered by branch dev:\n%s", config)
+		}
+	}
+
+	if !strings.Contains(github.Config, "registry.example.com/deploy-user/app:${{ github.sha }}") {
+		t.Errorf("expected github config to tag the image with the commit sha:\n%s", github.Config)
+	}
+
+	if !strings.Contains(gitlab.Config, "$CI_COMMIT_SHA") {
+		t.Errorf("expected gitlab config to tag the image with the commit sha:\n%s", gitlab.Config)
+	}
+
+	shell := shellCiConfig(params)
+	if !strings.Contains(shell.Config, "-u 'robot$deploy-user+app'") {
+		t.Errorf("expected the robot name to be quoted in the shell script:\n%s", shell.Config)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if quoted := shellQuote("it's"); quoted != `'it'\''s'` {
+		t.Errorf("unexpected quoting: %s", quoted)
+	}
+}
@@ -0,0 +1,199 @@
+package deployments
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/models/version"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_repo"
+	"github.com/kthcloud/go-deploy/pkg/log"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	jobOpts "github.com/kthcloud/go-deploy/service/v2/jobs/opts"
+)
+
+// DoCommand executes a command on the deployment.
+//
+// Rollbacks and builds are not handled here, since they are done by an update job and a build job.
+func (c *Client) DoCommand(id string, params *body.DeploymentCommand) error {
+	switch params.Command {
+	case "restart":
+		return c.Restart(id)
+	case "restartPod":
+		return c.RestartPod(id, *params.Pod)
+	case "pause":
+		return c.Pause(id)
+	case "resume":
+		return c.Resume(id)
+	case "scale":
+		return c.Scale(id, *params.Replicas, time.Duration(*params.DurationMinutes)*time.Minute)
+	case "promote":
+		return c.Promote(id)
+	case "abort":
+		return c.Abort(id)
+	}
+
+	return fmt.Errorf("unknown deployment command %s", params.Command)
+}
+
+// RestartPod restarts a single pod of the deployment.
+//
+// It returns sErrors.ErrPodNotFound if the pod does not belong to the deployment.
+func (c *Client) RestartPod(id, podName string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to restart pod %s in deployment %s. details: %w", podName, id, err)
+	}
+
+	d, err := c.Get(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if d == nil {
+		return sErrors.ErrDeploymentNotFound
+	}
+
+	c.addCommandLog(id, fmt.Sprintf("Restart of pod %s requested", podName))
+
+	err = c.K8s().RestartPod(id, podName)
+	if err != nil {
+		return makeError(err)
+	}
+
+	return nil
+}
+
+// Pause pauses the deployment.
+//
+// A paused deployment runs no replicas, but its configured replicas are kept so that it can be resumed.
+func (c *Client) Pause(id string) error {
+	return c.setPaused(id, true)
+}
+
+// Resume resumes a paused deployment.
+func (c *Client) Resume(id string) error {
+	return c.setPaused(id, false)
+}
+
+// Scale temporarily scales the main app of the deployment to the given number of replicas.
+//
+// The configured replicas are restored by a scheduled job once the duration has passed.
+// Scaling again before that replaces the current override.
+func (c *Client) Scale(id string, replicas int, duration time.Duration) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to scale deployment %s. details: %w", id, err)
+	}
+
+	d, err := c.Get(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if d == nil {
+		return sErrors.ErrDeploymentNotFound
+	}
+
+	expiresAt := time.Now().Add(duration)
+
+	err = deployment_repo.New().SetScaleOverride(id, &model.ScaleOverride{
+		Replicas:  replicas,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return makeError(err)
+	}
+
+	c.addCommandLog(id, fmt.Sprintf("Scale to %d replicas until %s requested", replicas, expiresAt.Format(time.RFC3339)))
+
+	err = c.K8s().Repair(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	err = c.V2.Jobs().Create(uuid.New().String(), d.OwnerID, model.JobResetDeploymentScale, version.V2, map[string]interface{}{
+		"id": id,
+	}, jobOpts.CreateOpts{RunAfter: &expiresAt})
+	if err != nil {
+		return makeError(err)
+	}
+
+	return nil
+}
+
+// ResetScale restores the configured replicas of the deployment if its scale override has expired.
+//
+// If the override was replaced by a later scale, nothing is done.
+func (c *Client) ResetScale(id string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to reset scale of deployment %s. details: %w", id, err)
+	}
+
+	cleared, err := deployment_repo.New().ClearExpiredScaleOverride(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if !cleared {
+		return nil
+	}
+
+	log.Println("Scale override of deployment", id, "expired. Restoring configured replicas")
+
+	err = c.K8s().Repair(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	return nil
+}
+
+// setPaused pauses or resumes the deployment and applies the change to the K8s setup.
+func (c *Client) setPaused(id string, paused bool) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to set paused to %t for deployment %s. details: %w", paused, id, err)
+	}
+
+	d, err := c.Get(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if d == nil {
+		return sErrors.ErrDeploymentNotFound
+	}
+
+	if d.Paused == paused {
+		return nil
+	}
+
+	err = deployment_repo.New().SetPaused(id, paused)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if paused {
+		c.addCommandLog(id, "Pause requested")
+	} else {
+		c.addCommandLog(id, "Resume requested")
+	}
+
+	err = c.K8s().Repair(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	return nil
+}
+
+// addCommandLog adds a log line about a command to the deployment.
+func (c *Client) addCommandLog(id, line string) {
+	c.AddLogs(id, model.Log{
+		Source: model.LogSourceDeployment,
+		Prefix: "[deployment]",
+		// Since this is sent as a string, and not a JSON object, we need to prepend the createdAt
+		Line:      fmt.Sprintf("%s %s", time.Now().Format(time.RFC3339), line),
+		CreatedAt: time.Now(),
+	})
+}
@@ -3,14 +3,18 @@ package deployments
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/kthcloud/go-deploy/dto/v2/body"
 	configModels "github.com/kthcloud/go-deploy/models/config"
 	"github.com/kthcloud/go-deploy/models/model"
 	"github.com/kthcloud/go-deploy/pkg/config"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_log_repo"
 	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_repo"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_revision_repo"
 	rErrors "github.com/kthcloud/go-deploy/pkg/db/resources/errors"
 	"github.com/kthcloud/go-deploy/pkg/db/resources/notification_repo"
 	"github.com/kthcloud/go-deploy/pkg/db/resources/resource_migration_repo"
@@ -20,6 +24,7 @@ import (
 	sUtils "github.com/kthcloud/go-deploy/service/utils"
 	"github.com/kthcloud/go-deploy/service/v2/deployments/opts"
 	"github.com/kthcloud/go-deploy/utils"
+	"github.com/kthcloud/go-deploy/utils/cryptoutils"
 	"github.com/kthcloud/go-deploy/utils/subsystemutils"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -244,6 +249,16 @@ func (c *Client) Create(id, ownerID string, deploymentCreate *body.DeploymentCre
 	fallbackImage := createImagePath(ownerID, deploymentCreate.Name)
 	fallbackPort := config.Config.Deployment.Port
 
+	envLists := [][]body.Env{deploymentCreate.Envs}
+	for _, sidecar := range deploymentCreate.Sidecars {
+		envLists = append(envLists, sidecar.Envs)
+	}
+
+	err := c.EncryptSecretEnvs(envLists...)
+	if err != nil {
+		return makeError(err)
+	}
+
 	params := &model.DeploymentCreateParams{}
 	params.FromDTO(deploymentCreate, fallbackZone, fallbackImage, fallbackPort)
 
@@ -255,6 +270,8 @@ func (c *Client) Create(id, ownerID string, deploymentCreate *body.DeploymentCre
 		params.RAM = config.Config.Deployment.Resources.Limits.RAM
 	}
 
+	setSidecarDefaults(params.Sidecars)
+
 	if !c.V2.System().ZoneHasCapability(params.Zone, configModels.ZoneCapabilityDeployment) {
 		return sErrors.NewZoneCapabilityMissingError(params.Zone, configModels.ZoneCapabilityDeployment)
 	}
@@ -285,7 +302,7 @@ func (c *Client) Create(id, ownerID string, deploymentCreate *body.DeploymentCre
 		return makeError(fmt.Errorf("deployment already exists for another user"))
 	}
 
-	if deployment.Type == model.DeploymentTypeCustom {
+	if deployment.HasOwnImage() {
 		err = c.Harbor().Create(id, params)
 		if err != nil {
 			return makeError(err)
@@ -307,17 +324,37 @@ func (c *Client) Create(id, ownerID string, deploymentCreate *body.DeploymentCre
 		return makeError(err)
 	}
 
+	deployment, err = c.Refresh(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	// The deployment is already updated, so failing to record it is not a reason to run the job again
+	err = c.createRevision(deployment, "")
+	if err != nil {
+		utils.PrettyPrintError(fmt.Errorf("failed to create revision for deployment %s. details: %w", deployment.ID, err))
+	}
+
+	if deployment.Type == model.DeploymentTypeGit {
+		err = c.scheduleBuild(deployment)
+		if err != nil {
+			return makeError(err)
+		}
+	}
+
 	return nil
 }
 
 // Update updates an existing deployment.
 //
 // It returns an error if the deployment is not found.
-func (c *Client) Update(id string, dtoUpdate *body.DeploymentUpdate) error {
+func (c *Client) Update(id string, dtoUpdate *body.DeploymentUpdate, updateOpts ...opts.UpdateOpts) error {
 	makeError := func(err error) error {
 		return fmt.Errorf("failed to update deployment. details: %w", err)
 	}
 
+	o := sUtils.GetFirstOrDefault(updateOpts)
+
 	d, err := c.Get(id, opts.GetOpts{Shared: true})
 	if err != nil {
 		return makeError(err)
@@ -332,12 +369,45 @@ func (c *Client) Update(id string, dtoUpdate *body.DeploymentUpdate) error {
 		return makeError(sErrors.ErrMainAppNotFound)
 	}
 
+	var envLists [][]body.Env
+	if dtoUpdate.Envs != nil {
+		envLists = append(envLists, *dtoUpdate.Envs)
+	}
+	if dtoUpdate.Sidecars != nil {
+		for _, sidecar := range *dtoUpdate.Sidecars {
+			envLists = append(envLists, sidecar.Envs)
+		}
+	}
+
+	err = c.EncryptSecretEnvs(envLists...)
+	if err != nil {
+		return makeError(err)
+	}
+
 	params := &model.DeploymentUpdateParams{}
 	params.FromDTO(dtoUpdate, d.Type)
 
-	if params.Name != nil && d.Type == model.DeploymentTypeCustom {
+	if params.Envs != nil {
+		keepSecretEnvValues(*params.Envs, mainApp.Envs)
+	}
+
+	if params.Sidecars != nil {
+		setSidecarDefaults(*params.Sidecars)
+
+		for _, sidecar := range *params.Sidecars {
+			keepSecretEnvValues(sidecar.Envs, d.Apps[sidecar.Name].Envs)
+		}
+	}
+
+	if params.Name != nil && d.HasOwnImage() {
 		image := createImagePath(d.OwnerID, *params.Name)
 		params.Image = &image
+	} else if dtoUpdate.Image != nil && d.HasOwnImage() {
+		// Custom deployments can only be pinned to a build in their own repository, which is done when rolling back
+		repository := createImagePath(d.OwnerID, d.Name)
+		if *dtoUpdate.Image == repository || strings.HasPrefix(*dtoUpdate.Image, repository+"@") {
+			params.Image = dtoUpdate.Image
+		}
 	}
 
 	// Don't update the custom domain secret if the update contains the same domain
@@ -345,6 +415,11 @@ func (c *Client) Update(id string, dtoUpdate *body.DeploymentUpdate) error {
 		params.CustomDomain = nil
 	}
 
+	// Removing the schedule ends the active window, so the replicas from before it are restored
+	if params.Schedule != nil && len(params.Schedule.Windows) == 0 && params.Replicas == nil && d.Schedule != nil && d.Schedule.ScaledFrom != nil {
+		params.Replicas = d.Schedule.ScaledFrom
+	}
+
 	err = deployment_repo.New().UpdateWithParams(id, params)
 	if err != nil {
 		if errors.Is(err, rErrors.ErrNonUniqueField) {
@@ -354,12 +429,19 @@ func (c *Client) Update(id string, dtoUpdate *body.DeploymentUpdate) error {
 		return makeError(err)
 	}
 
+	// The rollout is started once the update is stored, so that a failed update does not leave a rollout behind.
+	// d still holds the version from before the update, which the rollout keeps as the stable version.
+	err = c.startRollout(d, params)
+	if err != nil {
+		return makeError(err)
+	}
+
 	d, err = c.Refresh(id)
 	if err != nil {
 		return makeError(err)
 	}
 
-	if d.Type == model.DeploymentTypeCustom {
+	if d.HasOwnImage() {
 		err = c.Harbor().Update(id, params)
 		if err != nil {
 			return makeError(err)
@@ -376,6 +458,27 @@ func (c *Client) Update(id string, dtoUpdate *body.DeploymentUpdate) error {
 		return makeError(err)
 	}
 
+	d, err = c.Refresh(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if !o.NoRevision {
+		// The deployment is already updated, so failing to record it is not a reason to run the job again
+		err = c.createRevision(d, "")
+		if err != nil {
+			utils.PrettyPrintError(fmt.Errorf("failed to create revision for deployment %s. details: %w", d.ID, err))
+		}
+	}
+
+	// A new git source needs a new image, and so does a new name since the image is pushed to a repository named after it
+	if (params.Git != nil || params.Name != nil) && d.Type == model.DeploymentTypeGit {
+		err = c.scheduleBuild(d)
+		if err != nil {
+			return makeError(err)
+		}
+	}
+
 	return nil
 }
 
@@ -399,7 +502,7 @@ func (c *Client) UpdateOwner(id string, params *model.DeploymentUpdateOwnerParam
 	}
 
 	var newImage *string
-	if d.Type == model.DeploymentTypeCustom {
+	if d.HasOwnImage() {
 		image := createImagePath(params.NewOwnerID, d.Name)
 		newImage = &image
 	}
@@ -473,6 +576,16 @@ func (c *Client) Delete(id string) error {
 		return makeError(err)
 	}
 
+	err = deployment_revision_repo.New().WithDeploymentID(id).Erase()
+	if err != nil {
+		return makeError(err)
+	}
+
+	err = deployment_log_repo.New().WithDeploymentID(id).Erase()
+	if err != nil {
+		return makeError(err)
+	}
+
 	err = c.Harbor().Delete(id)
 	if err != nil {
 		return makeError(err)
@@ -598,24 +711,24 @@ func (c *Client) Restart(id string) error {
 func (c *Client) AddLogs(id string, logs ...model.Log) {
 	// logs are added best-effort, so we don't return an error here
 	go func() {
-		err := deployment_repo.New().AddLogsByName(id, logs...)
+		deployment, err := deployment_repo.New().GetByID(id)
 		if err != nil {
-			utils.PrettyPrintError(fmt.Errorf("failed to add logs to deployment %s. details: %w", id, err))
+			utils.PrettyPrintError(fmt.Errorf("failed to get deployment %s when adding logs. details: %w", id, err))
+			return
 		}
-	}()
-}
 
-// DoCommand executes a command on the deployment.
-//
-// It is purely done in best-effort
-func (c *Client) DoCommand(id string, command string) {
-	go func() {
-		switch command {
-		case "restart":
-			err := c.Restart(id)
-			if err != nil {
-				utils.PrettyPrintError(err)
-			}
+		if deployment == nil {
+			return
+		}
+
+		retention := model.DefaultLogRetention
+		if zone := config.Config.GetZone(deployment.Zone); zone != nil {
+			retention = zone.LogRetention()
+		}
+
+		err = deployment_log_repo.New().Create(id, deployment.Zone, retention, logs...)
+		if err != nil {
+			utils.PrettyPrintError(fmt.Errorf("failed to add logs to deployment %s. details: %w", id, err))
 		}
 	}()
 }
@@ -653,6 +766,8 @@ func (c *Client) CheckQuota(id string, opts *opts.QuotaOptions) error {
 			replicas = 1
 		}
 
+		replicas = replicaLimit(replicas, opts.Create.Autoscaling, opts.Create.Schedule)
+
 		if opts.Create.CpuCores != nil {
 			cpu = usage.CpuCores + *opts.Create.CpuCores*float64(replicas)
 		} else {
@@ -669,6 +784,10 @@ func (c *Client) CheckQuota(id string, opts *opts.QuotaOptions) error {
 			gpus = usage.Gpus + len(opts.Create.GPUs)
 		}
 
+		sidecarCpu, sidecarRam := sidecarUsage(opts.Create.Sidecars)
+		cpu += sidecarCpu
+		ram += sidecarRam
+
 		if cpu > quota.CpuCores {
 			return sErrors.NewQuotaExceededError(fmt.Sprintf("CPU quota exceeded. Current: %.1f, Quota: %.1f", cpu, quota.CpuCores))
 		}
@@ -692,20 +811,55 @@ func (c *Client) CheckQuota(id string, opts *opts.QuotaOptions) error {
 			return sErrors.ErrDeploymentNotFound
 		}
 
-		replicasBefore := deployment.GetMainApp().Replicas
-		cpuBefore := deployment.GetMainApp().CpuCores * float64(replicasBefore)
-		ramBefore := deployment.GetMainApp().RAM * float64(replicasBefore)
-		gpusBefore := len(deployment.GetMainApp().GPUs) * replicasBefore
+		// The usage before includes an active rollout, since it is counted in the usage of the user
+		mainUsageBefore := deployment.GetMainUsage()
+		cpuBefore := mainUsageBefore.CpuCores
+		ramBefore := mainUsageBefore.RAM
+		gpusBefore := mainUsageBefore.Gpus
+
+		var sidecarCpuBefore, sidecarRamBefore float64
+		for _, sidecar := range deployment.GetSidecarApps() {
+			sidecarCpuBefore += sidecar.CpuCores * float64(sidecar.Replicas)
+			sidecarRamBefore += sidecar.RAM * float64(sidecar.Replicas)
+		}
+
+		sidecarCpuAfter, sidecarRamAfter := sidecarCpuBefore, sidecarRamBefore
+		if opts.Update.Sidecars != nil {
+			sidecarCpuAfter, sidecarRamAfter = sidecarUsage(*opts.Update.Sidecars)
+		}
 
 		var replicasAfter int
 		var cpuAfter float64
 		var ramAfter float64
 		var gpusAfter int
 
+		mainAppAfter := *deployment.GetMainApp()
 		if opts.Update.Replicas != nil {
-			replicasAfter = *opts.Update.Replicas
-		} else {
-			replicasAfter = replicasBefore
+			mainAppAfter.Replicas = *opts.Update.Replicas
+		}
+
+		if opts.Update.Autoscaling != nil {
+			mainAppAfter.Autoscaling = &model.DeploymentAutoscaling{}
+			mainAppAfter.Autoscaling.FromDTO(opts.Update.Autoscaling)
+		}
+
+		scheduleAfter := deployment.Schedule
+		if opts.Update.Schedule != nil {
+			scheduleAfter = nil
+			if len(opts.Update.Schedule.Windows) > 0 {
+				scheduleAfter = &model.ScalingSchedule{}
+				scheduleAfter.FromDTO(opts.Update.Schedule)
+			} else if opts.Update.Replicas == nil && deployment.Schedule != nil && deployment.Schedule.ScaledFrom != nil {
+				// Removing the schedule restores the replicas from before the active window
+				mainAppAfter.Replicas = *deployment.Schedule.ScaledFrom
+			}
+		}
+
+		replicasAfter = mainAppAfter.GetPeakReplicaLimit(scheduleAfter)
+		if opts.ScaleOverride != nil {
+			replicasAfter = max(replicasAfter, *opts.ScaleOverride)
+		} else if deployment.ScaleOverride != nil {
+			replicasAfter = max(replicasAfter, deployment.ScaleOverride.Replicas)
 		}
 
 		if opts.Update.CpuCores != nil {
@@ -726,6 +880,9 @@ func (c *Client) CheckQuota(id string, opts *opts.QuotaOptions) error {
 			gpusAfter = usage.Gpus + len(deployment.GetMainApp().GPUs)*replicasAfter - gpusBefore
 		}
 
+		cpuAfter += sidecarCpuAfter - sidecarCpuBefore
+		ramAfter += sidecarRamAfter - sidecarRamBefore
+
 		if cpuAfter > quota.CpuCores {
 			return sErrors.NewQuotaExceededError(fmt.Sprintf("CPU quota exceeded. Current: %.1f, Quota: %.1f", cpuAfter, quota.CpuCores))
 		}
@@ -820,6 +977,112 @@ func (c *Client) markAccessedIfOwner(deployment *model.Deployment, drc *deployme
 	}
 }
 
+// EncryptSecretEnvs encrypts the values of all secret envs in place.
+//
+// It should be called before the envs are persisted anywhere, such as in job params,
+// to ensure that secret values are never stored in plaintext.
+// Envs already marked as encrypted are skipped, so it is safe to call more than once.
+func (c *Client) EncryptSecretEnvs(envLists ...[]body.Env) error {
+	var key []byte
+	for _, envs := range envLists {
+		for i := range envs {
+			if !envs[i].Secret || envs[i].Value == "" || envs[i].Encrypted {
+				continue
+			}
+
+			if key == nil {
+				var err error
+				key, err = config.Config.Deployment.GetEnvEncryptionKey()
+				if err != nil {
+					return fmt.Errorf("failed to encrypt secret envs. details: %w", err)
+				}
+			}
+
+			encrypted, err := cryptoutils.Encrypt(key, envs[i].Value)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt secret env %s. details: %w", envs[i].Name, err)
+			}
+
+			envs[i].Value = encrypted
+			envs[i].Encrypted = true
+		}
+	}
+
+	return nil
+}
+
+// keepSecretEnvValues sets the value of secret envs without a value to the value of the
+// secret env with the same name in current, if any.
+func keepSecretEnvValues(envs []model.DeploymentEnv, current []model.DeploymentEnv) {
+	for i := range envs {
+		if !envs[i].Secret || envs[i].Value != "" {
+			continue
+		}
+
+		idx := slices.IndexFunc(current, func(env model.DeploymentEnv) bool { return env.Secret && env.Name == envs[i].Name })
+		if idx != -1 {
+			envs[i].Value = current[idx].Value
+		}
+	}
+}
+
+// replicaLimit returns the highest number of replicas a deployment with the given settings can run,
+// including during the windows of its scaling schedule.
+func replicaLimit(replicas int, autoscaling *body.Autoscaling, schedule *body.ScalingSchedule) int {
+	app := model.App{Replicas: replicas}
+	if autoscaling != nil {
+		app.Autoscaling = &model.DeploymentAutoscaling{}
+		app.Autoscaling.FromDTO(autoscaling)
+	}
+
+	if schedule == nil {
+		return app.GetReplicaLimit()
+	}
+
+	scalingSchedule := &model.ScalingSchedule{}
+	scalingSchedule.FromDTO(schedule)
+	return app.GetPeakReplicaLimit(scalingSchedule)
+}
+
+// sidecarUsage returns the total CPU cores and RAM requested by the sidecars.
+// Sidecars without CPU cores or RAM specified are counted with the default limits.
+func sidecarUsage(sidecars []body.Sidecar) (float64, float64) {
+	var cpu, ram float64
+	for _, sidecar := range sidecars {
+		replicas := 1
+		if sidecar.Replicas != nil {
+			replicas = *sidecar.Replicas
+		}
+
+		if sidecar.CpuCores != nil {
+			cpu += *sidecar.CpuCores * float64(replicas)
+		} else {
+			cpu += config.Config.Deployment.Resources.Limits.CPU * float64(replicas)
+		}
+
+		if sidecar.RAM != nil {
+			ram += *sidecar.RAM * float64(replicas)
+		} else {
+			ram += config.Config.Deployment.Resources.Limits.RAM * float64(replicas)
+		}
+	}
+
+	return cpu, ram
+}
+
+// setSidecarDefaults sets the default CPU cores and RAM for sidecars that did not specify them.
+func setSidecarDefaults(sidecars []model.DeploymentSidecarParams) {
+	for i := range sidecars {
+		if sidecars[i].CpuCores == 0 {
+			sidecars[i].CpuCores = config.Config.Deployment.Resources.Limits.CPU
+		}
+
+		if sidecars[i].RAM == 0 {
+			sidecars[i].RAM = config.Config.Deployment.Resources.Limits.RAM
+		}
+	}
+}
+
 // createImagePath creates a complete container image path that can be pulled from.
 func createImagePath(ownerID, name string) string {
 	return fmt.Sprintf("%s/%s/%s", config.Config.Registry.URL, subsystemutils.GetPrefixedName(ownerID), name)
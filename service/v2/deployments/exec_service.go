@@ -0,0 +1,104 @@
+package deployments
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/config"
+	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s"
+	k8sModels "github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	"github.com/kthcloud/go-deploy/service/v2/deployments/opts"
+)
+
+// defaultShell is the command used when no command is given.
+// It prefers bash, but falls back to sh since many images do not ship bash.
+var defaultShell = []string{"/bin/sh", "-c", "TERM=xterm-256color; export TERM; [ -x /bin/bash ] && exec /bin/bash || exec /bin/sh"}
+
+// Exec executes a command in one of the deployment's pods and streams its input and output.
+//
+// The same access rules as Get apply, meaning owners, team members and admins can exec.
+// Every session is recorded as an audit event before it is started.
+// It blocks until the command exits or the context is cancelled.
+func (c *Client) Exec(ctx context.Context, id string, execOpts *opts.ExecOpts) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to exec in deployment %s. details: %w", id, err)
+	}
+
+	deployment, err := c.Get(id, opts.GetOpts{Shared: true})
+	if err != nil {
+		return makeError(err)
+	}
+
+	if deployment == nil || deployment.BeingDeleted() {
+		return sErrors.ErrDeploymentNotFound
+	}
+
+	zone := config.Config.GetZone(deployment.Zone)
+	if zone == nil {
+		return makeError(sErrors.ErrZoneNotFound)
+	}
+
+	pods, err := c.K8s().DeploymentPods(zone, deployment.Name)
+	if err != nil {
+		return makeError(err)
+	}
+
+	podName := execOpts.Pod
+	if podName == "" {
+		if len(pods) == 0 {
+			return sErrors.ErrPodNotFound
+		}
+
+		podName = pods[0].Name
+	} else if !slices.ContainsFunc(pods, func(p k8sModels.PodPublic) bool { return p.Name == podName }) {
+		return sErrors.ErrPodNotFound
+	}
+
+	command := execOpts.Command
+	if len(command) == 0 {
+		command = defaultShell
+	}
+
+	source := &model.Source{}
+	if c.V2.HasAuth() {
+		source.UserID = &c.V2.Auth().User.ID
+	}
+	if execOpts.ClientIP != "" {
+		source.IP = &execOpts.ClientIP
+	}
+
+	err = c.V2.Events().Create(uuid.New().String(), &model.EventCreateParams{
+		Type:   model.TypeDeploymentExec,
+		Source: source,
+		Metadata: map[string]interface{}{
+			"deploymentId": deployment.ID,
+			"zone":         deployment.Zone,
+			"pod":          podName,
+			"container":    execOpts.Container,
+			"command":      command,
+			"tty":          execOpts.TTY,
+		},
+	})
+	if err != nil {
+		return makeError(err)
+	}
+
+	err = c.K8s().Exec(ctx, zone, podName, &k8s.ExecOpts{
+		Container: execOpts.Container,
+		Command:   command,
+		TTY:       execOpts.TTY,
+		Stdin:     execOpts.Stdin,
+		Stdout:    execOpts.Stdout,
+		Stderr:    execOpts.Stderr,
+		Resize:    execOpts.Resize,
+	})
+	if err != nil {
+		return makeError(err)
+	}
+
+	return nil
+}
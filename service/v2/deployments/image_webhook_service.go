@@ -0,0 +1,302 @@
+package deployments
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/models/version"
+	"github.com/kthcloud/go-deploy/pkg/config"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_repo"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	jobOpts "github.com/kthcloud/go-deploy/service/v2/jobs/opts"
+	"github.com/kthcloud/go-deploy/utils"
+	"github.com/kthcloud/go-deploy/utils/cryptoutils"
+)
+
+// CreateImageWebhook creates the image webhook of a prebuilt deployment.
+// If the deployment already has a webhook, it is replaced, which invalidates its URL and secret.
+//
+// The returned URL and secret are not stored in plain text, and cannot be fetched again.
+// A secret is only created if the webhook requires signed payloads.
+// It returns sErrors.ErrDeploymentHasOwnImage if the deployment is not a prebuilt deployment.
+func (c *Client) CreateImageWebhook(id string, dtoCreate *body.ImageWebhookCreate) (*body.ImageWebhookCreated, error) {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to create image webhook for deployment %s. details: %w", id, err)
+	}
+
+	d, err := c.Get(id)
+	if err != nil {
+		return nil, makeError(err)
+	}
+
+	if d == nil {
+		return nil, sErrors.ErrDeploymentNotFound
+	}
+
+	if d.HasOwnImage() {
+		return nil, sErrors.ErrDeploymentHasOwnImage
+	}
+
+	token, err := generateWebhookKey()
+	if err != nil {
+		return nil, makeError(err)
+	}
+
+	var secret, encryptedSecret string
+	if dtoCreate.RequireSignature {
+		secret, err = generateWebhookKey()
+		if err != nil {
+			return nil, makeError(err)
+		}
+
+		key, err := config.Config.Deployment.GetEnvEncryptionKey()
+		if err != nil {
+			return nil, makeError(err)
+		}
+
+		encryptedSecret, err = cryptoutils.Encrypt(key, secret)
+		if err != nil {
+			return nil, makeError(err)
+		}
+	}
+
+	imageWebhook := &model.ImageWebhook{
+		TokenHash:        utils.HashString(token),
+		Secret:           encryptedSecret,
+		RequireSignature: dtoCreate.RequireSignature,
+		UpdateTag:        dtoCreate.UpdateTag,
+		CreatedAt:        time.Now(),
+	}
+
+	err = deployment_repo.New().SetImageWebhook(id, imageWebhook)
+	if err != nil {
+		return nil, makeError(err)
+	}
+
+	return &body.ImageWebhookCreated{
+		URL:              fmt.Sprintf("%s/v2/hooks/images/%s?token=%s", config.Config.ExternalUrl, id, token),
+		Secret:           secret,
+		RequireSignature: imageWebhook.RequireSignature,
+		UpdateTag:        imageWebhook.UpdateTag,
+		CreatedAt:        imageWebhook.CreatedAt,
+	}, nil
+}
+
+// DeleteImageWebhook deletes the image webhook of a deployment.
+func (c *Client) DeleteImageWebhook(id string) error {
+	err := deployment_repo.New().SetImageWebhook(id, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete image webhook for deployment %s. details: %w", id, err)
+	}
+
+	return nil
+}
+
+// HandleImageWebhook handles a payload sent to the image webhook of a deployment.
+//
+// The payload is verified using the token in the webhook URL, and the HMAC-SHA256 signature if the webhook has a secret.
+// If the pushed tag is the tag the deployment runs, the deployment is restarted.
+// If another tag is pushed and the webhook updates tags, an update job is created to change the image tag.
+func (c *Client) HandleImageWebhook(id, token, signature string, payload []byte) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to handle image webhook for deployment %s. details: %w", id, err)
+	}
+
+	d, err := c.Get(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if d == nil {
+		return sErrors.ErrDeploymentNotFound
+	}
+
+	if d.ImageWebhook == nil {
+		return sErrors.ErrImageWebhookNotFound
+	}
+
+	if subtle.ConstantTimeCompare([]byte(utils.HashString(token)), []byte(d.ImageWebhook.TokenHash)) != 1 {
+		return sErrors.ErrInvalidWebhookToken
+	}
+
+	if d.ImageWebhook.RequireSignature || (signature != "" && d.ImageWebhook.Secret != "") {
+		valid, err := validWebhookSignature(d.ImageWebhook.Secret, signature, payload)
+		if err != nil {
+			return makeError(err)
+		}
+
+		if !valid {
+			return sErrors.ErrInvalidWebhookSignature
+		}
+	}
+
+	var webhook body.ImageWebhook
+	err = json.Unmarshal(payload, &webhook)
+	if err != nil {
+		return sErrors.ErrInvalidWebhookPayload
+	}
+
+	repository, tag, source := pushedImage(&webhook)
+	if repository == "" || tag == "" {
+		return sErrors.ErrInvalidWebhookPayload
+	}
+
+	mainApp := d.GetMainApp()
+
+	currentRepository, currentTag := splitImage(mainApp.Image)
+	if currentTag == "" && !strings.Contains(mainApp.Image, "@") {
+		currentTag = "latest"
+	}
+
+	if normalizeRepository(repository) != normalizeRepository(currentRepository) {
+		return sErrors.ErrImageMismatch
+	}
+
+	switch {
+	case tag == currentTag:
+		c.addCommandLog(id, fmt.Sprintf("Received push of %s:%s from %s", repository, tag, source))
+
+		// Auto-updated images are pinned to a digest, so a restart would not pull the pushed image
+		if mainApp.AutoUpdate != nil {
+			err = c.CheckImageUpdate(id)
+		} else {
+			err = c.Restart(id)
+		}
+		if err != nil {
+			return makeError(err)
+		}
+	case d.ImageWebhook.UpdateTag:
+		image := fmt.Sprintf("%s:%s", currentRepository, tag)
+		c.addCommandLog(id, fmt.Sprintf("Received push of %s:%s from %s. Updating image to %s", repository, tag, source, image))
+
+		err = c.V2.Jobs().Create(uuid.New().String(), d.OwnerID, model.JobUpdateDeployment, version.V2, map[string]interface{}{
+			"id":     id,
+			"params": body.DeploymentUpdate{Image: &image},
+		}, jobOpts.CreateOpts{})
+		if err != nil {
+			return makeError(err)
+		}
+	default:
+		c.addCommandLog(id, fmt.Sprintf("Ignored push of %s:%s from %s, since the deployment runs another tag", repository, tag, source))
+	}
+
+	return nil
+}
+
+// validWebhookSignature checks the HMAC-SHA256 signature of a webhook payload.
+// The signature is hex encoded and can be prefixed with sha256=, as done by GitHub.
+func validWebhookSignature(encryptedSecret, signature string, payload []byte) (bool, error) {
+	if signature == "" {
+		return false, nil
+	}
+
+	key, err := config.Config.Deployment.GetEnvEncryptionKey()
+	if err != nil {
+		return false, err
+	}
+
+	secret, err := cryptoutils.Decrypt(key, encryptedSecret)
+	if err != nil {
+		return false, err
+	}
+
+	signatureBytes, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false, nil
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return hmac.Equal(signatureBytes, mac.Sum(nil)), nil
+}
+
+// pushedImage returns the repository and tag of the pushed image in a webhook payload, and the name of its source.
+// If the payload is not recognized, empty strings are returned.
+func pushedImage(webhook *body.ImageWebhook) (string, string, string) {
+	switch {
+	case webhook.PushData != nil && webhook.Repository != nil && webhook.Repository.RepoName != "":
+		return webhook.Repository.RepoName, webhook.PushData.Tag, "Docker Hub"
+	case webhook.Package != nil || webhook.RegistryPackage != nil:
+		githubPackage := webhook.Package
+		if githubPackage == nil {
+			githubPackage = webhook.RegistryPackage
+		}
+
+		if githubPackage.PackageVersion == nil {
+			return "", "", ""
+		}
+
+		repository, tag := splitImage(githubPackage.PackageVersion.PackageURL)
+		if metadata := githubPackage.PackageVersion.ContainerMetadata; metadata != nil && metadata.Tag.Name != "" {
+			tag = metadata.Tag.Name
+		}
+
+		return repository, tag, "GHCR"
+	case webhook.Image != "":
+		repository, tag := splitImage(webhook.Image)
+		if webhook.Tag != "" {
+			tag = webhook.Tag
+		}
+
+		return repository, tag, "webhook"
+	}
+
+	return "", "", ""
+}
+
+// splitImage splits an image reference into its repository and tag.
+// The digest of the image, if any, is dropped, and the tag is empty if the reference has none.
+func splitImage(image string) (string, string) {
+	if idx := strings.Index(image, "@"); idx != -1 {
+		image = image[:idx]
+	}
+
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		return image[:idx], image[idx+1:]
+	}
+
+	return image, ""
+}
+
+// normalizeRepository returns the fully qualified name of a repository,
+// so that, for example, nginx and docker.io/library/nginx are the same repository.
+func normalizeRepository(repository string) string {
+	repository = strings.ToLower(repository)
+
+	registry, path, found := strings.Cut(repository, "/")
+	if !found || (!strings.ContainsAny(registry, ".:") && registry != "localhost") {
+		registry, path = "docker.io", repository
+	}
+
+	if registry == "index.docker.io" || registry == "registry-1.docker.io" {
+		registry = "docker.io"
+	}
+
+	if registry == "docker.io" && !strings.Contains(path, "/") {
+		path = "library/" + path
+	}
+
+	return registry + "/" + path
+}
+
+// generateWebhookKey generates a random key for an image webhook.
+func generateWebhookKey() (string, error) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(key), nil
+}
@@ -0,0 +1,65 @@
+package deployments
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+)
+
+func TestNormalizeRepository(t *testing.T) {
+	tests := map[string]string{
+		"nginx":                         "docker.io/library/nginx",
+		"library/nginx":                 "docker.io/library/nginx",
+		"docker.io/library/nginx":       "docker.io/library/nginx",
+		"index.docker.io/kthcloud/app":  "docker.io/kthcloud/app",
+		"ghcr.io/KTHcloud/app":          "ghcr.io/kthcloud/app",
+		"localhost:5000/app":            "localhost:5000/app",
+		"registry.example.com/team/app": "registry.example.com/team/app",
+	}
+
+	for repository, expected := range tests {
+		if normalized := normalizeRepository(repository); normalized != expected {
+			t.Errorf("normalizeRepository(%s) = %s, expected %s", repository, normalized, expected)
+		}
+	}
+}
+
+func TestSplitImage(t *testing.T) {
+	tests := map[string][2]string{
+		"nginx":                         {"nginx", ""},
+		"nginx:1.27":                    {"nginx", "1.27"},
+		"localhost:5000/app":            {"localhost:5000/app", ""},
+		"localhost:5000/app:v2":         {"localhost:5000/app", "v2"},
+		"ghcr.io/kthcloud/app@sha256:0": {"ghcr.io/kthcloud/app", ""},
+	}
+
+	for image, expected := range tests {
+		repository, tag := splitImage(image)
+		if repository != expected[0] || tag != expected[1] {
+			t.Errorf("splitImage(%s) = (%s, %s), expected (%s, %s)", image, repository, tag, expected[0], expected[1])
+		}
+	}
+}
+
+func TestPushedImage(t *testing.T) {
+	payloads := map[string][3]string{
+		`{"push_data":{"tag":"v2"},"repository":{"repo_name":"kthcloud/app"}}`:                                                                                                 {"kthcloud/app", "v2", "Docker Hub"},
+		`{"action":"published","package":{"package_type":"container","package_version":{"package_url":"ghcr.io/kthcloud/app:v2","container_metadata":{"tag":{"name":"v2"}}}}}`: {"ghcr.io/kthcloud/app", "v2", "GHCR"},
+		`{"image":"ghcr.io/kthcloud/app:v2"}`:         {"ghcr.io/kthcloud/app", "v2", "webhook"},
+		`{"image":"ghcr.io/kthcloud/app","tag":"v3"}`: {"ghcr.io/kthcloud/app", "v3", "webhook"},
+		`{"repository":{"full_name":"kthcloud/app"}}`: {"", "", ""},
+	}
+
+	for payload, expected := range payloads {
+		var webhook body.ImageWebhook
+		if err := json.Unmarshal([]byte(payload), &webhook); err != nil {
+			t.Fatal(err)
+		}
+
+		repository, tag, source := pushedImage(&webhook)
+		if repository != expected[0] || tag != expected[1] || source != expected[2] {
+			t.Errorf("pushedImage(%s) = (%s, %s, %s), expected %v", payload, repository, tag, source, expected)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package k8s_service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/config"
+	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s"
+	k8sModels "github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	"github.com/kthcloud/go-deploy/service/v2/deployments/resources"
+	"github.com/kthcloud/go-deploy/utils"
+)
+
+// Build builds the git source of the deployment in a K8s job and pushes the image to the destination.
+//
+// The output of the build is sent to onLog as it is produced.
+// It returns sErrors.ErrBuildFailed if the build job fails.
+//
+// This function is blocking until the build job completes or the client's context is cancelled.
+func (c *Client) Build(id, destination string, onLog func(lines []model.Log)) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to build deployment %s in k8s. details: %w", id, err)
+	}
+
+	d, kc, _, err := c.Get(OptsNoGenerator(id))
+	if err != nil {
+		return makeError(err)
+	}
+
+	zone := config.Config.GetZone(d.Zone)
+	if zone == nil {
+		return makeError(sErrors.ErrZoneNotFound)
+	}
+
+	jobPublic := resources.K8s(d, zone, kc, getNamespaceName(zone)).BuildJob(destination)
+	if jobPublic == nil {
+		return sErrors.ErrDeploymentNotGit
+	}
+
+	_, err = kc.CreateJob(jobPublic)
+	if err != nil {
+		return makeError(err)
+	}
+
+	handler := func(k8sLines []k8sModels.LogLine) {
+		lines := make([]model.Log, 0, len(k8sLines))
+		for _, line := range k8sLines {
+			lines = append(lines, model.Log{
+				Source:    model.LogSourceBuild,
+				Prefix:    "[build]",
+				Line:      line.Line,
+				CreatedAt: line.CreatedAt,
+			})
+		}
+
+		onLog(lines)
+	}
+
+	err = kc.StreamJobLogs(c.ctx, jobPublic.Name, handler)
+	if err != nil {
+		// The job is only removed automatically once it has finished, which a job that never started does not
+		if deleteErr := kc.DeleteJob(jobPublic.Name); deleteErr != nil {
+			utils.PrettyPrintError(fmt.Errorf("failed to delete build job %s. details: %w", jobPublic.Name, deleteErr))
+		}
+
+		return makeError(err)
+	}
+
+	// The log stream ends when the container exits, but the job status might lag slightly behind
+	for {
+		status, err := kc.GetJobStatus(jobPublic.Name)
+		if err != nil {
+			return makeError(err)
+		}
+
+		switch status {
+		case k8s.JobStatusSucceeded:
+			return nil
+		case k8s.JobStatusFailed, "":
+			return sErrors.ErrBuildFailed
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return makeError(c.checkCancelled())
+		case <-time.After(1 * time.Second):
+		}
+	}
+}
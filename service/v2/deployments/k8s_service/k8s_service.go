@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 	"time"
 
 	configModels "github.com/kthcloud/go-deploy/models/config"
@@ -12,6 +13,7 @@ import (
 	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_repo"
 	"github.com/kthcloud/go-deploy/pkg/log"
 	"github.com/kthcloud/go-deploy/pkg/subsystems"
+	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s"
 	kErrors "github.com/kthcloud/go-deploy/pkg/subsystems/k8s/errors"
 	k8sModels "github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
 	"github.com/kthcloud/go-deploy/service/constants"
@@ -45,6 +47,10 @@ func (c *Client) Create(id string, params *model.DeploymentCreateParams) error {
 		return makeError(err)
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// PersistentVolume
 	for _, pvPublic := range g.PVs() {
 		err = resources.SsCreator(kc.CreatePV).
@@ -57,6 +63,10 @@ func (c *Client) Create(id string, params *model.DeploymentCreateParams) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// PersistentVolumeClaim
 	for _, pvcPublic := range g.PVCs() {
 		err = resources.SsCreator(kc.CreatePVC).
@@ -69,6 +79,10 @@ func (c *Client) Create(id string, params *model.DeploymentCreateParams) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// NetworkPolicies
 	for _, networkPolicyPublic := range g.NetworkPolicies() {
 		err = resources.SsCreator(kc.CreateNetworkPolicy).
@@ -81,10 +95,14 @@ func (c *Client) Create(id string, params *model.DeploymentCreateParams) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// Secret
 	for _, secretPublic := range g.Secrets() {
 		err = resources.SsCreator(kc.CreateSecret).
-			WithDbFunc(dbFunc(id, "secretMap."+secretPublic.Name)).
+			WithDbFunc(secretDbFunc(id, secretPublic.Name)).
 			WithPublic(&secretPublic).
 			Exec()
 
@@ -93,6 +111,10 @@ func (c *Client) Create(id string, params *model.DeploymentCreateParams) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// Deployment
 	for _, deploymentPublic := range g.Deployments() {
 		err = resources.SsCreator(kc.CreateDeployment).
@@ -105,6 +127,10 @@ func (c *Client) Create(id string, params *model.DeploymentCreateParams) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// Service
 	for _, servicePublic := range g.Services() {
 		err = resources.SsCreator(kc.CreateService).
@@ -117,6 +143,10 @@ func (c *Client) Create(id string, params *model.DeploymentCreateParams) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// Ingress
 	for _, ingressPublic := range g.Ingresses() {
 		err = resources.SsCreator(kc.CreateIngress).
@@ -133,6 +163,10 @@ func (c *Client) Create(id string, params *model.DeploymentCreateParams) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// HPA
 	for _, hpaPublic := range g.HPAs() {
 		err = resources.SsCreator(kc.CreateHPA).
@@ -145,6 +179,10 @@ func (c *Client) Create(id string, params *model.DeploymentCreateParams) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// OneShotJobs
 	for _, jobPublic := range g.OneShotJobs() {
 		err = kc.CreateOneShotJob(&jobPublic)
@@ -258,7 +296,7 @@ func (c *Client) Delete(id string, overwriteUserID ...string) error {
 
 		err := resources.SsDeleter(deleteFunc).
 			WithResourceID(secret.Name).
-			WithDbFunc(dbFunc(id, "secretMap."+mapName)).
+			WithDbFunc(secretDbFunc(id, mapName)).
 			Exec()
 
 		if err != nil {
@@ -349,6 +387,7 @@ func (c *Client) EnsureOwner(id string, oldOwnerID string) error {
 }
 
 // Restart restarts the deployment.
+// All sidecars of the deployment are restarted as well.
 func (c *Client) Restart(id string) error {
 	makeError := func(err error) error {
 		return fmt.Errorf("failed to restart k8s %s. details: %w", id, err)
@@ -368,6 +407,45 @@ func (c *Client) Restart(id string) error {
 		utils.PrettyPrintError(fmt.Errorf("k8s deployment %s not found when restarting, assuming it was deleted", d.Name))
 	}
 
+	for _, sidecar := range d.GetSidecarApps() {
+		name := constants.WithSidecarSuffix(d.Name, sidecar.Name)
+		if k8sDeployment := d.Subsystems.K8s.GetDeployment(name); subsystems.Created(k8sDeployment) {
+			err := kc.RestartDeployment(k8sDeployment.Name)
+			if err != nil {
+				return makeError(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RestartPod restarts a single pod of the deployment by deleting it.
+// The pod must belong to the deployment's main app.
+func (c *Client) RestartPod(id, podName string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to restart pod %s in k8s %s. details: %w", podName, id, err)
+	}
+
+	d, kc, _, err := c.Get(OptsNoGenerator(id))
+	if err != nil {
+		return makeError(err)
+	}
+
+	pods, err := kc.ListPodsByDeployName(d.Name)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if !slices.ContainsFunc(pods, func(p k8sModels.PodPublic) bool { return p.Name == podName }) {
+		return sErrors.ErrPodNotFound
+	}
+
+	err = kc.DeletePod(podName)
+	if err != nil {
+		return makeError(err)
+	}
+
 	return nil
 }
 
@@ -396,6 +474,10 @@ func (c *Client) Repair(id string) error {
 		return makeError(err)
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	networkPolicies := g.NetworkPolicies()
 	for mapName, networkPolicy := range d.Subsystems.K8s.GetNetworkPolicyMap() {
 		idx := slices.IndexFunc(networkPolicies, func(n k8sModels.NetworkPolicyPublic) bool { return n.Name == mapName })
@@ -423,6 +505,42 @@ func (c *Client) Repair(id string) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
+	// Secrets are repaired before deployments, since the deployments reference them
+	secrets := g.Secrets()
+	for mapName, secret := range d.Subsystems.K8s.GetSecretMap() {
+		idx := slices.IndexFunc(secrets, func(s k8sModels.SecretPublic) bool { return s.Name == mapName })
+		if idx == -1 {
+			err = resources.SsDeleter(kc.DeleteSecret).
+				WithResourceID(secret.Name).
+				WithDbFunc(secretDbFunc(id, mapName)).
+				Exec()
+
+			if err != nil {
+				return makeError(err)
+			}
+		}
+	}
+	for _, public := range secrets {
+		err = resources.SsRepairer(
+			kc.ReadSecret,
+			kc.CreateSecret,
+			kc.UpdateSecret,
+			kc.DeleteSecret,
+		).WithResourceID(public.Name).WithDbFunc(secretDbFunc(id, public.Name)).WithGenPublic(&public).Exec()
+
+		if err != nil {
+			return makeError(err)
+		}
+	}
+
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	deployments := g.Deployments()
 	for mapName, k8sDeployment := range d.Subsystems.K8s.GetDeploymentMap() {
 		idx := slices.IndexFunc(deployments, func(d k8sModels.DeploymentPublic) bool { return d.Name == mapName })
@@ -450,6 +568,10 @@ func (c *Client) Repair(id string) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	services := g.Services()
 	for mapName, k8sService := range d.Subsystems.K8s.GetServiceMap() {
 		idx := slices.IndexFunc(services, func(s k8sModels.ServicePublic) bool { return s.Name == mapName })
@@ -477,6 +599,10 @@ func (c *Client) Repair(id string) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	ingresses := g.Ingresses()
 	for mapName, ingress := range d.Subsystems.K8s.GetIngressMap() {
 		idx := slices.IndexFunc(ingresses, func(i k8sModels.IngressPublic) bool { return i.Name == mapName })
@@ -508,31 +634,8 @@ func (c *Client) Repair(id string) error {
 		}
 	}
 
-	secrets := g.Secrets()
-	for mapName, secret := range d.Subsystems.K8s.GetSecretMap() {
-		idx := slices.IndexFunc(secrets, func(s k8sModels.SecretPublic) bool { return s.Name == mapName })
-		if idx == -1 {
-			err = resources.SsDeleter(kc.DeleteSecret).
-				WithResourceID(secret.Name).
-				WithDbFunc(dbFunc(id, "secretMap."+mapName)).
-				Exec()
-
-			if err != nil {
-				return makeError(err)
-			}
-		}
-	}
-	for _, public := range secrets {
-		err = resources.SsRepairer(
-			kc.ReadSecret,
-			kc.CreateSecret,
-			kc.UpdateSecret,
-			kc.DeleteSecret,
-		).WithResourceID(public.Name).WithDbFunc(dbFunc(id, "secretMap."+public.Name)).WithGenPublic(&public).Exec()
-
-		if err != nil {
-			return makeError(err)
-		}
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
 	}
 
 	hpas := g.HPAs()
@@ -562,6 +665,10 @@ func (c *Client) Repair(id string) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// The following are special cases because of dependencies between PVCs, PVs and deployments.
 	// If we have any mismatch for PV or PVC, we need to delete and recreate everything
 
@@ -613,6 +720,10 @@ func (c *Client) Repair(id string) error {
 		return c.recreatePvPvcDeployments(id)
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// OneShotJobs should be kept last since they depend on the PVCs
 	oneShotJobs := g.OneShotJobs()
 	for _, public := range oneShotJobs {
@@ -645,6 +756,26 @@ func (c *Client) Pods(zone *configModels.Zone) ([]k8sModels.PodPublic, error) {
 	return kc.ListPods()
 }
 
+// DeploymentPods lists the running pods of a deployment in the cluster.
+func (c *Client) DeploymentPods(zone *configModels.Zone, deploymentName string) ([]k8sModels.PodPublic, error) {
+	_, kc, _, err := c.Get(OptsOnlyClient(zone))
+	if err != nil {
+		return nil, err
+	}
+
+	return kc.ListPodsByDeployName(deploymentName)
+}
+
+// Exec executes a command in a pod and streams its input and output.
+func (c *Client) Exec(ctx context.Context, zone *configModels.Zone, podName string, execOpts *k8s.ExecOpts) error {
+	_, kc, _, err := c.Get(OptsOnlyClient(zone))
+	if err != nil {
+		return err
+	}
+
+	return kc.Exec(ctx, podName, execOpts)
+}
+
 // SetupPodLogStream sets up a log stream for a pod.
 func (c *Client) SetupPodLogStream(ctx context.Context, zone *configModels.Zone, podName string, from time.Time, onLog func(deploymentName string, lines []model.Log)) error {
 	_, kc, _, err := c.Get(OptsOnlyClient(zone))
@@ -659,6 +790,7 @@ func (c *Client) SetupPodLogStream(ctx context.Context, zone *configModels.Zone,
 				Source:    model.LogSourcePod,
 				Prefix:    fmt.Sprintf("[pod %d]", 0),
 				Line:      line.Line,
+				Pod:       podName,
 				CreatedAt: line.CreatedAt,
 			})
 		}
@@ -851,3 +983,17 @@ func dbFunc(id, key string) func(interface{}) error {
 		return deployment_repo.New().SetSubsystem(id, "k8s."+key, data)
 	}
 }
+
+// secretDbFunc returns a function that updates a secret in the K8s subsystem.
+// The env secret holds the decrypted secret envs, so its data is never stored.
+func secretDbFunc(id, name string) func(interface{}) error {
+	return func(data interface{}) error {
+		if secret, ok := data.(*k8sModels.SecretPublic); ok && secret != nil && strings.HasSuffix(secret.Name, constants.WithEnvSecretSuffix("")) {
+			stripped := *secret
+			stripped.Data = nil
+			data = &stripped
+		}
+
+		return dbFunc(id, "secretMap."+name)(data)
+	}
+}
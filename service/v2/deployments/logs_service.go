@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_repo"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_log_repo"
 	"github.com/kthcloud/go-deploy/pkg/log"
+	"github.com/kthcloud/go-deploy/service/constants"
 	"github.com/kthcloud/go-deploy/service/errors"
+	sUtils "github.com/kthcloud/go-deploy/service/utils"
 	"github.com/kthcloud/go-deploy/service/v2/deployments/opts"
 	"github.com/kthcloud/go-deploy/utils"
 )
@@ -17,10 +20,6 @@ const (
 	// The handler should ignore these messages, as they are only intended to check if the log stream is working.
 	MessageSourceControl = "control"
 
-	// MessageSourceKeepAlive is the source of the message from the keep alive.
-	// They are sent to the client to keep the connection alive.
-	MessageSourceKeepAlive = "keep-alive"
-
 	// FetchPeriod is the period between each fetch of logs from the database.
 	FetchPeriod = 300 * time.Millisecond
 )
@@ -49,7 +48,7 @@ func (c *Client) SetupLogStream(id string, ctx context.Context, handler func(str
 		time.Sleep(500 * time.Millisecond)
 
 		// fetch history logs
-		logs, err := deployment_repo.New().GetLogs(id, history)
+		logs, err := deployment_log_repo.New().WithDeploymentID(id).ListLatest(history)
 		if err != nil {
 			utils.PrettyPrintError(fmt.Errorf("failed to get logs for deployment %s. details: %w", id, err))
 			return
@@ -73,12 +72,12 @@ func (c *Client) SetupLogStream(id string, ctx context.Context, handler func(str
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				handler(MessageSourceKeepAlive, "[keep-alive]", "keep-alive", time.Now())
+				handler(constants.MessageSourceKeepAlive, "[keep-alive]", "keep-alive", time.Now())
 			default:
 				time.Sleep(FetchPeriod)
 				handler(MessageSourceControl, "[control]", "fetching logs", time.Now())
 
-				logs, err = deployment_repo.New().GetLogsAfter(id, lastFetched)
+				logs, err = deployment_log_repo.New().WithDeploymentID(id).CreatedAfter(lastFetched).List()
 				if err != nil {
 					utils.PrettyPrintError(fmt.Errorf("failed to get logs for deployment %s after %s. details: %w", id, lastFetched, err))
 					return
@@ -97,3 +96,72 @@ func (c *Client) SetupLogStream(id string, ctx context.Context, handler func(str
 
 	return nil
 }
+
+// ListLogs lists logs of the deployment, with the oldest log first.
+//
+// It returns sErrors.ErrDeploymentNotFound if the deployment does not exist or the user does not have access to it.
+func (c *Client) ListLogs(id string, opts ...opts.ListLogsOpts) ([]model.DeploymentLog, error) {
+	o := sUtils.GetFirstOrDefault(opts)
+
+	client, err := c.logClient(id, &o)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Pagination != nil {
+		client.WithPagination(o.Pagination.Page, o.Pagination.PageSize)
+	}
+
+	return client.List()
+}
+
+// ForEachLog calls the callback for every log of the deployment that matches the options, with the oldest log first.
+//
+// Pagination is ignored, which makes it suitable for downloading all logs.
+// It returns sErrors.ErrDeploymentNotFound if the deployment does not exist or the user does not have access to it.
+func (c *Client) ForEachLog(id string, ctx context.Context, callback func(*model.DeploymentLog) error, opts ...opts.ListLogsOpts) error {
+	o := sUtils.GetFirstOrDefault(opts)
+
+	client, err := c.logClient(id, &o)
+	if err != nil {
+		return err
+	}
+
+	return client.ForEach(ctx, callback)
+}
+
+// logClient checks access to the deployment and returns a log client with the filters from the options applied.
+func (c *Client) logClient(id string, o *opts.ListLogsOpts) (*deployment_log_repo.Client, error) {
+	deployment, err := c.Get(id, opts.GetOpts{Shared: true})
+	if err != nil {
+		return nil, err
+	}
+
+	if deployment == nil {
+		return nil, errors.ErrDeploymentNotFound
+	}
+
+	client := deployment_log_repo.New().WithDeploymentID(id)
+
+	if len(o.Sources) > 0 {
+		client.WithSources(o.Sources...)
+	}
+
+	if o.Pod != nil {
+		client.WithPod(*o.Pod)
+	}
+
+	if o.Since != nil {
+		client.CreatedSince(*o.Since)
+	}
+
+	if o.Until != nil {
+		client.CreatedBefore(*o.Until)
+	}
+
+	if o.Search != nil && *o.Search != "" {
+		client.WithSearch(*o.Search)
+	}
+
+	return client, nil
+}
@@ -1,8 +1,12 @@
 package opts
 
 import (
+	"io"
+	"time"
+
 	body2 "github.com/kthcloud/go-deploy/dto/v2/body"
 	configModels "github.com/kthcloud/go-deploy/models/config"
+	k8sModels "github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
 	v1 "github.com/kthcloud/go-deploy/service/v2/utils"
 )
 
@@ -33,6 +37,31 @@ type ListOpts struct {
 	GpuClaimRequest *string
 }
 
+// ListRevisionsOpts is used to specify the options when listing revisions of a deployment.
+type ListRevisionsOpts struct {
+	Pagination *v1.Pagination
+}
+
+// ListLogsOpts is used to specify the options when listing logs of a deployment.
+type ListLogsOpts struct {
+	Pagination *v1.Pagination
+	Sources    []string
+	Pod        *string
+	Since      *time.Time
+	Until      *time.Time
+	Search     *string
+}
+
+// CiConfigOpts is used to specify the options when generating a CI config for a deployment.
+type CiConfigOpts struct {
+	// Format is the CI system to generate the config for. It defaults to model.CiFormatGithub.
+	Format string
+	// Branch is the branch that triggers the build. It defaults to main.
+	Branch *string
+	// TagStrategy is how the pushed image is tagged. It defaults to model.CiTagLatest.
+	TagStrategy string
+}
+
 // GetOpts is used to specify the options when getting a deployment.
 type GetOpts struct {
 	MigrationCode *string
@@ -40,8 +69,35 @@ type GetOpts struct {
 	Shared        bool
 }
 
+// UpdateOpts is used to specify the options when updating a deployment.
+type UpdateOpts struct {
+	// NoRevision skips storing a revision, such as for updates made by the scaling schedule.
+	NoRevision bool
+}
+
 // QuotaOptions is used to specify the options when getting a deployment's quota.
 type QuotaOptions struct {
 	Create *body2.DeploymentCreate
 	Update *body2.DeploymentUpdate
+	// ScaleOverride is the number of replicas the main app is temporarily scaled to, and is checked along with Update.
+	// If it is not set, the current scale override of the deployment is kept.
+	ScaleOverride *int
+}
+
+// ExecOpts is used to specify the options when executing a command in a deployment's pod.
+type ExecOpts struct {
+	// Pod is the name of the pod. If empty, the first running pod is used.
+	Pod       string
+	Container string
+	// Command is the command to run. If empty, an interactive shell is started.
+	Command []string
+	TTY     bool
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	Resize <-chan k8sModels.TerminalSize
+
+	// ClientIP is the IP of the client that opened the session, recorded in the audit event.
+	ClientIP string
 }
@@ -24,6 +24,8 @@ import (
 	"github.com/kthcloud/go-deploy/service/constants"
 	"github.com/kthcloud/go-deploy/service/generators"
 	"github.com/kthcloud/go-deploy/utils"
+	"github.com/kthcloud/go-deploy/utils/cryptoutils"
+	"github.com/kthcloud/go-deploy/utils/hashutils"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -41,7 +43,7 @@ func K8s(deployment *model.Deployment, zone *configModels.Zone, client *k8s.Clie
 	return &K8sGenerator{
 		namespace:  namespace,
 		client:     client,
-		deployment: deployment,
+		deployment: deployment.WithOverrides(),
 		zone:       zone,
 	}
 }
@@ -61,112 +63,21 @@ func (kg *K8sGenerator) Namespace() *models.NamespacePublic {
 func (kg *K8sGenerator) Deployments() []models.DeploymentPublic {
 	mainApp := kg.deployment.GetMainApp()
 
-	var imagePullSecrets []string
-	if kg.deployment.Type == model.DeploymentTypeCustom {
-		imagePullSecrets = []string{constants.WithImagePullSecretSuffix(kg.deployment.Name)}
-	}
-
-	k8sEnvs := make([]models.EnvVar, len(mainApp.Envs))
-	for i, env := range mainApp.Envs {
-		if env.Name == "PORT" || env.Name == "INTERNAL_PORTS" {
-			continue
-		}
-
-		k8sEnvs[i] = models.EnvVar{
-			Name:  env.Name,
-			Value: env.Value,
-		}
-	}
-
-	k8sEnvs = append(k8sEnvs, models.EnvVar{
-		Name:  "PORT",
-		Value: fmt.Sprintf("%d", mainApp.InternalPort),
-	})
-
-	if len(mainApp.InternalPorts) > 0 {
-		portsStr := make([]string, len(mainApp.InternalPorts))
-		for i, port := range mainApp.InternalPorts {
-			portsStr[i] = strconv.Itoa(port)
-		}
-
-		k8sEnvs = append(k8sEnvs, models.EnvVar{
-			Name:  "INTERNAL_PORTS",
-			Value: strings.Join(portsStr, ","),
-		})
-	}
-
-	k8sVolumes := make([]models.Volume, len(mainApp.Volumes))
-	for i, volume := range mainApp.Volumes {
-		pvcName := fmt.Sprintf("%s-%s", kg.deployment.Name, makeValidK8sName(volume.Name))
-		k8sVolumes[i] = models.Volume{
-			Name:      makeValidK8sName(volume.Name),
-			PvcName:   &pvcName,
-			MountPath: volume.AppPath,
-			Init:      volume.Init,
-		}
-	}
-
-	k8sResClaims := make([]models.DynamicResourceClaim, 0, len(mainApp.GPUs))
-	for _, gpu := range mainApp.GPUs {
-		rc := models.DynamicResourceClaim{
-			Name:    fmt.Sprintf("%s-%s", kg.deployment.Name, makeValidK8sName(gpu.Name)),
-			Request: []string{gpu.Name},
-		}
-
-		if gpu.ClaimName != "" {
-			rc.ResourceClaimName = &gpu.ClaimName
-		} else {
-			// needs to have one of them
-			continue
-		}
-
-		k8sResClaims = append(k8sResClaims, rc)
-	}
-
-	// TODO: make this more dynamic, dont just support nvidia
-	tolerations := make([]models.Toleration, 0, max(1, len(mainApp.GPUs)))
-	if len(mainApp.GPUs) > 0 {
-		tolerations = append(tolerations, models.Toleration{
-			Key:      "nvidia.com/gpu",
-			Operator: "Exists",
-			Effect:   "NoSchedule",
-		})
-	}
-
 	res := make([]models.DeploymentPublic, 0)
 
-	dep := models.DeploymentPublic{
-		Name:             kg.deployment.Name,
-		Namespace:        kg.namespace,
-		Labels:           map[string]string{"owner-id": kg.deployment.OwnerID},
-		Image:            mainApp.Image,
-		ImagePullSecrets: imagePullSecrets,
-		EnvVars:          k8sEnvs,
-		Resources: models.Resources{
-			Limits: models.Limits{
-				CPU:    formatCpuString(mainApp.CpuCores),
-				Memory: fmt.Sprintf("%dMi", int(mainApp.RAM*1000)),
-			},
-			Requests: models.Requests{
-				CPU:    formatCpuString(math.Min(config.Config.Deployment.Resources.Requests.CPU, mainApp.CpuCores)),
-				Memory: fmt.Sprintf("%dMi", int(math.Min(config.Config.Deployment.Resources.Requests.RAM, mainApp.RAM)*1000)),
-			},
-		},
-		Command:        make([]string, 0),
-		Args:           mainApp.Args,
-		InitCommands:   mainApp.InitCommands,
-		InitContainers: make([]models.InitContainer, 0),
-		Volumes:        k8sVolumes,
-		ResourceClaims: k8sResClaims,
-		Tolerations:    tolerations,
-		Disabled:       mainApp.Replicas == 0,
-	}
+	// During a rollout, the main K8s deployment keeps running the version from before the update
+	res = append(res, kg.mainAppDeployment(kg.deployment.Name, kg.deployment.GetStableApp()))
 
-	if d := kg.deployment.Subsystems.K8s.GetDeployment(kg.deployment.Name); subsystems.Created(d) {
-		dep.CreatedAt = d.CreatedAt
+	// The new version only serves a share of the traffic until it is promoted, so it is not scaled by an HPA
+	if kg.rolloutActive() {
+		rollout := kg.mainAppDeployment(constants.WithRolloutSuffix(kg.deployment.Name), mainApp)
+		rollout.FixedReplicas = kg.deployment.GetRolloutReplicas()
+		res = append(res, rollout)
 	}
 
-	res = append(res, dep)
+	for _, sidecar := range kg.deployment.GetSidecarApps() {
+		res = append(res, kg.sidecarDeployment(&sidecar))
+	}
 
 	if mainApp.Visibility == model.VisibilityAuth && mainApp.Replicas > 0 {
 
@@ -235,7 +146,7 @@ func (kg *K8sGenerator) Deployments() []models.DeploymentPublic {
 					"--cookie-refresh=1h",
 					"--pass-authorization-header=true",
 					"--scope=openid email",
-					"--upstream=" + fmt.Sprintf("http://%s:%d", kg.deployment.Name, mainApp.InternalPort),
+					"--upstream=" + fmt.Sprintf("http://%s:%d", kg.deployment.Name, kg.deployment.GetStableApp().InternalPort),
 					"--client-id=" + config.Config.Keycloak.UserClient.ClientID,
 					"--client-secret=" + config.Config.Keycloak.UserClient.ClientSecret,
 					"--cookie-secret=qHKgjlAFQBZOnGcdH5jIKV0Auzx5r8jzZenxhJnlZJg=",
@@ -292,51 +203,25 @@ func (kg *K8sGenerator) Services() []models.ServicePublic {
 
 	// If replicas == 0, it should not create a service
 	// If visibility == auth, it should create both a service for the deployment and the auth proxy
-
-	if mainApp.Replicas == 0 {
-		return make([]models.ServicePublic, 0)
-	}
+	// Sidecars get their own service if they expose any ports
 
 	res := make([]models.ServicePublic, 0)
 
-	// Add the base http port
-	ports := []models.Port{
-		{
-			Name:       "http",
-			Protocol:   "tcp",
-			Port:       mainApp.InternalPort,
-			TargetPort: mainApp.InternalPort,
-		},
-	}
-
-	// add all internalPorts to expose to the with the service
-	for _, p := range mainApp.InternalPorts {
-		if p == mainApp.InternalPort || p == 0 {
-			continue
+	for _, sidecar := range kg.deployment.GetSidecarApps() {
+		if se := kg.sidecarService(&sidecar); se != nil {
+			res = append(res, *se)
 		}
-
-		ports = append(ports, models.Port{
-			Name:       fmt.Sprintf("port-%d", p),
-			Protocol:   "tcp",
-			Port:       p,
-			TargetPort: p,
-		})
 	}
 
-	se := models.ServicePublic{
-		Name:      kg.deployment.Name,
-		Namespace: kg.namespace,
-		Ports:     ports,
-		Selector: map[string]string{
-			keys.LabelDeployName: kg.deployment.Name,
-		},
+	if mainApp.Replicas == 0 {
+		return res
 	}
 
-	if k8sService := kg.deployment.Subsystems.K8s.GetService(kg.deployment.Name); subsystems.Created(k8sService) {
-		se.CreatedAt = k8sService.CreatedAt
-	}
+	res = append(res, kg.mainAppService(kg.deployment.Name, kg.deployment.GetStableApp()))
 
-	res = append(res, se)
+	if kg.rolloutActive() {
+		res = append(res, kg.mainAppService(constants.WithRolloutSuffix(kg.deployment.Name), mainApp))
+	}
 
 	if mainApp.Visibility == model.VisibilityAuth {
 		authSe := models.ServicePublic{
@@ -368,6 +253,7 @@ func (kg *K8sGenerator) Ingresses() []models.IngressPublic {
 
 	var serviceName string
 	var servicePort int
+	var rewriteTarget *string
 
 	// If replicas == 0, it should point to the fallback-disabled deployment
 	// If visibility == auth, it should point to the auth proxy
@@ -376,25 +262,27 @@ func (kg *K8sGenerator) Ingresses() []models.IngressPublic {
 	if mainApp.Replicas == 0 {
 		serviceName = config.Config.Deployment.Fallback.Disabled.Name
 		servicePort = config.Config.Deployment.Port
+		rewriteTarget = kg.wakeUpRewriteTarget()
 	} else if mainApp.Visibility == model.VisibilityAuth {
 		serviceName = authProxyName(kg.deployment.Name)
 		servicePort = 4180
 	} else {
 		serviceName = kg.deployment.Name
-		servicePort = mainApp.InternalPort
+		servicePort = kg.deployment.GetStableApp().InternalPort
 	}
 
 	tlsSecret := constants.WildcardCertSecretName
 	in := models.IngressPublic{
-		Name:         kg.deployment.Name,
-		Namespace:    kg.namespace,
-		ServiceName:  serviceName,
-		ServicePort:  servicePort,
-		IngressClass: config.Config.Deployment.IngressClass,
-		Hosts:        []string{getExternalFQDN(kg.deployment.Name, kg.zone)},
-		Placeholder:  false,
-		TlsSecret:    &tlsSecret,
-		CustomCert:   nil,
+		Name:          kg.deployment.Name,
+		Namespace:     kg.namespace,
+		ServiceName:   serviceName,
+		ServicePort:   servicePort,
+		IngressClass:  config.Config.Deployment.IngressClass,
+		Hosts:         []string{getExternalFQDN(kg.deployment.Name, kg.zone)},
+		Placeholder:   false,
+		TlsSecret:     &tlsSecret,
+		CustomCert:    nil,
+		RewriteTarget: rewriteTarget,
 	}
 
 	if k8sIngress := kg.deployment.Subsystems.K8s.GetIngress(kg.deployment.Name); subsystems.Created(k8sIngress) {
@@ -415,7 +303,8 @@ func (kg *K8sGenerator) Ingresses() []models.IngressPublic {
 				ClusterIssuer: kg.zone.K8s.ClusterIssuer,
 				CommonName:    mainApp.CustomDomain.Domain,
 			},
-			TlsSecret: nil,
+			TlsSecret:     nil,
+			RewriteTarget: rewriteTarget,
 		}
 
 		if customK8sIngress := kg.deployment.Subsystems.K8s.GetIngress(constants.WithCustomDomainSuffix(kg.deployment.Name)); subsystems.Created(customK8sIngress) {
@@ -425,17 +314,43 @@ func (kg *K8sGenerator) Ingresses() []models.IngressPublic {
 		res = append(res, customIn)
 	}
 
+	hosts := make([]string, 0)
+	for _, ingress := range res {
+		hosts = append(hosts, ingress.Hosts...)
+	}
+
+	if rolloutIn := kg.rolloutIngress(hosts); rolloutIn != nil {
+		res = append(res, *rolloutIn)
+	}
+
 	return res
 }
 
+// wakeUpRewriteTarget returns the path that requests to a deployment scaled to 0 by its schedule are rewritten to,
+// so that the fallback-disabled page can show when the deployment wakes up, such as /?wakeUpAt=2024-01-01T07:00:00Z.
+// It returns nil if the deployment is not scaled to 0 by its schedule, e.g. if it is paused.
+func (kg *K8sGenerator) wakeUpRewriteTarget() *string {
+	if kg.deployment.Schedule == nil || kg.deployment.Paused || kg.deployment.ScaleOverride != nil {
+		return nil
+	}
+
+	wakeUpAt := kg.deployment.Schedule.WakeUpAt(time.Now())
+	if wakeUpAt == nil {
+		return nil
+	}
+
+	target := fmt.Sprintf("/?wakeUpAt=%s", wakeUpAt.UTC().Format(time.RFC3339))
+	return &target
+}
+
 func (kg *K8sGenerator) PVs() []models.PvPublic {
 	res := make([]models.PvPublic, 0)
-	if len(kg.deployment.GetMainApp().Volumes) == 0 {
+
+	volumes := kg.volumes()
+	if len(volumes) == 0 {
 		return res
 	}
 
-	volumes := kg.deployment.GetMainApp().Volumes
-
 	for _, v := range volumes {
 		res = append(res, models.PvPublic{
 			Name:      deploymentPvName(kg.deployment, v.Name),
@@ -469,12 +384,12 @@ func (kg *K8sGenerator) PVs() []models.PvPublic {
 
 func (kg *K8sGenerator) PVCs() []models.PvcPublic {
 	res := make([]models.PvcPublic, 0)
-	if len(kg.deployment.GetMainApp().Volumes) == 0 {
+
+	volumes := kg.volumes()
+	if len(volumes) == 0 {
 		return res
 	}
 
-	volumes := kg.deployment.GetMainApp().Volumes
-
 	for _, volume := range volumes {
 		res = append(res, models.PvcPublic{
 			Name:      deploymentPvcName(kg.deployment, volume.Name),
@@ -507,10 +422,10 @@ func (kg *K8sGenerator) PVCs() []models.PvcPublic {
 func (kg *K8sGenerator) Secrets() []models.SecretPublic {
 	res := make([]models.SecretPublic, 0)
 
-	if kg.deployment.Type == model.DeploymentTypeCustom {
+	if kg.deployment.HasOwnImage() {
 		var imagePullSecret *models.SecretPublic
 
-		if kg.deployment.Subsystems.Harbor.Robot.Created() && kg.deployment.Type == model.DeploymentTypeCustom {
+		if kg.deployment.Subsystems.Harbor.Robot.Created() && kg.deployment.HasOwnImage() {
 			registry := config.Config.Registry.URL
 			username := kg.deployment.Subsystems.Harbor.Robot.HarborName
 			password := kg.deployment.Subsystems.Harbor.Robot.Secret
@@ -539,6 +454,24 @@ func (kg *K8sGenerator) Secrets() []models.SecretPublic {
 		}
 	}
 
+	// secret envs
+	var envSecret *models.SecretPublic
+
+	if data := kg.envSecretData(); len(data) > 0 {
+		envSecret = &models.SecretPublic{
+			Name:      constants.WithEnvSecretSuffix(kg.deployment.Name),
+			Namespace: kg.namespace,
+			Type:      string(v1.SecretTypeOpaque),
+			Data:      data,
+		}
+
+		if secret := kg.deployment.Subsystems.K8s.GetSecret(constants.WithEnvSecretSuffix(kg.deployment.Name)); subsystems.Created(secret) {
+			envSecret.CreatedAt = secret.CreatedAt
+		}
+
+		res = append(res, *envSecret)
+	}
+
 	// wildcard certificate
 	/// swap namespaces temporarily
 	var wildcardCertSecret *models.SecretPublic
@@ -578,27 +511,33 @@ func (kg *K8sGenerator) HPAs() []models.HpaPublic {
 
 	mainApp := kg.deployment.GetMainApp()
 
-	minReplicas := 1
-	maxReplicas := mainApp.Replicas
+	for _, sidecar := range kg.deployment.GetSidecarApps() {
+		if hpa := kg.sidecarHPA(&sidecar); hpa != nil {
+			res = append(res, *hpa)
+		}
+	}
 
 	// If replicas == 0, it should point to the fallback-disabled deployment
-	// This means we can delete the HPA
-	if mainApp.Replicas == 0 {
+	// This means we can delete the HPA, which is also the case if autoscaling is disabled
+	if !mainApp.AutoscalingEnabled() {
 		return res
 	}
 
+	cpuTarget, memoryTarget, scaleDownStabilizationSeconds := autoscalingTargets(mainApp)
+
 	hpa := models.HpaPublic{
 		Name:        kg.deployment.Name,
 		Namespace:   kg.namespace,
-		MinReplicas: minReplicas,
-		MaxReplicas: maxReplicas,
+		MinReplicas: mainApp.GetMinReplicas(),
+		MaxReplicas: mainApp.GetMaxReplicas(),
 		Target: models.Target{
 			Kind:       "Deployment",
 			Name:       kg.deployment.Name,
 			ApiVersion: "apps/v1",
 		},
-		CpuAverageUtilization:    config.Config.Deployment.Resources.AutoScale.CpuThreshold,
-		MemoryAverageUtilization: config.Config.Deployment.Resources.AutoScale.MemoryThreshold,
+		CpuAverageUtilization:         cpuTarget,
+		MemoryAverageUtilization:      memoryTarget,
+		ScaleDownStabilizationSeconds: scaleDownStabilizationSeconds,
 	}
 
 	if h := kg.deployment.Subsystems.K8s.GetHPA(kg.deployment.Name); subsystems.Created(h) {
@@ -611,7 +550,9 @@ func (kg *K8sGenerator) HPAs() []models.HpaPublic {
 
 func (kg *K8sGenerator) OneShotJobs() []models.JobPublic {
 	res := make([]models.JobPublic, 0)
-	if len(kg.deployment.GetMainApp().Volumes) == 0 {
+
+	volumes := kg.volumes()
+	if len(volumes) == 0 {
 		return res
 	}
 
@@ -620,7 +561,7 @@ func (kg *K8sGenerator) OneShotJobs() []models.JobPublic {
 	args := []string{
 		"-p",
 	}
-	for _, v := range kg.deployment.GetMainApp().Volumes {
+	for _, v := range volumes {
 		if v.ServerPath == "" {
 			continue
 		}
@@ -649,6 +590,66 @@ func (kg *K8sGenerator) OneShotJobs() []models.JobPublic {
 	return res
 }
 
+// BuildJob returns a models.JobPublic that builds the deployment's git source with Kaniko and
+// pushes the image to the given destination.
+//
+// The image pull secret is mounted as the Docker config, since its robot account is also allowed to push.
+// The build is limited by the configured build resources, or by the resources of the main app if they are not set.
+// It returns nil if the deployment is not a git deployment.
+func (kg *K8sGenerator) BuildJob(destination string) *models.JobPublic {
+	if kg.deployment.Type != model.DeploymentTypeGit || kg.deployment.Git == nil {
+		return nil
+	}
+
+	git := kg.deployment.Git
+	gitContext := fmt.Sprintf("git://%s#refs/heads/%s", strings.TrimPrefix(strings.TrimPrefix(git.URL, "https://"), "http://"), git.Branch)
+
+	buildResources := config.Config.Deployment.Resources.Build
+	cpu, ram := buildResources.CPU, buildResources.RAM
+	if cpu == 0 {
+		cpu = kg.deployment.GetMainApp().CpuCores
+	}
+	if ram == 0 {
+		ram = kg.deployment.GetMainApp().RAM
+	}
+
+	secretName := constants.WithImagePullSecretSuffix(kg.deployment.Name)
+	ttl := 10 * time.Minute
+	// A failed build is reported to the user instead of being retried
+	maxTries := 0
+
+	return &models.JobPublic{
+		Name:      fmt.Sprintf("%s-build-%d", kg.deployment.Name, time.Now().Unix()),
+		Namespace: kg.namespace,
+		Image:     config.Config.Deployment.GetBuildImage(),
+		Args: []string{
+			"--context=" + gitContext,
+			"--dockerfile=" + git.DockerfilePath,
+			"--destination=" + destination,
+		},
+		Volumes: []models.Volume{
+			{
+				Name:       "docker-config",
+				SecretName: &secretName,
+				MountPath:  "/kaniko/.docker/config.json",
+				SubPath:    ".dockerconfigjson",
+			},
+		},
+		Resources: models.Resources{
+			Limits: models.Limits{
+				CPU:    formatCpuString(cpu),
+				Memory: fmt.Sprintf("%dMi", int(ram*1000)),
+			},
+			Requests: models.Requests{
+				CPU:    formatCpuString(math.Min(config.Config.Deployment.Resources.Requests.CPU, cpu)),
+				Memory: fmt.Sprintf("%dMi", int(math.Min(config.Config.Deployment.Resources.Requests.RAM, ram)*1000)),
+			},
+		},
+		MaxTries:         &maxTries,
+		TtlAfterFinished: &ttl,
+	}
+}
+
 func (kg *K8sGenerator) NetworkPolicies() []models.NetworkPolicyPublic {
 	res := make([]models.NetworkPolicyPublic, 0)
 
@@ -666,7 +667,7 @@ func (kg *K8sGenerator) NetworkPolicies() []models.NetworkPolicyPublic {
 		np := models.NetworkPolicyPublic{
 			Name:        deploymentNetworkPolicyName(kg.deployment.Name, egressRule.Name),
 			Namespace:   kg.namespace,
-			Selector:    map[string]string{keys.LabelDeployName: kg.deployment.Name},
+			Selector:    kg.networkPolicySelector(),
 			EgressRules: egressRules,
 			IngressRules: []models.IngressRule{
 				{
@@ -689,6 +690,539 @@ func (kg *K8sGenerator) NetworkPolicies() []models.NetworkPolicyPublic {
 	return res
 }
 
+// mainAppDeployment generates a K8s deployment that runs the given version of the main app.
+func (kg *K8sGenerator) mainAppDeployment(name string, app *model.App) models.DeploymentPublic {
+	var imagePullSecrets []string
+	if kg.deployment.HasOwnImage() {
+		imagePullSecrets = []string{constants.WithImagePullSecretSuffix(kg.deployment.Name)}
+	}
+
+	k8sEnvs := make([]models.EnvVar, len(app.Envs))
+	for i, env := range app.Envs {
+		if env.Name == "PORT" || env.Name == "INTERNAL_PORTS" {
+			continue
+		}
+
+		k8sEnvs[i] = kg.envVar(app, &env)
+	}
+
+	k8sEnvs = append(k8sEnvs, models.EnvVar{
+		Name:  "PORT",
+		Value: fmt.Sprintf("%d", app.InternalPort),
+	})
+
+	if len(app.InternalPorts) > 0 {
+		portsStr := make([]string, len(app.InternalPorts))
+		for i, port := range app.InternalPorts {
+			portsStr[i] = strconv.Itoa(port)
+		}
+
+		k8sEnvs = append(k8sEnvs, models.EnvVar{
+			Name:  "INTERNAL_PORTS",
+			Value: strings.Join(portsStr, ","),
+		})
+	}
+
+	k8sVolumes := make([]models.Volume, len(app.Volumes))
+	for i, volume := range app.Volumes {
+		pvcName := fmt.Sprintf("%s-%s", kg.deployment.Name, makeValidK8sName(volume.Name))
+		k8sVolumes[i] = models.Volume{
+			Name:      makeValidK8sName(volume.Name),
+			PvcName:   &pvcName,
+			MountPath: volume.AppPath,
+			Init:      volume.Init,
+		}
+	}
+
+	k8sResClaims := make([]models.DynamicResourceClaim, 0, len(app.GPUs))
+	for _, gpu := range app.GPUs {
+		rc := models.DynamicResourceClaim{
+			Name:    fmt.Sprintf("%s-%s", kg.deployment.Name, makeValidK8sName(gpu.Name)),
+			Request: []string{gpu.Name},
+		}
+
+		if gpu.ClaimName != "" {
+			rc.ResourceClaimName = &gpu.ClaimName
+		} else {
+			// needs to have one of them
+			continue
+		}
+
+		k8sResClaims = append(k8sResClaims, rc)
+	}
+
+	// TODO: make this more dynamic, dont just support nvidia
+	tolerations := make([]models.Toleration, 0, max(1, len(app.GPUs)))
+	if len(app.GPUs) > 0 {
+		tolerations = append(tolerations, models.Toleration{
+			Key:      "nvidia.com/gpu",
+			Operator: "Exists",
+			Effect:   "NoSchedule",
+		})
+	}
+
+	dep := models.DeploymentPublic{
+		Name:             name,
+		Namespace:        kg.namespace,
+		Labels:           kg.appLabels(),
+		Image:            app.GetPinnedImage(),
+		ImagePullSecrets: imagePullSecrets,
+		EnvVars:          k8sEnvs,
+		Resources: models.Resources{
+			Limits: models.Limits{
+				CPU:    formatCpuString(app.CpuCores),
+				Memory: fmt.Sprintf("%dMi", int(app.RAM*1000)),
+			},
+			Requests: models.Requests{
+				CPU:    formatCpuString(math.Min(config.Config.Deployment.Resources.Requests.CPU, app.CpuCores)),
+				Memory: fmt.Sprintf("%dMi", int(math.Min(config.Config.Deployment.Resources.Requests.RAM, app.RAM)*1000)),
+			},
+		},
+		Command:        make([]string, 0),
+		Args:           app.Args,
+		InitCommands:   app.InitCommands,
+		InitContainers: make([]models.InitContainer, 0),
+		Volumes:        k8sVolumes,
+		ResourceClaims: k8sResClaims,
+		Tolerations:    tolerations,
+		SecretHash:     secretHash(app),
+		Disabled:       app.Replicas == 0,
+	}
+
+	// Apps without autoscaling run a fixed number of replicas instead of being scaled by an HPA
+	if !app.AutoscalingEnabled() && app.Replicas > 0 {
+		dep.FixedReplicas = app.Replicas
+	}
+
+	if app.Probes != nil {
+		dep.LivenessProbe = probePublic(app.Probes.Liveness, app.InternalPort)
+		dep.ReadinessProbe = probePublic(app.Probes.Readiness, app.InternalPort)
+		dep.StartupProbe = probePublic(app.Probes.Startup, app.InternalPort)
+	}
+
+	if d := kg.deployment.Subsystems.K8s.GetDeployment(name); subsystems.Created(d) {
+		dep.CreatedAt = d.CreatedAt
+	}
+
+	return dep
+}
+
+// groupedByPartOf returns true if the K8s deployments of the deployment are grouped with the LabelPartOf label.
+//
+// Only deployments that run more than the main app need it. Others keep selecting their pods by name,
+// since adding a label to the pod template of an existing deployment restarts it.
+func (kg *K8sGenerator) groupedByPartOf() bool {
+	return len(kg.deployment.GetSidecarApps()) > 0 || kg.deployment.GetRolloutStrategy() != model.RolloutStrategyRolling
+}
+
+// appLabels returns the labels of the K8s deployments that run the apps of the deployment.
+func (kg *K8sGenerator) appLabels() map[string]string {
+	labels := map[string]string{"owner-id": kg.deployment.OwnerID}
+	if kg.groupedByPartOf() {
+		labels[keys.LabelPartOf] = kg.deployment.Name
+	}
+
+	return labels
+}
+
+// networkPolicySelector returns the selector for the pods of every app in the deployment.
+func (kg *K8sGenerator) networkPolicySelector() map[string]string {
+	if kg.groupedByPartOf() {
+		return map[string]string{keys.LabelPartOf: kg.deployment.Name}
+	}
+
+	return map[string]string{keys.LabelDeployName: kg.deployment.Name}
+}
+
+// rolloutActive returns true if the new version of the main app should run next to the version it replaces.
+func (kg *K8sGenerator) rolloutActive() bool {
+	return kg.deployment.GetRolloutReplicas() > 0
+}
+
+// rolloutIngress generates the K8s ingress for the new version of the main app during a rollout.
+//
+// With the canary strategy, it is a canary of the main ingress that receives a share of the traffic to its hosts.
+// With the blue/green strategy, it makes the new version reachable on its own preview host.
+// Since the traffic of auth deployments passes through the auth proxy, only public deployments get a rollout ingress.
+func (kg *K8sGenerator) rolloutIngress(hosts []string) *models.IngressPublic {
+	if !kg.rolloutActive() || kg.deployment.GetMainApp().Visibility != model.VisibilityPublic {
+		return nil
+	}
+
+	name := constants.WithRolloutSuffix(kg.deployment.Name)
+	tlsSecret := constants.WildcardCertSecretName
+
+	in := models.IngressPublic{
+		Name:         name,
+		Namespace:    kg.namespace,
+		ServiceName:  name,
+		ServicePort:  kg.deployment.GetMainApp().InternalPort,
+		IngressClass: config.Config.Deployment.IngressClass,
+		Hosts:        []string{getExternalFQDN(name, kg.zone)},
+		TlsSecret:    &tlsSecret,
+	}
+
+	if kg.deployment.Rollout.Strategy == model.RolloutStrategyCanary {
+		// The TLS of the hosts is already set up by the main ingress
+		weight := kg.deployment.GetCanaryWeight()
+		in.Hosts = hosts
+		in.TlsSecret = nil
+		in.CanaryWeight = &weight
+	}
+
+	if k8sIngress := kg.deployment.Subsystems.K8s.GetIngress(name); subsystems.Created(k8sIngress) {
+		in.CreatedAt = k8sIngress.CreatedAt
+	}
+
+	return &in
+}
+
+// sidecarDeployment generates the K8s deployment for a sidecar app.
+func (kg *K8sGenerator) sidecarDeployment(sidecar *model.App) models.DeploymentPublic {
+	name := constants.WithSidecarSuffix(kg.deployment.Name, sidecar.Name)
+
+	k8sEnvs := make([]models.EnvVar, len(sidecar.Envs))
+	for i, env := range sidecar.Envs {
+		k8sEnvs[i] = kg.envVar(sidecar, &env)
+	}
+
+	k8sVolumes := make([]models.Volume, len(sidecar.Volumes))
+	for i, volume := range sidecar.Volumes {
+		volumeName := appVolumeName(sidecar, volume.Name)
+		pvcName := deploymentPvcName(kg.deployment, volumeName)
+		k8sVolumes[i] = models.Volume{
+			Name:      makeValidK8sName(volumeName),
+			PvcName:   &pvcName,
+			MountPath: volume.AppPath,
+			Init:      volume.Init,
+		}
+	}
+
+	dep := models.DeploymentPublic{
+		Name:             name,
+		Namespace:        kg.namespace,
+		Labels:           kg.appLabels(),
+		Image:            sidecar.Image,
+		ImagePullSecrets: make([]string, 0),
+		EnvVars:          k8sEnvs,
+		Resources: models.Resources{
+			Limits: models.Limits{
+				CPU:    formatCpuString(sidecar.CpuCores),
+				Memory: fmt.Sprintf("%dMi", int(sidecar.RAM*1000)),
+			},
+			Requests: models.Requests{
+				CPU:    formatCpuString(math.Min(config.Config.Deployment.Resources.Requests.CPU, sidecar.CpuCores)),
+				Memory: fmt.Sprintf("%dMi", int(math.Min(config.Config.Deployment.Resources.Requests.RAM, sidecar.RAM)*1000)),
+			},
+		},
+		Command:        make([]string, 0),
+		Args:           sidecar.Args,
+		InitCommands:   make([]string, 0),
+		InitContainers: make([]models.InitContainer, 0),
+		Volumes:        k8sVolumes,
+		SecretHash:     secretHash(sidecar),
+		Disabled:       kg.sidecarDisabled(sidecar),
+	}
+
+	// Sidecars scale with the autoscaling policy of the main app, and run a fixed number of replicas without it
+	if !dep.Disabled && !kg.deployment.GetMainApp().AutoscalingEnabled() {
+		dep.FixedReplicas = sidecar.Replicas
+	}
+
+	if d := kg.deployment.Subsystems.K8s.GetDeployment(name); subsystems.Created(d) {
+		dep.CreatedAt = d.CreatedAt
+	}
+
+	return dep
+}
+
+// mainAppService generates the K8s service for the K8s deployment that runs the given version of the main app.
+func (kg *K8sGenerator) mainAppService(name string, app *model.App) models.ServicePublic {
+	// Add the base http port
+	ports := []models.Port{
+		{
+			Name:       "http",
+			Protocol:   "tcp",
+			Port:       app.InternalPort,
+			TargetPort: app.InternalPort,
+		},
+	}
+
+	// add all internalPorts to expose to the with the service
+	for _, p := range app.InternalPorts {
+		if p == app.InternalPort || p == 0 {
+			continue
+		}
+
+		ports = append(ports, models.Port{
+			Name:       fmt.Sprintf("port-%d", p),
+			Protocol:   "tcp",
+			Port:       p,
+			TargetPort: p,
+		})
+	}
+
+	se := models.ServicePublic{
+		Name:      name,
+		Namespace: kg.namespace,
+		Ports:     ports,
+		Selector: map[string]string{
+			keys.LabelDeployName: name,
+		},
+	}
+
+	if k8sService := kg.deployment.Subsystems.K8s.GetService(name); subsystems.Created(k8sService) {
+		se.CreatedAt = k8sService.CreatedAt
+	}
+
+	return se
+}
+
+// sidecarService generates the K8s service for a sidecar app.
+// It returns nil if the sidecar is disabled or does not expose any ports.
+func (kg *K8sGenerator) sidecarService(sidecar *model.App) *models.ServicePublic {
+	if kg.sidecarDisabled(sidecar) || len(sidecar.InternalPorts) == 0 {
+		return nil
+	}
+
+	name := constants.WithSidecarSuffix(kg.deployment.Name, sidecar.Name)
+
+	ports := make([]models.Port, 0, len(sidecar.InternalPorts))
+	for _, p := range sidecar.InternalPorts {
+		ports = append(ports, models.Port{
+			Name:       fmt.Sprintf("port-%d", p),
+			Protocol:   "tcp",
+			Port:       p,
+			TargetPort: p,
+		})
+	}
+
+	se := models.ServicePublic{
+		Name:      name,
+		Namespace: kg.namespace,
+		Ports:     ports,
+		Selector: map[string]string{
+			keys.LabelDeployName: name,
+		},
+	}
+
+	if k8sService := kg.deployment.Subsystems.K8s.GetService(name); subsystems.Created(k8sService) {
+		se.CreatedAt = k8sService.CreatedAt
+	}
+
+	return &se
+}
+
+// sidecarHPA generates the K8s HPA for a sidecar app.
+// It returns nil if the sidecar is disabled, or if the main app does not autoscale.
+func (kg *K8sGenerator) sidecarHPA(sidecar *model.App) *models.HpaPublic {
+	mainApp := kg.deployment.GetMainApp()
+	if kg.sidecarDisabled(sidecar) || !mainApp.AutoscalingEnabled() {
+		return nil
+	}
+
+	name := constants.WithSidecarSuffix(kg.deployment.Name, sidecar.Name)
+	cpuTarget, memoryTarget, scaleDownStabilizationSeconds := autoscalingTargets(mainApp)
+
+	hpa := models.HpaPublic{
+		Name:        name,
+		Namespace:   kg.namespace,
+		MinReplicas: 1,
+		MaxReplicas: sidecar.Replicas,
+		Target: models.Target{
+			Kind:       "Deployment",
+			Name:       name,
+			ApiVersion: "apps/v1",
+		},
+		CpuAverageUtilization:         cpuTarget,
+		MemoryAverageUtilization:      memoryTarget,
+		ScaleDownStabilizationSeconds: scaleDownStabilizationSeconds,
+	}
+
+	if h := kg.deployment.Subsystems.K8s.GetHPA(name); subsystems.Created(h) {
+		hpa.CreatedAt = h.CreatedAt
+	}
+
+	return &hpa
+}
+
+// sidecarDisabled returns whether a sidecar app should not run.
+// Sidecars only serve the main app, so they are disabled while the main app has no replicas.
+func (kg *K8sGenerator) sidecarDisabled(sidecar *model.App) bool {
+	return sidecar.Replicas == 0 || kg.deployment.GetMainApp().Replicas == 0
+}
+
+// autoscalingTargets returns the HPA targets of the autoscaling policy of an app.
+// Targets that are not set in the policy fall back to the configured defaults.
+func autoscalingTargets(app *model.App) (cpuTarget int, memoryTarget int, scaleDownStabilizationSeconds *int) {
+	cpuTarget = config.Config.Deployment.Resources.AutoScale.CpuThreshold
+	memoryTarget = config.Config.Deployment.Resources.AutoScale.MemoryThreshold
+
+	if autoscaling := app.Autoscaling; autoscaling != nil {
+		if autoscaling.CpuTarget > 0 {
+			cpuTarget = autoscaling.CpuTarget
+		}
+
+		if autoscaling.MemoryTarget > 0 {
+			memoryTarget = autoscaling.MemoryTarget
+		}
+
+		scaleDownStabilizationSeconds = autoscaling.ScaleDownStabilizationSeconds
+	}
+
+	return cpuTarget, memoryTarget, scaleDownStabilizationSeconds
+}
+
+// volumes returns the volumes of all apps in the deployment.
+// Sidecar volume names are prefixed with the sidecar name to avoid clashes with the main app.
+func (kg *K8sGenerator) volumes() []model.DeploymentVolume {
+	volumes := make([]model.DeploymentVolume, 0)
+	volumes = append(volumes, kg.deployment.GetMainApp().Volumes...)
+
+	for _, sidecar := range kg.deployment.GetSidecarApps() {
+		for _, volume := range sidecar.Volumes {
+			volume.Name = appVolumeName(&sidecar, volume.Name)
+			volumes = append(volumes, volume)
+		}
+	}
+
+	return volumes
+}
+
+// envVar converts an app's env to its K8s representation.
+// Secret envs are referenced from the deployment's env secret instead of being set directly.
+func (kg *K8sGenerator) envVar(app *model.App, env *model.DeploymentEnv) models.EnvVar {
+	if env.Secret {
+		return models.EnvVar{
+			Name: env.Name,
+			SecretRef: &models.SecretKeyRef{
+				Name: constants.WithEnvSecretSuffix(kg.deployment.Name),
+				Key:  envSecretKey(app, env),
+			},
+		}
+	}
+
+	return models.EnvVar{
+		Name:  env.Name,
+		Value: env.Value,
+	}
+}
+
+// envSecretData returns the decrypted secret envs of all apps in the deployment.
+// Envs that cannot be decrypted are skipped.
+func (kg *K8sGenerator) envSecretData() map[string][]byte {
+	data := make(map[string][]byte)
+
+	apps := append([]model.App{*kg.deployment.GetMainApp()}, kg.deployment.GetSidecarApps()...)
+
+	// Secrets that were removed by the update are kept until the rollout is done, since the old version still uses them
+	if kg.deployment.Rollout != nil {
+		apps = append([]model.App{*kg.deployment.GetStableApp()}, apps...)
+	}
+
+	for _, app := range apps {
+		for _, env := range app.Envs {
+			if !env.Secret {
+				continue
+			}
+
+			if env.Value == "" {
+				data[envSecretKey(&app, &env)] = []byte{}
+				continue
+			}
+
+			key, err := config.Config.Deployment.GetEnvEncryptionKey()
+			if err != nil {
+				utils.PrettyPrintError(fmt.Errorf("failed to get env encryption key for deployment %s. details: %w", kg.deployment.Name, err))
+				return data
+			}
+
+			value, err := cryptoutils.Decrypt(key, env.Value)
+			if err != nil {
+				utils.PrettyPrintError(fmt.Errorf("failed to decrypt secret env %s for deployment %s. details: %w", env.Name, kg.deployment.Name, err))
+				continue
+			}
+
+			data[envSecretKey(&app, &env)] = []byte(value)
+		}
+	}
+
+	return data
+}
+
+// envSecretKey returns the key of an env in the deployment's env secret
+func envSecretKey(app *model.App, env *model.DeploymentEnv) string {
+	return fmt.Sprintf("%s.%s", app.Name, env.Name)
+}
+
+// secretHash returns a hash of the app's encrypted secret envs.
+// Since every new value is encrypted with a new nonce, the hash changes whenever a secret is rotated.
+func secretHash(app *model.App) string {
+	secrets := make(map[string]string)
+	for _, env := range app.Envs {
+		if env.Secret {
+			secrets[env.Name] = env.Value
+		}
+	}
+
+	if len(secrets) == 0 {
+		return ""
+	}
+
+	hash, err := hashutils.HashDeterministicJSON(secrets)
+	if err != nil {
+		utils.PrettyPrintError(fmt.Errorf("failed to hash secret envs for app %s. details: %w", app.Name, err))
+		return ""
+	}
+
+	return hash
+}
+
+// probePublic converts a probe to its K8s representation.
+// Unset fields are given the K8s defaults, so that the generated probe matches what is read back from K8s.
+func probePublic(probe *model.DeploymentProbe, fallbackPort int) *models.Probe {
+	if probe == nil {
+		return nil
+	}
+
+	port := probe.Port
+	if port == 0 {
+		port = fallbackPort
+	}
+
+	public := &models.Probe{
+		InitialDelaySeconds: probe.InitialDelaySeconds,
+		PeriodSeconds:       probe.PeriodSeconds,
+		TimeoutSeconds:      probe.TimeoutSeconds,
+		FailureThreshold:    probe.FailureThreshold,
+	}
+
+	if public.PeriodSeconds == 0 {
+		public.PeriodSeconds = 10
+	}
+
+	if public.TimeoutSeconds == 0 {
+		public.TimeoutSeconds = 1
+	}
+
+	if public.FailureThreshold == 0 {
+		public.FailureThreshold = 3
+	}
+
+	switch probe.Type {
+	case model.ProbeTypeHttp:
+		public.HttpGet = &models.ProbeHttpGet{Path: probe.Path, Port: port}
+	case model.ProbeTypeTcp:
+		public.TcpSocket = &models.ProbeTcpSocket{Port: port}
+	case model.ProbeTypeExec:
+		public.Exec = &models.ProbeExec{Command: probe.Command}
+	default:
+		return nil
+	}
+
+	return public
+}
+
 // makeValidK8sName returns a valid Kubernetes name
 // It returns a string that conforms to the Kubernetes naming convention (RFC 1123)
 func makeValidK8sName(name string) string {
@@ -713,6 +1247,16 @@ func makeValidK8sName(name string) string {
 	return validName
 }
 
+// appVolumeName returns the volume name for an app.
+// The main app uses the volume name as is, while sidecars prefix it with the app name.
+func appVolumeName(app *model.App, volumeName string) string {
+	if app.Name == constants.AppName {
+		return volumeName
+	}
+
+	return fmt.Sprintf("%s-%s", app.Name, volumeName)
+}
+
 // deploymentPvName returns the PV name for a deployment
 func deploymentPvName(deployment *model.Deployment, volumeName string) string {
 	return fmt.Sprintf("%s-%s", deployment.Name, makeValidK8sName(volumeName))
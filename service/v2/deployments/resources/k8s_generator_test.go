@@ -0,0 +1,313 @@
+package resources
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	configModels "github.com/kthcloud/go-deploy/models/config"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
+	"github.com/kthcloud/go-deploy/utils"
+)
+
+func TestProbePublicRoundTrip(t *testing.T) {
+	probes := []*model.DeploymentProbe{
+		{Type: model.ProbeTypeHttp, Path: "/healthz"},
+		{Type: model.ProbeTypeHttp, Path: "/ready", Port: 9090, PeriodSeconds: 5, FailureThreshold: 6},
+		{Type: model.ProbeTypeTcp, InitialDelaySeconds: 15, TimeoutSeconds: 3},
+		{Type: model.ProbeTypeExec, Command: []string{"cat", "/tmp/healthy"}},
+	}
+
+	for _, probe := range probes {
+		public := probePublic(probe, 8080)
+		if public == nil {
+			t.Fatalf("expected probe for type %s, got nil", probe.Type)
+		}
+
+		// The repair loop compares the generated probe with the one read back from K8s,
+		// so converting back and forth must not change it
+		readBack := models.ProbeFromK8s(public.ToK8sProbe())
+		if !reflect.DeepEqual(public, readBack) {
+			t.Errorf("probe changed after round trip:\n got  %+v\n want %+v", readBack, public)
+		}
+	}
+}
+
+func TestProbePublicDefaults(t *testing.T) {
+	public := probePublic(&model.DeploymentProbe{Type: model.ProbeTypeTcp}, 8080)
+	if public == nil {
+		t.Fatal("expected probe, got nil")
+	}
+
+	if public.TcpSocket == nil || public.TcpSocket.Port != 8080 {
+		t.Errorf("expected tcp probe on fallback port 8080, got %+v", public.TcpSocket)
+	}
+
+	if public.PeriodSeconds != 10 || public.TimeoutSeconds != 1 || public.FailureThreshold != 3 {
+		t.Errorf("expected K8s default timings, got period=%d timeout=%d failure=%d", public.PeriodSeconds, public.TimeoutSeconds, public.FailureThreshold)
+	}
+
+	if probePublic(nil, 8080) != nil {
+		t.Error("expected nil probe for nil input")
+	}
+}
+
+func TestSecretHash(t *testing.T) {
+	app := &model.App{
+		Name: "main",
+		Envs: []model.DeploymentEnv{
+			{Name: "PLAIN", Value: "value"},
+		},
+	}
+
+	if hash := secretHash(app); hash != "" {
+		t.Errorf("expected empty hash for app without secret envs, got %s", hash)
+	}
+
+	app.Envs = append(app.Envs, model.DeploymentEnv{Name: "TOKEN", Value: "enc:first", Secret: true})
+	first := secretHash(app)
+	if first == "" {
+		t.Fatal("expected hash for app with secret envs, got empty string")
+	}
+
+	app.Envs[0].Value = "changed"
+	if secretHash(app) != first {
+		t.Error("expected hash to only depend on secret envs")
+	}
+
+	app.Envs[1].Value = "enc:second"
+	if secretHash(app) == first {
+		t.Error("expected hash to change when a secret env is rotated")
+	}
+}
+
+func TestScalingSchedule(t *testing.T) {
+	// Asleep on weekends and between 22:00 and 07:00, and scaled down during lunch on weekdays
+	schedule := &model.ScalingSchedule{
+		Windows: []model.ScalingWindow{
+			{Start: "0 22 * * *", End: "0 7 * * *", Replicas: 0},
+			{Start: "0 0 * * 6", End: "0 0 * * 1", Replicas: 0},
+			{Start: "0 12 * * 1-5", End: "0 13 * * 1-5", Replicas: 1},
+		},
+		Timezone: "UTC",
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		replicas *int
+		wakeUpAt *time.Time
+	}{
+		{name: "Weekday", now: time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)},
+		{name: "Weekday lunch", now: time.Date(2026, 10, 14, 12, 30, 0, 0, time.UTC), replicas: utils.PtrOf(1)},
+		{name: "Weekday night", now: time.Date(2026, 10, 14, 23, 0, 0, 0, time.UTC), replicas: utils.PtrOf(0), wakeUpAt: utils.PtrOf(time.Date(2026, 10, 15, 7, 0, 0, 0, time.UTC))},
+		{name: "Friday night", now: time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC), replicas: utils.PtrOf(0), wakeUpAt: utils.PtrOf(time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC))},
+		{name: "Saturday noon", now: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC), replicas: utils.PtrOf(0), wakeUpAt: utils.PtrOf(time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC))},
+		{name: "Window end", now: time.Date(2026, 10, 15, 7, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window := schedule.ActiveWindow(tt.now)
+			if (window == nil) != (tt.replicas == nil) || (window != nil && window.Replicas != *tt.replicas) {
+				t.Errorf("unexpected active window %+v", window)
+			}
+
+			wakeUpAt := schedule.WakeUpAt(tt.now)
+			if (wakeUpAt == nil) != (tt.wakeUpAt == nil) || (wakeUpAt != nil && !wakeUpAt.Equal(*tt.wakeUpAt)) {
+				t.Errorf("WakeUpAt() = %v, want %v", wakeUpAt, tt.wakeUpAt)
+			}
+		})
+	}
+
+	// Quota is counted at the peak of the schedule
+	app := &model.App{Replicas: 0, Autoscaling: &model.DeploymentAutoscaling{Disabled: true}}
+	if limit := app.GetPeakReplicaLimit(&model.ScalingSchedule{ScaledFrom: utils.PtrOf(4), Windows: schedule.Windows}); limit != 4 {
+		t.Errorf("expected a peak of 4 replicas, got %d", limit)
+	}
+}
+
+func TestScheduleWakeUpIngress(t *testing.T) {
+	zone := &configModels.Zone{}
+	zone.Domains.ParentDeployment = "app.example.com"
+
+	// A window that started this minute and ends on the next leap day
+	deployment := &model.Deployment{
+		Name: "app",
+		Apps: map[string]model.App{
+			"main": {Name: "main", InternalPort: 8080, Replicas: 0, Visibility: model.VisibilityPublic},
+		},
+		Schedule: &model.ScalingSchedule{
+			Windows:    []model.ScalingWindow{{Start: "* * * * *", End: "0 0 29 2 *", Replicas: 0}},
+			ScaledFrom: utils.PtrOf(2),
+		},
+	}
+
+	ingress := K8s(deployment, zone, nil, "deploy").Ingresses()[0]
+	if ingress.RewriteTarget == nil || !strings.HasPrefix(*ingress.RewriteTarget, "/?wakeUpAt=") {
+		t.Fatalf("expected the fallback ingress to include the wake-up time, got %+v", ingress.RewriteTarget)
+	}
+
+	wakeUpAt, err := time.Parse(time.RFC3339, strings.TrimPrefix(*ingress.RewriteTarget, "/?wakeUpAt="))
+	if err != nil || !wakeUpAt.After(time.Now()) || wakeUpAt.Month() != time.February || wakeUpAt.Day() != 29 {
+		t.Errorf("expected a wake-up time on the next leap day, got %s", *ingress.RewriteTarget)
+	}
+
+	// A paused deployment does not wake up with the schedule
+	deployment.Paused = true
+	if ingress := K8s(deployment, zone, nil, "deploy").Ingresses()[0]; ingress.RewriteTarget != nil {
+		t.Errorf("expected no wake-up time for a paused deployment, got %s", *ingress.RewriteTarget)
+	}
+}
+
+func TestBuildJob(t *testing.T) {
+	kg := &K8sGenerator{
+		namespace: "deploy",
+		deployment: &model.Deployment{
+			Name: "app",
+			Type: model.DeploymentTypeGit,
+			Git: &model.GitSource{
+				URL:            "https://github.com/kthcloud/go-deploy.git",
+				Branch:         "dev",
+				DockerfilePath: "build/Dockerfile",
+			},
+			Apps: map[string]model.App{
+				"main": {Name: "main", CpuCores: 0.5, RAM: 1},
+			},
+		},
+	}
+
+	job := kg.BuildJob("registry.example.com/deploy-user/app")
+	if job == nil {
+		t.Fatal("expected build job, got nil")
+	}
+
+	expectedArgs := []string{
+		"--context=git://github.com/kthcloud/go-deploy.git#refs/heads/dev",
+		"--dockerfile=build/Dockerfile",
+		"--destination=registry.example.com/deploy-user/app",
+	}
+	if !reflect.DeepEqual(job.Args, expectedArgs) {
+		t.Errorf("unexpected args:\n got  %v\n want %v", job.Args, expectedArgs)
+	}
+
+	if len(job.Volumes) != 1 || job.Volumes[0].SecretName == nil || *job.Volumes[0].SecretName != "app-image-pull-secret" {
+		t.Errorf("expected the image pull secret to be mounted, got %+v", job.Volumes)
+	}
+
+	// Without configured build resources, the build is limited like the main app
+	if job.Resources.Limits.CPU != "500m" || job.Resources.Limits.Memory != "1000Mi" {
+		t.Errorf("expected the limits of the main app, got %+v", job.Resources.Limits)
+	}
+
+	kg.deployment.Type = model.DeploymentTypeCustom
+	if kg.BuildJob("registry.example.com/deploy-user/app") != nil {
+		t.Error("expected no build job for a custom deployment")
+	}
+}
+
+func TestRolloutResources(t *testing.T) {
+	zone := &configModels.Zone{}
+	zone.Domains.ParentDeployment = "app.example.com"
+
+	deployment := &model.Deployment{
+		Name:     "app",
+		Strategy: &model.RolloutStrategy{Type: model.RolloutStrategyCanary, CanaryWeight: 25},
+		Apps: map[string]model.App{
+			"main": {Name: "main", Image: "nginx:1.26", InternalPort: 8080, Replicas: 3, Visibility: model.VisibilityPublic},
+		},
+		Rollout: &model.Rollout{
+			Strategy: model.RolloutStrategyCanary,
+			Stable:   model.App{Name: "main", Image: "nginx:1.25", InternalPort: 80, Replicas: 1},
+		},
+	}
+
+	kg := K8s(deployment, zone, nil, "deploy")
+
+	deployments := kg.Deployments()
+	if len(deployments) != 2 {
+		t.Fatalf("expected the main and rollout deployments, got %d", len(deployments))
+	}
+
+	if deployments[0].Name != "app" || deployments[0].Image != "nginx:1.25" {
+		t.Errorf("expected the main deployment to keep the stable image, got %s running %s", deployments[0].Name, deployments[0].Image)
+	}
+
+	if deployments[1].Name != "app-rollout" || deployments[1].Image != "nginx:1.26" || deployments[1].FixedReplicas != 1 {
+		t.Errorf("expected the rollout deployment to run 1 replica of the new image, got %s running %d of %s", deployments[1].Name, deployments[1].FixedReplicas, deployments[1].Image)
+	}
+
+	services := kg.Services()
+	if len(services) != 2 || services[0].Ports[0].Port != 80 || services[1].Name != "app-rollout" || services[1].Ports[0].Port != 8080 {
+		t.Errorf("expected a service for each version on its own port, got %+v", services)
+	}
+
+	ingresses := kg.Ingresses()
+	if len(ingresses) != 2 {
+		t.Fatalf("expected the main and canary ingresses, got %d", len(ingresses))
+	}
+
+	canary := ingresses[1]
+	if canary.ServiceName != "app-rollout" || canary.CanaryWeight == nil || *canary.CanaryWeight != 25 || !reflect.DeepEqual(canary.Hosts, ingresses[0].Hosts) {
+		t.Errorf("expected a canary ingress with weight 25 for the main host, got %+v", canary)
+	}
+
+	deployment.Rollout.Strategy = model.RolloutStrategyBlueGreen
+	preview := K8s(deployment, zone, nil, "deploy").Ingresses()[1]
+	if preview.CanaryWeight != nil || !reflect.DeepEqual(preview.Hosts, []string{"app-rollout.app.example.com"}) {
+		t.Errorf("expected a preview ingress on its own host, got %+v", preview)
+	}
+
+	// Promoting removes the rollout, which leaves only the new version
+	deployment.Rollout = nil
+	kg = K8s(deployment, zone, nil, "deploy")
+	if deployments := kg.Deployments(); len(deployments) != 1 || deployments[0].Image != "nginx:1.26" {
+		t.Errorf("expected only the main deployment with the new image, got %+v", deployments)
+	}
+
+	if len(kg.Ingresses()) != 1 {
+		t.Error("expected only the main ingress without a rollout")
+	}
+}
+
+func TestSidecarsFollowMainApp(t *testing.T) {
+	zone := &configModels.Zone{}
+	zone.Domains.ParentDeployment = "app.example.com"
+
+	deployment := &model.Deployment{
+		Name: "app",
+		Apps: map[string]model.App{
+			"main":  {Name: "main", InternalPort: 8080, Replicas: 2, Visibility: model.VisibilityPublic, Autoscaling: &model.DeploymentAutoscaling{CpuTarget: 50}},
+			"cache": {Name: "cache", Replicas: 3},
+		},
+	}
+
+	hpas := K8s(deployment, zone, nil, "deploy").HPAs()
+	if len(hpas) != 2 {
+		t.Fatalf("expected an HPA for the main app and the sidecar, got %d", len(hpas))
+	}
+
+	for _, hpa := range hpas {
+		if hpa.CpuAverageUtilization != 50 {
+			t.Errorf("expected HPA %s to use the autoscaling policy, got a cpu target of %d", hpa.Name, hpa.CpuAverageUtilization)
+		}
+	}
+
+	// Sidecars are disabled along with the main app
+	main := deployment.Apps["main"]
+	main.Replicas = 0
+	deployment.Apps["main"] = main
+
+	g := K8s(deployment, zone, nil, "deploy")
+	for _, dep := range g.Deployments() {
+		if !dep.Disabled {
+			t.Errorf("expected deployment %s to be disabled", dep.Name)
+		}
+	}
+
+	if hpas := g.HPAs(); len(hpas) != 0 {
+		t.Errorf("expected no HPAs, got %d", len(hpas))
+	}
+}
@@ -0,0 +1,141 @@
+package deployments
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_repo"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_revision_repo"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	sUtils "github.com/kthcloud/go-deploy/service/utils"
+	"github.com/kthcloud/go-deploy/service/v2/deployments/opts"
+	"github.com/kthcloud/go-deploy/utils"
+)
+
+// maxRevisions is the number of revisions that are kept for each deployment.
+// Older revisions are removed when a new revision is created.
+const maxRevisions = 50
+
+// ListRevisions lists the revisions of a deployment, with the latest revision first.
+//
+// It does not check access to the deployment, which should be done by the caller.
+// It returns an error if the deployment is not found.
+func (c *Client) ListRevisions(id string, opts ...opts.ListRevisionsOpts) ([]model.DeploymentRevision, error) {
+	o := sUtils.GetFirstOrDefault(opts)
+
+	deployment, err := c.Deployment(id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if deployment == nil {
+		return nil, sErrors.ErrDeploymentNotFound
+	}
+
+	rrc := deployment_revision_repo.New().WithDeploymentID(id)
+	if o.Pagination != nil {
+		rrc.WithPagination(o.Pagination.Page, o.Pagination.PageSize)
+	}
+
+	return rrc.List()
+}
+
+// GetRevision gets a revision of a deployment by its version.
+//
+// It does not check access to the deployment, which should be done by the caller.
+// It returns an error if the deployment is not found, and nil if the revision is not found.
+func (c *Client) GetRevision(id string, version int) (*model.DeploymentRevision, error) {
+	deployment, err := c.Deployment(id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if deployment == nil {
+		return nil, sErrors.ErrDeploymentNotFound
+	}
+
+	return deployment_revision_repo.New().WithDeploymentID(id).WithVersion(version).Get()
+}
+
+// RecordImagePush records a revision with the digest of an image pushed to the deployment's repository.
+//
+// If the deployment was rolled back to an earlier build, it is moved back to the latest build,
+// since a push means that the user wants the new image to run.
+func (c *Client) RecordImagePush(id, digest string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to record image push for deployment %s. details: %w", id, err)
+	}
+
+	d, err := c.Get(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if d == nil {
+		return sErrors.ErrDeploymentNotFound
+	}
+
+	mainApp := d.GetMainApp()
+
+	if repository := createImagePath(d.OwnerID, d.Name); d.HasOwnImage() && mainApp.Image != repository {
+		err = deployment_repo.New().UpdateWithParams(id, &model.DeploymentUpdateParams{Image: &repository})
+		if err != nil {
+			return makeError(err)
+		}
+
+		d, err = c.Refresh(id)
+		if err != nil {
+			return makeError(err)
+		}
+
+		err = c.K8s().Repair(id)
+		if err != nil {
+			return makeError(err)
+		}
+	}
+
+	// The deployment is already updated, so failing to record it is not a reason to run the job again
+	err = c.createRevision(d, digest)
+	if err != nil {
+		utils.PrettyPrintError(fmt.Errorf("failed to create revision for deployment %s. details: %w", d.ID, err))
+	}
+
+	return nil
+}
+
+// createRevision stores a snapshot of the deployment's main app as a new revision.
+// No revision is created if the snapshot is the same as the latest revision.
+func (c *Client) createRevision(d *model.Deployment, imageDigest string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to create revision for deployment %s. details: %w", d.ID, err)
+	}
+
+	mainApp := d.GetMainApp()
+
+	params := &model.DeploymentRevisionCreateParams{}
+	params.FromApp(mainApp, imageDigest)
+
+	latest, err := deployment_revision_repo.New().WithDeploymentID(d.ID).GetLatest()
+	if err != nil {
+		return makeError(err)
+	}
+
+	if latest != nil && latest.SameAs(params) {
+		return nil
+	}
+
+	revision, err := deployment_revision_repo.New().Create(uuid.NewString(), d.ID, params)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if revision.Version > maxRevisions {
+		err = deployment_revision_repo.New().WithDeploymentID(d.ID).OlderThan(revision.Version - maxRevisions + 1).Erase()
+		if err != nil {
+			return makeError(err)
+		}
+	}
+
+	return nil
+}
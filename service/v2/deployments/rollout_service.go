@@ -0,0 +1,207 @@
+package deployments
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/config"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_repo"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	"github.com/kthcloud/go-deploy/utils"
+	"github.com/kthcloud/go-deploy/utils/cryptoutils"
+)
+
+// Promote finishes the active rollout of the deployment by making the new version the only one.
+//
+// The main K8s deployment is updated to the new version, and the K8s resources of the rollout are removed.
+func (c *Client) Promote(id string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to promote rollout of deployment %s. details: %w", id, err)
+	}
+
+	d, err := c.Get(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if d == nil {
+		return sErrors.ErrDeploymentNotFound
+	}
+
+	if d.Rollout == nil {
+		return nil
+	}
+
+	err = deployment_repo.New().PromoteRollout(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	c.addCommandLog(id, "Promotion of rollout requested")
+
+	err = c.K8s().Repair(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	return nil
+}
+
+// Abort cancels the active rollout of the deployment by restoring the version it replaced.
+//
+// The K8s resources of the rollout are removed, and a revision is created for the restored version.
+func (c *Client) Abort(id string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to abort rollout of deployment %s. details: %w", id, err)
+	}
+
+	d, err := c.Get(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if d == nil {
+		return sErrors.ErrDeploymentNotFound
+	}
+
+	if d.Rollout == nil {
+		return nil
+	}
+
+	err = deployment_repo.New().AbortRollout(id, &d.Rollout.Stable)
+	if err != nil {
+		return makeError(err)
+	}
+
+	c.addCommandLog(id, "Abort of rollout requested")
+
+	err = c.K8s().Repair(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	d, err = c.Refresh(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	// The deployment is already updated, so failing to record it is not a reason to run the job again
+	err = c.createRevision(d, "")
+	if err != nil {
+		utils.PrettyPrintError(fmt.Errorf("failed to create revision for deployment %s. details: %w", d.ID, err))
+	}
+
+	return nil
+}
+
+// startRollout starts a rollout if the update changes the pods of the main app, and the deployment
+// uses the blue/green or canary strategy. If the update also changes the strategy, the new strategy is used.
+//
+// A deployment that runs no replicas is updated in place, since there is no traffic to move.
+// So is a deployment with auth visibility, since its traffic passes through the auth proxy, which only reaches the stable version.
+// If a rollout is already active, the update replaces the new version, and the version it replaces is kept.
+func (c *Client) startRollout(d *model.Deployment, params *model.DeploymentUpdateParams) error {
+	strategy := d.GetRolloutStrategy()
+	if params.Strategy != nil {
+		strategy = params.Strategy.Type
+	}
+
+	mainApp := d.GetMainApp()
+	if strategy == model.RolloutStrategyRolling || d.Rollout != nil || !changesPods(mainApp, params) {
+		return nil
+	}
+
+	visibility := mainApp.Visibility
+	if params.Visibility != nil {
+		visibility = *params.Visibility
+	}
+
+	if d.Paused || mainApp.Replicas == 0 || visibility == model.VisibilityAuth {
+		return nil
+	}
+
+	err := deployment_repo.New().StartRollout(d.ID, &model.Rollout{
+		Strategy:  strategy,
+		Stable:    *mainApp,
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	c.addCommandLog(d.ID, fmt.Sprintf("Update started a %s rollout. Use the promote or abort command to finish it", strategy))
+
+	return nil
+}
+
+// changesPods returns true if the update changes the pod template of the main app.
+// These are the settings that are restored when a rollout is aborted.
+func changesPods(app *model.App, params *model.DeploymentUpdateParams) bool {
+	switch {
+	case params.Image != nil && *params.Image != app.Image,
+		params.Envs != nil && !equalEnvs(*params.Envs, app.Envs),
+		params.Args != nil && !slices.Equal(*params.Args, app.Args),
+		params.InitCommands != nil && !slices.Equal(*params.InitCommands, app.InitCommands),
+		params.InternalPort != nil && *params.InternalPort != app.InternalPort,
+		params.InternalPorts != nil && !slices.Equal(*params.InternalPorts, app.InternalPorts),
+		params.Volumes != nil && !slices.Equal(*params.Volumes, app.Volumes),
+		params.GPUs != nil && !slices.Equal(*params.GPUs, app.GPUs),
+		params.CpuCores != nil && *params.CpuCores != app.CpuCores,
+		params.RAM != nil && *params.RAM != app.RAM:
+		return true
+	}
+
+	if params.Probes == nil {
+		return false
+	}
+
+	// Empty probes remove the current probes
+	if app.Probes == nil || app.Probes.Empty() {
+		return !params.Probes.Empty()
+	}
+
+	return !reflect.DeepEqual(*params.Probes, *app.Probes)
+}
+
+// equalEnvs returns true if the envs have the same names, values and secret flags, in the same order.
+// Secret values are encrypted with a random nonce, so they are decrypted before being compared.
+// If a secret value cannot be decrypted, the envs are considered different.
+func equalEnvs(a, b []model.DeploymentEnv) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	var key []byte
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Secret != b[i].Secret {
+			return false
+		}
+
+		if a[i].Value == b[i].Value {
+			continue
+		}
+
+		if !a[i].Secret {
+			return false
+		}
+
+		if key == nil {
+			var err error
+			key, err = config.Config.Deployment.GetEnvEncryptionKey()
+			if err != nil {
+				return false
+			}
+		}
+
+		valueA, errA := cryptoutils.Decrypt(key, a[i].Value)
+		valueB, errB := cryptoutils.Decrypt(key, b[i].Value)
+		if errA != nil || errB != nil || valueA != valueB {
+			return false
+		}
+	}
+
+	return true
+}
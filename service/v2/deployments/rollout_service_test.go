@@ -0,0 +1,48 @@
+package deployments
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/config"
+	"github.com/kthcloud/go-deploy/utils/cryptoutils"
+)
+
+func TestEqualEnvs(t *testing.T) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	config.Config.Deployment.EnvEncryptionKey = base64.StdEncoding.EncodeToString(key)
+
+	encrypt := func(value string) string {
+		encrypted, err := cryptoutils.Encrypt(key, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return encrypted
+	}
+
+	current := []model.DeploymentEnv{{Name: "PORT", Value: "8080"}, {Name: "TOKEN", Value: encrypt("secret"), Secret: true}}
+
+	tests := map[string]struct {
+		envs     []model.DeploymentEnv
+		expected bool
+	}{
+		"same ciphertext":      {envs: []model.DeploymentEnv{current[0], current[1]}, expected: true},
+		"same secret value":    {envs: []model.DeploymentEnv{current[0], {Name: "TOKEN", Value: encrypt("secret"), Secret: true}}, expected: true},
+		"new secret value":     {envs: []model.DeploymentEnv{current[0], {Name: "TOKEN", Value: encrypt("other"), Secret: true}}, expected: false},
+		"no longer secret":     {envs: []model.DeploymentEnv{current[0], {Name: "TOKEN", Value: "secret"}}, expected: false},
+		"new plain value":      {envs: []model.DeploymentEnv{{Name: "PORT", Value: "9090"}, current[1]}, expected: false},
+		"removed env":          {envs: []model.DeploymentEnv{current[0]}, expected: false},
+		"invalid secret value": {envs: []model.DeploymentEnv{current[0], {Name: "TOKEN", Value: "secret", Secret: true}}, expected: false},
+	}
+
+	for name, tt := range tests {
+		if equal := equalEnvs(tt.envs, current); equal != tt.expected {
+			t.Errorf("%s: equalEnvs = %v, expected %v", name, equal, tt.expected)
+		}
+	}
+}
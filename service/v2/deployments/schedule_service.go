@@ -0,0 +1,90 @@
+package deployments
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/models/version"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/deployment_repo"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	jobOpts "github.com/kthcloud/go-deploy/service/v2/jobs/opts"
+	"github.com/kthcloud/go-deploy/utils"
+)
+
+// ApplySchedule scales the main app of a deployment according to its scaling schedule.
+//
+// When a window starts, the current replicas are stored and an update job scales the app to the replicas of the window.
+// When the last active window ends, an update job restores the stored replicas.
+// Replicas changed while a window is active are kept until the window ends.
+//
+// Deployments without a schedule, and deployments that are busy, are skipped.
+func (c *Client) ApplySchedule(id string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to apply scaling schedule for deployment %s. details: %w", id, err)
+	}
+
+	d, err := c.Get(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if d == nil {
+		return sErrors.ErrDeploymentNotFound
+	}
+
+	if d.Schedule == nil || !d.Ready() || d.DoingActivity(model.ActivityUpdating) {
+		return nil
+	}
+
+	mainApp := d.GetMainApp()
+	window := d.Schedule.ActiveWindow(time.Now())
+
+	var replicas int
+	var scaledFrom, scaledTo *int
+	var logLine string
+	switch {
+	case window != nil && d.Schedule.ScaledFrom == nil:
+		replicas = window.Replicas
+		scaledFrom, scaledTo = &mainApp.Replicas, &window.Replicas
+		logLine = fmt.Sprintf("Scaling window %s to %s started. Scaling to %d replicas", window.Start, window.End, replicas)
+	case window != nil && (d.Schedule.ScaledTo == nil || *d.Schedule.ScaledTo != window.Replicas):
+		// Another window with other replicas became active, e.g. when overlapping windows end at different times
+		replicas = window.Replicas
+		scaledFrom, scaledTo = d.Schedule.ScaledFrom, &window.Replicas
+		logLine = fmt.Sprintf("Scaling window %s to %s is active. Scaling to %d replicas", window.Start, window.End, replicas)
+	case window == nil && d.Schedule.ScaledFrom != nil:
+		replicas = *d.Schedule.ScaledFrom
+		logLine = fmt.Sprintf("Scaling window ended. Restoring %d replicas", replicas)
+	default:
+		return nil
+	}
+
+	err = deployment_repo.New().SetScheduleScaled(id, scaledFrom, scaledTo)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if replicas != mainApp.Replicas {
+		err = c.V2.Jobs().Create(uuid.New().String(), d.OwnerID, model.JobUpdateDeployment, version.V2, map[string]interface{}{
+			"id":         id,
+			"params":     body.DeploymentUpdate{Replicas: &replicas},
+			"noRevision": true,
+		}, jobOpts.CreateOpts{})
+		if err != nil {
+			// The window is applied again on the next run, which needs the state from before it was applied
+			resetErr := deployment_repo.New().SetScheduleScaled(id, d.Schedule.ScaledFrom, d.Schedule.ScaledTo)
+			if resetErr != nil {
+				utils.PrettyPrintError(fmt.Errorf("failed to reset scaling schedule state for deployment %s. details: %w", id, resetErr))
+			}
+
+			return makeError(err)
+		}
+	}
+
+	c.addCommandLog(id, logLine)
+
+	return nil
+}
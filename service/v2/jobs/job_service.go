@@ -1,6 +1,8 @@
 package jobs
 
 import (
+	"fmt"
+
 	"github.com/kthcloud/go-deploy/dto/v2/body"
 	"github.com/kthcloud/go-deploy/models/model"
 	"github.com/kthcloud/go-deploy/pkg/db/resources/job_repo"
@@ -76,12 +78,31 @@ func (c *Client) List(opt ...opts.ListOpts) ([]model.Job, error) {
 }
 
 // Create creates a new job.
-func (c *Client) Create(id, userID, jobType, version string, args map[string]interface{}) error {
+//
+// If the job depends on other jobs, it is not run until they are completed,
+// and it is terminated if any of them is terminated.
+func (c *Client) Create(id, userID, jobType, version string, args map[string]interface{}, opts ...opts.CreateOpts) error {
+	o := utils.GetFirstOrDefault(opts)
+
+	if len(o.DependsOn) > 0 {
+		return job_repo.New().CreateWithDependencies(id, userID, jobType, version, o.DependsOn, args)
+	}
+
+	if o.RunAfter != nil {
+		return job_repo.New().CreateScheduled(id, userID, jobType, version, *o.RunAfter, args)
+	}
+
 	return job_repo.New().Create(id, userID, jobType, version, args)
 }
 
 // Update updates a job.
+//
+// Only admins can update jobs, except for cancelling, which the owner of the job can do as well.
 func (c *Client) Update(id string, jobUpdateDTO *body.JobUpdate) (*model.Job, error) {
+	if jobUpdateDTO.Status != nil && *jobUpdateDTO.Status == model.JobStatusCancelled {
+		return c.Cancel(id)
+	}
+
 	if c.V2.Auth() != nil && !c.V2.Auth().User.IsAdmin {
 		return nil, sErrors.ErrForbidden
 	}
@@ -99,6 +120,39 @@ func (c *Client) Update(id string, jobUpdateDTO *body.JobUpdate) (*model.Job, er
 	return c.RefreshJob(id, jmc)
 }
 
+// Cancel cancels a job.
+//
+// A running job is notified through its context and stops at the next step that honours it.
+// It returns nil if the job is not found, and ErrJobFinished if the job is already finished.
+func (c *Client) Cancel(id string) (*model.Job, error) {
+	job, err := c.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job == nil {
+		return nil, nil
+	}
+
+	reason := "cancelled by system"
+	if c.V2.HasAuth() {
+		reason = fmt.Sprintf("cancelled by user %s", c.V2.Auth().User.ID)
+	}
+
+	jmc := job_repo.New()
+
+	cancelled, err := jmc.MarkCancelled(id, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cancelled {
+		return nil, sErrors.ErrJobFinished
+	}
+
+	return c.RefreshJob(id, jmc)
+}
+
 // Exists checks if a job exists.
 func (c *Client) Exists(id string) (bool, error) {
 	return job_repo.New().ExistsByID(id)
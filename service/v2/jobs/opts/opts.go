@@ -1,6 +1,8 @@
 package opts
 
 import (
+	"time"
+
 	"github.com/kthcloud/go-deploy/service/v2/utils"
 )
 
@@ -20,3 +22,11 @@ type ListOpts struct {
 	Status          []string
 	ExcludeStatus   []string
 }
+
+// CreateOpts is used to pass options to the Create method
+type CreateOpts struct {
+	// DependsOn is the list of job IDs that must be completed before the job can run
+	DependsOn []string
+	// RunAfter is the earliest time the job can run. It is ignored if DependsOn is set
+	RunAfter *time.Time
+}
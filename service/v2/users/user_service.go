@@ -45,10 +45,11 @@ func (c *Client) GetUsage(userID string) (*model.UserUsage, error) {
 	}
 
 	usage := &model.UserUsage{
-		CpuCores: float64(vmUsage.CpuCores) + deploymentUsage.CpuCores,
-		RAM:      float64(vmUsage.RAM) + deploymentUsage.RAM,
-		DiskSize: vmUsage.DiskSize,
-		Gpus:     deploymentUsage.Gpus,
+		CpuCores:  float64(vmUsage.CpuCores) + deploymentUsage.CpuCores,
+		RAM:       float64(vmUsage.RAM) + deploymentUsage.RAM,
+		DiskSize:  vmUsage.DiskSize,
+		Snapshots: vmUsage.Snapshots,
+		Gpus:      deploymentUsage.Gpus,
 	}
 
 	return usage, nil
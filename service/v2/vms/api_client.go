@@ -9,6 +9,7 @@ import (
 	"github.com/kthcloud/go-deploy/service/v2/vms/gpu_leases"
 	"github.com/kthcloud/go-deploy/service/v2/vms/k8s_service"
 	"github.com/kthcloud/go-deploy/service/v2/vms/snapshots"
+	"github.com/kthcloud/go-deploy/service/v2/vms/vm_images"
 )
 
 // Client is the client for the Deployment service.
@@ -43,6 +44,11 @@ func (c *Client) GpuGroups() api.GpuGroups {
 	return gpu_groups.New(c.V2, c.Cache)
 }
 
+// Images returns the client for the VM Images service.
+func (c *Client) Images() api.VmImages {
+	return vm_images.New(c.V2, c.Cache)
+}
+
 // Snapshots returns the client for the Snapshots service.
 func (c *Client) Snapshots() api.Snapshots {
 	return snapshots.New(c.V2, c.Cache)
@@ -50,5 +56,5 @@ func (c *Client) Snapshots() api.Snapshots {
 
 // K8s returns the client for the K8s service.
 func (c *Client) K8s() *k8s_service.Client {
-	return k8s_service.New(c.Cache)
+	return k8s_service.New(c.Cache).WithContext(c.V2.Context())
 }
@@ -0,0 +1,90 @@
+package vms
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/vm_log_repo"
+	"github.com/kthcloud/go-deploy/pkg/log"
+	"github.com/kthcloud/go-deploy/service/constants"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	"github.com/kthcloud/go-deploy/service/v2/vms/opts"
+	"github.com/kthcloud/go-deploy/utils"
+)
+
+const (
+	// ConsoleFetchPeriod is the period between each fetch of console logs from the database.
+	ConsoleFetchPeriod = 300 * time.Millisecond
+)
+
+// SetupConsoleStream sets up a stream of the VM's serial console output.
+//
+// The output is captured by the logger, so the stream reads from the database rather than the console itself.
+// It sends the last history lines first, and then continuously sends new lines until the context is cancelled.
+func (c *Client) SetupConsoleStream(id string, ctx context.Context, handler func(string, string, string, time.Time), history int) error {
+	vm, err := c.Get(id, opts.GetOpts{Shared: true})
+	if err != nil {
+		return err
+	}
+
+	if vm == nil {
+		return sErrors.ErrVmNotFound
+	}
+
+	if vm.BeingDeleted() {
+		log.Println("VM", id, "is being deleted. not setting up console stream")
+		return nil
+	}
+
+	go func() {
+		// fetch history logs
+		logs, err := vm_log_repo.New().WithVmID(id).ListLatest(history)
+		if err != nil {
+			utils.PrettyPrintError(fmt.Errorf("failed to get console logs for vm %s. details: %w", id, err))
+			return
+		}
+
+		for _, item := range logs {
+			handler(model.LogSourceConsole, "[console]", item.Line, item.CreatedAt)
+		}
+
+		// Fetch live logs
+		lastFetched := time.Now()
+		if len(logs) > 0 {
+			lastFetched = logs[len(logs)-1].CreatedAt
+		}
+
+		// Keep-alive packet every 30 seconds
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				handler(constants.MessageSourceKeepAlive, "[keep-alive]", "keep-alive", time.Now())
+			default:
+				time.Sleep(ConsoleFetchPeriod)
+
+				logs, err = vm_log_repo.New().WithVmID(id).CreatedAfter(lastFetched).List()
+				if err != nil {
+					utils.PrettyPrintError(fmt.Errorf("failed to get console logs for vm %s after %s. details: %w", id, lastFetched, err))
+					return
+				}
+
+				for _, item := range logs {
+					handler(model.LogSourceConsole, "[console]", item.Line, item.CreatedAt)
+				}
+
+				if len(logs) > 0 {
+					lastFetched = logs[len(logs)-1].CreatedAt
+				}
+			}
+		}
+	}()
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package k8s_service
 
 import (
+	"context"
 	"fmt"
 
 	configModels "github.com/kthcloud/go-deploy/models/config"
@@ -62,6 +63,9 @@ func OptsOnlyClient(zone string, extraOpts ...opts.ExtraOpts) *opts.Opts {
 // It contains a Client, which is used to lazy-load and cache data.
 type Client struct {
 	client.BaseClient[Client]
+
+	// ctx is checked between steps of long-running operations, which stop early if it is cancelled.
+	ctx context.Context
 }
 
 // New creates a new Client and injects the cache.
@@ -74,11 +78,27 @@ func New(cache ...*core.Cache) *Client {
 		ca = core.NewCache()
 	}
 
-	c := &Client{BaseClient: client.NewBaseClient[Client](ca)}
+	c := &Client{BaseClient: client.NewBaseClient[Client](ca), ctx: context.Background()}
 	c.BaseClient.SetParent(c)
 	return c
 }
 
+// WithContext sets the context that long-running operations honour.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	c.ctx = ctx
+	return c
+}
+
+// checkCancelled returns an error once the context is cancelled.
+// Long-running operations call it between their steps, so a cancelled job stops at the next step.
+func (c *Client) checkCancelled() error {
+	if err := c.ctx.Err(); err != nil {
+		return fmt.Errorf("stopped since the operation was cancelled. details: %w", err)
+	}
+
+	return nil
+}
+
 // Get returns the VM, client, and generator.
 //
 // Depending on the options specified, some return values may be nil.
@@ -163,6 +183,7 @@ func withClient(zone *configModels.Zone, namespace string) (*k8s.Client, error)
 	k8sClient, err := k8s.New(&k8s.ClientConf{
 		K8sClient:         zone.K8s.Client,
 		KubeVirtK8sClient: zone.K8s.KubeVirtClient,
+		RestConfig:        zone.K8s.RestConfig,
 		Namespace:         namespace,
 	})
 	if err != nil {
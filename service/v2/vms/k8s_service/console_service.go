@@ -0,0 +1,64 @@
+package k8s_service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	configModels "github.com/kthcloud/go-deploy/models/config"
+	"github.com/kthcloud/go-deploy/models/model"
+	kErrors "github.com/kthcloud/go-deploy/pkg/subsystems/k8s/errors"
+	k8sModels "github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+)
+
+// ListRunningVmis lists all running VMIs in a zone.
+func (c *Client) ListRunningVmis(zone *configModels.Zone) ([]k8sModels.RunningVmi, error) {
+	_, kc, _, err := c.Get(OptsOnlyClient(zone.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	return kc.ListRunningVmis()
+}
+
+// StreamConsole reads the serial console of a VMI and sends the output to the callback.
+// It blocks until the context is cancelled or the console is disconnected.
+//
+// It returns sErrors.ErrVmNotFound if the VMI does not exist,
+// and sErrors.ErrVmConsoleIdle if the console had no output for idleTimeout.
+func (c *Client) StreamConsole(ctx context.Context, zone *configModels.Zone, vmiName string, idleTimeout time.Duration, onLog func(lines []model.Log)) error {
+	_, kc, _, err := c.Get(OptsOnlyClient(zone.Name))
+	if err != nil {
+		return err
+	}
+
+	handler := func(k8sLines []k8sModels.LogLine) {
+		lines := make([]model.Log, 0, len(k8sLines))
+		for _, line := range k8sLines {
+			lines = append(lines, model.Log{
+				Source:    model.LogSourceConsole,
+				Prefix:    "[console]",
+				Line:      line.Line,
+				CreatedAt: line.CreatedAt,
+			})
+		}
+
+		onLog(lines)
+	}
+
+	err = kc.StreamVmConsole(ctx, vmiName, idleTimeout, handler)
+	if err != nil {
+		if errors.Is(err, kErrors.ErrNotFound) {
+			return sErrors.ErrVmNotFound
+		}
+
+		if errors.Is(err, kErrors.ErrIdle) {
+			return sErrors.ErrVmConsoleIdle
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -52,6 +52,10 @@ func (c *Client) Create(id string, params *model.VmCreateParams) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// PVs
 	for _, pvPublic := range g.PVs() {
 		err = resources.SsCreator(kc.CreatePV).
@@ -64,6 +68,10 @@ func (c *Client) Create(id string, params *model.VmCreateParams) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// PVCs
 	for _, pvcPublic := range g.PVCs() {
 		err = resources.SsCreator(kc.CreatePVC).
@@ -76,6 +84,10 @@ func (c *Client) Create(id string, params *model.VmCreateParams) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// Deployment
 	for _, deploymentPublic := range g.Deployments() {
 		err = resources.SsCreator(kc.CreateDeployment).
@@ -88,6 +100,10 @@ func (c *Client) Create(id string, params *model.VmCreateParams) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// VM
 	err = resources.SsCreator(kc.CreateVM).
 		WithDbFunc(dbFunc(id, "vm")).
@@ -98,6 +114,10 @@ func (c *Client) Create(id string, params *model.VmCreateParams) error {
 		return makeError(err)
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// Service
 	for _, servicePublic := range g.Services() {
 		for idx, port := range servicePublic.Ports {
@@ -124,6 +144,10 @@ func (c *Client) Create(id string, params *model.VmCreateParams) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// Ingress
 	for _, ingressPublic := range g.Ingresses() {
 		err = resources.SsCreator(kc.CreateIngress).
@@ -140,6 +164,10 @@ func (c *Client) Create(id string, params *model.VmCreateParams) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	// Secret
 	for _, secretPublic := range g.Secrets() {
 		err = resources.SsCreator(kc.CreateSecret).
@@ -214,6 +242,16 @@ func (c *Client) Delete(id string, overwriteUserID ...string) error {
 		}
 	}
 
+	// Detached disks, which are no longer part of the VM spec
+	if k8sVmID := vm.Subsystems.K8s.VM.ID; k8sVmID != "" {
+		for name := range vm.DetachedDiskMap {
+			err = kc.DeleteDataVolume(k8sModels.DataDiskName(k8sVmID, name))
+			if err != nil {
+				return makeError(err)
+			}
+		}
+	}
+
 	// VM
 	err = resources.SsDeleter(kc.DeleteVM).
 		WithResourceID(vm.Subsystems.K8s.VM.ID).
@@ -321,6 +359,10 @@ func (c *Client) Repair(id string) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	if k8sVM := &vm.Subsystems.K8s.VM; subsystems.Created(k8sVM) {
 		err = resources.SsRepairer(
 			kc.ReadVM,
@@ -343,6 +385,10 @@ func (c *Client) Repair(id string) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	deployments := g.Deployments()
 	for mapName, k8sDeployment := range vm.Subsystems.K8s.DeploymentMap {
 		idx := slices.IndexFunc(deployments, func(d k8sModels.DeploymentPublic) bool { return d.Name == mapName })
@@ -370,6 +416,10 @@ func (c *Client) Repair(id string) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	services := g.Services()
 	for mapName, k8sService := range vm.Subsystems.K8s.ServiceMap {
 		idx := slices.IndexFunc(services, func(s k8sModels.ServicePublic) bool { return s.Name == mapName })
@@ -412,6 +462,10 @@ func (c *Client) Repair(id string) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	ingresses := g.Ingresses()
 	for mapName, ingress := range vm.Subsystems.K8s.IngressMap {
 		idx := slices.IndexFunc(ingresses, func(i k8sModels.IngressPublic) bool { return i.Name == mapName })
@@ -443,6 +497,10 @@ func (c *Client) Repair(id string) error {
 		}
 	}
 
+	if err = c.checkCancelled(); err != nil {
+		return makeError(err)
+	}
+
 	secrets := g.Secrets()
 	for mapName, secret := range vm.Subsystems.K8s.SecretMap {
 		idx := slices.IndexFunc(secrets, func(s k8sModels.SecretPublic) bool { return s.Name == mapName })
@@ -620,6 +678,37 @@ func (c *Client) DoAction(id string, action *model.VmActionParams) error {
 	return nil
 }
 
+// StorageClassAllowsExpansion returns whether disks of a storage class can be expanded in the zone of the VM.
+func (c *Client) StorageClassAllowsExpansion(vmID, storageClass string) (bool, error) {
+	_, kc, _, err := c.Get(OptsNoGenerator(vmID))
+	if err != nil {
+		return false, err
+	}
+
+	return kc.StorageClassAllowsExpansion(storageClass)
+}
+
+// DeleteDetachedDisk deletes the volume of a data disk that was detached from a VM.
+func (c *Client) DeleteDetachedDisk(vmID, diskName string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to delete detached disk %s for vm %s. details: %w", diskName, vmID, err)
+	}
+
+	vm, kc, _, err := c.Get(OptsNoGenerator(vmID))
+	if err != nil {
+		return makeError(err)
+	}
+
+	if k8sVmID := vm.Subsystems.K8s.VM.ID; k8sVmID != "" {
+		err = kc.DeleteDataVolume(k8sModels.DataDiskName(k8sVmID, diskName))
+		if err != nil {
+			return makeError(err)
+		}
+	}
+
+	return nil
+}
+
 // EnsureOwner ensures the owner of the K8s setup, by deleting and then trigger a call to Repair.
 func (c *Client) EnsureOwner(id, oldOwnerID string) error {
 	makeError := func(err error) error {
@@ -5,7 +5,9 @@ import (
 	"github.com/kthcloud/go-deploy/models/model"
 	"github.com/kthcloud/go-deploy/pkg/subsystems"
 	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
 	"github.com/kthcloud/go-deploy/service/resources"
+	"github.com/kthcloud/go-deploy/utils"
 )
 
 func (c *Client) CreateVmSnapshot(vmID string, params *model.CreateSnapshotParams) (*model.SnapshotV2, error) {
@@ -32,9 +34,10 @@ func (c *Client) CreateVmSnapshot(vmID string, params *model.CreateSnapshotParam
 	}
 
 	snapshotPublic := &models.VmSnapshotPublic{
-		Name:      params.Name,
-		Namespace: kc.Namespace,
-		VmID:      vm.Subsystems.K8s.VM.ID,
+		Name:        params.Name,
+		Namespace:   kc.Namespace,
+		VmID:        vm.Subsystems.K8s.VM.ID,
+		UserCreated: params.UserCreated,
 	}
 
 	err = resources.SsCreator(kc.CreateVmSnapshot).
@@ -82,3 +85,98 @@ func (c *Client) DeleteVmSnapshot(vmID string, id string) error {
 
 	return nil
 }
+
+// VmSnapshotReady returns whether a snapshot of the VM is ready to use.
+// The status is read from K8s, since the stored status is only updated when the VM is repaired.
+func (c *Client) VmSnapshotReady(vmID string, id string) (bool, error) {
+	_, kc, _, err := c.Get(OptsNoGenerator(vmID))
+	if err != nil {
+		return false, err
+	}
+
+	snapshot, err := kc.ReadVmSnapshot(id)
+	if err != nil {
+		return false, fmt.Errorf("failed to read snapshot %s of k8s vm %s. details: %w", id, vmID, err)
+	}
+
+	if snapshot == nil {
+		return false, sErrors.ErrSnapshotNotFound
+	}
+
+	return snapshot.ReadyToUse, nil
+}
+
+// WaitVmSnapshotReady waits for a snapshot of the VM to be ready to use.
+func (c *Client) WaitVmSnapshotReady(vmID string, id string) error {
+	_, kc, _, err := c.Get(OptsNoGenerator(vmID))
+	if err != nil {
+		return err
+	}
+
+	err = kc.WaitVmSnapshotReady(c.ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to wait for snapshot %s of k8s vm %s. details: %w", id, vmID, err)
+	}
+
+	return nil
+}
+
+// ApplyVmSnapshot restores a stopped VM from one of its snapshots.
+//
+// The VirtualMachineRestore is deleted once the restore is done, whether it succeeded or not, since it cannot be reused.
+func (c *Client) ApplyVmSnapshot(vmID string, id string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to apply snapshot %s to k8s vm %s. details: %w", id, vmID, err)
+	}
+
+	vm, kc, _, err := c.Get(OptsNoGenerator(vmID))
+	if err != nil {
+		return makeError(err)
+	}
+
+	if vm == nil {
+		return sErrors.ErrVmNotFound
+	}
+
+	if !subsystems.Created(&vm.Subsystems.K8s.VM) {
+		return makeError(fmt.Errorf("vm not found"))
+	}
+
+	snapshot := vm.Subsystems.K8s.GetVmSnapshotByID(id)
+	if snapshot == nil {
+		return sErrors.ErrSnapshotNotFound
+	}
+
+	// KubeVirt does not start the restore until the VM is fully stopped
+	err = kc.WaitVmiDeleted(c.ctx, vm.Subsystems.K8s.VM.ID)
+	if err != nil {
+		return makeError(err)
+	}
+
+	restore, err := kc.CreateVmRestore(&models.VmRestorePublic{
+		Namespace:  kc.Namespace,
+		VmID:       vm.Subsystems.K8s.VM.ID,
+		SnapshotID: snapshot.ID,
+	})
+	if err != nil {
+		return makeError(err)
+	}
+
+	if restore == nil {
+		return makeError(fmt.Errorf("vm restore not found after creation"))
+	}
+
+	defer func() {
+		deleteErr := kc.DeleteVmRestore(restore.ID)
+		if deleteErr != nil {
+			utils.PrettyPrintError(makeError(deleteErr))
+		}
+	}()
+
+	err = kc.WaitVmRestoreCompleted(c.ctx, restore.ID)
+	if err != nil {
+		return makeError(err)
+	}
+
+	return nil
+}
@@ -74,6 +74,13 @@ type ListGpuGroupOpts struct {
 	Pagination *utils.Pagination
 }
 
+// ListVmImageOpts is used to specify the options when listing VM images.
+type ListVmImageOpts struct {
+	Pagination *utils.Pagination
+	// Role only includes images the role may use
+	Role *string
+}
+
 // GetSnapshotOpts is used to specify the options when getting a VM's snapshot.
 type GetSnapshotOpts struct {
 }
@@ -1,6 +1,7 @@
 package resources
 
 import (
+	"encoding/json"
 	"fmt"
 	configModels "github.com/kthcloud/go-deploy/models/config"
 	"github.com/kthcloud/go-deploy/models/model"
@@ -31,21 +32,32 @@ type K8sGenerator struct {
 }
 
 type CloudInit struct {
-	FQDN            string          `yaml:"fqdn"`
-	Users           []CloudInitUser `yaml:"users"`
-	SshPasswordAuth bool            `yaml:"ssh_pwauth"`
-	RunCMD          []string        `yaml:"runcmd"`
+	FQDN            string               `yaml:"fqdn"`
+	Users           []CloudInitUser      `yaml:"users"`
+	SshPasswordAuth bool                 `yaml:"ssh_pwauth"`
+	Packages        []string             `yaml:"packages,omitempty"`
+	WriteFiles      []CloudInitWriteFile `yaml:"write_files,omitempty"`
+	RunCMD          []string             `yaml:"runcmd,omitempty"`
 }
 
 type CloudInitUser struct {
 	Name              string   `yaml:"name"`
-	Sudo              []string `yaml:"sudo"`
+	Sudo              []string `yaml:"sudo,omitempty"`
+	Groups            []string `yaml:"groups,omitempty"`
 	Passwd            string   `yaml:"passwd,omitempty"`
 	LockPasswd        bool     `yaml:"lock_passwd"`
 	Shell             string   `yaml:"shell"`
 	SshAuthorizedKeys []string `yaml:"ssh_authorized_keys"`
 }
 
+type CloudInitWriteFile struct {
+	Path        string `yaml:"path"`
+	Content     string `yaml:"content"`
+	Permissions string `yaml:"permissions,omitempty"`
+	Owner       string `yaml:"owner,omitempty"`
+	Append      bool   `yaml:"append,omitempty"`
+}
+
 func K8s(vm *model.VM, zone *configModels.Zone, client *k8s.Client, namespace string, extraAuthorizedKeys []string) *K8sGenerator {
 	return &K8sGenerator{
 		vm:                  vm,
@@ -73,11 +85,23 @@ func (kg *K8sGenerator) VM() *models.VmPublic {
 	sshPublicKeys[0] = kg.vm.SshPublicKey
 	copy(sshPublicKeys[1:], kg.extraAuthorizedKeys)
 
+	// VMs created without an image from the catalogue use the default image, which is bootstrapped as root
+	image := config.Config.VM.Image
+	user := "root"
+	runCMD := []string{"git clone https://github.com/kthcloud/boostrap-vm.git init && cd init && chmod +x run.sh && ./run.sh"}
+	if kg.vm.Image != nil {
+		image = kg.vm.Image.URL
+		user = kg.vm.Image.DefaultUser
+		if !kg.vm.Image.Bootstrap {
+			runCMD = nil
+		}
+	}
+
 	cloudInit := CloudInit{
 		FQDN: kg.vm.Name,
 		Users: []CloudInitUser{
 			{
-				Name:              "root",
+				Name:              user,
 				Sudo:              []string{"ALL=(ALL) NOPASSWD:ALL"},
 				LockPasswd:        false,
 				Shell:             "/bin/bash",
@@ -85,9 +109,32 @@ func (kg *K8sGenerator) VM() *models.VmPublic {
 			},
 		},
 		SshPasswordAuth: false,
-		RunCMD:          []string{"git clone https://github.com/kthcloud/boostrap-vm.git init && cd init && chmod +x run.sh && ./run.sh"},
+		RunCMD:          runCMD,
 	}
 
+	if kg.vm.CloudInit != nil {
+		mergeUserCloudInit(&cloudInit, kg.vm.CloudInit)
+	}
+
+	disks := make([]models.VmDiskPublic, 0, len(kg.vm.DiskMap))
+	for _, disk := range kg.vm.DiskMap {
+		storageClass := models.DefaultVmDiskStorageClass
+		if disk.StorageClass != nil {
+			storageClass = *disk.StorageClass
+		}
+
+		disks = append(disks, models.VmDiskPublic{
+			Name:         disk.Name,
+			Size:         disk.Size,
+			StorageClass: storageClass,
+		})
+	}
+
+	// The disks are read back in the order of the manifest, so they must be ordered the same way every time
+	slices.SortFunc(disks, func(a, b models.VmDiskPublic) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
 	vmPublic := models.VmPublic{
 		Name:      vmName(kg.vm),
 		Namespace: kg.namespace,
@@ -96,13 +143,19 @@ func (kg *K8sGenerator) VM() *models.VmPublic {
 		RAM:       kg.vm.Specs.RAM,
 		DiskSize:  kg.vm.Specs.DiskSize,
 		GPUs:      make([]string, 0),
+		Disks:     disks,
 		CloudInit: createCloudInitString(&cloudInit),
-		// Temporary image URL
-		Image:     config.Config.VM.Image,
+		Image:     image,
 		Running:   true,
 		CreatedAt: time.Time{},
 	}
 
+	// VMs without a user-supplied cloud-init keep the instance ID generated by KubeVirt,
+	// so that repairs do not make cloud-init run again on existing VMs
+	if kg.vm.CloudInit != nil {
+		vmPublic.InstanceID = cloudInitInstanceID(kg.vm)
+	}
+
 	if vm := &kg.vm.Subsystems.K8s.VM; subsystems.Created(vm) {
 		vmPublic.ID = vm.ID
 		vmPublic.Running = vm.Running
@@ -344,6 +397,68 @@ func createCloudInitString(cloudInit *CloudInit) string {
 	return "#cloud-config\n" + string(yamlBytes)
 }
 
+// mergeUserCloudInit adds the user-supplied cloud-init to the one the system requires.
+// The system user keeps its sudo rights and SSH keys, and the system commands run before the user's.
+func mergeUserCloudInit(cloudInit *CloudInit, userCloudInit *model.CloudInit) {
+	cloudInit.Packages = append(cloudInit.Packages, userCloudInit.Packages...)
+	cloudInit.RunCMD = append(cloudInit.RunCMD, userCloudInit.RunCMD...)
+
+	for _, file := range userCloudInit.WriteFiles {
+		cloudInit.WriteFiles = append(cloudInit.WriteFiles, CloudInitWriteFile{
+			Path:        file.Path,
+			Content:     file.Content,
+			Permissions: utils.ZeroDeref(file.Permissions),
+			Owner:       utils.ZeroDeref(file.Owner),
+			Append:      file.Append,
+		})
+	}
+
+	for _, user := range userCloudInit.Users {
+		// A user with the same name as the system user only adds SSH keys and groups to it
+		idx := slices.IndexFunc(cloudInit.Users, func(u CloudInitUser) bool { return u.Name == user.Name })
+		if idx != -1 {
+			for _, key := range user.SshAuthorizedKeys {
+				if !slices.Contains(cloudInit.Users[idx].SshAuthorizedKeys, key) {
+					cloudInit.Users[idx].SshAuthorizedKeys = append(cloudInit.Users[idx].SshAuthorizedKeys, key)
+				}
+			}
+			cloudInit.Users[idx].Groups = append(cloudInit.Users[idx].Groups, user.Groups...)
+			continue
+		}
+
+		cloudInitUser := CloudInitUser{
+			Name:              user.Name,
+			Groups:            user.Groups,
+			LockPasswd:        true,
+			Shell:             "/bin/bash",
+			SshAuthorizedKeys: user.SshAuthorizedKeys,
+		}
+
+		if user.Shell != nil {
+			cloudInitUser.Shell = *user.Shell
+		}
+
+		if user.Sudo {
+			cloudInitUser.Sudo = []string{"ALL=(ALL) NOPASSWD:ALL"}
+		}
+
+		if cloudInitUser.SshAuthorizedKeys == nil {
+			cloudInitUser.SshAuthorizedKeys = make([]string, 0)
+		}
+
+		cloudInit.Users = append(cloudInit.Users, cloudInitUser)
+	}
+}
+
+// cloudInitInstanceID returns the cloud-init instance ID of a VM, which is derived from its user-supplied cloud-init.
+// Cloud-init treats the VM as a new instance when the user-supplied cloud-init changes, and runs its per-instance modules again.
+// The generated parts of the user-data, such as the SSH keys, are left out, so that changing them does not run it again.
+func cloudInitInstanceID(vm *model.VM) string {
+	// The cloud-init only holds strings, slices and structs, so it can always be marshalled, and always the same way
+	userCloudInit, _ := json.Marshal(vm.CloudInit)
+	return fmt.Sprintf("%s-%s", vm.Name, utils.HashStringAlphanumericLower(string(userCloudInit))[:12])
+}
+
 // vmName returns the VM name for a VM
 func vmName(vm *model.VM) string {
 	return vm.Name
@@ -0,0 +1,151 @@
+package resources
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s"
+	"github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
+)
+
+func systemCloudInit() CloudInit {
+	return CloudInit{
+		FQDN: "vm",
+		Users: []CloudInitUser{
+			{
+				Name:              "root",
+				Sudo:              []string{"ALL=(ALL) NOPASSWD:ALL"},
+				Shell:             "/bin/bash",
+				SshAuthorizedKeys: []string{"owner-key", "admin-key"},
+			},
+		},
+		RunCMD: []string{"bootstrap"},
+	}
+}
+
+func TestMergeUserCloudInit(t *testing.T) {
+	cloudInit := systemCloudInit()
+
+	mergeUserCloudInit(&cloudInit, &model.CloudInit{
+		Packages:   []string{"nginx"},
+		WriteFiles: []model.CloudInitFile{{Path: "/etc/motd", Content: "hello"}},
+		RunCMD:     []string{"systemctl enable --now nginx"},
+		Users: []model.CloudInitUser{
+			{Name: "root", SshAuthorizedKeys: []string{"owner-key", "extra-key"}},
+			{Name: "alice", SshAuthorizedKeys: []string{"alice-key"}},
+		},
+	})
+
+	if !slices.Equal(cloudInit.RunCMD, []string{"bootstrap", "systemctl enable --now nginx"}) {
+		t.Errorf("expected system commands to run first, got %v", cloudInit.RunCMD)
+	}
+
+	if len(cloudInit.Packages) != 1 || len(cloudInit.WriteFiles) != 1 {
+		t.Errorf("expected packages and files to be added, got %v and %v", cloudInit.Packages, cloudInit.WriteFiles)
+	}
+
+	if len(cloudInit.Users) != 2 {
+		t.Fatalf("expected the system user and one extra user, got %d users", len(cloudInit.Users))
+	}
+
+	root := cloudInit.Users[0]
+	if !slices.Equal(root.SshAuthorizedKeys, []string{"owner-key", "admin-key", "extra-key"}) {
+		t.Errorf("expected system keys to be kept, got %v", root.SshAuthorizedKeys)
+	}
+
+	if len(root.Sudo) == 0 {
+		t.Error("expected system user to keep sudo")
+	}
+
+	alice := cloudInit.Users[1]
+	if alice.Sudo != nil || !alice.LockPasswd {
+		t.Errorf("expected extra user without sudo and with locked password, got %+v", alice)
+	}
+}
+
+func TestCreateCloudInitStringUnchangedWithoutUserCloudInit(t *testing.T) {
+	// Existing VMs without a user-supplied cloud-init must not be changed by repairs
+	cloudInit := systemCloudInit()
+
+	expected := `#cloud-config
+fqdn: vm
+users:
+    - name: root
+      sudo:
+        - ALL=(ALL) NOPASSWD:ALL
+      lock_passwd: false
+      shell: /bin/bash
+      ssh_authorized_keys:
+        - owner-key
+        - admin-key
+ssh_pwauth: false
+runcmd:
+    - bootstrap
+`
+
+	if actual := createCloudInitString(&cloudInit); actual != expected {
+		t.Errorf("unexpected cloud-init:\n%s", actual)
+	}
+}
+
+func TestVmDisksRoundTrip(t *testing.T) {
+	public := &models.VmPublic{
+		ID:        "vm-id",
+		Name:      "vm",
+		Namespace: "namespace",
+		CpuCores:  2,
+		RAM:       4,
+		DiskSize:  20,
+		Disks: []models.VmDiskPublic{
+			{Name: "data", Size: 50, StorageClass: models.DefaultVmDiskStorageClass},
+			{Name: "scratch", Size: 10, StorageClass: "fast"},
+		},
+		Image:     "https://example.com/image.img",
+		CreatedAt: time.Now(),
+	}
+
+	// The repair loop recreates VMs that differ from what is read back, which would delete their disks
+	readBack := models.CreateVmPublicFromRead(k8s.CreateVmManifest(public))
+	if readBack.DiskSize != public.DiskSize {
+		t.Errorf("expected root disk size %d, got %d", public.DiskSize, readBack.DiskSize)
+	}
+
+	if !slices.Equal(readBack.Disks, public.Disks) {
+		t.Errorf("disks changed after round trip:\n got  %+v\n want %+v", readBack.Disks, public.Disks)
+	}
+}
+
+func TestCloudInitInstanceID(t *testing.T) {
+	vm := &model.VM{Name: "vm", SshPublicKey: "ssh-ed25519 first", CloudInit: &model.CloudInit{Packages: []string{"nginx"}}}
+
+	instanceID := cloudInitInstanceID(vm)
+	if instanceID != cloudInitInstanceID(&model.VM{Name: "vm", SshPublicKey: "ssh-ed25519 first", CloudInit: &model.CloudInit{Packages: []string{"nginx"}}}) {
+		t.Error("expected the same instance ID for the same cloud-init")
+	}
+
+	if instanceID != cloudInitInstanceID(&model.VM{Name: "vm", SshPublicKey: "ssh-ed25519 second", CloudInit: &model.CloudInit{Packages: []string{"nginx"}}}) {
+		t.Error("expected the same instance ID when only the SSH key changes")
+	}
+
+	if instanceID == cloudInitInstanceID(&model.VM{Name: "vm", SshPublicKey: "ssh-ed25519 first", CloudInit: &model.CloudInit{Packages: []string{"redis"}}}) {
+		t.Error("expected a new instance ID when the cloud-init changes")
+	}
+
+	// The instance ID is read back from the VM, so it must survive a round trip to not trigger repairs
+	public := &models.VmPublic{
+		ID:         "vm-id",
+		Name:       "vm",
+		Namespace:  "namespace",
+		CpuCores:   2,
+		RAM:        4,
+		DiskSize:   20,
+		InstanceID: instanceID,
+		CreatedAt:  time.Now(),
+	}
+
+	if readBack := models.CreateVmPublicFromRead(k8s.CreateVmManifest(public)); readBack.InstanceID != instanceID {
+		t.Errorf("expected instance ID %s, got %s", instanceID, readBack.InstanceID)
+	}
+}
@@ -0,0 +1,84 @@
+package snapshots
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/models/version"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/vm_repo"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	jobOpts "github.com/kthcloud/go-deploy/service/v2/jobs/opts"
+)
+
+// ApplyPolicies runs the snapshot policies of a VM.
+//
+// A system snapshot job is created for every policy that is due, and delete jobs are created for
+// the oldest snapshots of a policy when it has more snapshots than its retention.
+// Snapshots created by users are never deleted.
+//
+// VMs without snapshot policies, and VMs that are busy, are skipped.
+func (c *Client) ApplyPolicies(vmID string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to apply snapshot policies for vm %s. details: %w", vmID, err)
+	}
+
+	vm, err := c.VM(vmID, nil)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if vm == nil {
+		return sErrors.ErrVmNotFound
+	}
+
+	if len(vm.SnapshotPolicyMap) == 0 || !vm.Ready() || vm.DoingActivity(model.ActivityUpdating) {
+		return nil
+	}
+
+	now := time.Now()
+	for name, policy := range vm.SnapshotPolicyMap {
+		var snapshotJobID string
+		if policy.Due(now) {
+			snapshotJobID = uuid.New().String()
+			err = c.V2.Jobs().Create(snapshotJobID, vm.OwnerID, model.JobCreateSystemVmSnapshot, version.V2, map[string]interface{}{
+				"id": vm.ID,
+				"params": model.CreateSnapshotParams{
+					Name:        policy.SnapshotName(now),
+					UserCreated: false,
+				},
+			}, jobOpts.CreateOpts{})
+			if err != nil {
+				return makeError(err)
+			}
+
+			err = vm_repo.New().SetSnapshotPolicyRun(vm.ID, name, now)
+			if err != nil {
+				return makeError(err)
+			}
+		}
+
+		err = c.pruneSnapshots(vm, &policy, snapshotJobID)
+		if err != nil {
+			return makeError(err)
+		}
+	}
+
+	return nil
+}
+
+// pruneSnapshots creates delete jobs for the oldest snapshots of a policy that are beyond its retention.
+//
+// If snapshotJobID is set, the snapshot it creates counts toward the retention, and the delete jobs
+// depend on it. This way old snapshots are only deleted once the new snapshot exists.
+func (c *Client) pruneSnapshots(vm *model.VM, policy *model.SnapshotPolicy, snapshotJobID string) error {
+	retention := policy.Retention
+	createOpts := jobOpts.CreateOpts{}
+	if snapshotJobID != "" {
+		retention--
+		createOpts.DependsOn = []string{snapshotJobID}
+	}
+
+	return c.deleteOldestSnapshots(vm, policy.OwnsSnapshot, retention, createOpts)
+}
@@ -1,17 +1,34 @@
 package snapshots
 
 import (
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/models/version"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/job_repo"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/vm_repo"
 	"github.com/kthcloud/go-deploy/pkg/log"
-	"github.com/kthcloud/go-deploy/service/utils"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	sUtils "github.com/kthcloud/go-deploy/service/utils"
+	jobOpts "github.com/kthcloud/go-deploy/service/v2/jobs/opts"
 	"github.com/kthcloud/go-deploy/service/v2/vms/opts"
+	"github.com/kthcloud/go-deploy/utils"
 	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// preRestorePrefix is the prefix of the snapshots taken before a snapshot is applied.
+	preRestorePrefix = "pre-restore-"
+	// preRestoreRetention is the number of pre-restore snapshots kept for each VM.
+	preRestoreRetention = 3
 )
 
 // Get gets a snapshot
 func (c *Client) Get(vmID string, id string, opts ...opts.GetSnapshotOpts) (*model.SnapshotV2, error) {
-	_ = utils.GetFirstOrDefault(opts)
+	_ = sUtils.GetFirstOrDefault(opts)
 
 	vm, err := c.VM(vmID, nil)
 	if err != nil {
@@ -37,7 +54,7 @@ func (c *Client) Get(vmID string, id string, opts ...opts.GetSnapshotOpts) (*mod
 
 // GetByName gets a snapshot by name
 func (c *Client) GetByName(vmID string, name string, opts ...opts.GetSnapshotOpts) (*model.SnapshotV2, error) {
-	_ = utils.GetFirstOrDefault(opts)
+	_ = sUtils.GetFirstOrDefault(opts)
 
 	vm, err := c.VM(vmID, nil)
 	if err != nil {
@@ -61,9 +78,9 @@ func (c *Client) GetByName(vmID string, name string, opts ...opts.GetSnapshotOpt
 	}, nil
 }
 
-// List lists snapshots
+// List lists snapshots, with the oldest snapshot first
 func (c *Client) List(vmID string, opts ...opts.ListSnapshotOpts) ([]model.SnapshotV2, error) {
-	_ = utils.GetFirstOrDefault(opts)
+	o := sUtils.GetFirstOrDefault(opts)
 
 	vm, err := c.VM(vmID, nil)
 	if err != nil {
@@ -88,12 +105,16 @@ func (c *Client) List(vmID string, opts ...opts.ListSnapshotOpts) ([]model.Snaps
 		return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
 	})
 
+	if o.Pagination != nil {
+		snapshots = utils.GetPage(snapshots, o.Pagination.PageSize, o.Pagination.Page)
+	}
+
 	return snapshots, nil
 }
 
 // Create creates a snapshot
 func (c *Client) Create(vmID string, opts ...opts.CreateSnapshotOpts) (*model.SnapshotV2, error) {
-	o := utils.GetFirstOrDefault(opts)
+	o := sUtils.GetFirstOrDefault(opts)
 
 	makeError := func(err error) error {
 		return fmt.Errorf("failed to create snapshot for vm %s. details: %w", vmID, err)
@@ -162,16 +183,175 @@ func (c *Client) Delete(vmID, snapshotID string) error {
 }
 
 // Apply applies a snapshot
+//
+// A system snapshot of the current state is taken first, so the restore can be undone.
+// The VM is stopped while the snapshot is restored, and started again afterwards if it was running.
+// It returns sErrors.ErrSnapshotNotReady if the snapshot is not ready to use, in which case the VM is left as is.
+// Only the latest pre-restore snapshots are kept, and older ones are deleted once the restore is done.
+//
+// The pre-restore snapshot and whether the VM was running are stored on the VM until the restore is done,
+// so that a restore that is run again, e.g. when its job is retried, reuses them instead of snapshotting the stopped VM.
 func (c *Client) Apply(vmID, snapshotID string) error {
 	makeError := func(err error) error {
 		return fmt.Errorf("failed to apply snapshot %s to vm %s. details: %w", snapshotID, vmID, err)
 	}
 
-	//log.Println("Applying snapshot", snapshotID, "to vm", id)
-	//err := c.K8s().ApplyVmSnapshot(id, snapshotID)
-	//if err != nil {
-	//	return makeError(err)
-	//}
+	vm, err := c.VM(vmID, nil)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if vm == nil {
+		return sErrors.ErrVmNotFound
+	}
+
+	if vm.Subsystems.K8s.GetVmSnapshotByID(snapshotID) == nil {
+		return sErrors.ErrSnapshotNotFound
+	}
+
+	ready, err := c.V2.VMs().K8s().VmSnapshotReady(vmID, snapshotID)
+	if err != nil {
+		if errors.Is(err, sErrors.ErrSnapshotNotFound) {
+			return err
+		}
+
+		return makeError(err)
+	}
+
+	if !ready {
+		return sErrors.ErrSnapshotNotReady
+	}
+
+	restore := vm.Restore
+	if restore == nil {
+		restore = &model.VmRestore{WasRunning: vm.Subsystems.K8s.VM.Running}
+	}
+
+	if restore.PreRestoreSnapshotID == "" || vm.Subsystems.K8s.GetVmSnapshotByID(restore.PreRestoreSnapshotID) == nil {
+		preRestore, err := c.Create(vmID, opts.CreateSnapshotOpts{System: &model.CreateSnapshotParams{
+			Name:        preRestorePrefix + time.Now().UTC().Format("20060102150405"),
+			UserCreated: false,
+		}})
+		if err != nil {
+			return makeError(err)
+		}
+
+		if preRestore == nil {
+			return makeError(fmt.Errorf("pre-restore snapshot not found after creation"))
+		}
+
+		restore.PreRestoreSnapshotID = preRestore.ID
+	}
+
+	// The state is stored before the VM is stopped, since it cannot be read from the VM once it is stopped
+	err = vm_repo.New().SetRestore(vmID, restore)
+	if err != nil {
+		return makeError(err)
+	}
+
+	// The restore cannot be undone until the snapshot of the current state is complete, so the VM keeps running until then
+	err = c.V2.VMs().K8s().WaitVmSnapshotReady(vmID, restore.PreRestoreSnapshotID)
+	if err != nil {
+		return makeError(err)
+	}
+
+	log.Println("Applying snapshot", snapshotID, "to vm", vmID)
+	err = c.V2.VMs().K8s().DoAction(vmID, &model.VmActionParams{Action: model.ActionStop})
+	if err != nil {
+		return makeError(err)
+	}
+
+	restoreErr := c.V2.VMs().K8s().ApplyVmSnapshot(vmID, snapshotID)
+
+	// Start the VM again even if the restore failed, so it is not left stopped
+	if restore.WasRunning {
+		err = c.V2.VMs().K8s().DoAction(vmID, &model.VmActionParams{Action: model.ActionStart})
+		if err != nil {
+			return makeError(err)
+		}
+	}
+
+	if restoreErr != nil {
+		return makeError(restoreErr)
+	}
+
+	err = vm_repo.New().SetRestore(vmID, nil)
+	if err != nil {
+		return makeError(err)
+	}
+
+	// The restore is done at this point, so failing to prune is not a reason to run it again
+	err = c.prunePreRestoreSnapshots(vmID)
+	if err != nil {
+		log.Warnf("Failed to prune pre-restore snapshots of vm %s. details: %s", vmID, err)
+	}
+
+	return nil
+}
+
+// prunePreRestoreSnapshots creates delete jobs for the pre-restore snapshots of a VM beyond the latest preRestoreRetention.
+func (c *Client) prunePreRestoreSnapshots(vmID string) error {
+	vm, err := c.Refresh(vmID)
+	if err != nil {
+		return err
+	}
+
+	if vm == nil {
+		return nil
+	}
+
+	isPreRestore := func(name string) bool {
+		return strings.HasPrefix(name, preRestorePrefix)
+	}
 
-	return makeError(fmt.Errorf("not implemented"))
+	return c.deleteOldestSnapshots(vm, isPreRestore, preRestoreRetention, jobOpts.CreateOpts{})
+}
+
+// deleteOldestSnapshots creates delete jobs for the oldest system snapshots matched by owns,
+// so that only the latest retention snapshots are kept.
+// Snapshots that already have a pending delete job are skipped.
+func (c *Client) deleteOldestSnapshots(vm *model.VM, owns func(name string) bool, retention int, createOpts jobOpts.CreateOpts) error {
+	owned := make([]string, 0)
+	createdAt := make(map[string]time.Time)
+	for _, snapshot := range vm.Subsystems.K8s.GetVmSnapshotMap() {
+		if snapshot.UserCreated || !owns(snapshot.Name) {
+			continue
+		}
+
+		owned = append(owned, snapshot.ID)
+		createdAt[snapshot.ID] = snapshot.CreatedAt
+	}
+
+	if len(owned) <= retention {
+		return nil
+	}
+
+	sort.Slice(owned, func(i, j int) bool {
+		return createdAt[owned[i]].Before(createdAt[owned[j]])
+	})
+
+	for _, snapshotID := range owned[:len(owned)-retention] {
+		exists, err := job_repo.New().
+			IncludeTypes(model.JobDeleteVmSnapshot).
+			ExcludeStatus(model.JobStatusTerminated, model.JobStatusCompleted, model.JobStatusCancelled).
+			FilterArgs("snapshotId", snapshotID).
+			ExistsAny()
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			continue
+		}
+
+		err = c.V2.Jobs().Create(uuid.New().String(), vm.OwnerID, model.JobDeleteVmSnapshot, version.V2, map[string]interface{}{
+			"id":         vm.ID,
+			"snapshotId": snapshotID,
+		}, createOpts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
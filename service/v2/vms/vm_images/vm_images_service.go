@@ -0,0 +1,121 @@
+package vm_images
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/vm_image_repo"
+	sErrors "github.com/kthcloud/go-deploy/service/errors"
+	sUtils "github.com/kthcloud/go-deploy/service/utils"
+	"github.com/kthcloud/go-deploy/service/v2/vms/opts"
+)
+
+// Get gets a VM image by ID
+func (c *Client) Get(id string) (*model.VmImage, error) {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to get vm image %s. details: %w", id, err)
+	}
+
+	image, err := vm_image_repo.New().GetByID(id)
+	if err != nil {
+		return nil, makeError(err)
+	}
+
+	return image, nil
+}
+
+// List lists VM images in the catalogue
+func (c *Client) List(opts ...opts.ListVmImageOpts) ([]model.VmImage, error) {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to list vm images. details: %w", err)
+	}
+
+	o := sUtils.GetFirstOrDefault(opts)
+
+	vic := vm_image_repo.New()
+
+	if o.Pagination != nil {
+		vic.WithPagination(o.Pagination.Page, o.Pagination.PageSize)
+	}
+
+	if o.Role != nil {
+		vic.WithRole(*o.Role)
+	}
+
+	images, err := vic.List()
+	if err != nil {
+		return nil, makeError(err)
+	}
+
+	return images, nil
+}
+
+// Create adds an image to the catalogue
+//
+// It returns ErrVmImageAlreadyExists if an image with the same ID exists.
+func (c *Client) Create(dtoVmImageCreate *body.VmImageCreate) (*model.VmImage, error) {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to create vm image. details: %w", err)
+	}
+
+	params := model.VmImageCreateParams{}.FromDTO(dtoVmImageCreate)
+
+	image, err := vm_image_repo.New().Create(&params)
+	if err != nil {
+		if errors.Is(err, vm_image_repo.ErrVmImageAlreadyExists) {
+			return nil, sErrors.ErrVmImageAlreadyExists
+		}
+
+		return nil, makeError(err)
+	}
+
+	return image, nil
+}
+
+// Update updates an image in the catalogue
+//
+// VMs that were created from the image are not affected.
+// It returns ErrVmImageNotFound if the image does not exist.
+func (c *Client) Update(id string, dtoVmImageUpdate *body.VmImageUpdate) (*model.VmImage, error) {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to update vm image %s. details: %w", id, err)
+	}
+
+	vic := vm_image_repo.New()
+
+	exists, err := vic.ExistsByID(id)
+	if err != nil {
+		return nil, makeError(err)
+	}
+
+	if !exists {
+		return nil, sErrors.ErrVmImageNotFound
+	}
+
+	params := model.VmImageUpdateParams{}.FromDTO(dtoVmImageUpdate)
+
+	err = vic.UpdateWithParams(id, &params)
+	if err != nil {
+		return nil, makeError(err)
+	}
+
+	return c.Get(id)
+}
+
+// Delete removes an image from the catalogue
+//
+// VMs that were created from the image are not affected.
+func (c *Client) Delete(id string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to delete vm image %s. details: %w", id, err)
+	}
+
+	err := vm_image_repo.New().EraseByID(id)
+	if err != nil {
+		return makeError(err)
+	}
+
+	return nil
+}
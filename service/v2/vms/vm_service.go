@@ -3,6 +3,7 @@ package vms
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -16,9 +17,11 @@ import (
 	"github.com/kthcloud/go-deploy/pkg/db/resources/notification_repo"
 	"github.com/kthcloud/go-deploy/pkg/db/resources/resource_migration_repo"
 	"github.com/kthcloud/go-deploy/pkg/db/resources/team_repo"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/vm_log_repo"
 	"github.com/kthcloud/go-deploy/pkg/db/resources/vm_port_repo"
 	"github.com/kthcloud/go-deploy/pkg/db/resources/vm_repo"
 	"github.com/kthcloud/go-deploy/pkg/log"
+	k8sModels "github.com/kthcloud/go-deploy/pkg/subsystems/k8s/models"
 	sErrors "github.com/kthcloud/go-deploy/service/errors"
 	serviceUtils "github.com/kthcloud/go-deploy/service/utils"
 	"github.com/kthcloud/go-deploy/service/v2/vms/opts"
@@ -211,6 +214,19 @@ func (c *Client) Create(id, ownerID string, dtoVmCreate *body.VmCreate) error {
 		return sErrors.NewZoneCapabilityMissingError(fallbackZone, configModels.ZoneCapabilityVM)
 	}
 
+	if dtoVmCreate.Image != nil {
+		image, err := c.Images().Get(*dtoVmCreate.Image)
+		if err != nil {
+			return makeError(err)
+		}
+
+		if image == nil {
+			return sErrors.ErrVmImageNotFound
+		}
+
+		params.Image = image.ToRef()
+	}
+
 	_, err := vm_repo.New(version.V2).Create(id, ownerID, &params)
 	if err != nil {
 		if errors.Is(err, rErrors.ErrNonUniqueField) {
@@ -238,7 +254,60 @@ func (c *Client) Update(id string, dtoVmUpdate *body.VmUpdate) error {
 
 	vmUpdate := model.VmUpdateParams{}.FromDTOv2(dtoVmUpdate)
 
+	// If a snapshot is specified, the VM is restored from it instead
+	if vmUpdate.SnapshotID != nil {
+		err := c.Snapshots().Apply(id, *vmUpdate.SnapshotID)
+		if err != nil {
+			if errors.Is(err, sErrors.ErrSnapshotNotFound) || errors.Is(err, sErrors.ErrSnapshotNotReady) || errors.Is(err, sErrors.ErrVmNotFound) {
+				return err
+			}
+
+			return makeError(err)
+		}
+
+		return nil
+	}
+
 	// Otherwise, update the VM as usual
+	if vmUpdate.SnapshotPolicyMap != nil {
+		vm, err := c.VM(id, nil)
+		if err != nil {
+			return makeError(err)
+		}
+
+		if vm == nil {
+			return sErrors.ErrVmNotFound
+		}
+
+		// Policies that are kept as they are should not start counting from now again
+		for name, policy := range *vmUpdate.SnapshotPolicyMap {
+			if current, ok := vm.SnapshotPolicyMap[name]; ok && current.Schedule == policy.Schedule && current.Timezone == policy.Timezone {
+				policy.LastRunAt = current.LastRunAt
+				(*vmUpdate.SnapshotPolicyMap)[name] = policy
+			}
+		}
+	}
+
+	if vmUpdate.DiskMap != nil {
+		vm, err := c.VM(id, nil)
+		if err != nil {
+			return makeError(err)
+		}
+
+		if vm == nil {
+			return sErrors.ErrVmNotFound
+		}
+
+		// Disks that are left out keep their volumes, so they can be attached again or deleted explicitly
+		diskNames := make([]string, 0, len(*vmUpdate.DiskMap))
+		for name := range *vmUpdate.DiskMap {
+			diskNames = append(diskNames, name)
+		}
+
+		detachedDiskMap := detachedDisksAfterUpdate(vm, diskNames)
+		vmUpdate.DetachedDiskMap = &detachedDiskMap
+	}
+
 	if vmUpdate.PortMap != nil {
 		// We don't want to give new secrets for the same custom domains
 		vm, err := c.VM(id, nil)
@@ -273,11 +342,26 @@ func (c *Client) Update(id string, dtoVmUpdate *body.VmUpdate) error {
 		return makeError(err)
 	}
 
+	// Snapshot policies are not part of the VM itself, so there is nothing to apply
+	if onlySnapshotPolicies(&vmUpdate) {
+		return nil
+	}
+
 	err = c.K8s().Repair(id)
 	if err != nil {
 		return makeError(err)
 	}
 
+	// Cloud-init only runs when the VM boots, so it is applied on the next reboot instead
+	if onlyCloudInit(&vmUpdate) {
+		return nil
+	}
+
+	// KubeVirt grows the root disk of a running VM itself when online expansion is enabled
+	if onlyRootDiskSize(&vmUpdate) && config.Config.VM.ExpandDisks {
+		return nil
+	}
+
 	// Restart VM to ensure possibly new specs are applied
 	err = c.K8s().DoAction(id, &model.VmActionParams{Action: model.ActionRestart})
 	if err != nil {
@@ -334,6 +418,45 @@ func (c *Client) Delete(id string) error {
 		return makeError(err)
 	}
 
+	err = vm_log_repo.New().WithVmID(id).Erase()
+	if err != nil {
+		return makeError(err)
+	}
+
+	return nil
+}
+
+// DeleteDetachedDisk deletes a data disk that was detached from a VM, along with its data.
+//
+// It returns an error if the VM is not found, and sErrors.ErrVmDiskNotFound if the disk is not detached.
+func (c *Client) DeleteDetachedDisk(id, diskName string) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to delete detached disk %s for vm %s. details: %w", diskName, id, err)
+	}
+
+	vm, err := c.VM(id, nil)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if vm == nil {
+		return sErrors.ErrVmNotFound
+	}
+
+	if _, ok := vm.DetachedDiskMap[diskName]; !ok {
+		return sErrors.ErrVmDiskNotFound
+	}
+
+	err = c.K8s().DeleteDetachedDisk(id, diskName)
+	if err != nil {
+		return makeError(err)
+	}
+
+	err = vm_repo.New(version.V2).UnsetByID(id, "detachedDiskMap."+diskName)
+	if err != nil {
+		return makeError(err)
+	}
+
 	return nil
 }
 
@@ -573,11 +696,16 @@ func (c *Client) SshConnectionString(id string) (*string, error) {
 		return nil, makeError(sErrors.ErrZoneNotFound)
 	}
 
+	user := "root"
+	if vm.Image != nil {
+		user = vm.Image.DefaultUser
+	}
+
 	var sshConnectionString *string
 	if service := vm.Subsystems.K8s.GetService(fmt.Sprintf("%s-priv-22-prot-tcp", vm.Name)); service != nil {
 		for _, port := range service.Ports {
 			if port.TargetPort == 22 {
-				sshConnectionString = utils.StrPtr(fmt.Sprintf("ssh root@%s -p %d", strings.Split(zone.Domains.ParentVM, ":")[0], port.Port))
+				sshConnectionString = utils.StrPtr(fmt.Sprintf("ssh %s@%s -p %d", user, strings.Split(zone.Domains.ParentVM, ":")[0], port.Port))
 			}
 		}
 	}
@@ -615,6 +743,9 @@ func (c *Client) CheckQuota(id, userID string, quota *model.Quotas, opts ...opts
 		totalCpuCores := float64(usage.CpuCores + o.Create.CpuCores)
 		totalRam := float64(usage.RAM + o.Create.RAM)
 		totalDiskSize := float64(usage.DiskSize + o.Create.DiskSize)
+		for _, disk := range o.Create.Disks {
+			totalDiskSize += float64(disk.Size)
+		}
 
 		if totalCpuCores > quota.CpuCores {
 			return sErrors.NewQuotaExceededError(fmt.Sprintf("CPU cores quota exceeded. Current: %f, Quota: %f", totalCpuCores, quota.CpuCores))
@@ -628,7 +759,7 @@ func (c *Client) CheckQuota(id, userID string, quota *model.Quotas, opts ...opts
 			return sErrors.NewQuotaExceededError(fmt.Sprintf("Disk size quota exceeded. Current: %f, Quota: %f", totalDiskSize, quota.DiskSize))
 		}
 	} else if o.Update != nil {
-		if o.Update.CpuCores == nil && o.Update.RAM == nil {
+		if o.Update.CpuCores == nil && o.Update.RAM == nil && o.Update.DiskSize == nil && o.Update.Disks == nil {
 			return nil
 		}
 
@@ -662,8 +793,82 @@ func (c *Client) CheckQuota(id, userID string, quota *model.Quotas, opts ...opts
 				return sErrors.NewQuotaExceededError(fmt.Sprintf("RAM quota exceeded. Current: %f, Quota: %f", totalRam, quota.RAM))
 			}
 		}
+
+		if o.Update.DiskSize != nil || o.Update.Disks != nil {
+			// The disk size of the VM after the update replaces its current disk size in the usage
+			totalDiskSize := float64(usage.DiskSize + diskSizeAfterUpdate(vm, o.Update) - vm.TotalDiskSize())
+			if totalDiskSize > quota.DiskSize {
+				return sErrors.NewQuotaExceededError(fmt.Sprintf("Disk size quota exceeded. Current: %f, Quota: %f", totalDiskSize, quota.DiskSize))
+			}
+		}
 	} else if o.CreateSnapshot != nil {
-		// This is reserved for future use when snapshots are implemented
+		// System snapshots, such as those taken by snapshot policies, are not counted in the usage
+		totalSnapshots := usage.Snapshots + 1
+		if totalSnapshots > quota.Snapshots {
+			return sErrors.NewQuotaExceededError(fmt.Sprintf("Snapshot quota exceeded. Current: %d, Quota: %d", totalSnapshots, quota.Snapshots))
+		}
+	}
+
+	return nil
+}
+
+// CheckDiskExpansion checks that the storage classes of the disks grown by an update allow expansion.
+//
+// It returns a sErrors.DiskNotExpandableError if any of them does not.
+func (c *Client) CheckDiskExpansion(id string, dtoVmUpdate *body.VmUpdate) error {
+	makeError := func(err error) error {
+		return fmt.Errorf("failed to check disk expansion for vm %s. details: %w", id, err)
+	}
+
+	if dtoVmUpdate.DiskSize == nil && dtoVmUpdate.Disks == nil {
+		return nil
+	}
+
+	vm, err := c.VM(id, nil)
+	if err != nil {
+		return makeError(err)
+	}
+
+	if vm == nil {
+		return makeError(sErrors.ErrVmNotFound)
+	}
+
+	var storageClasses []string
+	if dtoVmUpdate.DiskSize != nil && *dtoVmUpdate.DiskSize > vm.Specs.DiskSize {
+		storageClasses = append(storageClasses, k8sModels.DefaultVmDiskStorageClass)
+	}
+
+	if dtoVmUpdate.Disks != nil {
+		for _, disk := range *dtoVmUpdate.Disks {
+			current, ok := vm.DiskMap[disk.Name]
+			if !ok {
+				current, ok = vm.DetachedDiskMap[disk.Name]
+			}
+
+			if !ok || disk.Size <= current.Size {
+				continue
+			}
+
+			storageClass := k8sModels.DefaultVmDiskStorageClass
+			if current.StorageClass != nil {
+				storageClass = *current.StorageClass
+			}
+
+			if !slices.Contains(storageClasses, storageClass) {
+				storageClasses = append(storageClasses, storageClass)
+			}
+		}
+	}
+
+	for _, storageClass := range storageClasses {
+		expandable, err := c.K8s().StorageClassAllowsExpansion(id, storageClass)
+		if err != nil {
+			return makeError(err)
+		}
+
+		if !expandable {
+			return sErrors.NewDiskNotExpandableError(storageClass)
+		}
 	}
 
 	return nil
@@ -691,3 +896,93 @@ func (c *Client) markAccessedIfOwner(vm *model.VM, vrc *vm_repo.Client) {
 		_ = vrc.MarkAccessed(vm.ID)
 	}
 }
+
+// onlySnapshotPolicies returns whether an update only changes the snapshot policies of a VM.
+func onlySnapshotPolicies(params *model.VmUpdateParams) bool {
+	return params.SnapshotPolicyMap != nil &&
+		params.CloudInit == nil &&
+		params.Name == nil &&
+		params.OwnerID == nil &&
+		params.PortMap == nil &&
+		params.CpuCores == nil &&
+		params.RAM == nil &&
+		params.DiskSize == nil &&
+		params.DiskMap == nil &&
+		params.NeverStale == nil
+}
+
+// onlyRootDiskSize returns whether an update only changes the root disk size of a VM, possibly along with its
+// snapshot policies or cloud-init.
+func onlyRootDiskSize(params *model.VmUpdateParams) bool {
+	return params.DiskSize != nil &&
+		params.Name == nil &&
+		params.OwnerID == nil &&
+		params.PortMap == nil &&
+		params.CpuCores == nil &&
+		params.RAM == nil &&
+		params.DiskMap == nil &&
+		params.NeverStale == nil
+}
+
+// diskSizeAfterUpdate returns the total disk size of a VM once an update is applied.
+// Data disks in the update replace the current ones.
+func diskSizeAfterUpdate(vm *model.VM, update *body.VmUpdate) int {
+	diskSize := vm.Specs.DiskSize
+	if update.DiskSize != nil {
+		diskSize = *update.DiskSize
+	}
+
+	if update.Disks != nil {
+		diskNames := make([]string, 0, len(*update.Disks))
+		for _, disk := range *update.Disks {
+			diskSize += disk.Size
+			diskNames = append(diskNames, disk.Name)
+		}
+
+		for _, disk := range detachedDisksAfterUpdate(vm, diskNames) {
+			diskSize += disk.Size
+		}
+	} else {
+		for _, disk := range vm.DiskMap {
+			diskSize += disk.Size
+		}
+
+		for _, disk := range vm.DetachedDiskMap {
+			diskSize += disk.Size
+		}
+	}
+
+	return diskSize
+}
+
+// detachedDisksAfterUpdate returns the detached data disks of a VM once its data disks are replaced by the given ones.
+// Disks that are left out are detached, and detached disks that are added again are attached.
+func detachedDisksAfterUpdate(vm *model.VM, diskNames []string) map[string]model.VmDisk {
+	detachedDiskMap := make(map[string]model.VmDisk)
+	for name, disk := range vm.DetachedDiskMap {
+		if !slices.Contains(diskNames, name) {
+			detachedDiskMap[name] = disk
+		}
+	}
+
+	for name, disk := range vm.DiskMap {
+		if !slices.Contains(diskNames, name) {
+			detachedDiskMap[name] = disk
+		}
+	}
+
+	return detachedDiskMap
+}
+
+// onlyCloudInit returns whether an update only changes the cloud-init of a VM, possibly along with its snapshot policies.
+func onlyCloudInit(params *model.VmUpdateParams) bool {
+	return params.CloudInit != nil &&
+		params.Name == nil &&
+		params.OwnerID == nil &&
+		params.PortMap == nil &&
+		params.CpuCores == nil &&
+		params.RAM == nil &&
+		params.DiskSize == nil &&
+		params.DiskMap == nil &&
+		params.NeverStale == nil
+}
@@ -0,0 +1,90 @@
+package vms
+
+import (
+	"testing"
+
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/models/model"
+)
+
+func TestDiskSizeAfterUpdate(t *testing.T) {
+	// 20 GB root disk, 15 GB of data disks and an 8 GB disk that was detached earlier
+	vm := &model.VM{
+		Specs: model.VmSpecs{DiskSize: 20},
+		DiskMap: map[string]model.VmDisk{
+			"data":    {Name: "data", Size: 10},
+			"scratch": {Name: "scratch", Size: 5},
+		},
+		DetachedDiskMap: map[string]model.VmDisk{
+			"old": {Name: "old", Size: 8},
+		},
+	}
+
+	// The user has another VM using 30 GB
+	usage := 30 + vm.TotalDiskSize()
+
+	intPtr := func(i int) *int { return &i }
+
+	tests := []struct {
+		name          string
+		update        body.VmUpdate
+		expectedUsage int
+	}{
+		{
+			name:          "root disk grows",
+			update:        body.VmUpdate{DiskSize: intPtr(40)},
+			expectedUsage: 30 + 40 + 15 + 8,
+		},
+		{
+			name:          "data disk grows",
+			update:        body.VmUpdate{Disks: &[]body.VmDisk{{Name: "data", Size: 25}, {Name: "scratch", Size: 5}}},
+			expectedUsage: 30 + 20 + 30 + 8,
+		},
+		{
+			name:          "data disks are replaced and the old ones are kept",
+			update:        body.VmUpdate{Disks: &[]body.VmDisk{{Name: "new", Size: 50}}},
+			expectedUsage: 30 + 20 + 50 + 15 + 8,
+		},
+		{
+			name:          "data disks are detached and kept",
+			update:        body.VmUpdate{DiskSize: intPtr(30), Disks: &[]body.VmDisk{}},
+			expectedUsage: 30 + 30 + 15 + 8,
+		},
+		{
+			name:          "detached disk is attached again",
+			update:        body.VmUpdate{Disks: &[]body.VmDisk{{Name: "data", Size: 10}, {Name: "scratch", Size: 5}, {Name: "old", Size: 8}}},
+			expectedUsage: 30 + 20 + 15 + 8,
+		},
+		{
+			name:          "nothing changes",
+			update:        body.VmUpdate{DiskSize: intPtr(20)},
+			expectedUsage: 30 + 20 + 15 + 8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := usage + diskSizeAfterUpdate(vm, &tt.update) - vm.TotalDiskSize()
+			if got != tt.expectedUsage {
+				t.Errorf("expected disk usage %d, got %d", tt.expectedUsage, got)
+			}
+		})
+	}
+}
+
+func TestOnlyRootDiskSize(t *testing.T) {
+	diskSize := 40
+	cpuCores := 4
+
+	if !onlyRootDiskSize(&model.VmUpdateParams{DiskSize: &diskSize}) {
+		t.Error("expected an update of only the root disk size to be detected")
+	}
+
+	if onlyRootDiskSize(&model.VmUpdateParams{DiskSize: &diskSize, CpuCores: &cpuCores}) {
+		t.Error("expected an update that also changes cpu cores to need a restart")
+	}
+
+	if onlyRootDiskSize(&model.VmUpdateParams{DiskSize: &diskSize, DiskMap: &map[string]model.VmDisk{}}) {
+		t.Error("expected an update that also changes data disks to need a restart")
+	}
+}
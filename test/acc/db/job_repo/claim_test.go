@@ -0,0 +1,120 @@
+package job_repo
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/job_repo"
+	"github.com/kthcloud/go-deploy/pkg/jobs/utils"
+	"github.com/kthcloud/go-deploy/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimInCreationOrder(t *testing.T) {
+	jobType := withJobType(t)
+
+	first := withJob(t, jobType, nil)
+	second := withJob(t, jobType, nil)
+
+	claimed := claimNext(t, jobType)
+	if assert.NotNil(t, claimed, "no job was claimed") {
+		assert.Equal(t, first.ID, claimed.ID, "oldest job was not claimed first")
+		assert.Equal(t, model.JobStatusRunning, claimed.Status, "claimed job is not running")
+		assert.Equal(t, "acc", claimed.WorkerID, "claimed job has the wrong worker")
+	}
+
+	claimed = claimNext(t, jobType)
+	if assert.NotNil(t, claimed, "no job was claimed") {
+		assert.Equal(t, second.ID, claimed.ID, "second job was not claimed")
+	}
+
+	assert.Nil(t, claimNext(t, jobType), "a job was claimed twice")
+}
+
+func TestClaimWaitsForResource(t *testing.T) {
+	jobType := withJobType(t)
+	args := map[string]interface{}{"id": uuid.NewString()}
+
+	first := withJob(t, jobType, args)
+	second := withJob(t, jobType, args)
+
+	claimed := claimNext(t, jobType)
+	if assert.NotNil(t, claimed, "no job was claimed") {
+		assert.Equal(t, first.ID, claimed.ID, "oldest job was not claimed first")
+	}
+
+	assert.Nil(t, claimNext(t, jobType), "job was claimed while its resource was busy")
+
+	err := job_repo.New().MarkCompleted(first.ID)
+	test.NoError(t, err, "failed to mark job as completed")
+
+	claimed = claimNext(t, jobType)
+	if assert.NotNil(t, claimed, "job was not claimed after its resource was released") {
+		assert.Equal(t, second.ID, claimed.ID, "second job was not claimed")
+	}
+}
+
+func TestClaimSkipsBusyResource(t *testing.T) {
+	jobType := withJobType(t)
+	busyArgs := map[string]interface{}{"id": uuid.NewString()}
+
+	// More jobs than are fetched at a time, so that the other resource is not on the first page
+	for i := 0; i < 25; i++ {
+		withJob(t, jobType, busyArgs)
+	}
+	other := withJob(t, jobType, map[string]interface{}{"id": uuid.NewString()})
+
+	claimed := claimNext(t, jobType)
+	if assert.NotNil(t, claimed, "no job was claimed") {
+		assert.Equal(t, busyArgs["id"], claimed.Args["id"], "oldest job was not claimed first")
+	}
+
+	claimed = claimNext(t, jobType)
+	if assert.NotNil(t, claimed, "job for another resource was starved by the busy resource") {
+		assert.Equal(t, other.ID, claimed.ID, "job for another resource was not claimed")
+	}
+}
+
+func TestCancelledJobHoldsResource(t *testing.T) {
+	jobType := withJobType(t)
+	args := map[string]interface{}{"id": uuid.NewString()}
+
+	first := withJob(t, jobType, args)
+	second := withJob(t, jobType, args)
+
+	claimed := claimNext(t, jobType)
+	if !assert.NotNil(t, claimed, "no job was claimed") {
+		return
+	}
+
+	cancelled, err := job_repo.New().MarkCancelled(first.ID, "acc")
+	test.NoError(t, err, "failed to cancel job")
+	assert.True(t, cancelled, "job was not cancelled")
+
+	assert.Nil(t, claimNext(t, jobType), "job was claimed while the cancelled job was still running")
+
+	err = job_repo.New().ReleaseLease(first.ID, "acc")
+	test.NoError(t, err, "failed to release lease")
+
+	claimed = claimNext(t, jobType)
+	if assert.NotNil(t, claimed, "job was not claimed after the cancelled job stopped") {
+		assert.Equal(t, second.ID, claimed.ID, "second job was not claimed")
+	}
+}
+
+func TestDeleteCancelsRelatedJobs(t *testing.T) {
+	jobType := withJobType(t)
+	args := map[string]interface{}{"id": uuid.NewString()}
+
+	deleteJob := withJob(t, jobType, args)
+	related := withJob(t, jobType, args)
+	unrelated := withJob(t, jobType, map[string]interface{}{"id": uuid.NewString()})
+
+	err := utils.CancelRelatedJobs(deleteJob, args["id"].(string))
+	test.NoError(t, err, "failed to cancel related jobs")
+
+	assert.Equal(t, model.JobStatusPending, getJob(t, deleteJob.ID).Status, "delete job was cancelled")
+	assert.Equal(t, model.JobStatusCancelled, getJob(t, related.ID).Status, "related job was not cancelled")
+	assert.Equal(t, model.JobStatusPending, getJob(t, unrelated.ID).Status, "unrelated job was cancelled")
+}
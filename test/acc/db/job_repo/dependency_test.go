@@ -0,0 +1,67 @@
+package job_repo
+
+import (
+	"testing"
+
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/job_repo"
+	"github.com/kthcloud/go-deploy/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependentWaitsForDependency(t *testing.T) {
+	jobType := withJobType(t)
+
+	dependency := withJob(t, jobType, nil)
+	dependent := withJob(t, jobType, nil, dependency.ID)
+
+	claimed := claimNext(t, jobType)
+	if assert.NotNil(t, claimed, "no job was claimed") {
+		assert.Equal(t, dependency.ID, claimed.ID, "dependency was not claimed first")
+	}
+
+	assert.Nil(t, claimNext(t, jobType), "dependent was claimed before its dependency completed")
+
+	err := job_repo.New().MarkCompleted(dependency.ID)
+	test.NoError(t, err, "failed to mark dependency as completed")
+
+	assert.Empty(t, getJob(t, dependent.ID).WaitingFor, "dependent is still waiting for its dependency")
+
+	claimed = claimNext(t, jobType)
+	if assert.NotNil(t, claimed, "dependent was not claimed after its dependency completed") {
+		assert.Equal(t, dependent.ID, claimed.ID, "dependent was not claimed")
+	}
+}
+
+func TestDependentTerminatedWithDependency(t *testing.T) {
+	jobType := withJobType(t)
+
+	dependency := withJob(t, jobType, nil)
+	dependent := withJob(t, jobType, nil, dependency.ID)
+	transitive := withJob(t, jobType, nil, dependent.ID)
+
+	err := job_repo.New().MarkTerminated(dependency.ID, "acc")
+	test.NoError(t, err, "failed to mark dependency as terminated")
+
+	assert.Equal(t, model.JobStatusTerminated, getJob(t, dependent.ID).Status, "dependent was not terminated")
+	assert.Equal(t, model.JobStatusTerminated, getJob(t, transitive.ID).Status, "transitive dependent was not terminated")
+	assert.Nil(t, claimNext(t, jobType), "a terminated job was claimed")
+}
+
+func TestDependencyAlreadyFinished(t *testing.T) {
+	jobType := withJobType(t)
+
+	completed := withJob(t, jobType, nil)
+	err := job_repo.New().MarkCompleted(completed.ID)
+	test.NoError(t, err, "failed to mark job as completed")
+
+	terminated := withJob(t, jobType, nil)
+	err = job_repo.New().MarkTerminated(terminated.ID, "acc")
+	test.NoError(t, err, "failed to mark job as terminated")
+
+	released := withJob(t, jobType, nil, completed.ID)
+	assert.Empty(t, getJob(t, released.ID).WaitingFor, "job is waiting for a completed dependency")
+
+	failed := withJob(t, jobType, nil, terminated.ID)
+	assert.Equal(t, model.JobStatusTerminated, getJob(t, failed.ID).Status, "job with a terminated dependency was not terminated")
+}
@@ -0,0 +1,63 @@
+package job_repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kthcloud/go-deploy/models/model"
+	"github.com/kthcloud/go-deploy/models/version"
+	"github.com/kthcloud/go-deploy/pkg/db/resources/job_repo"
+	"github.com/kthcloud/go-deploy/test"
+	"github.com/kthcloud/go-deploy/test/acc"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const leaseDuration = time.Minute
+
+// withJobType returns a job type that is unique to the test, so that jobs from other tests are never claimed.
+func withJobType(t *testing.T) string {
+	jobType := acc.GenName()
+	t.Cleanup(func() {
+		_, err := job_repo.New().Collection.DeleteMany(context.TODO(), bson.D{{Key: "type", Value: jobType}})
+		test.NoError(t, err, "failed to clean up jobs")
+	})
+
+	return jobType
+}
+
+func withJob(t *testing.T, jobType string, args map[string]interface{}, dependsOn ...string) *model.Job {
+	id := uuid.NewString()
+
+	var err error
+	if len(dependsOn) > 0 {
+		err = job_repo.New().CreateWithDependencies(id, "acc", jobType, version.V2, dependsOn, args)
+	} else {
+		err = job_repo.New().Create(id, "acc", jobType, version.V2, args)
+	}
+	test.NoError(t, err, "failed to create job")
+
+	// Jobs are ordered by creation time, so make sure no two jobs get the same timestamp
+	time.Sleep(10 * time.Millisecond)
+
+	return getJob(t, id)
+}
+
+func getJob(t *testing.T, id string) *model.Job {
+	job, err := job_repo.New().GetByID(id)
+	test.NoError(t, err, "failed to get job")
+	if job == nil {
+		t.Fatalf("job %s not found", id)
+	}
+
+	return job
+}
+
+// claimNext claims the next job of the given type, and returns nil if there is none.
+func claimNext(t *testing.T, jobType string) *model.Job {
+	job, err := job_repo.New().IncludeTypes(jobType).GetNext("acc", leaseDuration)
+	test.NoError(t, err, "failed to claim next job")
+
+	return job
+}
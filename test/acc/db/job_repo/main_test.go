@@ -0,0 +1,21 @@
+package job_repo
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/kthcloud/go-deploy/pkg/db"
+	"github.com/kthcloud/go-deploy/test/acc"
+)
+
+func TestMain(m *testing.M) {
+	acc.Setup()
+	if err := db.Setup(); err != nil {
+		log.Fatalf("Failed to set up database: %v", err)
+	}
+	code := m.Run()
+	db.Shutdown()
+	acc.Shutdown()
+	os.Exit(code)
+}
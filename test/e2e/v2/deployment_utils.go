@@ -49,6 +49,16 @@ func ListDeployments(t *testing.T, query string, user ...string) []body.Deployme
 	return e2e.MustParse[[]body.DeploymentRead](t, resp)
 }
 
+func ListDeploymentRevisions(t *testing.T, id string, user ...string) []body.DeploymentRevisionRead {
+	resp := e2e.DoGetRequest(t, DeploymentPath+id+"/revisions", user...)
+	return e2e.MustParse[[]body.DeploymentRevisionRead](t, resp)
+}
+
+func ListDeploymentLogs(t *testing.T, id string, query string, user ...string) []body.DeploymentLogRead {
+	resp := e2e.DoGetRequest(t, DeploymentPath+id+"/logs"+query, user...)
+	return e2e.MustParse[[]body.DeploymentLogRead](t, resp)
+}
+
 func UpdateDeployment(t *testing.T, id string, requestBody body.DeploymentUpdate, user ...string) body.DeploymentRead {
 	resp := e2e.DoPostRequest(t, DeploymentPath+id, requestBody, user...)
 	deploymentUpdated := e2e.MustParse[body.DeploymentUpdated](t, resp)
@@ -8,6 +8,7 @@ import (
 	"github.com/kthcloud/go-deploy/test/e2e/v2"
 	"github.com/stretchr/testify/assert"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"testing"
@@ -90,6 +91,49 @@ func TestCreateWithCustomImage(t *testing.T) {
 	v2.WithDeployment(t, requestBody)
 }
 
+func TestCreateWithGit(t *testing.T) {
+	t.Parallel()
+
+	requestBody := body.DeploymentCreate{
+		Name: e2e.GenName(),
+		Git: &body.DeploymentGit{
+			URL: "https://github.com/kthcloud/go-deploy.git",
+		},
+	}
+
+	// The build is not waited for, since it depends on the repository being buildable
+	resp := e2e.DoPostRequest(t, v2.DeploymentsPath, requestBody)
+	deploymentCreated := e2e.MustParse[body.DeploymentCreated](t, resp)
+	t.Cleanup(func() {
+		v2.CleanUpDeployment(t, deploymentCreated.ID)
+	})
+
+	v2.WaitForJobFinished(t, deploymentCreated.JobID, nil)
+
+	deploymentRead := v2.GetDeployment(t, deploymentCreated.ID)
+	assert.Equal(t, model.DeploymentTypeGit, deploymentRead.Type)
+	if assert.NotNil(t, deploymentRead.Git) {
+		assert.Equal(t, requestBody.Git.URL, deploymentRead.Git.URL)
+		assert.Equal(t, model.DefaultGitBranch, deploymentRead.Git.Branch)
+		assert.Equal(t, model.DefaultDockerfilePath, deploymentRead.Git.DockerfilePath)
+	}
+
+	reqBody := body.DeploymentCommand{Command: "build"}
+	resp = e2e.DoPostRequest(t, v2.DeploymentPath+deploymentCreated.ID+"/command", reqBody)
+	commandCreated := e2e.MustParse[body.DeploymentCommandCreated](t, resp)
+	assert.NotEmpty(t, commandCreated.JobID)
+}
+
+func TestBuildNotGit(t *testing.T) {
+	t.Parallel()
+
+	deployment, _ := v2.WithDeployment(t, body.DeploymentCreate{Name: e2e.GenName()})
+
+	reqBody := body.DeploymentCommand{Command: "build"}
+	resp := e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/command", reqBody)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
 func TestCreateWithCustomDomain(t *testing.T) {
 	t.Parallel()
 
@@ -232,6 +276,152 @@ func TestCreateWithCustomSpecs(t *testing.T) {
 	assert.Greater(t, d.Specs.RAM, 1.0, "ram was not set")
 }
 
+func TestCreateWithSidecars(t *testing.T) {
+	t.Parallel()
+
+	requestBody := body.DeploymentCreate{
+		Name: e2e.GenName(),
+		Sidecars: []body.Sidecar{
+			{
+				Name:          "cache",
+				Image:         "redis:latest",
+				InternalPorts: []int{6379},
+			},
+		},
+	}
+
+	d, _ := v2.WithDeployment(t, requestBody)
+
+	assert.Len(t, d.Sidecars, 1, "sidecar was not created")
+	assert.Equal(t, "cache", d.Sidecars[0].Name, "sidecar name mismatch")
+	assert.Equal(t, []int{6379}, d.Sidecars[0].InternalPorts, "sidecar ports mismatch")
+}
+
+func TestCreateWithAutoscaling(t *testing.T) {
+	t.Parallel()
+
+	minReplicas := 2
+	maxReplicas := 4
+	cpuTarget := 60
+
+	d, _ := v2.WithDeployment(t, body.DeploymentCreate{
+		Name: e2e.GenName(),
+		Autoscaling: &body.Autoscaling{
+			MinReplicas: &minReplicas,
+			MaxReplicas: &maxReplicas,
+			CpuTarget:   &cpuTarget,
+		},
+	})
+
+	assert.NotNil(t, d.Autoscaling, "autoscaling was not returned")
+	assert.True(t, d.Autoscaling.Enabled, "autoscaling was not enabled")
+	assert.Equal(t, minReplicas, d.Autoscaling.MinReplicas, "min replicas mismatch")
+	assert.Equal(t, maxReplicas, d.Autoscaling.MaxReplicas, "max replicas mismatch")
+	assert.Equal(t, cpuTarget, d.Autoscaling.CpuTarget, "cpu target mismatch")
+}
+
+func TestCreateWithAutoscalingTooBig(t *testing.T) {
+	t.Parallel()
+
+	// Fetch the quota for the user
+	quota := v2.GetUser(t, model.TestPowerUserID).Quota
+
+	// Quota should be checked against the max replicas, not the initial replicas
+	cpuCores := quota.CpuCores / 2
+	replicas := 1
+	maxReplicas := 3
+
+	v2.WithAssumedFailedDeployment(t, body.DeploymentCreate{
+		Name:        e2e.GenName(),
+		Replicas:    &replicas,
+		CpuCores:    &cpuCores,
+		Autoscaling: &body.Autoscaling{MaxReplicas: &maxReplicas},
+	}, e2e.PowerUser)
+}
+
+func TestCreateWithAutoUpdate(t *testing.T) {
+	t.Parallel()
+
+	image := "nginx:1.25"
+	versionRange := "1.x"
+
+	d, _ := v2.WithDeployment(t, body.DeploymentCreate{
+		Name:  e2e.GenName(),
+		Image: &image,
+		Envs:  []body.Env{{Name: "PORT", Value: "80"}},
+		AutoUpdate: &body.AutoUpdate{
+			Policy: model.AutoUpdatePolicySemver,
+			Range:  &versionRange,
+		},
+	})
+
+	assert.NotNil(t, d.AutoUpdate, "auto-update was not returned")
+	assert.Equal(t, model.AutoUpdatePolicySemver, d.AutoUpdate.Policy, "auto-update policy mismatch")
+	assert.Equal(t, versionRange, *d.AutoUpdate.Range, "auto-update range mismatch")
+
+	none := body.AutoUpdate{Policy: model.AutoUpdatePolicyNone}
+	d = v2.UpdateDeployment(t, d.ID, body.DeploymentUpdate{AutoUpdate: &none})
+	assert.Nil(t, d.AutoUpdate, "auto-update was not removed")
+}
+
+func TestCreateWithInvalidAutoUpdate(t *testing.T) {
+	t.Parallel()
+
+	image := "nginx"
+	invalidRange := "latest"
+
+	v2.WithAssumedFailedDeployment(t, body.DeploymentCreate{
+		Name:       e2e.GenName(),
+		Image:      &image,
+		AutoUpdate: &body.AutoUpdate{Policy: model.AutoUpdatePolicySemver},
+	})
+
+	v2.WithAssumedFailedDeployment(t, body.DeploymentCreate{
+		Name:       e2e.GenName(),
+		Image:      &image,
+		AutoUpdate: &body.AutoUpdate{Policy: model.AutoUpdatePolicySemver, Range: &invalidRange},
+	})
+}
+
+func TestCreateWithSchedule(t *testing.T) {
+	t.Parallel()
+
+	timezone := "Europe/Stockholm"
+	windows := []body.ScalingWindow{
+		{Start: "0 22 * * *", End: "0 7 * * *", Replicas: 0},
+		{Start: "0 0 * * 6", End: "0 0 * * 1", Replicas: 0},
+	}
+
+	d, _ := v2.WithDeployment(t, body.DeploymentCreate{
+		Name:     e2e.GenName(),
+		Schedule: &body.ScalingSchedule{Windows: windows, Timezone: &timezone},
+	})
+
+	if assert.NotNil(t, d.Schedule, "schedule was not returned") {
+		assert.Equal(t, windows, d.Schedule.Windows, "schedule windows mismatch")
+		assert.Equal(t, timezone, d.Schedule.Timezone, "schedule timezone mismatch")
+	}
+
+	d = v2.UpdateDeployment(t, d.ID, body.DeploymentUpdate{Schedule: &body.ScalingSchedule{Windows: []body.ScalingWindow{}}})
+	assert.Nil(t, d.Schedule, "schedule was not removed")
+}
+
+func TestCreateWithInvalidSchedule(t *testing.T) {
+	t.Parallel()
+
+	invalidTimezone := "Mars/Olympus_Mons"
+
+	v2.WithAssumedFailedDeployment(t, body.DeploymentCreate{
+		Name:     e2e.GenName(),
+		Schedule: &body.ScalingSchedule{Windows: []body.ScalingWindow{{Start: "0 25 * * *", End: "0 7 * * *"}}},
+	})
+
+	v2.WithAssumedFailedDeployment(t, body.DeploymentCreate{
+		Name:     e2e.GenName(),
+		Schedule: &body.ScalingSchedule{Windows: []body.ScalingWindow{{Start: "0 22 * * *", End: "0 7 * * *"}}, Timezone: &invalidTimezone},
+	})
+}
+
 func TestUpdate(t *testing.T) {
 	t.Parallel()
 
@@ -336,9 +526,9 @@ func TestCommand(t *testing.T) {
 	for _, command := range commands {
 		reqBody := body.DeploymentCommand{Command: command}
 		resp := e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/command", reqBody)
-		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		commandCreated := e2e.MustParse[body.DeploymentCommandCreated](t, resp)
 
-		time.Sleep(3 * time.Second)
+		v2.WaitForJobFinished(t, commandCreated.JobID, nil)
 
 		v2.WaitForDeploymentRunning(t, deployment.ID, func(deploymentRead *body.DeploymentRead) bool {
 			//make sure it is accessible
@@ -350,6 +540,129 @@ func TestCommand(t *testing.T) {
 	}
 }
 
+func TestPauseResume(t *testing.T) {
+	t.Parallel()
+
+	replicas := 2
+	deployment, _ := v2.WithDeployment(t, body.DeploymentCreate{Name: e2e.GenName(), Replicas: &replicas})
+
+	resp := e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/command", body.DeploymentCommand{Command: "pause"})
+	commandCreated := e2e.MustParse[body.DeploymentCommandCreated](t, resp)
+	v2.WaitForJobFinished(t, commandCreated.JobID, nil)
+
+	paused := v2.GetDeployment(t, deployment.ID)
+	assert.True(t, paused.Paused, "deployment was not paused")
+	assert.Equal(t, replicas, paused.Specs.Replicas, "replicas were lost when pausing")
+
+	// Pausing twice should fail
+	resp = e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/command", body.DeploymentCommand{Command: "pause"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/command", body.DeploymentCommand{Command: "resume"})
+	commandCreated = e2e.MustParse[body.DeploymentCommandCreated](t, resp)
+	v2.WaitForJobFinished(t, commandCreated.JobID, nil)
+
+	resumed := v2.GetDeployment(t, deployment.ID)
+	assert.False(t, resumed.Paused, "deployment was not resumed")
+	assert.Equal(t, replicas, resumed.Specs.Replicas, "replicas were not restored when resuming")
+}
+
+func TestScale(t *testing.T) {
+	t.Parallel()
+
+	deployment, _ := v2.WithDeployment(t, body.DeploymentCreate{Name: e2e.GenName()})
+
+	replicas := 2
+	duration := 10
+	resp := e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/command", body.DeploymentCommand{Command: "scale", Replicas: &replicas, DurationMinutes: &duration})
+	commandCreated := e2e.MustParse[body.DeploymentCommandCreated](t, resp)
+	v2.WaitForJobFinished(t, commandCreated.JobID, nil)
+
+	scaled := v2.GetDeployment(t, deployment.ID)
+	if assert.NotNil(t, scaled.ScaleOverride, "deployment was not scaled") {
+		assert.Equal(t, replicas, scaled.ScaleOverride.Replicas)
+	}
+
+	// Scaling requires a duration
+	resp = e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/command", body.DeploymentCommand{Command: "scale", Replicas: &replicas})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestRollout(t *testing.T) {
+	t.Parallel()
+
+	image := "nginx:1.25"
+	deployment, _ := v2.WithDeployment(t, body.DeploymentCreate{
+		Name:     e2e.GenName(),
+		Image:    &image,
+		Envs:     []body.Env{{Name: "PORT", Value: "80"}},
+		Strategy: &body.RolloutStrategy{Type: model.RolloutStrategyBlueGreen},
+	})
+
+	assert.Equal(t, model.RolloutStrategyBlueGreen, deployment.Strategy.Type, "strategy mismatch")
+
+	// Promoting without an active rollout should fail
+	resp := e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/command", body.DeploymentCommand{Command: "promote"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	newImage := "nginx:1.26"
+	updated := v2.UpdateDeployment(t, deployment.ID, body.DeploymentUpdate{Image: &newImage})
+	if assert.NotNil(t, updated.Rollout, "update did not start a rollout") {
+		assert.Equal(t, model.RolloutStrategyBlueGreen, updated.Rollout.Strategy)
+	}
+
+	resp = e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/command", body.DeploymentCommand{Command: "abort"})
+	commandCreated := e2e.MustParse[body.DeploymentCommandCreated](t, resp)
+	v2.WaitForJobFinished(t, commandCreated.JobID, nil)
+
+	aborted := v2.GetDeployment(t, deployment.ID)
+	assert.Nil(t, aborted.Rollout, "rollout was not aborted")
+	assert.Equal(t, image, *aborted.Image, "image was not restored when aborting")
+
+	updated = v2.UpdateDeployment(t, deployment.ID, body.DeploymentUpdate{Image: &newImage})
+	assert.NotNil(t, updated.Rollout, "update did not start a rollout")
+
+	resp = e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/command", body.DeploymentCommand{Command: "promote"})
+	commandCreated = e2e.MustParse[body.DeploymentCommandCreated](t, resp)
+	v2.WaitForJobFinished(t, commandCreated.JobID, nil)
+
+	promoted := v2.GetDeployment(t, deployment.ID)
+	assert.Nil(t, promoted.Rollout, "rollout was not promoted")
+	assert.Equal(t, newImage, *promoted.Image, "image was not kept when promoting")
+}
+
+func TestRollback(t *testing.T) {
+	t.Parallel()
+
+	deployment, _ := v2.WithDeployment(t, body.DeploymentCreate{
+		Name: e2e.GenName(),
+		Envs: []body.Env{{Name: "e2e", Value: "first"}},
+	})
+
+	v2.UpdateDeployment(t, deployment.ID, body.DeploymentUpdate{
+		Envs: &[]body.Env{{Name: "e2e", Value: "second"}},
+	})
+
+	revisions := v2.ListDeploymentRevisions(t, deployment.ID)
+	assert.Len(t, revisions, 2, "expected one revision for the create and one for the update")
+	assert.Equal(t, 2, revisions[0].Version, "revisions are not sorted with the latest first")
+
+	revision := 1
+	resp := e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/command", body.DeploymentCommand{Command: "rollback", Revision: &revision})
+	commandCreated := e2e.MustParse[body.DeploymentCommandCreated](t, resp)
+	assert.NotEmpty(t, commandCreated.JobID, "rollback did not return a job")
+
+	v2.WaitForJobFinished(t, commandCreated.JobID, nil)
+
+	rolledBack := v2.GetDeployment(t, deployment.ID)
+	assert.Contains(t, rolledBack.Envs, body.Env{Name: "e2e", Value: "first"}, "env was not rolled back")
+
+	// Rolling back to a revision that does not exist should fail
+	revision = 1000
+	resp = e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/command", body.DeploymentCommand{Command: "rollback", Revision: &revision})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
 func TestInvalidCommand(t *testing.T) {
 	t.Parallel()
 
@@ -364,6 +677,65 @@ func TestInvalidCommand(t *testing.T) {
 	}
 }
 
+func TestImageWebhook(t *testing.T) {
+	t.Parallel()
+
+	image := "nginx:latest"
+	deployment, _ := v2.WithDeployment(t, body.DeploymentCreate{
+		Name:  e2e.GenName(),
+		Image: &image,
+		Envs: []body.Env{
+			{
+				Name:  "PORT",
+				Value: "80",
+			},
+		},
+	})
+
+	resp := e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/imageWebhook", body.ImageWebhookCreate{})
+	imageWebhook := e2e.MustParse[body.ImageWebhookCreated](t, resp)
+	assert.Empty(t, imageWebhook.Secret, "secret should only be created for webhooks requiring signatures")
+
+	webhookURL, err := url.Parse(imageWebhook.URL)
+	assert.NoError(t, err, "webhook url could not be parsed")
+	token := webhookURL.Query().Get("token")
+	assert.NotEmpty(t, token)
+
+	hookPath := "/v2/hooks/images/" + deployment.ID + "?token="
+
+	// Invalid token
+	resp = e2e.DoPostRequest(t, hookPath+"invalid", body.ImageWebhook{Image: "nginx:latest"})
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Another image
+	resp = e2e.DoPostRequest(t, hookPath+token, body.ImageWebhook{Image: "ghcr.io/kthcloud/other:latest"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// Push of the current tag restarts the deployment
+	resp = e2e.DoPostRequest(t, hookPath+token, body.ImageWebhook{Image: "docker.io/library/nginx:latest"})
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	deploymentRead := v2.GetDeployment(t, deployment.ID)
+	if assert.NotNil(t, deploymentRead.ImageWebhook) {
+		assert.False(t, deploymentRead.ImageWebhook.RequireSignature)
+	}
+
+	resp = e2e.DoDeleteRequest(t, v2.DeploymentPath+deployment.ID+"/imageWebhook")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp = e2e.DoPostRequest(t, hookPath+token, body.ImageWebhook{Image: "nginx:latest"})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestImageWebhookCustom(t *testing.T) {
+	t.Parallel()
+
+	deployment, _ := v2.WithDeployment(t, body.DeploymentCreate{Name: e2e.GenName()})
+
+	resp := e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/imageWebhook", body.ImageWebhookCreate{})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
 func TestFetchCiConfig(t *testing.T) {
 	t.Parallel()
 
@@ -387,6 +759,22 @@ func TestFetchCiConfig(t *testing.T) {
 	err := e2e.ReadResponseBody(t, resp, &ciConfig)
 	assert.NoError(t, err, "ci config was not fetched")
 	assert.NotEmpty(t, ciConfig.Config)
+	assert.Equal(t, model.CiFormatGithub, ciConfig.Format)
+
+	for _, format := range []string{model.CiFormatGitlab, model.CiFormatForgejo, model.CiFormatShell} {
+		resp = e2e.DoGetRequest(t, v2.DeploymentPath+deploymentCustom.ID+"/ciConfig?format="+format+"&branch=dev&tag=sha")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		ciConfig = body.CiConfig{}
+		err = e2e.ReadResponseBody(t, resp, &ciConfig)
+		assert.NoError(t, err, "ci config was not fetched")
+		assert.Equal(t, format, ciConfig.Format)
+		assert.NotEmpty(t, ciConfig.Filename)
+		assert.Contains(t, ciConfig.Config, "dev")
+	}
+
+	resp = e2e.DoGetRequest(t, v2.DeploymentPath+deploymentCustom.ID+"/ciConfig?format=jenkins")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
 	// Not ci config for prebuilt deployments
 	resp = e2e.DoGetRequest(t, v2.DeploymentPath+deploymentPrebuilt.ID+"/ciConfig")
@@ -402,3 +790,52 @@ func TestFetchLogs(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
 }
+
+func TestListLogs(t *testing.T) {
+	t.Parallel()
+
+	deployment, _ := v2.WithDeployment(t, body.DeploymentCreate{Name: e2e.GenName()})
+
+	resp := e2e.DoPostRequest(t, v2.DeploymentPath+deployment.ID+"/command", body.DeploymentCommand{Command: "restart"})
+	commandCreated := e2e.MustParse[body.DeploymentCommandCreated](t, resp)
+	v2.WaitForJobFinished(t, commandCreated.JobID, nil)
+
+	// Logs are added in the background, so they might not be available right away
+	e2e.FetchUntil(t, v2.DeploymentPath+deployment.ID+"/logs?source=deployment&search=restart+REQUESTED", func(resp *http.Response) bool {
+		logs := e2e.MustParse[[]body.DeploymentLogRead](t, resp)
+		return len(logs) > 0
+	})
+
+	logs := v2.ListDeploymentLogs(t, deployment.ID, "?source=build")
+	for _, l := range logs {
+		assert.Equal(t, "build", l.Source, "logs were not filtered by source")
+	}
+
+	logs = v2.ListDeploymentLogs(t, deployment.ID, "?since="+url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339)))
+	assert.Empty(t, logs, "logs were not filtered by time")
+
+	resp = e2e.DoGetRequest(t, v2.DeploymentPath+deployment.ID+"/logs?format=ndjson")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "attachment")
+
+	resp = e2e.DoGetRequest(t, v2.DeploymentPath+deployment.ID+"/logs?format=xml")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestExec(t *testing.T) {
+	t.Parallel()
+
+	deployment, _ := v2.WithDeployment(t, body.DeploymentCreate{Name: e2e.GenName()})
+
+	// A plain request must be rejected, since exec requires a WebSocket upgrade
+	resp := e2e.DoGetRequest(t, v2.DeploymentPath+deployment.ID+"/exec")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestExecNotFound(t *testing.T) {
+	t.Parallel()
+
+	resp := e2e.DoGetRequest(t, v2.DeploymentPath+uuid.NewString()+"/exec")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
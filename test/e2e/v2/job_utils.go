@@ -55,7 +55,9 @@ func WaitForJobFinished(t *testing.T, id string, callback func(*body.JobRead) bo
 	e2e.FetchUntil(t, JobPath+id, func(resp *http.Response) bool {
 		jobRead := e2e.MustParse[body.JobRead](t, resp)
 
-		if jobRead.Status == status_codes.GetMsg(status_codes.JobFinished) || jobRead.Status == status_codes.GetMsg(status_codes.JobTerminated) {
+		if jobRead.Status == status_codes.GetMsg(status_codes.JobFinished) ||
+			jobRead.Status == status_codes.GetMsg(status_codes.JobTerminated) ||
+			jobRead.Status == status_codes.GetMsg(status_codes.JobCancelled) {
 			if callback == nil || callback(&jobRead) {
 				return true
 			}
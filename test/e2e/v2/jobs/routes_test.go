@@ -6,6 +6,7 @@ import (
 	"github.com/kthcloud/go-deploy/test/e2e"
 	"github.com/kthcloud/go-deploy/test/e2e/v2"
 	"github.com/stretchr/testify/assert"
+	"net/http"
 	"os"
 	"testing"
 )
@@ -62,3 +63,17 @@ func TestUpdate(t *testing.T) {
 
 	v2.UpdateJob(t, jobID, body.JobUpdate{Status: &terminatedStatus}, e2e.AdminUser)
 }
+
+func TestCancelFinished(t *testing.T) {
+	t.Parallel()
+
+	// Owners are allowed to cancel their own jobs, but only as long as they are not finished
+
+	_, jobID := v2.WithDeployment(t, body.DeploymentCreate{Name: e2e.GenName()})
+	v2.WaitForJobFinished(t, jobID, nil)
+
+	cancelledStatus := model.JobStatusCancelled
+
+	resp := e2e.DoPostRequest(t, v2.JobPath+jobID, body.JobUpdate{Status: &cancelledStatus})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "finished job was cancelled")
+}
@@ -0,0 +1,50 @@
+package vm_images
+
+import (
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/test/e2e"
+	v2 "github.com/kthcloud/go-deploy/test/e2e/v2"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	if e2e.VmTestsEnabled {
+		e2e.Setup()
+		code := m.Run()
+		e2e.Shutdown()
+		os.Exit(code)
+	}
+}
+
+func TestListVmImages(t *testing.T) {
+	t.Parallel()
+
+	queries := []string{
+		"?page=1&pageSize=10",
+	}
+
+	for _, query := range queries {
+		images := v2.ListVmImages(t, query)
+		for _, image := range images {
+			assert.Equal(t, image, v2.GetVmImage(t, image.ID))
+		}
+	}
+}
+
+func TestCreateVmImageAsNonAdmin(t *testing.T) {
+	t.Parallel()
+
+	requestBody := body.VmImageCreate{
+		ID:          e2e.GenName(),
+		DisplayName: "Test image",
+		URL:         "https://cloud-images.ubuntu.com/noble/current/noble-server-cloudimg-amd64.img",
+		DefaultUser: "ubuntu",
+		MinDiskSize: 10,
+	}
+
+	resp := e2e.DoPostRequest(t, v2.VmImagesPath, requestBody, e2e.DefaultUser)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,22 @@
+package v2
+
+import (
+	"github.com/kthcloud/go-deploy/dto/v2/body"
+	"github.com/kthcloud/go-deploy/test/e2e"
+	"testing"
+)
+
+const (
+	VmImagePath  = "/v2/vmImages/"
+	VmImagesPath = "/v2/vmImages"
+)
+
+func GetVmImage(t *testing.T, id string, userID ...string) body.VmImageRead {
+	resp := e2e.DoGetRequest(t, VmImagePath+id, userID...)
+	return e2e.MustParse[body.VmImageRead](t, resp)
+}
+
+func ListVmImages(t *testing.T, query string, userID ...string) []body.VmImageRead {
+	resp := e2e.DoGetRequest(t, VmImagesPath+query, userID...)
+	return e2e.MustParse[[]body.VmImageRead](t, resp)
+}
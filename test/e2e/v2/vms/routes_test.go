@@ -59,6 +59,106 @@ func TestCreate(t *testing.T) {
 	v2.WithVM(t, requestBody)
 }
 
+func TestCreateWithSnapshotPolicies(t *testing.T) {
+	//t.Parallel()
+
+	timezone := "Europe/Stockholm"
+	requestBody := body.VmCreate{
+		Name:         e2e.GenName(),
+		SshPublicKey: v2.WithSshPublicKey(t),
+		CpuCores:     2,
+		RAM:          2,
+		DiskSize:     20,
+		SnapshotPolicies: []body.VmSnapshotPolicy{
+			{Name: "daily", Schedule: "0 3 * * *", Retention: 7},
+			{Name: "weekly", Schedule: "0 3 * * 0", Retention: 4, Timezone: &timezone},
+		},
+	}
+
+	vm := v2.WithVM(t, requestBody)
+
+	assert.Len(t, vm.SnapshotPolicies, 2)
+	for _, policy := range vm.SnapshotPolicies {
+		assert.NotNil(t, policy.NextRunAt)
+		switch policy.Name {
+		case "daily":
+			assert.Equal(t, 7, policy.Retention)
+			assert.Equal(t, model.DefaultScheduleTimezone, policy.Timezone)
+		case "weekly":
+			assert.Equal(t, 4, policy.Retention)
+			assert.Equal(t, timezone, policy.Timezone)
+		default:
+			t.Errorf("unexpected snapshot policy %s", policy.Name)
+		}
+	}
+}
+
+func TestCreateWithInvalidSnapshotPolicies(t *testing.T) {
+	//t.Parallel()
+
+	invalidPolicies := [][]body.VmSnapshotPolicy{
+		{{Name: "daily", Schedule: "0 3 * *", Retention: 7}},
+		{{Name: "daily", Schedule: "0 3 * * *", Retention: 0}},
+		{{Name: "Daily!", Schedule: "0 3 * * *", Retention: 7}},
+		{{Name: "daily", Schedule: "0 3 * * *", Retention: 7}, {Name: "daily", Schedule: "0 4 * * *", Retention: 7}},
+	}
+
+	for _, policies := range invalidPolicies {
+		requestBody := body.VmCreate{
+			Name:             e2e.GenName(),
+			SshPublicKey:     v2.WithSshPublicKey(t),
+			CpuCores:         2,
+			RAM:              2,
+			DiskSize:         20,
+			SnapshotPolicies: policies,
+		}
+
+		resp := e2e.DoPostRequest(t, v2.VmsPath, requestBody)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestCreateWithInvalidImage(t *testing.T) {
+	//t.Parallel()
+
+	requestBody := body.VmCreate{
+		Name:         e2e.GenName(),
+		SshPublicKey: v2.WithSshPublicKey(t),
+		CpuCores:     2,
+		RAM:          2,
+		DiskSize:     20,
+		Image:        e2e.StrPtr("non-existing-image"),
+	}
+
+	resp := e2e.DoPostRequest(t, v2.VmsPath, requestBody)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestCreateWithInvalidCloudInit(t *testing.T) {
+	//t.Parallel()
+
+	invalidCloudInits := []body.VmCloudInit{
+		{Packages: []string{"--allow-unauthenticated"}},
+		{WriteFiles: []body.VmCloudInitFile{{Path: "relative/path", Content: "hello"}}},
+		{Users: []body.VmCloudInitUser{{Name: "alice"}, {Name: "alice"}}},
+		{Users: []body.VmCloudInitUser{{Name: "alice", SshAuthorizedKeys: []string{"not-a-key"}}}},
+	}
+
+	for _, cloudInit := range invalidCloudInits {
+		requestBody := body.VmCreate{
+			Name:         e2e.GenName(),
+			SshPublicKey: v2.WithSshPublicKey(t),
+			CpuCores:     2,
+			RAM:          2,
+			DiskSize:     20,
+			CloudInit:    &cloudInit,
+		}
+
+		resp := e2e.DoPostRequest(t, v2.VmsPath, requestBody)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
 func TestCreateWithInvalidBody(t *testing.T) {
 	//t.Parallel()
 
@@ -245,6 +345,38 @@ func TestUpdate(t *testing.T) {
 	// TODO: Make sure the VM actually has the new specs (e.g. by running a command over SSH)
 }
 
+func TestUpdateWithInvalidSnapshot(t *testing.T) {
+	//t.Parallel()
+
+	vm := v2.WithDefaultVM(t)
+
+	snapshotID := "vm-snapshot-" + uuid.NewString()
+	resp := e2e.DoPostRequest(t, v2.VmPath+vm.ID, body.VmUpdate{SnapshotID: &snapshotID})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	updatedCpuCores := 2
+	resp = e2e.DoPostRequest(t, v2.VmPath+vm.ID, body.VmUpdate{SnapshotID: &snapshotID, CpuCores: &updatedCpuCores})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestUpdateWithInvalidDisks(t *testing.T) {
+	//t.Parallel()
+
+	vm := v2.WithDefaultVM(t)
+
+	smallerDiskSize := vm.Specs.DiskSize - 1
+	resp := e2e.DoPostRequest(t, v2.VmPath+vm.ID, body.VmUpdate{DiskSize: &smallerDiskSize})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	duplicateDisks := []body.VmDisk{{Name: "data", Size: 1}, {Name: "data", Size: 2}}
+	resp = e2e.DoPostRequest(t, v2.VmPath+vm.ID, body.VmUpdate{Disks: &duplicateDisks})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	unknownStorageClass := []body.VmDisk{{Name: "data", Size: 1, StorageClass: e2e.StrPtr("non-existing-class")}}
+	resp = e2e.DoPostRequest(t, v2.VmPath+vm.ID, body.VmUpdate{Disks: &unknownStorageClass})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
 func TestCreateShared(t *testing.T) {
 	//t.Parallel()
 
@@ -297,3 +429,38 @@ func TestInvalidAction(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	}
 }
+
+func TestConsole(t *testing.T) {
+	//t.Parallel()
+
+	vm := v2.WithDefaultVM(t)
+
+	resp := e2e.DoGetRequest(t, v2.VmPath+vm.ID+"/console-sse")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+}
+
+func TestConsoleNotFound(t *testing.T) {
+	//t.Parallel()
+
+	resp := e2e.DoGetRequest(t, v2.VmPath+uuid.NewString()+"/console-sse")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestListAndGetSnapshots(t *testing.T) {
+	//t.Parallel()
+
+	vm := v2.WithDefaultVM(t)
+
+	resp := e2e.DoGetRequest(t, v2.VmPath+vm.ID+"/snapshots")
+	snapshots := e2e.MustParse[[]body.VmSnapshotRead](t, resp)
+
+	for _, snapshot := range snapshots {
+		resp = e2e.DoGetRequest(t, v2.VmPath+vm.ID+"/snapshots/"+snapshot.ID)
+		read := e2e.MustParse[body.VmSnapshotRead](t, resp)
+		assert.Equal(t, snapshot.ID, read.ID)
+	}
+
+	resp = e2e.DoGetRequest(t, v2.VmPath+vm.ID+"/snapshots/vm-snapshot-"+uuid.NewString())
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
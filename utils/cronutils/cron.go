@@ -0,0 +1,209 @@
+package cronutils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearch is how far Next and Prev look for a matching time.
+// Expressions that never match, such as 0 0 31 2 *, have no next or previous time within it.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// Schedule is a parsed cron expression with the five standard fields: minute, hour, day of month, month and day of week.
+type Schedule struct {
+	minutes  []bool
+	hours    []bool
+	days     []bool
+	months   []bool
+	weekdays []bool
+
+	// restricted days of month and weekdays are combined with OR, as in standard cron
+	daysRestricted     bool
+	weekdaysRestricted bool
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	// 7 is also Sunday
+	{name: "day of week", min: 0, max: 7},
+}
+
+// Parse parses a cron expression, such as "0 22 * * *" or "30 7 * * 1-5".
+//
+// Each field supports *, single values, ranges such as 1-5, lists such as 1,3,5 and steps such as */15 or 0-30/10.
+// Day of week is 0-7, where both 0 and 7 are Sunday.
+func Parse(expression string) (*Schedule, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("invalid cron expression %s. expected %d fields, got %d", expression, len(fields), len(parts))
+	}
+
+	sets := make([][]bool, len(fields))
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %s. details: %w", expression, err)
+		}
+
+		sets[i] = set
+	}
+
+	// Sunday can be given as both 0 and 7
+	sets[4][0] = sets[4][0] || sets[4][7]
+
+	return &Schedule{
+		minutes:            sets[0],
+		hours:              sets[1],
+		days:               sets[2],
+		months:             sets[3],
+		weekdays:           sets[4][:7],
+		daysRestricted:     parts[2] != "*",
+		weekdaysRestricted: parts[4] != "*",
+	}, nil
+}
+
+// Valid returns true if the expression can be parsed by Parse.
+func Valid(expression string) bool {
+	_, err := Parse(expression)
+	return err == nil
+}
+
+// Next returns the first time after t that matches the schedule, in the location of t.
+// It returns the zero time if the schedule does not match within five years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	// Start at the next whole minute, since the schedule has minute precision
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+
+	for next.Before(limit) {
+		switch {
+		case !s.months[next.Month()]:
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !s.matchesDay(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case !s.hours[next.Hour()]:
+			// Truncate works on absolute time, so it would not find the whole hour in zones with a half-hour offset
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+		case !s.minutes[next.Minute()]:
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+
+	return time.Time{}
+}
+
+// Prev returns the last time at or before t that matches the schedule, in the location of t.
+// It returns the zero time if the schedule has not matched within five years.
+func (s *Schedule) Prev(t time.Time) time.Time {
+	prev := t.Truncate(time.Minute)
+	limit := t.Add(-maxSearch)
+
+	for prev.After(limit) {
+		switch {
+		case !s.months[prev.Month()]:
+			// The last minute of the previous month
+			prev = time.Date(prev.Year(), prev.Month(), 1, 0, 0, 0, 0, prev.Location()).Add(-time.Minute)
+		case !s.matchesDay(prev):
+			prev = time.Date(prev.Year(), prev.Month(), prev.Day(), 0, 0, 0, 0, prev.Location()).Add(-time.Minute)
+		case !s.hours[prev.Hour()]:
+			// The last minute of the previous hour
+			prev = time.Date(prev.Year(), prev.Month(), prev.Day(), prev.Hour(), 0, 0, 0, prev.Location()).Add(-time.Minute)
+		case !s.minutes[prev.Minute()]:
+			prev = prev.Add(-time.Minute)
+		default:
+			return prev
+		}
+	}
+
+	return time.Time{}
+}
+
+// matchesDay returns true if the day of t matches the day of month and day of week fields.
+func (s *Schedule) matchesDay(t time.Time) bool {
+	day := s.days[t.Day()]
+	weekday := s.weekdays[t.Weekday()]
+
+	if s.daysRestricted && s.weekdaysRestricted {
+		return day || weekday
+	}
+
+	return day && weekday
+}
+
+// parseField parses a single field of a cron expression into the set of values it matches.
+func parseField(part string, f field) ([]bool, error) {
+	set := make([]bool, f.max+1)
+
+	for _, item := range strings.Split(part, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step %s in %s field", stepPart, f.name)
+			}
+		}
+
+		var lower, upper int
+		switch {
+		case rangePart == "*":
+			lower, upper = f.min, f.max
+		case strings.Contains(rangePart, "-"):
+			lowerPart, upperPart, _ := strings.Cut(rangePart, "-")
+
+			var err error
+			if lower, err = parseValue(lowerPart, f); err != nil {
+				return nil, err
+			}
+
+			if upper, err = parseValue(upperPart, f); err != nil {
+				return nil, err
+			}
+
+			if lower > upper {
+				return nil, fmt.Errorf("invalid range %s in %s field", rangePart, f.name)
+			}
+		default:
+			value, err := parseValue(rangePart, f)
+			if err != nil {
+				return nil, err
+			}
+
+			// A single value with a step, such as 5/15, runs from the value to the end of the field
+			lower, upper = value, value
+			if hasStep {
+				upper = f.max
+			}
+		}
+
+		for value := lower; value <= upper; value += step {
+			set[value] = true
+		}
+	}
+
+	return set, nil
+}
+
+// parseValue parses a single number in a field and checks that it is within the bounds of the field.
+func parseValue(s string, f field) (int, error) {
+	value, err := strconv.Atoi(s)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("invalid value %s in %s field. must be between %d and %d", s, f.name, f.min, f.max)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,118 @@
+package cronutils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// Saturday
+	from := time.Date(2026, 10, 17, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		expected   time.Time
+	}{
+		{expression: "* * * * *", expected: time.Date(2026, 10, 17, 12, 31, 0, 0, time.UTC)},
+		{expression: "0 22 * * *", expected: time.Date(2026, 10, 17, 22, 0, 0, 0, time.UTC)},
+		{expression: "0 7 * * *", expected: time.Date(2026, 10, 18, 7, 0, 0, 0, time.UTC)},
+		{expression: "*/15 * * * *", expected: time.Date(2026, 10, 17, 12, 45, 0, 0, time.UTC)},
+		{expression: "0 0 * * 1", expected: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{expression: "0 0 * * 7", expected: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{expression: "30 7 * * 1-5", expected: time.Date(2026, 10, 19, 7, 30, 0, 0, time.UTC)},
+		{expression: "0 12 1 * *", expected: time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)},
+		{expression: "0 0 1 1 *", expected: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Day of month and day of week are combined with OR when both are restricted
+		{expression: "0 0 20 * 1", expected: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			s, err := Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("Parse(%s) failed: %v", tt.expression, err)
+			}
+
+			if got := s.Next(from); !got.Equal(tt.expected) {
+				t.Errorf("Next() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPrev(t *testing.T) {
+	// Saturday
+	from := time.Date(2026, 10, 17, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		expected   time.Time
+	}{
+		{expression: "* * * * *", expected: time.Date(2026, 10, 17, 12, 30, 0, 0, time.UTC)},
+		{expression: "0 22 * * *", expected: time.Date(2026, 10, 16, 22, 0, 0, 0, time.UTC)},
+		{expression: "0 7 * * *", expected: time.Date(2026, 10, 17, 7, 0, 0, 0, time.UTC)},
+		{expression: "*/20 * * * *", expected: time.Date(2026, 10, 17, 12, 20, 0, 0, time.UTC)},
+		{expression: "30 7 * * 1-5", expected: time.Date(2026, 10, 16, 7, 30, 0, 0, time.UTC)},
+		{expression: "0 0 1 1 *", expected: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{expression: "59 23 31 * *", expected: time.Date(2026, 8, 31, 23, 59, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			s, err := Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("Parse(%s) failed: %v", tt.expression, err)
+			}
+
+			if got := s.Prev(from); !got.Equal(tt.expected) {
+				t.Errorf("Prev() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNextNeverMatches(t *testing.T) {
+	s, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next() = %s, want zero time", got)
+	}
+
+	if got := s.Prev(time.Now()); !got.IsZero() {
+		t.Errorf("Prev() = %s, want zero time", got)
+	}
+}
+
+func TestHalfHourOffset(t *testing.T) {
+	// Asia/Kolkata is UTC+5:30, so its whole hours are not whole hours in UTC
+	location, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+
+	s, err := Parse("0 11 * * *")
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	from := time.Date(2026, 10, 17, 10, 45, 0, 0, location)
+
+	if got, expected := s.Next(from), time.Date(2026, 10, 17, 11, 0, 0, 0, location); !got.Equal(expected) {
+		t.Errorf("Next() = %s, want %s", got, expected)
+	}
+
+	if got, expected := s.Prev(from), time.Date(2026, 10, 16, 11, 0, 0, 0, location); !got.Equal(expected) {
+		t.Errorf("Prev() = %s, want %s", got, expected)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *", "* * * * * *"} {
+		if Valid(expression) {
+			t.Errorf("Valid(%q) = true, want false", expression)
+		}
+	}
+}
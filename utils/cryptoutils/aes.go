@@ -0,0 +1,95 @@
+package cryptoutils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// encryptedPrefix is prepended to all encrypted values, as a version marker for the format.
+const encryptedPrefix = "enc:"
+
+// ErrInvalidKey is returned when the key is not a valid AES-256 key.
+var ErrInvalidKey = errors.New("key must be 32 bytes")
+
+// ErrInvalidCiphertext is returned when the ciphertext cannot be decrypted.
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// ParseKey decodes a base64 encoded AES-256 key.
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key. details: %w", err)
+	}
+
+	if len(key) != 32 {
+		return nil, ErrInvalidKey
+	}
+
+	return key, nil
+}
+
+// Encrypt encrypts the plaintext with AES-256-GCM.
+// The result is prefixed and base64 encoded, and contains the nonce followed by the sealed data.
+func Encrypt(key []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce. details: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a ciphertext produced by Encrypt.
+func Decrypt(key []byte, ciphertext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(ciphertext, encryptedPrefix) {
+		return "", ErrInvalidCiphertext
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, encryptedPrefix))
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", ErrInvalidCiphertext
+	}
+
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+
+	return string(plaintext), nil
+}
+
+// newGCM creates an AES-GCM cipher from the key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,44 @@
+package cryptoutils_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/kthcloud/go-deploy/utils/cryptoutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	ciphertext, err := cryptoutils.Encrypt(key, "hunter2")
+	require.NoError(t, err)
+	require.NotContains(t, ciphertext, "hunter2")
+
+	plaintext, err := cryptoutils.Decrypt(key, ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", plaintext)
+
+	// The nonce is random, so the same plaintext should not give the same ciphertext
+	other, err := cryptoutils.Encrypt(key, "hunter2")
+	require.NoError(t, err)
+	require.NotEqual(t, ciphertext, other)
+}
+
+func TestDecryptWithWrongKey(t *testing.T) {
+	ciphertext, err := cryptoutils.Encrypt(bytes.Repeat([]byte{1}, 32), "hunter2")
+	require.NoError(t, err)
+
+	_, err = cryptoutils.Decrypt(bytes.Repeat([]byte{2}, 32), ciphertext)
+	require.ErrorIs(t, err, cryptoutils.ErrInvalidCiphertext)
+}
+
+func TestParseKey(t *testing.T) {
+	key, err := cryptoutils.ParseKey(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	require.NoError(t, err)
+	require.Len(t, key, 32)
+
+	_, err = cryptoutils.ParseKey(base64.StdEncoding.EncodeToString([]byte("too short")))
+	require.ErrorIs(t, err, cryptoutils.ErrInvalidKey)
+}
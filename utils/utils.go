@@ -120,6 +120,15 @@ func NonZeroOrNil(t time.Time) *time.Time {
 	return &t
 }
 
+// NonZeroIntOrNil returns a pointer to an int if the int is not zero, otherwise it returns nil
+func NonZeroIntOrNil(i int) *int {
+	if i == 0 {
+		return nil
+	}
+
+	return &i
+}
+
 const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
 // GenerateSalt generates the salt that can be used when hashing a password
@@ -0,0 +1,205 @@
+package versionutils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SemVer is a release version as used in image tags, such as 1.25.3 or v2.1.
+type SemVer struct {
+	Major uint64
+	Minor uint64
+	Patch uint64
+}
+
+// SemVerRange is a range of versions, with an inclusive lower bound and an optional exclusive upper bound.
+type SemVerRange struct {
+	lower SemVer
+	upper *SemVer
+}
+
+// ParseSemVer parses an image tag as a version.
+// A leading v is allowed, and a missing minor or patch version is zero.
+// Tags with a pre-release or variant suffix, such as 1.25-alpine or 2.0.0-rc1, are not considered versions.
+func ParseSemVer(tag string) (SemVer, bool) {
+	parts := strings.Split(strings.TrimPrefix(tag, "v"), ".")
+	if len(parts) > 3 {
+		return SemVer{}, false
+	}
+
+	var numbers [3]uint64
+	for i, part := range parts {
+		number, ok := parseNumber(part)
+		if !ok {
+			return SemVer{}, false
+		}
+
+		numbers[i] = number
+	}
+
+	return SemVer{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, true
+}
+
+// Compare returns -1, 0 or 1 if the version is lower than, equal to or higher than the other version.
+func (v SemVer) Compare(other SemVer) int {
+	for _, pair := range [][2]uint64{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] < pair[1] {
+			return -1
+		}
+
+		if pair[0] > pair[1] {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func (v SemVer) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// ParseSemVerRange parses a version range.
+//
+// The following forms are supported:
+//   - "*" or "x" matches any version
+//   - "1", "1.x" or "1.x.x" matches any 1.y.z version
+//   - "1.2" or "1.2.x" matches any 1.2.z version
+//   - "1.2.3" matches only 1.2.3
+//   - "^1.2.3" matches 1.2.3 and later versions without a breaking change, i.e., below 2.0.0, or below 0.3.0 for 0.2.3
+//   - "~1.2.3" matches 1.2.3 and later patch versions, i.e., below 1.3.0
+func ParseSemVerRange(r string) (*SemVerRange, error) {
+	r = strings.TrimSpace(r)
+
+	makeError := func() error {
+		return fmt.Errorf("invalid version range %s", r)
+	}
+
+	if r == "*" || r == "x" || r == "X" {
+		return &SemVerRange{}, nil
+	}
+
+	if strings.HasPrefix(r, "^") || strings.HasPrefix(r, "~") {
+		base, ok := ParseSemVer(r[1:])
+		if !ok {
+			return nil, makeError()
+		}
+
+		var upper SemVer
+		switch {
+		case r[0] == '~':
+			upper = SemVer{Major: base.Major, Minor: base.Minor + 1}
+		case base.Major > 0:
+			upper = SemVer{Major: base.Major + 1}
+		default:
+			upper = SemVer{Minor: base.Minor + 1}
+		}
+
+		return &SemVerRange{lower: base, upper: &upper}, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r, "v"), ".")
+	if len(parts) > 3 {
+		return nil, makeError()
+	}
+
+	// The range is all versions that start with the given numbers, so the last given number is incremented for the upper bound
+	var numbers []uint64
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			// Wildcards must be trailing, e.g. 1.x.2 is not a valid range
+			for _, rest := range parts[i:] {
+				if rest != "x" && rest != "X" && rest != "*" {
+					return nil, makeError()
+				}
+			}
+			break
+		}
+
+		number, ok := parseNumber(part)
+		if !ok {
+			return nil, makeError()
+		}
+
+		numbers = append(numbers, number)
+	}
+
+	if len(numbers) == 0 {
+		return &SemVerRange{}, nil
+	}
+
+	var lower, upper SemVer
+	switch len(numbers) {
+	case 1:
+		lower = SemVer{Major: numbers[0]}
+		upper = SemVer{Major: numbers[0] + 1}
+	case 2:
+		lower = SemVer{Major: numbers[0], Minor: numbers[1]}
+		upper = SemVer{Major: numbers[0], Minor: numbers[1] + 1}
+	default:
+		lower = SemVer{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}
+		upper = SemVer{Major: numbers[0], Minor: numbers[1], Patch: numbers[2] + 1}
+	}
+
+	return &SemVerRange{lower: lower, upper: &upper}, nil
+}
+
+// ValidSemVerRange returns true if the range can be parsed by ParseSemVerRange.
+func ValidSemVerRange(r string) bool {
+	_, err := ParseSemVerRange(r)
+	return err == nil
+}
+
+// Contains returns true if the version is in the range.
+func (r *SemVerRange) Contains(v SemVer) bool {
+	return v.Compare(r.lower) >= 0 && (r.upper == nil || v.Compare(*r.upper) < 0)
+}
+
+// HighestInRange returns the tag with the highest version in the range.
+// Tags that are not versions are ignored. If several tags have the same version, such as 1.2 and 1.2.0,
+// the most specific one is returned, since less specific tags usually move to later versions.
+// It returns false if no tag is in the range.
+func (r *SemVerRange) HighestInRange(tags []string) (string, bool) {
+	var best string
+	var bestVersion SemVer
+	found := false
+
+	for _, tag := range tags {
+		version, ok := ParseSemVer(tag)
+		if !ok || !r.Contains(version) {
+			continue
+		}
+
+		if !found {
+			best, bestVersion, found = tag, version, true
+			continue
+		}
+
+		switch cmp := version.Compare(bestVersion); {
+		case cmp > 0, cmp == 0 && specificity(tag) > specificity(best):
+			best, bestVersion = tag, version
+		}
+	}
+
+	return best, found
+}
+
+// specificity returns how many version numbers a tag contains.
+func specificity(tag string) int {
+	return strings.Count(tag, ".") + 1
+}
+
+// parseNumber parses a version number, which must be a non-empty string of digits.
+func parseNumber(s string) (uint64, bool) {
+	if s == "" || strings.TrimLeft(s, "0123456789") != "" {
+		return 0, false
+	}
+
+	number, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return number, true
+}
@@ -0,0 +1,118 @@
+package versionutils
+
+import (
+	"testing"
+)
+
+func TestParseSemVer(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected SemVer
+		ok       bool
+	}{
+		{tag: "1.25.3", expected: SemVer{1, 25, 3}, ok: true},
+		{tag: "v2.1", expected: SemVer{2, 1, 0}, ok: true},
+		{tag: "7", expected: SemVer{7, 0, 0}, ok: true},
+		{tag: "latest", ok: false},
+		{tag: "1.25-alpine", ok: false},
+		{tag: "2.0.0-rc1", ok: false},
+		{tag: "1.2.3.4", ok: false},
+		{tag: "1..2", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, ok := ParseSemVer(tt.tag)
+			if ok != tt.ok {
+				t.Fatalf("ParseSemVer(%s) ok = %v, want %v", tt.tag, ok, tt.ok)
+			}
+
+			if ok && got != tt.expected {
+				t.Errorf("ParseSemVer(%s) = %s, want %s", tt.tag, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSemVerRangeContains(t *testing.T) {
+	tests := []struct {
+		name     string
+		r        string
+		version  SemVer
+		expected bool
+	}{
+		{name: "Any version", r: "*", version: SemVer{3, 1, 4}, expected: true},
+		{name: "Major range", r: "1.x", version: SemVer{1, 9, 2}, expected: true},
+		{name: "Major range excludes next major", r: "1.x", version: SemVer{2, 0, 0}, expected: false},
+		{name: "Major range without wildcard", r: "1", version: SemVer{1, 0, 0}, expected: true},
+		{name: "Minor range", r: "1.2.x", version: SemVer{1, 2, 9}, expected: true},
+		{name: "Minor range excludes next minor", r: "1.2", version: SemVer{1, 3, 0}, expected: false},
+		{name: "Exact version", r: "1.2.3", version: SemVer{1, 2, 3}, expected: true},
+		{name: "Exact version excludes next patch", r: "1.2.3", version: SemVer{1, 2, 4}, expected: false},
+		{name: "Caret", r: "^1.2.3", version: SemVer{1, 9, 0}, expected: true},
+		{name: "Caret excludes lower", r: "^1.2.3", version: SemVer{1, 2, 2}, expected: false},
+		{name: "Caret excludes next major", r: "^1.2.3", version: SemVer{2, 0, 0}, expected: false},
+		{name: "Caret on zero major", r: "^0.2.3", version: SemVer{0, 3, 0}, expected: false},
+		{name: "Tilde", r: "~1.2.3", version: SemVer{1, 2, 7}, expected: true},
+		{name: "Tilde excludes next minor", r: "~1.2.3", version: SemVer{1, 3, 0}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseSemVerRange(tt.r)
+			if err != nil {
+				t.Fatalf("ParseSemVerRange(%s) failed: %v", tt.r, err)
+			}
+
+			if got := r.Contains(tt.version); got != tt.expected {
+				t.Errorf("%s.Contains(%s) = %v, want %v", tt.r, tt.version, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseSemVerRangeInvalid(t *testing.T) {
+	for _, r := range []string{"", "latest", "1.x.2", "^1.x", "1.2.3.4", ">=1.2"} {
+		if ValidSemVerRange(r) {
+			t.Errorf("ValidSemVerRange(%q) = true, want false", r)
+		}
+	}
+}
+
+func TestHighestInRange(t *testing.T) {
+	tags := []string{"latest", "1.24.0", "1.25", "1.25.3", "1.25.10-alpine", "1.26.1", "2.0.0", "alpine"}
+
+	tests := []struct {
+		r        string
+		expected string
+		found    bool
+	}{
+		{r: "1.x", expected: "1.26.1", found: true},
+		{r: "1.25.x", expected: "1.25.3", found: true},
+		{r: "*", expected: "2.0.0", found: true},
+		{r: "3.x", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.r, func(t *testing.T) {
+			r, err := ParseSemVerRange(tt.r)
+			if err != nil {
+				t.Fatalf("ParseSemVerRange(%s) failed: %v", tt.r, err)
+			}
+
+			got, found := r.HighestInRange(tags)
+			if found != tt.found || got != tt.expected {
+				t.Errorf("HighestInRange() = %s, %v, want %s, %v", got, found, tt.expected, tt.found)
+			}
+		})
+	}
+}
+
+func TestHighestInRangePrefersSpecificTag(t *testing.T) {
+	r, _ := ParseSemVerRange("1.x")
+
+	got, _ := r.HighestInRange([]string{"1.2", "1.2.0", "1"})
+	if got != "1.2.0" {
+		t.Errorf("HighestInRange() = %s, want 1.2.0", got)
+	}
+}